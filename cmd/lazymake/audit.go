@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rshelekhov/lazymake/internal/audit"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// auditSeverities are the --fail-on thresholds of audit, most severe first
+var auditSeverities = []string{"critical", "warning", "info"}
+
+// auditScanTimeout bounds Makefile discovery; audits scan whole repositories
+const auditScanTimeout = 30 * time.Second
+
+var auditCmd = &cobra.Command{
+	Use:   "audit [dir]",
+	Short: "Check every Makefile in a directory tree for dangerous targets",
+	Long: `Audit discovers all Makefiles under dir (default: the current directory),
+checks every target against the safety rules and reports the findings grouped
+by severity. Exits with status 1 when a finding is at or above --fail-on, or
+when safety checks are disabled and --allow-disabled isn't given.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runAudit,
+}
+
+func init() {
+	addReportFlags(auditCmd, auditSeverities)
+	auditCmd.Flags().Int("max-depth", 0, "Maximum directory depth to search (default: workspace discovery depth)")
+	auditCmd.Flags().Bool("allow-disabled", false, "Succeed with an empty report when safety checks are disabled")
+
+	rootCmd.AddCommand(auditCmd)
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	maxDepth, _ := cmd.Flags().GetInt("max-depth")
+	allowDisabled, _ := cmd.Flags().GetBool("allow-disabled")
+
+	flags, err := readReportFlags(cmd, auditSeverities)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	// A disabled checker finds nothing, which shouldn't pass for a clean audit
+	if !cfg.Safety.Enabled {
+		if !allowDisabled {
+			return errors.New("safety checks are disabled (safety.enabled or LAZYMAKE_SAFETY_ENABLED); pass --allow-disabled to audit anyway")
+		}
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "warning: safety checks are disabled, nothing will be reported")
+	}
+
+	selection, err := safety.SelectProfile(cfg.Safety, safety.DetectEnvironment(root))
+	if err != nil {
+		return err
+	}
+
+	discovery := workspace.DefaultDiscoveryOptions()
+	discovery.Timeout = auditScanTimeout
+	if maxDepth > 0 {
+		discovery.MaxDepth = maxDepth
+	}
+
+	report, err := audit.Run(audit.Options{
+		Root:      root,
+		Discovery: discovery,
+		Safety:    cfg.Safety,
+		Profile:   selection.Profile,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := flags.write(cmd, report.Write); err != nil {
+		return err
+	}
+
+	if flags.never() {
+		return nil
+	}
+	if threshold, _ := safety.ParseSeverity(flags.failOn); report.Exceeds(threshold) {
+		return errSilentExit
+	}
+	return nil
+}
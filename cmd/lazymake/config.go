@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate, inspect and create .lazymake.yaml files",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Check config files for unknown keys and invalid values",
+	Long: `Validate checks config files against the lazymake schema: misspelled or
+unknown keys, values of the wrong type, unsupported options and patterns that
+don't compile are reported with their line.
+
+Without arguments, the global (~/.lazymake.yaml) and project (./.lazymake.yaml)
+files are checked, along with values given by LAZYMAKE_* environment variables
+and flags. Exits with status 1 when a problem is found.`,
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runConfigValidate,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the settings in use and where they come from",
+	Long: `Show prints the merged settings that differ from the defaults, each with
+where it comes from: a config file, a LAZYMAKE_* environment variable or a flag.
+With --effective, every setting in use is printed, defaults included.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runConfigShow,
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a starter .lazymake.yaml",
+	Long: `Init writes a commented starter config to ./.lazymake.yaml (or
+~/.lazymake.yaml with --global). The file points editors at the lazymake
+schema for completion and inline checks.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runConfigInit,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config file locations",
+	Long: `Path prints where the global and project config files are read from and
+whether they exist. With --global or --project, only that path is printed.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runConfigPath,
+}
+
+func init() {
+	configShowCmd.Flags().Bool("effective", false, "Include settings left at their defaults")
+	configInitCmd.Flags().Bool("global", false, "Create ~/.lazymake.yaml instead of ./.lazymake.yaml")
+	configInitCmd.Flags().Bool("force", false, "Replace an existing file")
+	configPathCmd.Flags().Bool("global", false, "Print only the global config path")
+	configPathCmd.Flags().Bool("project", false, "Print only the project config path")
+	configPathCmd.MarkFlagsMutuallyExclusive("global", "project")
+
+	configCmd.AddCommand(configValidateCmd, configShowCmd, configInitCmd, configPathCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	w := cmd.OutOrStdout()
+
+	ok := true
+	files := args
+	if len(files) == 0 {
+		// Values given by LAZYMAKE_* variables and flags are checked too
+		if problems := config.ValidateOverrides(); len(problems) > 0 {
+			ok = false
+			_, _ = fmt.Fprintln(w, "✗ environment and flags")
+			for _, problem := range problems {
+				_, _ = fmt.Fprintf(w, "  %v\n", problem)
+			}
+		}
+
+		files = existingConfigFiles()
+		if len(files) == 0 && ok {
+			_, _ = fmt.Fprintln(w, "No config files found (create one with: lazymake config init)")
+			return nil
+		}
+	}
+
+	for _, file := range files {
+		problems, err := config.ValidateFile(file)
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			_, _ = fmt.Fprintf(w, "✓ %s\n", displayPath(absPath(file)))
+			continue
+		}
+
+		ok = false
+		_, _ = fmt.Fprintf(w, "✗ %s\n", displayPath(absPath(file)))
+		for _, problem := range problems {
+			problem.File = displayPath(absPath(problem.File))
+			_, _ = fmt.Fprintf(w, "  %v\n", problem)
+		}
+	}
+
+	if !ok {
+		return errSilentExit
+	}
+	return nil
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	effective, _ := cmd.Flags().GetBool("effective")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	writeConfigFiles(cmd.OutOrStdout(), "# ")
+	if n := len(cfg.Problems); n > 0 {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "# %d %s found: run lazymake config validate\n", n, pluralize(n, "problem"))
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout())
+
+	writeSettings(cmd.OutOrStdout(), cfg.Effective(), effective)
+	return nil
+}
+
+// writeSettings prints settings as YAML with their origin as a trailing comment
+// Unless all is set, settings at their defaults are left out.
+func writeSettings(w io.Writer, settings []config.Setting, all bool) {
+	section := ""
+	printed := 0
+	for _, setting := range settings {
+		if !all && setting.Origin == config.OriginDefault {
+			continue
+		}
+		printed++
+
+		if setting.Section() == "" {
+			_, _ = fmt.Fprintf(w, "%s: %s  # %s\n", setting.Name(), setting.Value, setting.Origin)
+			section = ""
+			continue
+		}
+		if setting.Section() != section {
+			section = setting.Section()
+			_, _ = fmt.Fprintf(w, "%s:\n", section)
+		}
+		_, _ = fmt.Fprintf(w, "  %s: %s  # %s\n", setting.Name(), setting.Value, setting.Origin)
+	}
+
+	if printed == 0 {
+		_, _ = fmt.Fprintln(w, "# All settings are at their defaults (see --effective)")
+	}
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) error {
+	global, _ := cmd.Flags().GetBool("global")
+	force, _ := cmd.Flags().GetBool("force")
+
+	path := config.ProjectConfigPath()
+	if global {
+		path = config.GlobalConfigPath()
+		if path == "" {
+			return errors.New("can't find the home directory")
+		}
+	}
+
+	if err := config.WriteTemplate(path, force); err != nil {
+		if errors.Is(err, config.ErrConfigExists) {
+			return fmt.Errorf("%w (use --force to replace it)", err)
+		}
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", absPath(path))
+	return nil
+}
+
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	global, _ := cmd.Flags().GetBool("global")
+	project, _ := cmd.Flags().GetBool("project")
+
+	switch {
+	case global:
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), config.GlobalConfigPath())
+	case project:
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), absPath(config.ProjectConfigPath()))
+	default:
+		writeConfigFiles(cmd.OutOrStdout(), "")
+	}
+	return nil
+}
+
+// writeConfigFiles prints the global and project config paths and whether they exist
+func writeConfigFiles(w io.Writer, prefix string) {
+	for _, file := range []struct{ name, path string }{
+		{"global: ", config.GlobalConfigPath()},
+		{"project:", absPath(config.ProjectConfigPath())},
+	} {
+		status := ""
+		if _, err := os.Stat(file.path); err != nil {
+			status = " (not found)"
+		}
+		_, _ = fmt.Fprintf(w, "%s%s %s%s\n", prefix, file.name, file.path, status)
+	}
+}
+
+// pluralize adds an "s" to word unless count is 1
+func pluralize(count int, word string) string {
+	if count == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// existingConfigFiles returns the global and project config files that exist
+func existingConfigFiles() []string {
+	var files []string
+	for _, path := range []string{config.GlobalConfigPath(), config.ProjectConfigPath()} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// absPath makes a path absolute, keeping it as is if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// loadConfig loads the settings for a command and prints config problems to
+// stderr; the settings with invalid values are left out
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, problem := range cfg.Problems {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", problem)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/docs"
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/spf13/cobra"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs [makefile]",
+	Short: "Generate Markdown, HTML or man page documentation from a Makefile",
+	Long: `Docs renders every target of a Makefile (default: the configured Makefile, or
+the one make would read in the current directory) with its description,
+section, dependencies, documented arguments and safety warnings, followed by
+the Makefile's variables. The format is --format, or guessed from the
+--output file's extension (.md, .html, .7).
+
+With --check, nothing is written: docs exits with status 1 when the --output
+file doesn't match what it would generate, so CI can catch stale docs.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runDocs,
+}
+
+func init() {
+	docsCmd.Flags().String("format", "", "Output format: markdown, html or man (default: from --output, else markdown)")
+	docsCmd.Flags().StringP("output", "o", "", "Write the documentation to a file instead of stdout")
+	docsCmd.Flags().Bool("check", false, "Exit non-zero if --output is out of date instead of writing it")
+	docsCmd.Flags().String("title", "", "Page title (default: the Makefile's header comment, else its file name)")
+
+	rootCmd.AddCommand(docsCmd)
+}
+
+func runDocs(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	check, _ := cmd.Flags().GetBool("check")
+	title, _ := cmd.Flags().GetString("title")
+
+	if format == "" {
+		format = docs.FormatForPath(output)
+	}
+	switch strings.ToLower(format) {
+	case "":
+		format = docs.FormatMarkdown
+	case docs.FormatMarkdown, "md", docs.FormatHTML, docs.FormatMan:
+	default:
+		return fmt.Errorf("invalid --format %q: want markdown, html or man", format)
+	}
+	if check && output == "" {
+		return errors.New("--check needs --output: the file to compare against")
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	path := cfg.MakefilePath
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if path == "" {
+		if path, err = makefile.Find("."); err != nil {
+			return err
+		}
+	}
+
+	page, err := docs.Build(path, docs.Options{Title: title, Safety: cfg.Safety})
+	if err != nil {
+		return err
+	}
+
+	var generated bytes.Buffer
+	if err := page.Write(&generated, format); err != nil {
+		return err
+	}
+
+	switch {
+	case check:
+		current, err := os.ReadFile(output)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", output, err)
+		}
+		if !bytes.Equal(current, generated.Bytes()) {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s is out of date with %s; run lazymake docs -o %s to update it\n", output, path, output)
+			return errSilentExit
+		}
+		return nil
+
+	case output != "":
+		if err := os.WriteFile(output, generated.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		return nil
+
+	default:
+		_, err := cmd.OutOrStdout().Write(generated.Bytes())
+		return err
+	}
+}
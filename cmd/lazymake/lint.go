@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/rshelekhov/lazymake/internal/lint"
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/spf13/cobra"
+)
+
+// lintSeverities are the --fail-on thresholds of lint, most severe first
+var lintSeverities = []string{"error", "warning", "info"}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [makefile...]",
+	Short: "Check Makefiles for common mistakes",
+	Long: `Lint checks each Makefile (default: the configured Makefile, or the one make
+would read in the current directory) for missing .PHONY declarations, recipes
+indented with spaces, undefined and unused variables and more. Rule severities
+come from the lint section of .lazymake.yaml. Exits with status 1 when a
+diagnostic is at or above --fail-on.`,
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runLint,
+}
+
+func init() {
+	addReportFlags(lintCmd, lintSeverities)
+	lintCmd.Flags().Bool("list-rules", false, "List the rules with their configured severity and exit")
+
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	listRules, _ := cmd.Flags().GetBool("list-rules")
+
+	flags, err := readReportFlags(cmd, lintSeverities)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if listRules {
+		return writeLintRules(cmd.OutOrStdout(), cfg.Lint)
+	}
+
+	paths := args
+	if len(paths) == 0 {
+		path := cfg.MakefilePath
+		if path == "" {
+			if path, err = makefile.Find("."); err != nil {
+				return err
+			}
+		}
+		paths = []string{path}
+	}
+
+	report := lint.Run(paths, cfg.Lint)
+
+	if err := flags.write(cmd, report.Write); err != nil {
+		return err
+	}
+
+	threshold, _ := lint.ParseSeverity(flags.failOn)
+	if len(report.Errors) > 0 || (!flags.never() && report.Exceeds(threshold)) {
+		return errSilentExit
+	}
+	return nil
+}
+
+// writeLintRules lists the rule catalog with each rule's configured severity
+func writeLintRules(w io.Writer, cfg *lint.Config) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, rule := range lint.Rules() {
+		severity := lint.Off
+		if s, on := cfg.Severity(rule); on {
+			severity = s.String()
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", rule.ID, severity, rule.Description)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the targets of a Makefile for scripts and pickers",
+	Long: `List prints the targets of the configured Makefile (or the one make would read
+in the current directory) in Makefile order, with their descriptions,
+prerequisites, danger level under the active safety profile and recent usage
+from the execution history. Targets marked @hidden are left out unless --all
+is given.
+
+The tsv format has no header and one target per line: name, description,
+prerequisites (space-separated), danger level, last run (RFC 3339) and run
+count, so it can be piped into fzf, cut or awk.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runList,
+}
+
+func init() {
+	listCmd.Flags().String("format", "table", "Output format: table, json or tsv")
+	listCmd.Flags().Bool("all", false, "Include targets marked @hidden")
+
+	rootCmd.AddCommand(listCmd)
+}
+
+// listedTarget is a target as printed by lazymake list
+type listedTarget struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Section      string   `json:"section,omitempty"` // @group, else the "##@" section
+	Dependencies []string `json:"dependencies"`      // Prerequisites that are targets in the Makefile
+	Hidden       bool     `json:"hidden,omitempty"`
+
+	DangerLevel string   `json:"danger_level,omitempty"` // critical, warning or info ("" for a safe target)
+	Rules       []string `json:"rules,omitempty"`        // Matched rule IDs, including prerequisites'
+
+	Recent        int        `json:"recent,omitempty"` // Position in the recent targets (1 = most recent)
+	LastRun       *time.Time `json:"last_run,omitempty"`
+	RunCount      int        `json:"run_count,omitempty"` // Runs kept by performance retention
+	AvgDurationMs int64      `json:"avg_duration_ms,omitempty"`
+}
+
+func runList(cmd *cobra.Command, _ []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	all, _ := cmd.Flags().GetBool("all")
+
+	format = strings.ToLower(format)
+	switch format {
+	case "table", "json", "tsv":
+	default:
+		return fmt.Errorf("invalid --format %q: want table, json or tsv", format)
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadProject(cfg)
+	if err != nil {
+		return err
+	}
+
+	tracker := openTracker(cmd, cfg)
+	defer func() { _ = tracker.Close() }()
+
+	targets := listTargets(p, tracker.History, all)
+
+	w := cmd.OutOrStdout()
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(targets)
+	case "tsv":
+		return writeTargetsTSV(w, targets)
+	default:
+		return writeTargetsTable(w, targets)
+	}
+}
+
+// listTargets collects the runnable targets with their safety results and usage
+// Pattern rules are left out, and hidden targets unless all is set.
+func listTargets(p *project.Project, hist *history.History, all bool) []listedTarget {
+	recent := make(map[string]int)
+	usage := make(map[string]history.Entry)
+	for i, entry := range hist.GetRecent(p.Path) {
+		recent[entry.Name] = i + 1
+		usage[entry.Name] = entry
+	}
+
+	targets := make([]listedTarget, 0, len(p.Targets))
+	seen := make(map[string]bool)
+	for _, t := range p.Targets {
+		if seen[t.Name] || strings.Contains(t.Name, "%") || (t.Docs.Hidden && !all) {
+			continue
+		}
+		seen[t.Name] = true
+
+		listed := listedTarget{
+			Name:         t.Name,
+			Description:  t.Description,
+			Section:      t.Docs.Group,
+			Dependencies: []string{},
+			Hidden:       t.Docs.Hidden,
+			Recent:       recent[t.Name],
+		}
+		if listed.Section == "" {
+			listed.Section = t.Section
+		}
+		if node := p.Graph.Nodes[t.Name]; node != nil {
+			for _, dep := range node.Dependencies {
+				listed.Dependencies = append(listed.Dependencies, dep.Target.Name)
+			}
+		}
+
+		if result := p.Results[t.Name]; result != nil && result.IsDangerous {
+			listed.DangerLevel = strings.ToLower(result.DangerLevel.String())
+			listed.Rules = result.RuleIDs()
+		}
+
+		if entry, ok := usage[t.Name]; ok {
+			lastRun := entry.LastUsed
+			listed.LastRun = &lastRun
+		}
+		// UseCount also counts runs that were only started; the series has one sample per run
+		if trend := hist.GetTrend(p.Path, t.Name); trend != nil {
+			listed.RunCount = trend.Runs
+		}
+		if stats := hist.GetPerformanceStats(p.Path, t.Name); stats != nil {
+			listed.AvgDurationMs = stats.AvgDuration.Milliseconds()
+		}
+
+		targets = append(targets, listed)
+	}
+	return targets
+}
+
+// writeTargetsTable prints the targets as an aligned table
+func writeTargetsTable(w io.Writer, targets []listedTarget) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TARGET\tDANGER\tDEPENDS ON\tLAST RUN\tRUNS\tDESCRIPTION")
+	for _, t := range targets {
+		danger, lastRun, runs := "-", "-", "-"
+		if t.DangerLevel != "" {
+			danger = t.DangerLevel
+		}
+		if t.LastRun != nil {
+			lastRun = t.LastRun.Local().Format("2006-01-02 15:04")
+			runs = strconv.Itoa(t.RunCount)
+		}
+		deps := strings.Join(t.Dependencies, " ")
+		if deps == "" {
+			deps = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", t.Name, danger, deps, lastRun, runs, t.Description)
+	}
+	return tw.Flush()
+}
+
+// writeTargetsTSV prints one tab-separated line per target, without a header
+func writeTargetsTSV(w io.Writer, targets []listedTarget) error {
+	field := strings.NewReplacer("\t", " ", "\n", " ")
+	for _, t := range targets {
+		lastRun := ""
+		if t.LastRun != nil {
+			lastRun = t.LastRun.Format(time.RFC3339)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n",
+			t.Name,
+			field.Replace(t.Description),
+			strings.Join(t.Dependencies, " "),
+			t.DangerLevel,
+			lastRun,
+			t.RunCount,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/testutil"
+	"github.com/rshelekhov/lazymake/internal/tracking"
+)
+
+// TestListCountsOneRun counts a run once, though it's recorded when it starts
+// and again when it finishes
+func TestListCountsOneRun(t *testing.T) {
+	root := t.TempDir()
+	main := testutil.WriteFile(t, filepath.Join(root, "Makefile"), "build:\n\t@true\n")
+
+	t.Chdir(root)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("LAZYMAKE_MAKEFILE", main)
+
+	if err := runRun(runCmd, []string{"build"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := loadProject(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := tracking.Open(cfg)
+	defer func() { _ = tracker.Close() }()
+
+	targets := listTargets(p, tracker.History, false)
+	if len(targets) != 1 || targets[0].LastRun == nil || targets[0].RunCount != 1 {
+		t.Errorf("listTargets() = %+v, want build run once", targets)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -9,7 +10,6 @@ import (
 	"github.com/rshelekhov/lazymake/internal/tui"
 	"github.com/rshelekhov/lazymake/internal/workspace"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var rootCmd = &cobra.Command{
@@ -20,12 +20,9 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.Flags().StringP("file", "f", "Makefile", "Path to Makefile")
-
-	if err := viper.BindPFlag("makefile", rootCmd.Flags().Lookup("file")); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error binding makefile flag: %v\n", err)
-		os.Exit(1)
-	}
+	// -f/--file, --safety-profile and a flag for every other setting that fits on
+	// the command line (--export-format, --safety-enabled=false, ...), for every command
+	config.BindFlags(rootCmd.PersistentFlags())
 }
 
 func run(cmd *cobra.Command, args []string) error {
@@ -46,16 +43,29 @@ func run(cmd *cobra.Command, args []string) error {
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
-	if _, err := p.Run(); err != nil {
-		return err
+	// Finish the session's exports and close its history
+	final, err := p.Run()
+	if final, ok := final.(tui.Model); ok {
+		_ = final.Close()
 	}
-
-	return nil
+	return err
 }
 
+// errSilentExit exits with status 1 without printing an error
+var errSilentExit = errors.New("exit status 1")
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		// run exits with make's exit status
+		var exitCode exitCodeError
+		if errors.As(err, &exitCode) {
+			os.Exit(int(exitCode))
+		}
+
+		// The command has already reported why it failed
+		if !errors.Is(err, errSilentExit) {
+			fmt.Println(err)
+		}
 		os.Exit(1)
 	}
 }
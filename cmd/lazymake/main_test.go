@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestSettingFlags gives every subcommand the flags for every setting
+func TestSettingFlags(t *testing.T) {
+	for _, cmd := range []*cobra.Command{runCmd, listCmd, auditCmd, docsCmd} {
+		for _, name := range []string{"file", "safety-profile", "export-format", "safety-enabled", "storage-backend"} {
+			if cmd.InheritedFlags().Lookup(name) == nil {
+				t.Errorf("%s has no --%s flag", cmd.Name(), name)
+			}
+		}
+	}
+}
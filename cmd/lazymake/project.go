@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/project"
+)
+
+// loadProject loads the configured Makefile (or the one in the current
+// directory) the way the TUI loads it
+func loadProject(cfg *config.Config) (*project.Project, error) {
+	path := cfg.MakefilePath
+	if path == "" {
+		var err error
+		if path, err = makefile.Find("."); err != nil {
+			return nil, err
+		}
+	}
+	return project.Load(path, cfg.Safety)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// reportFormats are the formats lint and audit write their reports in
+var reportFormats = []string{"text", "json", "sarif"}
+
+// reportFlags are the --format, --fail-on and --output flags of lint and audit
+type reportFlags struct {
+	format string
+	failOn string // A severity, or "never"
+	output string // "" = stdout
+}
+
+// addReportFlags registers the report flags on cmd
+// severities are the --fail-on thresholds, most severe first; the first is the default.
+func addReportFlags(cmd *cobra.Command, severities []string) {
+	cmd.Flags().String("format", reportFormats[0], "Report format: "+joinChoices(reportFormats))
+	cmd.Flags().String("fail-on", severities[0], "Exit non-zero at this severity or above: "+joinChoices(append(slices.Clone(severities), "never")))
+	cmd.Flags().StringP("output", "o", "", "Write the report to a file instead of stdout")
+}
+
+// readReportFlags reads the report flags and checks the format and threshold
+func readReportFlags(cmd *cobra.Command, severities []string) (reportFlags, error) {
+	format, _ := cmd.Flags().GetString("format")
+	failOn, _ := cmd.Flags().GetString("fail-on")
+	output, _ := cmd.Flags().GetString("output")
+	flags := reportFlags{format: strings.ToLower(format), failOn: strings.ToLower(failOn), output: output}
+
+	if !slices.Contains(reportFormats, flags.format) {
+		return flags, fmt.Errorf("invalid --format %q: want %s", format, joinChoices(reportFormats))
+	}
+	if !flags.never() && !slices.Contains(severities, flags.failOn) {
+		return flags, fmt.Errorf("invalid --fail-on %q: want %s", failOn, joinChoices(append(slices.Clone(severities), "never")))
+	}
+	return flags, nil
+}
+
+// never reports whether --fail-on never turns the exit status off
+func (f reportFlags) never() bool {
+	return f.failOn == "never"
+}
+
+// write writes a report to --output, or to stdout
+func (f reportFlags) write(cmd *cobra.Command, write func(w io.Writer, format string) error) error {
+	if f.output == "" {
+		return write(cmd.OutOrStdout(), f.format)
+	}
+
+	file, err := os.Create(f.output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", f.output, err)
+	}
+	defer func() { _ = file.Close() }()
+	return write(file, f.format)
+}
+
+// joinChoices lists choices for help and errors: "a, b or c"
+func joinChoices(choices []string) string {
+	if len(choices) < 2 {
+		return strings.Join(choices, "")
+	}
+	return strings.Join(choices[:len(choices)-1], ", ") + " or " + choices[len(choices)-1]
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/spf13/cobra"
+)
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Work with custom safety rules and rule packs",
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test [path...]",
+	Short: "Check custom safety rules against their example commands",
+	Long: `Test loads custom safety rules and runs each rule's tests: the commands
+listed under match must be flagged, those under no_match must not.
+
+Without arguments, the rules from the config files and their rule_paths are
+tested. With arguments, only the given rule pack files or directories are.
+Exits with status 1 when a rule is invalid or an example fails.`,
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runRulesTest,
+}
+
+func init() {
+	rulesCmd.AddCommand(rulesTestCmd)
+	rootCmd.AddCommand(rulesCmd)
+}
+
+func runRulesTest(cmd *cobra.Command, args []string) error {
+	var rules []safety.Rule
+	var loadErrors []safety.RuleError
+
+	if len(args) > 0 {
+		rules, loadErrors = config.LoadRulePacks(absPaths(args))
+	} else {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		rules, loadErrors = cfg.Safety.CustomRules, cfg.Safety.RuleErrors
+	}
+
+	if !writeRuleTests(cmd.OutOrStdout(), rules, loadErrors) {
+		return errSilentExit
+	}
+	return nil
+}
+
+// writeRuleTests tests each rule and prints the results grouped by source file
+// Returns false if any rule failed to load or failed a test.
+func writeRuleTests(w io.Writer, rules []safety.Rule, loadErrors []safety.RuleError) bool {
+	ok := len(loadErrors) == 0
+	examples, failed, untested := 0, 0, 0
+
+	source := "\x00" // No source printed yet
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Source != source {
+			source = rule.Source
+			_, _ = fmt.Fprintf(w, "%s\n", displayPath(source))
+		}
+
+		failures, err := rule.Test()
+		switch {
+		case err != nil:
+			ok = false
+			failed++
+			_, _ = fmt.Fprintf(w, "  ✗ %s: %v\n", rule.ID, err)
+		case rule.Tests.Count() == 0:
+			untested++
+			_, _ = fmt.Fprintf(w, "  - %s (no tests)\n", rule.ID)
+		case len(failures) > 0:
+			ok = false
+			failed++
+			examples += rule.Tests.Count()
+			_, _ = fmt.Fprintf(w, "  ✗ %s\n", rule.ID)
+			for _, failure := range failures {
+				if failure.WantMatch {
+					_, _ = fmt.Fprintf(w, "      should match:     %s\n", failure.Line)
+				} else {
+					_, _ = fmt.Fprintf(w, "      should not match: %s\n", failure.Line)
+				}
+			}
+		default:
+			examples += rule.Tests.Count()
+			_, _ = fmt.Fprintf(w, "  ✓ %s (%d examples)\n", rule.ID, rule.Tests.Count())
+		}
+	}
+
+	if len(loadErrors) > 0 {
+		_, _ = fmt.Fprintln(w)
+		for _, err := range loadErrors {
+			_, _ = fmt.Fprintf(w, "error: %v\n", err)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%d rules, %d examples: %d failed, %d without tests, %d invalid\n",
+		len(rules), examples, failed, untested, len(loadErrors))
+	return ok
+}
+
+// absPaths makes command-line paths absolute, so errors name files unambiguously
+func absPaths(paths []string) []string {
+	result := make([]string, len(paths))
+	for i, path := range paths {
+		result[i] = absPath(path)
+	}
+	return result
+}
+
+// displayPath shortens a path relative to the working directory when it is inside it
+func displayPath(path string) string {
+	if path == "" {
+		return "(config)"
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return path
+}
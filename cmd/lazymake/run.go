@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/executor"
+	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/project"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/tracking"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <target> [VAR=value...]",
+	Short: "Run a target with safety checks, recording it like the TUI does",
+	Long: `Run runs a target of the configured Makefile (or the one make would read in
+the current directory), passing VAR=value arguments on to make. Output is
+streamed to stdout as make prints it, and the run is recorded in the execution
+history, exported and added to the shell history exactly as if it had been
+started from the TUI.
+
+Targets the active safety profile wants confirmed are confirmed on the
+terminal: typing the target or environment name, waiting out a countdown or
+giving a reason, as the profile and rules require. Without a terminal, or
+with $CI set, run refuses such targets unless --yes is given. Exits with
+make's exit status.`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints errors
+	RunE:          runRun,
+}
+
+func init() {
+	runCmd.Flags().BoolP("yes", "y", false, "Run targets that need confirmation without asking")
+	runCmd.Flags().String("reason", "", "Reason for running the target, for targets that require one")
+
+	rootCmd.AddCommand(runCmd)
+}
+
+// exitCodeError exits with make's exit status without printing an error
+type exitCodeError int
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	yes, _ := cmd.Flags().GetBool("yes")
+	reason, _ := cmd.Flags().GetString("reason")
+
+	name, overrides := args[0], args[1:]
+	for _, arg := range overrides {
+		if !strings.Contains(arg, "=") {
+			return fmt.Errorf("invalid argument %q: want VAR=value (run one target at a time)", arg)
+		}
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadProject(cfg)
+	if err != nil {
+		return err
+	}
+	if _, ok := p.Target(name); !ok {
+		return fmt.Errorf("no target %q in %s", name, p.Path)
+	}
+
+	// Variable overrides (e.g. DEST=/) change what the recipe runs, so check it again
+	result := p.Results[name]
+	var confirmation safety.Confirmation
+	if p.Checker != nil {
+		if len(overrides) > 0 {
+			result = p.Checker.WithOverrides(overrides).CheckGraph(p.Graph)[name]
+		}
+		confirmation = p.Checker.Confirmation(name, result)
+	}
+
+	var confirmed *export.Confirmation
+	if p.Profile.ConfirmPolicy().RequiresConfirmation(result, confirmation) {
+		prompt := confirmPrompt{
+			in:  bufio.NewReader(cmd.InOrStdin()),
+			out: cmd.ErrOrStderr(),
+		}
+		if confirmed, err = prompt.confirm(p, name, result, confirmation, yes, reason); err != nil {
+			return err
+		}
+	}
+
+	tracker := openTracker(cmd, cfg)
+	defer func() { _ = tracker.Close() }()
+
+	tracker.Started(p.Path, name)
+	run, interrupted := streamTarget(cmd.OutOrStdout(), name, p.Path, overrides)
+	tracker.Finished(tracking.Run{
+		MakefilePath: p.Path,
+		Target:       name,
+		Args:         overrides,
+		Result:       run,
+		Confirmation: confirmed,
+	})
+
+	switch {
+	case run.Err == nil:
+		return nil
+	case interrupted:
+		return fmt.Errorf("%s was interrupted", name)
+	case run.ExitCode > 0:
+		return exitCodeError(run.ExitCode)
+	default:
+		// make couldn't be started
+		return run.Err
+	}
+}
+
+// openTracker sets up run tracking, warning on stderr when the configured
+// history backend can't be used
+func openTracker(cmd *cobra.Command, cfg *config.Config) *tracking.Tracker {
+	tracker := tracking.Open(cfg)
+	if tracker.HistoryError != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", tracker.HistoryError)
+	}
+	return tracker
+}
+
+// streamTarget runs a target, copying its output to w as it arrives
+// An interrupt stops make, and the run is still returned so it gets recorded.
+func streamTarget(w io.Writer, name, makefilePath string, args []string) (result executor.Result, interrupted bool) {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+
+	start := time.Now()
+	chunks, cancel := executor.ExecuteStreaming(name, makefilePath, args...)
+	defer cancel()
+
+	var output strings.Builder
+	var err error
+	for chunks != nil {
+		select {
+		case chunk, ok := <-chunks:
+			switch {
+			case !ok:
+				chunks = nil
+			case chunk.Done:
+				err = chunk.Err
+			default:
+				output.WriteString(chunk.Data)
+				_, _ = io.WriteString(w, chunk.Data)
+			}
+		case <-interrupts:
+			interrupted = true
+			cancel()
+		}
+	}
+
+	end := time.Now()
+	return executor.Result{
+		Output:    output.String(),
+		Err:       err,
+		Duration:  end.Sub(start),
+		ExitCode:  executor.ExitCode(err),
+		StartTime: start,
+		EndTime:   end,
+	}, interrupted
+}
+
+// isInteractive reports whether confirmations can be asked for on the terminal
+func isInteractive() bool {
+	if os.Getenv("CI") != "" {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stderr.Fd())
+}
+
+// confirmPrompt asks for a target's safety confirmation on the terminal
+type confirmPrompt struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// confirm asks for everything the confirmation requires and describes how the
+// target was confirmed for history and exports
+// With yes set nothing is asked; a required reason must then come from --reason.
+func (c confirmPrompt) confirm(p *project.Project, name string, result *safety.CheckResult, confirmation safety.Confirmation, yes bool, reason string) (*export.Confirmation, error) {
+	record := &export.Confirmation{
+		Reason: strings.TrimSpace(reason),
+	}
+	if result != nil && result.IsDangerous {
+		record.DangerLevel = strings.ToLower(result.DangerLevel.String())
+		record.Rules = result.RuleIDs()
+	}
+	if p.Profile.Profile != nil {
+		record.Profile = p.Profile.Profile.Name
+	}
+
+	if yes {
+		if confirmation.RequireReason && record.Reason == "" {
+			return nil, fmt.Errorf("%s requires a reason: pass --reason", name)
+		}
+		record.ConfirmedAt = time.Now()
+		return record, nil
+	}
+	if !isInteractive() {
+		return nil, fmt.Errorf("%s needs confirmation under the %s safety profile; pass --yes to run it without a terminal", name, record.Profile)
+	}
+
+	c.describe(name, result, record.Profile)
+
+	if countdown := confirmation.Countdown.Round(time.Second); countdown > 0 {
+		_, _ = fmt.Fprintf(c.out, "Waiting %s before %s can be confirmed...\n", countdown, name)
+		time.Sleep(countdown)
+		record.CountdownSeconds = int(countdown.Seconds())
+	}
+
+	if phrase := confirmation.Phrase(name, p.Environment.Name()); phrase != "" {
+		typed, err := c.ask(fmt.Sprintf("Type %q to run it: ", phrase))
+		if err != nil {
+			return nil, err
+		}
+		if typed != phrase {
+			return nil, fmt.Errorf("confirmation didn't match %q; %s was not run", phrase, name)
+		}
+		record.Typed = typed
+	} else {
+		answer, err := c.ask(fmt.Sprintf("Run %s? [y/N] ", name))
+		if err != nil {
+			return nil, err
+		}
+		if a := strings.ToLower(answer); a != "y" && a != "yes" {
+			return nil, fmt.Errorf("%s was not run", name)
+		}
+	}
+
+	if confirmation.RequireReason && record.Reason == "" {
+		answer, err := c.ask("Reason: ")
+		if err != nil {
+			return nil, err
+		}
+		if record.Reason = answer; record.Reason == "" {
+			return nil, fmt.Errorf("%s requires a reason; it was not run", name)
+		}
+	}
+
+	record.ConfirmedAt = time.Now()
+	return record, nil
+}
+
+// describe prints why a target needs confirmation
+func (c confirmPrompt) describe(name string, result *safety.CheckResult, profile string) {
+	if result == nil || !result.IsDangerous {
+		_, _ = fmt.Fprintf(c.out, "%s needs confirmation under the %s safety profile\n", name, profile)
+		return
+	}
+
+	_, _ = fmt.Fprintf(c.out, "%s is %s under the %s safety profile:\n", name, result.DangerLevel, profile)
+	for _, match := range result.Matches {
+		_, _ = fmt.Fprintf(c.out, "  %-8s  %s: %s\n            %s\n", match.Severity, match.Rule.ID, match.Rule.Description, match.MatchedLine)
+	}
+	for _, dep := range result.Dependencies {
+		for _, match := range dep.Result.Matches {
+			_, _ = fmt.Fprintf(c.out, "  %-8s  %s: %s (via %s)\n            %s\n", match.Severity, match.Rule.ID, match.Rule.Description, strings.Join(dep.Path, " → "), match.MatchedLine)
+		}
+	}
+}
+
+// ask prints a question and reads the answer line
+func (c confirmPrompt) ask(question string) (string, error) {
+	_, _ = fmt.Fprint(c.out, question)
+	answer, err := c.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/testutil"
+)
+
+// TestRunRefusesDangerousSubMake refuses a target whose recursive make runs a
+// dangerous target of another Makefile
+func TestRunRefusesDangerousSubMake(t *testing.T) {
+	root := t.TempDir()
+	main := testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"delegate:\n"+
+			"\t$(MAKE) -C infra destroy\n")
+	testutil.WriteFile(t, filepath.Join(root, "infra", "Makefile"),
+		"destroy:\n"+
+			"\tterraform destroy -auto-approve\n")
+
+	t.Chdir(root)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("CI", "true")
+	t.Setenv("LAZYMAKE_MAKEFILE", main)
+
+	err := runRun(runCmd, []string{"delegate"})
+	if err == nil || !strings.Contains(err.Error(), "needs confirmation") {
+		t.Fatalf("runRun() = %v, want a refusal without --yes", err)
+	}
+}
@@ -1,10 +1,20 @@
 package config
 
 import (
+	"bytes"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
 	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/lint"
 	"github.com/rshelekhov/lazymake/internal/safety"
 	"github.com/rshelekhov/lazymake/internal/shell"
+	"github.com/rshelekhov/lazymake/internal/workspace"
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 )
 
 type Config struct {
@@ -12,48 +22,196 @@ type Config struct {
 	Export           *export.Config
 	ShellIntegration *shell.Config
 	Safety           *safety.Config
+	Performance      *history.Config
+	Storage          *history.StorageConfig
+	Lint             *lint.Config
+	Workspace        *workspace.Config
+
+	Origins  map[string]Origin // Where settings came from, keyed "section.key" (see Origin)
+	Problems []Problem         // Config file errors; the valid settings are still used
 }
 
 func Load() (*Config, error) {
-	// Keep Viper config name/path for Cobra flag binding (--file flag).
-	// The global Viper instance is still used by Cobra for CLI flags.
-	viper.SetConfigName(".lazymake")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("$HOME")
+	// Load global and project config files independently
+	global, globalProblems := loadFile(GlobalConfigPath())
+	project, projectProblems := loadFile(ProjectConfigPath())
+	overrides := Overrides()
+	env := envLayer(overrides)
+	flags := flagLayer(overrides, boundFlags)
 
-	// Read config so Cobra flag defaults work (ignore missing file)
-	_ = viper.ReadInConfig()
+	// Sources in precedence order, lowest first
+	layers := []layer{
+		{viper: global, origin: fixedOrigin(OriginGlobal)},
+		{viper: project, origin: fixedOrigin(OriginProject)},
+		{viper: env.viper, origin: env.originOf},
+		{viper: flags.viper, origin: flags.originOf},
+	}
 
-	// Load global and project config files independently
-	globalViper := loadViperFromFile(globalConfigPath())
-	projectViper := loadViperFromFile(projectConfigPath())
+	cfg := &Config{
+		Origins:  make(map[string]Origin),
+		Problems: slices.Concat(globalProblems, projectProblems, env.problems, flags.problems),
+	}
+
+	// Merge each section across the layers
+	cfg.Export = mergeLayers(layers, "export", cfg.Origins, readExportConfig, mergeExportConfigs)
+	cfg.ShellIntegration = mergeLayers(layers, "shell_integration", cfg.Origins, readShellConfig, mergeShellConfigs)
+	cfg.Safety = mergeLayers(layers, "safety", cfg.Origins, readSafetyConfig, mergeSafetyConfigs)
+	addRulePacks(cfg.Safety)
+	cfg.Performance = mergeLayers(layers, "performance", cfg.Origins, readPerformanceConfig, mergePerformanceConfigs)
+	cfg.Storage = mergeLayers(layers, "storage", cfg.Origins, readStorageConfig, mergeStorageConfigs)
+	cfg.Lint = mergeLayers(layers, "lint", cfg.Origins, readLintConfig, mergeLintConfigs)
+	cfg.Workspace = mergeLayers(layers, "workspace", cfg.Origins, readWorkspaceConfig, mergeWorkspaceConfigs)
+
+	// Makefile path: the last layer that sets it wins
+	for _, l := range layers {
+		if l.viper != nil && l.viper.IsSet("makefile") {
+			cfg.MakefilePath = l.viper.GetString("makefile")
+			cfg.Origins["makefile"] = l.origin("makefile")
+		}
+	}
+
+	// A safety profile requested by flag or environment variable skips context detection
+	switch origin := cfg.Origin("safety.profile"); origin {
+	case OriginDefault, OriginGlobal, OriginProject:
+	default:
+		cfg.Safety.ProfileSource = string(origin)
+	}
 
-	// Read each section from both files
-	globalExport, globalExportSet := readExportConfig(globalViper)
-	projectExport, projectExportSet := readExportConfig(projectViper)
+	return cfg, nil
+}
 
-	globalShell, globalShellSet := readShellConfig(globalViper)
-	projectShell, projectShellSet := readShellConfig(projectViper)
+// layer is one source of settings: a config file, the environment or the flags
+type layer struct {
+	viper  *viper.Viper            // nil if the source has no settings
+	origin func(key string) Origin // Origin of a "section.key" set by this layer
+}
 
-	globalSafety, globalSafetySet := readSafetyConfig(globalViper)
-	projectSafety, projectSafetySet := readSafetyConfig(projectViper)
+func fixedOrigin(origin Origin) func(string) Origin {
+	return func(string) Origin { return origin }
+}
 
-	// Merge each section
-	mergedExport := mergeExportConfigs(globalExport, projectExport, globalExportSet, projectExportSet)
-	mergedShell := mergeShellConfigs(globalShell, projectShell, globalShellSet, projectShellSet)
-	mergedSafety := mergeSafetyConfigs(globalSafety, projectSafety, globalSafetySet, projectSafetySet)
+// mergeLayers reads a section from each layer and merges them in order with the
+// section's merge rules, as for the global and project files: scalars from a later
+// layer override, lists are combined. origins records which layers set each key.
+func mergeLayers[T any](
+	layers []layer,
+	section string,
+	origins map[string]Origin,
+	read func(*viper.Viper) (T, fieldSet),
+	merge func(base, over T, baseSet, overSet fieldSet) T,
+) T {
+	merged, mergedSet := read(layers[0].viper)
+	recordOrigins(origins, section, mergedSet, layers[0].origin)
 
-	cfg := &Config{
-		Export:           mergedExport,
-		ShellIntegration: mergedShell,
-		Safety:           mergedSafety,
+	for _, l := range layers[1:] {
+		cfg, set := read(l.viper)
+		recordOrigins(origins, section, set, l.origin)
+		merged = merge(merged, cfg, mergedSet, set)
+
+		combined := make(fieldSet, len(mergedSet)+len(set))
+		maps.Copy(combined, mergedSet)
+		maps.Copy(combined, set)
+		mergedSet = combined
 	}
+	return merged
+}
 
-	// CLI flag override for makefile path
-	if viper.IsSet("makefile") {
-		cfg.MakefilePath = viper.GetString("makefile")
+// itemKeys are settings whose entries are read one by one, skipping invalid
+// ones (as invalid rules are), so a problem inside an entry keeps the others
+var itemKeys = map[string]bool{
+	"safety.custom_rules":        true,
+	"safety.profiles":            true,
+	"safety.detect":              true,
+	"safety.target_confirmation": true,
+	"lint.rules":                 true,
+}
+
+// loadFile reads a config file and checks it against the schema
+// Settings with invalid values are left out, as for environment variables and
+// flags, except safety.enabled, which is turned on: a typo must not disable
+// the checks. Returns nil if the file doesn't exist or can't be read.
+func loadFile(path string) (*viper.Viper, []Problem) {
+	v := loadViperFromFile(path)
+	if v == nil {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return v, nil
+	}
+	problems := Validate(path, data)
+	if len(problems) == 0 {
+		return v, nil
 	}
 
-	return cfg, nil
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return v, problems
+	}
+	for _, problem := range problems {
+		dropInvalid(doc.Content[0], problem.Path)
+	}
+	cleaned, err := yaml.Marshal(&doc)
+	if err != nil {
+		return v, problems
+	}
+
+	v = viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(cleaned)); err != nil {
+		return nil, problems
+	}
+	return v, problems
+}
+
+// dropInvalid removes the setting a problem at path belongs to from a config
+// file's root mapping
+func dropInvalid(root *yaml.Node, path string) {
+	if path == "" || root.Kind != yaml.MappingNode {
+		return
+	}
+	section, rest, _ := strings.Cut(path, ".")
+	if rest == "" {
+		removeKey(root, section) // Unknown or malformed section
+		return
+	}
+	name, _, _ := strings.Cut(rest, ".")
+	name, _, _ = strings.Cut(name, "[")
+	key := section + "." + name
+	if itemKeys[key] && rest != name {
+		return
+	}
+
+	node := mappingValue(root, section)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	if key == "safety.enabled" {
+		if value := mappingValue(node, name); value != nil {
+			*value = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}
+		}
+		return
+	}
+	removeKey(node, name)
+}
+
+// mappingValue returns the value of a key in a mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeKey removes a key and its value from a mapping
+func removeKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = slices.Delete(node.Content, i, i+2)
+			return
+		}
+	}
 }
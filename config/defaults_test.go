@@ -4,8 +4,11 @@ import (
 	"testing"
 
 	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/lint"
 	"github.com/rshelekhov/lazymake/internal/safety"
 	"github.com/rshelekhov/lazymake/internal/shell"
+	"github.com/rshelekhov/lazymake/internal/workspace"
 )
 
 // These tests ensure code defaults match documented values in docs/guides/configuration.md
@@ -76,6 +79,71 @@ func TestSafetyDefaultsMatchDocumented(t *testing.T) {
 	if d.CustomRules != nil {
 		t.Errorf("safety.custom_rules default = %v, want nil", d.CustomRules)
 	}
+	if d.RulePaths != nil {
+		t.Errorf("safety.rule_paths default = %v, want nil", d.RulePaths)
+	}
+	if d.Profile != "" {
+		t.Errorf("safety.profile default = %q, want empty (built-in default profile)", d.Profile)
+	}
+
+	// Documented values of the built-in profile that configured profiles start from
+	p := safety.DefaultProfile()
+	if p.Confirm.String() != "critical" {
+		t.Errorf("profile confirm default = %s, want critical", p.Confirm)
+	}
+	if len(p.Downgrade) != 3 || len(p.Escalate) != 0 {
+		t.Errorf("profile downgrade/escalate defaults = %v/%v, want [clean interactive development]/[]", p.Downgrade, p.Escalate)
+	}
+}
+
+func TestPerformanceDefaultsMatchDocumented(t *testing.T) {
+	d := history.Defaults()
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"retention_days", d.RetentionDays, 90},
+		{"max_samples", d.MaxSamples, 500},
+		{"regression_threshold", d.RegressionThreshold, 3.0},
+		{"min_samples", d.MinSamples, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("performance.%s default = %v, want %v — update docs if default changed", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageDefaultsMatchDocumented(t *testing.T) {
+	d := history.StorageDefaults()
+	if d.Backend != "json" {
+		t.Errorf("storage.backend default = %q, want %q — update docs if default changed", d.Backend, "json")
+	}
+}
+
+func TestLintDefaultsMatchDocumented(t *testing.T) {
+	d := lint.Defaults()
+	if !d.Enabled {
+		t.Error("lint.enabled default = false, want true — update docs if default changed")
+	}
+	if len(lint.Rules()) != 9 {
+		t.Errorf("expected 9 lint rules, got %d — update docs if rules were added/removed", len(lint.Rules()))
+	}
+}
+
+func TestWorkspaceDefaultsMatchDocumented(t *testing.T) {
+	d := workspace.Defaults()
+	if d.Aggregate {
+		t.Error("workspace.aggregate default = true, want false — update docs if default changed")
+	}
+	if d.MaxDepth != 3 {
+		t.Errorf("workspace.max_depth default = %d, want 3 — update docs if default changed", d.MaxDepth)
+	}
 }
 
 func TestBuiltinSafetyRulesCount(t *testing.T) {
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/safety"
+)
+
+// Origin names where a setting's value came from
+// Besides the constants, it is the environment variable or flag that set it
+// ("$LAZYMAKE_EXPORT_FORMAT", "--export-format"); a list or map combined from
+// several sources names them all: "global + project".
+type Origin string
+
+const (
+	OriginDefault Origin = "default"
+	OriginGlobal  Origin = "global"
+	OriginProject Origin = "project"
+)
+
+// mergedKeys are the keys combined across sources instead of overridden
+var mergedKeys = map[string]bool{
+	"enabled_rules":       true,
+	"exclude_targets":     true,
+	"custom_rules":        true,
+	"rule_paths":          true,
+	"profiles":            true,
+	"detect":              true,
+	"target_confirmation": true,
+	"rules":               true,
+}
+
+// recordOrigins records the keys of a section set by one source
+// Keys never set are left out: they have their default value.
+func recordOrigins(origins map[string]Origin, section string, set fieldSet, origin func(key string) Origin) {
+	for key := range set {
+		fullKey := section + "." + key
+		if previous, ok := origins[fullKey]; ok && mergedKeys[key] {
+			origins[fullKey] = previous + " + " + origin(fullKey)
+		} else {
+			origins[fullKey] = origin(fullKey)
+		}
+	}
+}
+
+// Origin returns where a setting came from, by "section.key" ("export.format")
+func (c *Config) Origin(key string) Origin {
+	if origin, ok := c.Origins[key]; ok {
+		return origin
+	}
+	return OriginDefault
+}
+
+// Setting is one effective config value, formatted as YAML
+type Setting struct {
+	Key    string // "section.key", or "makefile"
+	Value  string
+	Origin Origin
+}
+
+// Section returns the part of Key before the dot ("" for top-level keys)
+func (s Setting) Section() string {
+	section, _, found := strings.Cut(s.Key, ".")
+	if !found {
+		return ""
+	}
+	return section
+}
+
+// Name returns the part of Key after the section
+func (s Setting) Name() string {
+	_, name, found := strings.Cut(s.Key, ".")
+	if !found {
+		return s.Key
+	}
+	return name
+}
+
+// Effective lists every setting with its value in use and its origin, in the
+// order of the schema. Rules, profiles and confirmations are summarized by name.
+func (c *Config) Effective() []Setting {
+	var settings []Setting
+	add := func(key string, value any) {
+		settings = append(settings, Setting{Key: key, Value: formatValue(value), Origin: c.Origin(key)})
+	}
+
+	add("makefile", c.MakefilePath)
+
+	add("safety.enabled", c.Safety.Enabled)
+	add("safety.enabled_rules", c.Safety.EnabledRules)
+	add("safety.exclude_targets", c.Safety.ExcludeTargets)
+	add("safety.custom_rules", ruleIDs(c.Safety.CustomRules))
+	add("safety.rule_paths", c.Safety.RulePaths)
+	add("safety.profile", c.Safety.Profile)
+	add("safety.profiles", slices.Sorted(maps.Keys(c.Safety.Profiles)))
+	add("safety.detect", detectProfiles(c.Safety.Detect))
+	add("safety.target_confirmation", slices.Sorted(maps.Keys(c.Safety.TargetConfirmations)))
+
+	add("export.enabled", c.Export.Enabled)
+	add("export.output_dir", c.Export.OutputDir)
+	add("export.format", c.Export.Format)
+	add("export.naming_strategy", c.Export.NamingStrategy)
+	add("export.max_file_size_mb", c.Export.MaxFileSize)
+	add("export.max_files", c.Export.MaxFiles)
+	add("export.keep_days", c.Export.KeepDays)
+	add("export.success_only", c.Export.SuccessOnly)
+	add("export.exclude_targets", c.Export.ExcludeTargets)
+
+	add("shell_integration.enabled", c.ShellIntegration.Enabled)
+	add("shell_integration.shell", c.ShellIntegration.Shell)
+	add("shell_integration.history_file", c.ShellIntegration.HistoryFile)
+	add("shell_integration.include_timestamp", c.ShellIntegration.IncludeTimestamp)
+	add("shell_integration.format_template", c.ShellIntegration.FormatTemplate)
+	add("shell_integration.exclude_targets", c.ShellIntegration.ExcludeTargets)
+
+	add("performance.retention_days", c.Performance.RetentionDays)
+	add("performance.max_samples", c.Performance.MaxSamples)
+	add("performance.regression_threshold", c.Performance.RegressionThreshold)
+	add("performance.min_samples", c.Performance.MinSamples)
+
+	add("storage.backend", c.Storage.Backend)
+
+	add("lint.enabled", c.Lint.Enabled)
+	add("lint.rules", lintRules(c.Lint.Rules))
+	add("lint.exclude_targets", c.Lint.ExcludeTargets)
+
+	add("workspace.aggregate", c.Workspace.Aggregate)
+	add("workspace.max_depth", c.Workspace.MaxDepth)
+
+	return settings
+}
+
+// ruleIDs names custom rules by ID
+func ruleIDs(rules []safety.Rule) []string {
+	ids := make([]string, len(rules))
+	for i, rule := range rules {
+		ids[i] = rule.ID
+	}
+	return ids
+}
+
+// detectProfiles names detect rules by the profile they select, in order
+func detectProfiles(rules []safety.DetectRule) []string {
+	profiles := make([]string, len(rules))
+	for i, rule := range rules {
+		profiles[i] = rule.Profile
+	}
+	return profiles
+}
+
+// lintRules lists configured lint rules as "id: severity", by ID
+func lintRules(rules map[string]string) []string {
+	entries := make([]string, 0, len(rules))
+	for _, id := range slices.Sorted(maps.Keys(rules)) {
+		entries = append(entries, id+": "+rules[id])
+	}
+	return entries
+}
+
+// formatValue renders a setting as a YAML flow value
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return `""`
+		}
+		return quoteIfNeeded(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = quoteIfNeeded(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// quoteIfNeeded quotes strings YAML would read as something else or can't parse bare
+func quoteIfNeeded(s string) string {
+	if strings.ContainsAny(s, ":#{}[],&*!|>'\"%@`") || strings.TrimSpace(s) != s {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/lint"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/shell"
+	"github.com/rshelekhov/lazymake/internal/workspace"
+)
+
+// defaultConfig returns a Config with every section at its defaults
+func defaultConfig() *Config {
+	return &Config{
+		Export:           export.Defaults(),
+		ShellIntegration: shell.Defaults(),
+		Safety:           safety.DefaultConfig(),
+		Performance:      history.Defaults(),
+		Storage:          history.StorageDefaults(),
+		Lint:             lint.Defaults(),
+		Workspace:        workspace.Defaults(),
+		Origins:          make(map[string]Origin),
+	}
+}
+
+func TestLoad_RecordsOriginsAndProblems(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("LAZYMAKE_SAFETY_PROFILE", "")
+	t.Chdir(project)
+
+	writeRulePack(t, filepath.Join(home, ".lazymake.yaml"), `
+export:
+  enabled: true
+  format: log
+safety:
+  exclude_targets: [clean]
+`)
+	writeRulePack(t, filepath.Join(project, ".lazymake.yaml"), `
+export:
+  format: both
+  max_file_size: 10
+safety:
+  exclude_targets: [reset-db]
+performance:
+  min_samples: 3
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want Origin
+	}{
+		{"export.enabled", OriginGlobal},
+		{"export.format", OriginProject},
+		{"export.naming_strategy", OriginDefault},
+		{"safety.exclude_targets", "global + project"},
+		{"performance.min_samples", OriginProject},
+		{"storage.backend", OriginDefault},
+	}
+	for _, tt := range tests {
+		if got := cfg.Origin(tt.key); got != tt.want {
+			t.Errorf("Origin(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	// The misspelled key is reported, and the rest of the file still applies
+	if len(cfg.Problems) != 1 || !strings.Contains(cfg.Problems[0].Message, `"max_file_size"`) {
+		t.Errorf("expected one max_file_size problem, got %v", cfg.Problems)
+	}
+	if cfg.Export.Format != "both" {
+		t.Errorf("export.format = %q, want both", cfg.Export.Format)
+	}
+}
+
+func TestLoad_DropsInvalidFileSettings(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("LAZYMAKE_SAFETY_PROFILE", "")
+	t.Chdir(project)
+
+	writeRulePack(t, filepath.Join(home, ".lazymake.yaml"), `
+export:
+  format: log
+safety:
+  enabled: false
+`)
+	writeRulePack(t, filepath.Join(project, ".lazymake.yaml"), `
+export:
+  format: xml
+  max_files: 3
+safety:
+  enabled: yes
+  exclude_targets: [reset-db]
+  custom_rules:
+    - id: no-curl
+      patterns: ["curl"]
+    - id: broken
+      severity: bogus
+      patterns: ["wget"]
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Problems) != 3 {
+		t.Errorf("expected format, enabled and severity problems, got %v", cfg.Problems)
+	}
+	// An invalid value falls back to the layer below; safety stays on
+	if cfg.Export.Format != "log" || cfg.Export.MaxFiles != 3 {
+		t.Errorf("export = %+v, want the global format and the project max_files", cfg.Export)
+	}
+	if !cfg.Safety.Enabled {
+		t.Error("safety.enabled: yes should keep safety checks on")
+	}
+	if len(cfg.Safety.ExcludeTargets) != 1 || len(cfg.Safety.CustomRules) != 1 || cfg.Safety.CustomRules[0].ID != "no-curl" {
+		t.Errorf("safety = %+v, want the valid settings and rules kept", cfg.Safety)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Export.Format = "both"
+	cfg.Safety.ExcludeTargets = []string{"clean", "db:reset"}
+	cfg.Safety.CustomRules = []safety.Rule{{ID: "no-prod"}, {ID: "no-force"}}
+	cfg.Origins["export.format"] = OriginProject
+
+	settings := map[string]Setting{}
+	for _, setting := range cfg.Effective() {
+		settings[setting.Key] = setting
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		origin Origin
+	}{
+		{"makefile", `""`, OriginDefault},
+		{"export.format", "both", OriginProject},
+		{"safety.exclude_targets", `[clean, "db:reset"]`, OriginDefault},
+		{"safety.custom_rules", "[no-prod, no-force]", OriginDefault},
+		{"performance.regression_threshold", "3", OriginDefault},
+		{"shell_integration.format_template", `"make {target}"`, OriginDefault},
+	}
+	for _, tt := range tests {
+		got := settings[tt.key]
+		if got.Value != tt.value || got.Origin != tt.origin {
+			t.Errorf("%s = %s (%s), want %s (%s)", tt.key, got.Value, got.Origin, tt.value, tt.origin)
+		}
+	}
+}
+
+// Every key in the schema must be listed by Effective, and the other way around
+func TestEffective_MatchesSchema(t *testing.T) {
+	root, err := rootSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{}
+	for name, property := range root.Properties {
+		property = root.resolve(property)
+		if len(property.Properties) == 0 {
+			want[name] = true
+			continue
+		}
+		for key := range property.Properties {
+			want[name+"."+key] = true
+		}
+	}
+
+	for _, setting := range defaultConfig().Effective() {
+		if !want[setting.Key] {
+			t.Errorf("%s isn't in the schema", setting.Key)
+		}
+		delete(want, setting.Key)
+	}
+	for key := range want {
+		t.Errorf("%s is missing from Effective", key)
+	}
+}
@@ -1,12 +1,20 @@
 package config
 
 import (
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/lint"
 	"github.com/rshelekhov/lazymake/internal/safety"
 	"github.com/rshelekhov/lazymake/internal/shell"
+	"github.com/rshelekhov/lazymake/internal/workspace"
 	"github.com/spf13/viper"
 )
 
@@ -26,8 +34,8 @@ func loadViperFromFile(path string) *viper.Viper {
 	return v
 }
 
-// globalConfigPath returns the path to the global config file (~/.lazymake.yaml).
-func globalConfigPath() string {
+// GlobalConfigPath returns the path to the global config file (~/.lazymake.yaml).
+func GlobalConfigPath() string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -36,8 +44,8 @@ func globalConfigPath() string {
 	return filepath.Join(homeDir, ".lazymake.yaml")
 }
 
-// projectConfigPath returns the path to the project config file (./.lazymake.yaml).
-func projectConfigPath() string {
+// ProjectConfigPath returns the path to the project config file (./.lazymake.yaml).
+func ProjectConfigPath() string {
 	return ".lazymake.yaml"
 }
 
@@ -192,11 +200,143 @@ func readSafetyConfig(v *viper.Viper) (*safety.Config, fieldSet) {
 	if v.IsSet("safety.custom_rules") {
 		var customRules []map[string]interface{}
 		if err := v.UnmarshalKey("safety.custom_rules", &customRules); err == nil {
-			cfg.CustomRules = parseCustomRules(customRules)
+			cfg.CustomRules, cfg.RuleErrors = parseCustomRules(customRules, v.ConfigFileUsed())
+		} else {
+			cfg.RuleErrors = append(cfg.RuleErrors, safety.RuleError{Source: v.ConfigFileUsed(), Err: fmt.Errorf("custom_rules: %w", err)})
 		}
 		set["custom_rules"] = true
 	}
 
+	if v.IsSet("safety.rule_paths") {
+		cfg.RulePaths = resolveRulePaths(v.GetStringSlice("safety.rule_paths"), filepath.Dir(v.ConfigFileUsed()))
+		set["rule_paths"] = true
+	}
+
+	if v.IsSet("safety.profile") {
+		cfg.Profile = strings.ToLower(v.GetString("safety.profile"))
+		set["profile"] = true
+	}
+
+	if v.IsSet("safety.profiles") {
+		cfg.Profiles = parseProfiles(v.GetStringMap("safety.profiles"))
+		set["profiles"] = true
+	}
+
+	if v.IsSet("safety.detect") {
+		var detectRules []map[string]interface{}
+		if err := v.UnmarshalKey("safety.detect", &detectRules); err == nil {
+			cfg.Detect = parseDetectRules(detectRules)
+		}
+		set["detect"] = true
+	}
+
+	if v.IsSet("safety.target_confirmation") {
+		cfg.TargetConfirmations = parseTargetConfirmations(v.GetStringMap("safety.target_confirmation"))
+		set["target_confirmation"] = true
+	}
+
+	return cfg, set
+}
+
+// readPerformanceConfig reads the performance section from a Viper instance.
+// Returns the config and a fieldSet of explicitly set keys.
+func readPerformanceConfig(v *viper.Viper) (*history.Config, fieldSet) {
+	if v == nil {
+		return history.Defaults(), nil
+	}
+
+	cfg := history.Defaults()
+	set := make(fieldSet)
+
+	if v.IsSet("performance.retention_days") {
+		cfg.RetentionDays = v.GetInt("performance.retention_days")
+		set["retention_days"] = true
+	}
+
+	if v.IsSet("performance.max_samples") {
+		cfg.MaxSamples = v.GetInt("performance.max_samples")
+		set["max_samples"] = true
+	}
+
+	if v.IsSet("performance.regression_threshold") {
+		cfg.RegressionThreshold = v.GetFloat64("performance.regression_threshold")
+		set["regression_threshold"] = true
+	}
+
+	if v.IsSet("performance.min_samples") {
+		cfg.MinSamples = v.GetInt("performance.min_samples")
+		set["min_samples"] = true
+	}
+
+	return cfg, set
+}
+
+// readStorageConfig reads the storage section from a Viper instance.
+// Returns the config and a fieldSet of explicitly set keys.
+func readStorageConfig(v *viper.Viper) (*history.StorageConfig, fieldSet) {
+	if v == nil {
+		return history.StorageDefaults(), nil
+	}
+
+	cfg := history.StorageDefaults()
+	set := make(fieldSet)
+
+	if v.IsSet("storage.backend") {
+		cfg.Backend = v.GetString("storage.backend")
+		set["backend"] = true
+	}
+
+	return cfg, set
+}
+
+// readLintConfig reads the lint section from a Viper instance.
+// Returns the config and a fieldSet of explicitly set keys.
+func readLintConfig(v *viper.Viper) (*lint.Config, fieldSet) {
+	if v == nil {
+		return lint.Defaults(), nil
+	}
+
+	cfg := lint.Defaults()
+	set := make(fieldSet)
+
+	if v.IsSet("lint.enabled") {
+		cfg.Enabled = v.GetBool("lint.enabled")
+		set["enabled"] = true
+	}
+
+	if v.IsSet("lint.rules") {
+		cfg.Rules = v.GetStringMapString("lint.rules")
+		set["rules"] = true
+	}
+
+	if v.IsSet("lint.exclude_targets") {
+		cfg.ExcludeTargets = v.GetStringSlice("lint.exclude_targets")
+		set["exclude_targets"] = true
+	}
+
+	return cfg, set
+}
+
+// readWorkspaceConfig reads the workspace section from a Viper instance.
+// Returns the config and a fieldSet of explicitly set keys.
+func readWorkspaceConfig(v *viper.Viper) (*workspace.Config, fieldSet) {
+	if v == nil {
+		return workspace.Defaults(), nil
+	}
+
+	cfg := workspace.Defaults()
+	set := make(fieldSet)
+
+	if v.IsSet("workspace.aggregate") {
+		cfg.Aggregate = v.GetBool("workspace.aggregate")
+		set["aggregate"] = true
+	}
+
+	if v.IsSet("workspace.max_depth") {
+		cfg.MaxDepth = v.GetInt("workspace.max_depth")
+		set["max_depth"] = true
+	}
+
 	return cfg, set
 }
 
@@ -316,39 +456,344 @@ func mergeSafetyConfigs(global, project *safety.Config, globalSet, projectSet fi
 		result.CustomRules = nil
 	}
 
+	// RulePaths: union, deduplicated (already absolute)
+	result.RulePaths = mergeStringSliceUnion(global.RulePaths, project.RulePaths)
+	if len(result.RulePaths) == 0 {
+		result.RulePaths = nil
+	}
+
+	// RuleErrors: append, so both files' problems are reported
+	result.RuleErrors = append(result.RuleErrors, global.RuleErrors...)
+	result.RuleErrors = append(result.RuleErrors, project.RuleErrors...)
+	if len(result.RuleErrors) == 0 {
+		result.RuleErrors = nil
+	}
+
+	// Profile: project overrides global
+	if projectSet["profile"] {
+		result.Profile = project.Profile
+	} else if globalSet["profile"] {
+		result.Profile = global.Profile
+	}
+
+	// Profiles: merged by name, a project profile replaces a global one with the same name
+	if len(global.Profiles)+len(project.Profiles) > 0 {
+		result.Profiles = make(map[string]*safety.Profile)
+		maps.Copy(result.Profiles, global.Profiles)
+		maps.Copy(result.Profiles, project.Profiles)
+	}
+
+	// Detect: project rules first, since the first matching rule wins
+	result.Detect = append(result.Detect, project.Detect...)
+	result.Detect = append(result.Detect, global.Detect...)
+	if len(result.Detect) == 0 {
+		result.Detect = nil
+	}
+
+	// TargetConfirmations: merged by pattern, project entries replace global ones
+	if len(global.TargetConfirmations)+len(project.TargetConfirmations) > 0 {
+		result.TargetConfirmations = make(map[string]safety.Confirmation)
+		maps.Copy(result.TargetConfirmations, global.TargetConfirmations)
+		maps.Copy(result.TargetConfirmations, project.TargetConfirmations)
+	}
+
+	return result
+}
+
+// mergePerformanceConfigs merges global and project performance configurations.
+// Scalars: project overrides global.
+func mergePerformanceConfigs(global, project *history.Config, globalSet, projectSet fieldSet) *history.Config {
+	result := history.Defaults()
+
+	applyInt := func(field *int, key string, gVal, pVal int) {
+		if projectSet[key] {
+			*field = pVal
+		} else if globalSet[key] {
+			*field = gVal
+		}
+	}
+
+	applyInt(&result.RetentionDays, "retention_days", global.RetentionDays, project.RetentionDays)
+	applyInt(&result.MaxSamples, "max_samples", global.MaxSamples, project.MaxSamples)
+	applyInt(&result.MinSamples, "min_samples", global.MinSamples, project.MinSamples)
+
+	if projectSet["regression_threshold"] {
+		result.RegressionThreshold = project.RegressionThreshold
+	} else if globalSet["regression_threshold"] {
+		result.RegressionThreshold = global.RegressionThreshold
+	}
+
+	return result
+}
+
+// mergeStorageConfigs merges global and project storage configurations.
+// Scalars: project overrides global.
+func mergeStorageConfigs(global, project *history.StorageConfig, globalSet, projectSet fieldSet) *history.StorageConfig {
+	result := history.StorageDefaults()
+
+	if projectSet["backend"] {
+		result.Backend = project.Backend
+	} else if globalSet["backend"] {
+		result.Backend = global.Backend
+	}
+
+	return result
+}
+
+// mergeLintConfigs merges global and project lint configurations.
+// Scalars: project overrides global. Rules: merged by ID, project entries replace
+// global ones. Slices: union, deduplicated.
+func mergeLintConfigs(global, project *lint.Config, globalSet, projectSet fieldSet) *lint.Config {
+	result := lint.Defaults()
+
+	if projectSet["enabled"] {
+		result.Enabled = project.Enabled
+	} else if globalSet["enabled"] {
+		result.Enabled = global.Enabled
+	}
+
+	if len(global.Rules)+len(project.Rules) > 0 {
+		result.Rules = make(map[string]string)
+		maps.Copy(result.Rules, global.Rules)
+		maps.Copy(result.Rules, project.Rules)
+	}
+
+	result.ExcludeTargets = mergeStringSliceUnion(global.ExcludeTargets, project.ExcludeTargets)
+
+	return result
+}
+
+// mergeWorkspaceConfigs merges global and project workspace configurations.
+// Scalars: project overrides global.
+func mergeWorkspaceConfigs(global, project *workspace.Config, globalSet, projectSet fieldSet) *workspace.Config {
+	result := workspace.Defaults()
+
+	if projectSet["aggregate"] {
+		result.Aggregate = project.Aggregate
+	} else if globalSet["aggregate"] {
+		result.Aggregate = global.Aggregate
+	}
+
+	if projectSet["max_depth"] {
+		result.MaxDepth = project.MaxDepth
+	} else if globalSet["max_depth"] {
+		result.MaxDepth = global.MaxDepth
+	}
+
 	return result
 }
 
 // parseCustomRules converts YAML map to safety.Rule structs.
-func parseCustomRules(rulesMaps []map[string]interface{}) []safety.Rule {
+// Invalid rules are left out and reported as errors; source names the file
+// they come from.
+func parseCustomRules(rulesMaps []map[string]interface{}, source string) ([]safety.Rule, []safety.RuleError) {
 	var rules []safety.Rule
+	var errs []safety.RuleError
 
-	for _, ruleMap := range rulesMaps {
-		rule := safety.Rule{
-			ID:          getString(ruleMap, "id"),
-			Description: getString(ruleMap, "description"),
-			Suggestion:  getString(ruleMap, "suggestion"),
-			Patterns:    getStringSlice(ruleMap, "patterns"),
+	for i, ruleMap := range rulesMaps {
+		rule, err := parseCustomRule(ruleMap)
+		if err != nil {
+			if rule.ID == "" {
+				err = fmt.Errorf("rule #%d: %w", i+1, err)
+			}
+			errs = append(errs, safety.RuleError{Source: source, RuleID: rule.ID, Err: err})
+			continue
+		}
+		rule.Source = source
+		rules = append(rules, rule)
+	}
+
+	return rules, errs
+}
+
+// parseCustomRule converts one YAML rule and checks that it can be used:
+// an id, a valid severity (warning if omitted) and patterns or a command that compile.
+// Errors name the rule, like safety.Rule.Compile errors.
+func parseCustomRule(ruleMap map[string]interface{}) (safety.Rule, error) {
+	rule := safety.Rule{
+		ID:          getString(ruleMap, "id"),
+		Description: getString(ruleMap, "description"),
+		Suggestion:  getString(ruleMap, "suggestion"),
+		Patterns:    getStringSlice(ruleMap, "patterns"),
+		Command:     getString(ruleMap, "command"),
+		Args:        parseArgPredicates(ruleMap),
+		Severity:    safety.SeverityWarning,
+	}
+	if m, ok := ruleMap["confirmation"].(map[string]interface{}); ok {
+		rule.Confirmation = parseConfirmation(m)
+	}
+	if m, ok := ruleMap["tests"].(map[string]interface{}); ok {
+		rule.Tests = safety.RuleTests{
+			Match:   getStringSlice(m, "match"),
+			NoMatch: getStringSlice(m, "no_match"),
+		}
+	}
+
+	if rule.ID == "" {
+		return rule, fmt.Errorf("missing id")
+	}
+
+	if severityStr := getString(ruleMap, "severity"); severityStr != "" {
+		severity, err := safety.ParseSeverity(severityStr)
+		if err != nil {
+			return rule, fmt.Errorf("rule %s: %w: want critical, warning or info", rule.ID, err)
+		}
+		rule.Severity = severity
+	}
+
+	// Compile a copy: the checker compiles its own rules
+	probe := rule
+	probe.Args = slices.Clone(rule.Args)
+	if err := probe.Compile(); err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
+
+// parseArgPredicates converts the args list of a command rule to predicates.
+// Each entry sets one of has, flag, matches or without.
+func parseArgPredicates(ruleMap map[string]interface{}) []safety.ArgPredicate {
+	entries, ok := ruleMap["args"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	predicates := make([]safety.ArgPredicate, 0, len(entries))
+	for _, entry := range entries {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		predicates = append(predicates, safety.ArgPredicate{
+			Has:     getString(m, "has"),
+			Flag:    getString(m, "flag"),
+			Matches: getString(m, "matches"),
+			Without: getString(m, "without"),
+		})
+	}
+
+	return predicates
+}
+
+// parseProfiles converts the safety.profiles map to named profiles.
+// Each profile starts from the built-in default; the fields it sets replace the defaults.
+// Invalid severities or confirm settings keep the default value.
+func parseProfiles(profilesMap map[string]interface{}) map[string]*safety.Profile {
+	profiles := make(map[string]*safety.Profile, len(profilesMap))
+
+	for name, raw := range profilesMap {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			m = map[string]interface{}{} // Empty profile: built-in defaults under a new name
 		}
 
-		severityStr := getString(ruleMap, "severity")
-		switch severityStr {
-		case "critical":
-			rule.Severity = safety.SeverityCritical
-		case "warning":
-			rule.Severity = safety.SeverityWarning
-		case "info":
-			rule.Severity = safety.SeverityInfo
-		default:
-			rule.Severity = safety.SeverityWarning
+		profile := safety.DefaultProfile()
+		profile.Name = strings.ToLower(name)
+
+		if keywords, ok := m["keywords"].(map[string]interface{}); ok {
+			applyKeywords := func(field *[]string, key string) {
+				if _, set := keywords[key]; set {
+					*field = getStringSlice(keywords, key)
+				}
+			}
+			applyKeywords(&profile.CleanKeywords, "clean")
+			applyKeywords(&profile.DevelopmentKeywords, "development")
+			applyKeywords(&profile.ProductionKeywords, "production")
+			applyKeywords(&profile.CriticalKeywords, "critical")
+		}
+
+		// An empty list turns all downgrades (or escalations) off
+		if _, set := m["downgrade"]; set {
+			profile.Downgrade = getStringSlice(m, "downgrade")
+		}
+		if _, set := m["escalate"]; set {
+			profile.Escalate = getStringSlice(m, "escalate")
 		}
 
+		if overrides, ok := m["severity_overrides"].(map[string]interface{}); ok {
+			profile.SeverityOverrides = make(map[string]safety.Severity, len(overrides))
+			for ruleID := range overrides {
+				if severity, err := safety.ParseSeverity(getString(overrides, ruleID)); err == nil {
+					profile.SeverityOverrides[strings.ToLower(ruleID)] = severity
+				}
+			}
+		}
+
+		if confirm := getString(m, "confirm"); confirm != "" {
+			if policy, err := safety.ParseConfirmPolicy(confirm); err == nil {
+				profile.Confirm = policy
+			}
+		}
+
+		profiles[profile.Name] = profile
+	}
+
+	return profiles
+}
+
+// parseDetectRules converts the safety.detect list to detect rules.
+// Entries without a profile are skipped.
+func parseDetectRules(rulesMaps []map[string]interface{}) []safety.DetectRule {
+	var rules []safety.DetectRule
+
+	for _, ruleMap := range rulesMaps {
+		rule := safety.DetectRule{
+			Profile:     strings.ToLower(getString(ruleMap, "profile")),
+			KubeContext: getString(ruleMap, "kube_context"),
+			AWSProfile:  getString(ruleMap, "aws_profile"),
+			GitBranch:   getString(ruleMap, "git_branch"),
+			Env:         getString(ruleMap, "env"),
+		}
+		if rule.Profile == "" {
+			continue
+		}
 		rules = append(rules, rule)
 	}
 
 	return rules
 }
 
+// parseTargetConfirmations converts the safety.target_confirmation map
+// (target name or glob -> confirmation settings).
+func parseTargetConfirmations(targetsMap map[string]interface{}) map[string]safety.Confirmation {
+	confirmations := make(map[string]safety.Confirmation, len(targetsMap))
+	for pattern, raw := range targetsMap {
+		if m, ok := raw.(map[string]interface{}); ok {
+			confirmations[strings.ToLower(pattern)] = parseConfirmation(m)
+		}
+	}
+	return confirmations
+}
+
+// parseConfirmation reads type (target or environment), countdown (seconds or
+// a duration like "10s") and reason. Invalid values are ignored.
+func parseConfirmation(m map[string]interface{}) safety.Confirmation {
+	var confirmation safety.Confirmation
+
+	if confirmType, err := safety.ParseConfirmType(getString(m, "type")); err == nil {
+		confirmation.Type = confirmType
+	}
+
+	switch countdown := m["countdown"].(type) {
+	case int:
+		confirmation.Countdown = time.Duration(countdown) * time.Second
+	case float64:
+		confirmation.Countdown = time.Duration(countdown * float64(time.Second))
+	case string:
+		if d, err := time.ParseDuration(countdown); err == nil {
+			confirmation.Countdown = d
+		}
+	}
+	confirmation.Countdown = max(confirmation.Countdown, 0)
+
+	if reason, ok := m["reason"].(bool); ok {
+		confirmation.RequireReason = reason
+	}
+
+	return confirmation
+}
+
 // getString extracts a string value from a map.
 func getString(m map[string]interface{}, key string) string {
 	if val, ok := m[key]; ok {
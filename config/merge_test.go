@@ -3,9 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/history"
 	"github.com/rshelekhov/lazymake/internal/safety"
 	"github.com/rshelekhov/lazymake/internal/shell"
 	"github.com/spf13/viper"
@@ -540,6 +543,264 @@ safety:
 				}
 			},
 		},
+		{
+			name: "project file — safety command rule with argument conditions",
+			globalYAML: "",
+			projectYAML: `
+safety:
+  custom_rules:
+    - id: helm-prod-uninstall
+      severity: critical
+      command: helm
+      args:
+        - has: uninstall
+        - matches: "^--namespace=prod"
+        - without: --dry-run
+`,
+			check: func(t *testing.T, vp viperPair) {
+				gs, gset := readSafetyConfig(vp.global)
+				ps, pset := readSafetyConfig(vp.project)
+				r := mergeSafetyConfigs(gs, ps, gset, pset)
+				if len(r.CustomRules) != 1 {
+					t.Fatalf("expected 1 custom rule, got %d", len(r.CustomRules))
+				}
+				rule := r.CustomRules[0]
+				if rule.Command != "helm" {
+					t.Errorf("expected command=helm, got %q", rule.Command)
+				}
+				want := []safety.ArgPredicate{
+					{Has: "uninstall"},
+					{Matches: "^--namespace=prod"},
+					{Without: "--dry-run"},
+				}
+				if len(rule.Args) != len(want) {
+					t.Fatalf("expected %d argument conditions, got %d", len(want), len(rule.Args))
+				}
+				for i := range want {
+					if rule.Args[i] != want[i] {
+						t.Errorf("args[%d] = %+v, want %+v", i, rule.Args[i], want[i])
+					}
+				}
+			},
+		},
+		{
+			name: "both files — safety profiles merged by name, detect rules project first",
+			globalYAML: `
+safety:
+  profile: local
+  profiles:
+    local:
+      confirm: never
+    prod-oncall:
+      confirm: warning
+  detect:
+    - profile: prod-oncall
+      kube_context: "*prod*"
+`,
+			projectYAML: `
+safety:
+  profiles:
+    prod-oncall:
+      downgrade: []
+      escalate: [production]
+      keywords:
+        production: [prod-east, live]
+      severity_overrides:
+        git-force-push: critical
+  detect:
+    - profile: local
+      git_branch: "feature/*"
+`,
+			check: func(t *testing.T, vp viperPair) {
+				gs, gset := readSafetyConfig(vp.global)
+				ps, pset := readSafetyConfig(vp.project)
+				r := mergeSafetyConfigs(gs, ps, gset, pset)
+				if r.Profile != "local" {
+					t.Errorf("expected profile=local from global, got %q", r.Profile)
+				}
+				if len(r.Profiles) != 2 {
+					t.Fatalf("expected 2 profiles, got %d", len(r.Profiles))
+				}
+				if !r.Profiles["local"].Confirm.Never {
+					t.Error("expected global local profile with confirm=never")
+				}
+
+				// The project's prod-oncall replaces the global one entirely
+				oncall := r.Profiles["prod-oncall"]
+				if oncall.Confirm.MinSeverity != safety.SeverityCritical {
+					t.Errorf("expected default confirm for project prod-oncall, got %s", oncall.Confirm)
+				}
+				if len(oncall.Downgrade) != 0 {
+					t.Errorf("expected no downgrades, got %v", oncall.Downgrade)
+				}
+				assertSliceEqual(t, oncall.Escalate, []string{"production"})
+				assertSliceEqual(t, oncall.ProductionKeywords, []string{"prod-east", "live"})
+				assertSliceEqual(t, oncall.CleanKeywords, safety.DefaultProfile().CleanKeywords)
+				if oncall.SeverityOverrides["git-force-push"] != safety.SeverityCritical {
+					t.Errorf("expected git-force-push override, got %v", oncall.SeverityOverrides)
+				}
+
+				if len(r.Detect) != 2 || r.Detect[0].Profile != "local" || r.Detect[1].KubeContext != "*prod*" {
+					t.Errorf("expected project detect rule first, got %+v", r.Detect)
+				}
+			},
+		},
+		{
+			name: "both files — confirmation settings per rule and target",
+			globalYAML: `
+safety:
+  target_confirmation:
+    "*-prod":
+      countdown: 5
+    deploy-prod:
+      type: target
+`,
+			projectYAML: `
+safety:
+  target_confirmation:
+    Deploy-Prod:
+      type: environment
+      countdown: 1m
+      reason: true
+  custom_rules:
+    - id: terraform-destroy
+      severity: critical
+      patterns: ["terraform\\s+destroy"]
+      confirmation:
+        type: bogus
+        countdown: "10s"
+        reason: true
+`,
+			check: func(t *testing.T, vp viperPair) {
+				gs, gset := readSafetyConfig(vp.global)
+				ps, pset := readSafetyConfig(vp.project)
+				r := mergeSafetyConfigs(gs, ps, gset, pset)
+
+				if len(r.TargetConfirmations) != 2 {
+					t.Fatalf("expected 2 target confirmations, got %v", r.TargetConfirmations)
+				}
+				if c := r.TargetConfirmations["*-prod"]; c.Countdown != 5*time.Second {
+					t.Errorf("expected 5s countdown for *-prod, got %v", c.Countdown)
+				}
+				// The project's entry replaces the global one with the same name
+				want := safety.Confirmation{Type: safety.ConfirmTypeEnvironment, Countdown: time.Minute, RequireReason: true}
+				if c := r.TargetConfirmations["deploy-prod"]; c != want {
+					t.Errorf("expected project deploy-prod confirmation %+v, got %+v", want, c)
+				}
+
+				if len(r.CustomRules) != 1 {
+					t.Fatalf("expected 1 custom rule, got %d", len(r.CustomRules))
+				}
+				// An invalid type is ignored, the other settings are kept
+				want = safety.Confirmation{Countdown: 10 * time.Second, RequireReason: true}
+				if c := r.CustomRules[0].Confirmation; c != want {
+					t.Errorf("expected rule confirmation %+v, got %+v", want, c)
+				}
+			},
+		},
+		{
+			name: "both files — performance scalars merged",
+			globalYAML: `
+performance:
+  retention_days: 30
+  regression_threshold: 2.5
+`,
+			projectYAML: `
+performance:
+  max_samples: 100
+  regression_threshold: 4
+`,
+			check: func(t *testing.T, vp viperPair) {
+				gp, gset := readPerformanceConfig(vp.global)
+				pp, pset := readPerformanceConfig(vp.project)
+				r := mergePerformanceConfigs(gp, pp, gset, pset)
+				if r.RetentionDays != 30 {
+					t.Errorf("expected retention_days=30 from global, got %d", r.RetentionDays)
+				}
+				if r.MaxSamples != 100 {
+					t.Errorf("expected max_samples=100 from project, got %d", r.MaxSamples)
+				}
+				if r.RegressionThreshold != 4 {
+					t.Errorf("expected regression_threshold=4 (project wins), got %v", r.RegressionThreshold)
+				}
+				if r.MinSamples != history.Defaults().MinSamples {
+					t.Errorf("expected default min_samples, got %d", r.MinSamples)
+				}
+			},
+		},
+		{
+			name: "both files — storage backend from project",
+			globalYAML: `
+storage:
+  backend: json
+`,
+			projectYAML: `
+storage:
+  backend: sqlite
+`,
+			check: func(t *testing.T, vp viperPair) {
+				gs, gset := readStorageConfig(vp.global)
+				ps, pset := readStorageConfig(vp.project)
+				r := mergeStorageConfigs(gs, ps, gset, pset)
+				if r.Backend != history.BackendSQLite {
+					t.Errorf("expected backend=sqlite (project wins), got %s", r.Backend)
+				}
+			},
+		},
+		{
+			name: "both files — workspace depth from global, aggregate from project",
+			globalYAML: `
+workspace:
+  aggregate: false
+  max_depth: 5
+`,
+			projectYAML: `
+workspace:
+  aggregate: true
+`,
+			check: func(t *testing.T, vp viperPair) {
+				gw, gset := readWorkspaceConfig(vp.global)
+				pw, pset := readWorkspaceConfig(vp.project)
+				r := mergeWorkspaceConfigs(gw, pw, gset, pset)
+				if !r.Aggregate {
+					t.Error("expected aggregate=true (project wins)")
+				}
+				if r.MaxDepth != 5 {
+					t.Errorf("expected max_depth=5 (from global), got %d", r.MaxDepth)
+				}
+			},
+		},
+		{
+			name: "both files — lint rules merged by ID",
+			globalYAML: `
+lint:
+  enabled: false
+  rules:
+    missing-phony: "off"
+    unused-variable: warning
+  exclude_targets: [help]
+`,
+			projectYAML: `
+lint:
+  rules:
+    unused-variable: "off"
+  exclude_targets: [ci-*]
+`,
+			check: func(t *testing.T, vp viperPair) {
+				gl, gset := readLintConfig(vp.global)
+				pl, pset := readLintConfig(vp.project)
+				r := mergeLintConfigs(gl, pl, gset, pset)
+				if r.Enabled {
+					t.Error("expected enabled=false from global")
+				}
+				if r.Rules["missing-phony"] != "off" || r.Rules["unused-variable"] != "off" {
+					t.Errorf("expected missing-phony off from global and unused-variable off from project, got %v", r.Rules)
+				}
+				if len(r.ExcludeTargets) != 2 {
+					t.Errorf("expected exclude_targets union, got %v", r.ExcludeTargets)
+				}
+			},
+		},
 		{
 			name: "only global file — global values used",
 			globalYAML: `export:
@@ -689,16 +950,35 @@ func TestParseCustomRules(t *testing.T) {
 			"severity":    "critical",
 			"patterns":    []interface{}{"pattern1", "pattern2"},
 			"suggestion":  "try this instead",
+			"tests": map[string]interface{}{
+				"match":    []interface{}{"pattern1 --now"},
+				"no_match": []interface{}{"echo safe"},
+			},
 		},
 		{
 			"id":       "default-severity",
+			"patterns": []interface{}{"something"},
+		},
+		{
+			"id":       "bad-severity",
 			"severity": "unknown-value",
+			"patterns": []interface{}{"something"},
+		},
+		{
+			"id":       "bad-pattern",
+			"patterns": []interface{}{"("},
+		},
+		{
+			"id": "no-patterns",
+		},
+		{
+			"patterns": []interface{}{"missing-id"},
 		},
 	}
 
-	rules := parseCustomRules(rulesMaps)
+	rules, errs := parseCustomRules(rulesMaps, "/repo/.lazymake.yaml")
 	if len(rules) != 2 {
-		t.Fatalf("expected 2 rules, got %d", len(rules))
+		t.Fatalf("expected 2 valid rules, got %d", len(rules))
 	}
 
 	if rules[0].ID != "test-rule" {
@@ -713,11 +993,33 @@ func TestParseCustomRules(t *testing.T) {
 	if rules[0].Suggestion != "try this instead" {
 		t.Errorf("expected suggestion='try this instead', got %s", rules[0].Suggestion)
 	}
+	if rules[0].Source != "/repo/.lazymake.yaml" {
+		t.Errorf("expected the config file as source, got %q", rules[0].Source)
+	}
+	if rules[0].Tests.Count() != 2 || rules[0].Tests.NoMatch[0] != "echo safe" {
+		t.Errorf("expected match and no_match examples, got %+v", rules[0].Tests)
+	}
 
-	// Unknown severity defaults to warning
+	// Omitted severity defaults to warning
 	if rules[1].Severity != safety.SeverityWarning {
 		t.Errorf("expected default severity=warning, got %v", rules[1].Severity)
 	}
+
+	// Invalid rules are reported, not silently dropped
+	wantErrors := []string{
+		"rule bad-severity: invalid severity",
+		"rule bad-pattern: invalid pattern",
+		"rule no-patterns: needs patterns or a command",
+		"rule #6: missing id",
+	}
+	if len(errs) != len(wantErrors) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrors), errs)
+	}
+	for i, want := range wantErrors {
+		if !strings.Contains(errs[i].Error(), want) || !strings.HasPrefix(errs[i].Error(), "/repo/.lazymake.yaml: ") {
+			t.Errorf("error %d: expected %q with the file name, got %q", i, want, errs[i].Error())
+		}
+	}
 }
 
 // assertSliceEqual checks that two string slices have the same elements in the same order.
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"cmp"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// EnvPrefix starts the environment variable of every setting: LAZYMAKE_EXPORT_FORMAT
+const EnvPrefix = "LAZYMAKE_"
+
+// Override is a setting that can also be given by environment variable or flag
+// Precedence, lowest first: default, global file, project file, environment, flag.
+type Override struct {
+	Key   string // "export.format", or "makefile"
+	Env   string // "LAZYMAKE_EXPORT_FORMAT"
+	Flag  string // "export-format"
+	Short string // One-letter flag ("" for none)
+	Type  string // Schema type: "string", "boolean", "integer", "number" or "array" (comma-separated strings)
+	Usage string
+}
+
+// flagNames renames flags that predate the naming scheme
+var flagNames = map[string]struct{ name, short string }{
+	"makefile": {"file", "f"},
+}
+
+// flagUsages replaces the schema description where the flag means more than the key
+var flagUsages = map[string]string{
+	"makefile":       "Path to Makefile",
+	"safety.profile": "Safety profile to use (overrides detection)",
+}
+
+// Overrides lists the settings that have an environment variable and a flag, in
+// key order. Rules, profiles, detect rules and target confirmations need a file.
+func Overrides() []Override {
+	root, err := rootSchema()
+	if err != nil {
+		return nil
+	}
+
+	var overrides []Override
+	add := func(key string, property *schemaNode) {
+		schema := root.resolve(property)
+		kind := overrideType(root, schema)
+		if kind == "" {
+			return
+		}
+
+		override := Override{
+			Key:   key,
+			Env:   EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_")),
+			Flag:  strings.ReplaceAll(strings.ReplaceAll(key, ".", "-"), "_", "-"),
+			Type:  kind,
+			Usage: cmp.Or(property.Description, schema.Description),
+		}
+		if name, ok := flagNames[key]; ok {
+			override.Flag, override.Short = name.name, name.short
+		}
+		if usage, ok := flagUsages[key]; ok {
+			override.Usage = usage
+		}
+		if len(schema.Enum) > 0 {
+			override.Usage += ": " + enumList(schema.Enum)
+		}
+		if kind == "array" {
+			override.Usage += " (comma-separated)"
+		}
+		overrides = append(overrides, override)
+	}
+
+	for name, property := range root.Properties {
+		section := root.resolve(property)
+		if len(section.Properties) == 0 {
+			add(name, property)
+			continue
+		}
+		for key, schema := range section.Properties {
+			add(name+"."+key, schema)
+		}
+	}
+
+	slices.SortFunc(overrides, func(a, b Override) int { return strings.Compare(a.Key, b.Key) })
+	return overrides
+}
+
+// overrideType returns how a setting is given on the command line, or "" if it can't be
+func overrideType(root, schema *schemaNode) string {
+	if len(schema.Enum) > 0 {
+		return "string"
+	}
+
+	types := schema.types()
+	if len(types) != 1 {
+		return ""
+	}
+	switch types[0] {
+	case "string", "boolean", "integer", "number":
+		return types[0]
+	case "array":
+		if items := root.resolve(schema.Items); items != nil && slices.Equal(items.types(), []string{"string"}) {
+			return "array"
+		}
+	}
+	return ""
+}
+
+// boundFlags holds the override flags registered by BindFlags; Load reads the ones given
+var boundFlags *pflag.FlagSet
+
+// BindFlags adds a flag for every override to fs
+func BindFlags(fs *pflag.FlagSet) {
+	for _, override := range Overrides() {
+		switch override.Type {
+		case "boolean":
+			fs.BoolP(override.Flag, override.Short, false, override.Usage)
+		case "integer":
+			fs.IntP(override.Flag, override.Short, 0, override.Usage)
+		case "number":
+			fs.Float64P(override.Flag, override.Short, 0, override.Usage)
+		case "array":
+			fs.StringSliceP(override.Flag, override.Short, nil, override.Usage)
+		default:
+			fs.StringP(override.Flag, override.Short, "", override.Usage)
+		}
+	}
+	boundFlags = fs
+}
+
+// ValidateOverrides checks the values of LAZYMAKE_* environment variables and
+// override flags; invalid values are left out by Load
+func ValidateOverrides() []Problem {
+	overrides := Overrides()
+	return slices.Concat(envLayer(overrides).problems, flagLayer(overrides, boundFlags).problems)
+}
+
+// overrideLayer is the settings given by environment variables or flags, read
+// like a config file so the merge rules (and field sets) are the same
+type overrideLayer struct {
+	viper    *viper.Viper
+	origins  map[string]Origin // Key -> variable or flag name
+	problems []Problem
+}
+
+// originOf returns the variable or flag that set a key
+func (l *overrideLayer) originOf(key string) Origin {
+	return l.origins[key]
+}
+
+// envLayer reads the settings given by LAZYMAKE_* environment variables
+// Empty variables are ignored.
+func envLayer(overrides []Override) *overrideLayer {
+	return newOverrideLayer(overrides, func(o Override) (string, []string, Origin, bool) {
+		value := os.Getenv(o.Env)
+		if value == "" {
+			return "", nil, "", false
+		}
+		return value, splitList(value), Origin("$" + o.Env), true
+	})
+}
+
+// flagLayer reads the override flags given on the command line (nil fs = none)
+func flagLayer(overrides []Override, fs *pflag.FlagSet) *overrideLayer {
+	return newOverrideLayer(overrides, func(o Override) (string, []string, Origin, bool) {
+		if fs == nil {
+			return "", nil, "", false
+		}
+		flag := fs.Lookup(o.Flag)
+		if flag == nil || !flag.Changed {
+			return "", nil, "", false
+		}
+		var list []string
+		if o.Type == "array" {
+			list, _ = fs.GetStringSlice(o.Flag)
+		}
+		return flag.Value.String(), list, Origin("--" + o.Flag), true
+	})
+}
+
+// newOverrideLayer validates the given values and sets the valid ones
+// lookup returns the raw value, the list for array settings, and the origin.
+func newOverrideLayer(overrides []Override, lookup func(Override) (string, []string, Origin, bool)) *overrideLayer {
+	layer := &overrideLayer{viper: viper.New(), origins: make(map[string]Origin)}
+
+	root, err := rootSchema()
+	if err != nil {
+		return layer
+	}
+
+	for _, override := range overrides {
+		raw, list, origin, ok := lookup(override)
+		if !ok {
+			continue
+		}
+
+		node := overrideNode(override, raw, list)
+		v := &validator{file: string(origin), root: root}
+		v.validate(node, schemaFor(root, override.Key), override.Key)
+		if len(v.problems) > 0 {
+			layer.problems = append(layer.problems, v.problems...)
+			continue // Invalid values are left out, as invalid rules are
+		}
+
+		if override.Type == "array" {
+			layer.viper.Set(override.Key, list)
+		} else {
+			layer.viper.Set(override.Key, raw)
+		}
+		layer.origins[override.Key] = origin
+	}
+	return layer
+}
+
+// overrideNode builds the YAML node a value would have in a config file
+// Strings keep their text ("123" stays a string); other values are resolved
+// as YAML would ("false" is a boolean).
+func overrideNode(override Override, raw string, list []string) *yaml.Node {
+	if override.Type == "array" {
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range list {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		}
+		return node
+	}
+
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: raw}
+	if override.Type == "string" {
+		node.Tag = "!!str"
+	}
+	return node
+}
+
+// schemaFor returns the schema of a "section.key" setting
+func schemaFor(root *schemaNode, key string) *schemaNode {
+	schema := root
+	for _, name := range strings.Split(key, ".") {
+		schema = root.resolve(schema)
+		if schema == nil {
+			return nil
+		}
+		schema = schema.Properties[name]
+	}
+	return schema
+}
+
+// splitList splits a comma-separated environment value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestOverrides(t *testing.T) {
+	overrides := map[string]Override{}
+	for _, override := range Overrides() {
+		overrides[override.Key] = override
+	}
+
+	tests := []struct {
+		key, env, flag, kind string
+	}{
+		{"makefile", "LAZYMAKE_MAKEFILE", "file", "string"},
+		{"export.format", "LAZYMAKE_EXPORT_FORMAT", "export-format", "string"},
+		{"safety.enabled", "LAZYMAKE_SAFETY_ENABLED", "safety-enabled", "boolean"},
+		{"safety.exclude_targets", "LAZYMAKE_SAFETY_EXCLUDE_TARGETS", "safety-exclude-targets", "array"},
+		{"safety.profile", "LAZYMAKE_SAFETY_PROFILE", "safety-profile", "string"},
+		{"shell_integration.include_timestamp", "LAZYMAKE_SHELL_INTEGRATION_INCLUDE_TIMESTAMP", "shell-integration-include-timestamp", "boolean"},
+		{"performance.regression_threshold", "LAZYMAKE_PERFORMANCE_REGRESSION_THRESHOLD", "performance-regression-threshold", "number"},
+		{"storage.backend", "LAZYMAKE_STORAGE_BACKEND", "storage-backend", "string"},
+		{"workspace.aggregate", "LAZYMAKE_WORKSPACE_AGGREGATE", "workspace-aggregate", "boolean"},
+	}
+	for _, tt := range tests {
+		got, ok := overrides[tt.key]
+		if !ok {
+			t.Errorf("%s has no override", tt.key)
+			continue
+		}
+		if got.Env != tt.env || got.Flag != tt.flag || got.Type != tt.kind {
+			t.Errorf("%s = %s --%s (%s), want %s --%s (%s)", tt.key, got.Env, got.Flag, got.Type, tt.env, tt.flag, tt.kind)
+		}
+	}
+
+	// Settings that don't fit on a command line need a file
+	for _, key := range []string{"safety.custom_rules", "safety.profiles", "safety.detect", "safety.target_confirmation"} {
+		if _, ok := overrides[key]; ok {
+			t.Errorf("%s shouldn't have an override", key)
+		}
+	}
+}
+
+// bindTestFlags registers the override flags on a new flag set and parses args
+func bindTestFlags(t *testing.T, args ...string) {
+	t.Helper()
+	previous := boundFlags
+	t.Cleanup(func() { boundFlags = previous })
+
+	fs := pflag.NewFlagSet("lazymake", pflag.ContinueOnError)
+	BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_EnvAndFlagOverrides(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(project)
+
+	writeRulePack(t, filepath.Join(home, ".lazymake.yaml"), `
+safety:
+  exclude_targets: [clean]
+export:
+  format: log
+  max_files: 5
+`)
+	writeRulePack(t, filepath.Join(project, ".lazymake.yaml"), `
+export:
+  format: json
+  enabled: true
+`)
+
+	t.Setenv("LAZYMAKE_SAFETY_ENABLED", "false")
+	t.Setenv("LAZYMAKE_SAFETY_EXCLUDE_TARGETS", "reset-db, seed")
+	t.Setenv("LAZYMAKE_EXPORT_FORMAT", "both")
+	t.Setenv("LAZYMAKE_EXPORT_MAX_FILES", "lots") // Invalid: reported, file value kept
+	t.Setenv("LAZYMAKE_SAFETY_PROFILE", "CI")
+	bindTestFlags(t, "--export-format", "log", "--safety-exclude-targets", "deploy", "-f", "build.mk")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Safety.Enabled {
+		t.Error("expected safety disabled by LAZYMAKE_SAFETY_ENABLED")
+	}
+	if cfg.Export.Format != "log" {
+		t.Errorf("export.format = %q, want log from the flag", cfg.Export.Format)
+	}
+	if !cfg.Export.Enabled || cfg.Export.MaxFiles != 5 {
+		t.Errorf("expected file settings to be kept, got enabled=%v max_files=%d", cfg.Export.Enabled, cfg.Export.MaxFiles)
+	}
+	if cfg.MakefilePath != "build.mk" {
+		t.Errorf("makefile = %q, want build.mk", cfg.MakefilePath)
+	}
+	assertSliceEqual(t, cfg.Safety.ExcludeTargets, []string{"clean", "reset-db", "seed", "deploy"})
+
+	// A profile given by environment variable skips detection
+	if cfg.Safety.Profile != "ci" || cfg.Safety.ProfileSource != "$LAZYMAKE_SAFETY_PROFILE" {
+		t.Errorf("profile = %q from %q, want ci from $LAZYMAKE_SAFETY_PROFILE", cfg.Safety.Profile, cfg.Safety.ProfileSource)
+	}
+
+	origins := map[string]Origin{
+		"safety.enabled":         "$LAZYMAKE_SAFETY_ENABLED",
+		"safety.exclude_targets": "global + $LAZYMAKE_SAFETY_EXCLUDE_TARGETS + --safety-exclude-targets",
+		"export.format":          "--export-format",
+		"export.enabled":         OriginProject,
+		"export.max_files":       OriginGlobal,
+		"makefile":               "--file",
+	}
+	for key, want := range origins {
+		if got := cfg.Origin(key); got != want {
+			t.Errorf("Origin(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	if len(cfg.Problems) != 1 || cfg.Problems[0].File != "$LAZYMAKE_EXPORT_MAX_FILES" ||
+		!strings.Contains(cfg.Problems[0].Message, "expected integer") {
+		t.Errorf("expected one LAZYMAKE_EXPORT_MAX_FILES problem, got %v", cfg.Problems)
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	t.Setenv("LAZYMAKE_STORAGE_BACKEND", "postgres")
+	t.Setenv("LAZYMAKE_EXPORT_OUTPUT_DIR", "123") // Strings aren't read as numbers
+	bindTestFlags(t, "--shell-integration-shell", "tcsh")
+
+	problems := ValidateOverrides()
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %v", problems)
+	}
+	if got := problems[0].Error(); got != `$LAZYMAKE_STORAGE_BACKEND: storage.backend: invalid value "postgres": want json or sqlite` {
+		t.Errorf("unexpected problem: %s", got)
+	}
+	if problems[1].File != "--shell-integration-shell" {
+		t.Errorf("expected a --shell-integration-shell problem, got %v", problems[1])
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/spf13/viper"
+)
+
+// resolveRulePaths makes rule pack paths absolute
+// Relative paths are relative to the directory of the config file that lists
+// them; a leading ~ is the home directory.
+func resolveRulePaths(paths []string, baseDir string) []string {
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || rest[0] == '/' || rest[0] == filepath.Separator) {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		resolved = append(resolved, path)
+	}
+	return resolved
+}
+
+// LoadRulePacks reads safety rules from rule pack files
+// Each path is a YAML file or a directory whose *.yaml and *.yml files are read
+// in name order. A pack file holds a list of rules under "rules:", in the same
+// format as safety.custom_rules. Invalid rules, unreadable files and rules
+// whose id was already loaded are reported as errors and left out.
+func LoadRulePacks(paths []string) ([]safety.Rule, []safety.RuleError) {
+	var rules []safety.Rule
+	var errs []safety.RuleError
+
+	for _, path := range paths {
+		files, err := rulePackFiles(path)
+		if err != nil {
+			errs = append(errs, safety.RuleError{Source: path, Err: err})
+			continue
+		}
+
+		for _, file := range files {
+			packRules, packErrs := loadRulePack(file)
+			errs = append(errs, packErrs...)
+			rules = append(rules, packRules...)
+		}
+	}
+
+	return dropDuplicateRules(nil, rules, errs)
+}
+
+// addRulePacks loads cfg.RulePaths into cfg.CustomRules
+// Inline custom rules take precedence over pack rules with the same id.
+func addRulePacks(cfg *safety.Config) {
+	if len(cfg.RulePaths) == 0 {
+		return
+	}
+
+	rules, errs := LoadRulePacks(cfg.RulePaths)
+	rules, errs = dropDuplicateRules(cfg.CustomRules, rules, errs)
+
+	cfg.CustomRules = append(cfg.CustomRules, rules...)
+	cfg.RuleErrors = append(cfg.RuleErrors, errs...)
+}
+
+// rulePackFiles lists the pack files at path: the file itself, or the YAML files in a directory
+func rulePackFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule pack: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule pack directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadRulePack reads the rules of a single pack file
+func loadRulePack(file string) ([]safety.Rule, []safety.RuleError) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, []safety.RuleError{{Source: file, Err: fmt.Errorf("invalid YAML: %w", err)}}
+	}
+
+	if !v.IsSet("rules") {
+		return nil, []safety.RuleError{{Source: file, Err: fmt.Errorf("no rules: list them under \"rules:\"")}}
+	}
+
+	var ruleMaps []map[string]interface{}
+	if err := v.UnmarshalKey("rules", &ruleMaps); err != nil {
+		return nil, []safety.RuleError{{Source: file, Err: fmt.Errorf("rules must be a list: %w", err)}}
+	}
+
+	return parseCustomRules(ruleMaps, file)
+}
+
+// dropDuplicateRules leaves out rules whose id is already in existing or earlier in rules
+func dropDuplicateRules(existing, rules []safety.Rule, errs []safety.RuleError) ([]safety.Rule, []safety.RuleError) {
+	defined := make(map[string]string, len(existing)+len(rules))
+	for _, rule := range existing {
+		defined[rule.ID] = rule.Source
+	}
+
+	kept := rules[:0]
+	for _, rule := range rules {
+		if source, ok := defined[rule.ID]; ok {
+			err := fmt.Errorf("rule %s: duplicate id, already defined", rule.ID)
+			if source != "" {
+				err = fmt.Errorf("rule %s: duplicate id, already defined in %s", rule.ID, source)
+			}
+			errs = append(errs, safety.RuleError{Source: rule.Source, RuleID: rule.ID, Err: err})
+			continue
+		}
+		defined[rule.ID] = rule.Source
+		kept = append(kept, rule)
+	}
+
+	if len(kept) == 0 {
+		kept = nil
+	}
+	return kept, errs
+}
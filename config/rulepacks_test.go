@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/safety"
+)
+
+func writeRulePack(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRulePacks(t *testing.T) {
+	dir := t.TempDir()
+	packs := filepath.Join(dir, "packs")
+
+	writeRulePack(t, filepath.Join(packs, "b-k8s.yaml"), `
+rules:
+  - id: prod-kubectl-delete
+    severity: critical
+    patterns: ["kubectl\\s+delete.*--context[= ]prod"]
+    description: Deletes resources in production
+    tests:
+      match: ["kubectl delete ns app --context prod"]
+      no_match: ["kubectl get pods --context prod"]
+  - id: broken
+    patterns: ["("]
+`)
+	writeRulePack(t, filepath.Join(packs, "a-git.yml"), `
+rules:
+  - id: push-main
+    command: git
+    args:
+      - has: push
+      - has: main
+`)
+	writeRulePack(t, filepath.Join(packs, "c-dup.yaml"), `
+rules:
+  - id: push-main
+    patterns: ["git push origin main"]
+`)
+	writeRulePack(t, filepath.Join(packs, "README.md"), "not a pack")
+	writeRulePack(t, filepath.Join(dir, "single.yaml"), "severity: critical\n")
+
+	rules, errs := LoadRulePacks([]string{packs, filepath.Join(dir, "single.yaml"), filepath.Join(dir, "missing")})
+
+	// Files are read in name order
+	if len(rules) != 2 || rules[0].ID != "push-main" || rules[1].ID != "prod-kubectl-delete" {
+		t.Fatalf("expected push-main and prod-kubectl-delete, got %+v", rules)
+	}
+	if rules[0].Source != filepath.Join(packs, "a-git.yml") {
+		t.Errorf("expected the pack file as source, got %q", rules[0].Source)
+	}
+	if rules[1].Severity != safety.SeverityCritical || rules[1].Tests.Count() != 2 {
+		t.Errorf("unexpected rule %+v", rules[1])
+	}
+
+	wantErrors := []string{
+		"rule broken: invalid pattern",
+		"single.yaml: no rules",
+		"missing: failed to read rule pack",
+		"rule push-main: duplicate id, already defined in " + filepath.Join(packs, "a-git.yml"),
+	}
+	if len(errs) != len(wantErrors) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrors), errs)
+	}
+	for i, want := range wantErrors {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("error %d: expected %q, got %q", i, want, errs[i].Error())
+		}
+	}
+}
+
+func TestAddRulePacks_InlineRulesTakePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeRulePack(t, filepath.Join(dir, "pack.yaml"), `
+rules:
+  - id: shared
+    patterns: ["from-pack"]
+  - id: pack-only
+    patterns: ["pack-only"]
+`)
+
+	cfg := safety.DefaultConfig()
+	cfg.CustomRules = []safety.Rule{{ID: "shared", Patterns: []string{"inline"}, Source: ".lazymake.yaml"}}
+	cfg.RulePaths = []string{dir}
+
+	addRulePacks(cfg)
+
+	if len(cfg.CustomRules) != 2 || cfg.CustomRules[0].Patterns[0] != "inline" || cfg.CustomRules[1].ID != "pack-only" {
+		t.Errorf("expected the inline rule to win, got %+v", cfg.CustomRules)
+	}
+	if len(cfg.RuleErrors) != 1 || !strings.Contains(cfg.RuleErrors[0].Error(), "already defined in .lazymake.yaml") {
+		t.Errorf("expected a duplicate id error, got %v", cfg.RuleErrors)
+	}
+}
+
+func TestReadSafetyConfig_ResolvesRulePaths(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, ".lazymake.yaml")
+	writeRulePack(t, configFile, "safety:\n  rule_paths:\n    - rules\n    - /etc/lazymake/rules\n")
+
+	cfg, set := readSafetyConfig(loadViperFromFile(configFile))
+	if !set["rule_paths"] {
+		t.Error("expected rule_paths to be marked as set")
+	}
+	assertSliceEqual(t, cfg.RulePaths, []string{filepath.Join(dir, "rules"), "/etc/lazymake/rules"})
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Template is the starter config written by "lazymake config init"
+// Its first line points yaml-language-server at the schema.
+//
+//go:embed template.yaml
+var Template []byte
+
+// ErrConfigExists is returned by WriteTemplate when the file is already there
+var ErrConfigExists = errors.New("config file already exists")
+
+// WriteTemplate writes the starter config to path
+// An existing file is only replaced when force is set.
+func WriteTemplate(path string, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s: %w", path, ErrConfigExists)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(Template); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,431 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Schema is the JSON Schema of .lazymake.yaml
+// Editors that support yaml-language-server can use it for completion and checks.
+//
+//go:embed lazymake.schema.json
+var Schema []byte
+
+// Problem is an error in a config file found by Validate
+type Problem struct {
+	File    string
+	Line    int    // 1-based (0 if unknown)
+	Column  int    // 1-based (0 if unknown)
+	Path    string // Key path: "safety.custom_rules[1].severity" ("" for the whole file)
+	Message string
+}
+
+func (p Problem) Error() string {
+	var b strings.Builder
+	b.WriteString(p.File)
+	if p.Line > 0 {
+		fmt.Fprintf(&b, ":%d", p.Line)
+		if p.Column > 0 {
+			fmt.Fprintf(&b, ":%d", p.Column)
+		}
+	}
+	b.WriteString(": ")
+	if p.Path != "" {
+		b.WriteString(p.Path + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidateFile checks a config file against the schema
+// Returns an error only if the file can't be read; YAML syntax errors are problems.
+func ValidateFile(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Validate(path, data), nil
+}
+
+// Validate checks YAML config data against the schema
+// Unknown keys, wrong types, invalid values and patterns that don't compile
+// are reported with their line; file names the data in messages.
+func Validate(file string, data []byte) []Problem {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Problem{yamlSyntaxProblem(file, err)}
+	}
+	if len(doc.Content) == 0 {
+		return nil // Empty file
+	}
+
+	root, err := rootSchema()
+	if err != nil {
+		return []Problem{{File: file, Message: err.Error()}}
+	}
+
+	v := &validator{file: file, root: root}
+	v.validate(doc.Content[0], root, "")
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Line < v.problems[j].Line
+	})
+	return v.problems
+}
+
+// yamlLinePattern finds the line in a YAML syntax error: "yaml: line 3: ..."
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// yamlSyntaxProblem converts a YAML parse error to a problem
+func yamlSyntaxProblem(file string, err error) Problem {
+	message := err.Error()
+	problem := Problem{File: file}
+	if m := yamlLinePattern.FindStringSubmatch(message); m != nil {
+		problem.Line, _ = strconv.Atoi(m[1])
+		message = message[len(m[0]):]
+	}
+	problem.Message = "invalid YAML: " + strings.TrimPrefix(message, "yaml: ")
+	return problem
+}
+
+// schemaNode is the subset of JSON Schema the config schema uses
+type schemaNode struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 json.RawMessage        `json:"type"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+	Enum                 []any                  `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	MinProperties        *int                   `json:"minProperties"`
+	MaxProperties        *int                   `json:"maxProperties"`
+	Required             []string               `json:"required"`
+	AnyOf                []*schemaNode          `json:"anyOf"`
+	Format               string                 `json:"format"`
+	ErrorMessage         string                 `json:"errorMessage"`
+	Defs                 map[string]*schemaNode `json:"$defs"`
+	Description          string                 `json:"description"`
+	Default              any                    `json:"default"`
+}
+
+// types returns the allowed JSON types ("" = any)
+func (s *schemaNode) types() []string {
+	if len(s.Type) == 0 {
+		return nil
+	}
+	var one string
+	if json.Unmarshal(s.Type, &one) == nil {
+		return []string{one}
+	}
+	var many []string
+	_ = json.Unmarshal(s.Type, &many)
+	return many
+}
+
+// additional returns the schema for keys not in Properties
+// closed is true when additionalProperties is false.
+func (s *schemaNode) additional() (schema *schemaNode, closed bool) {
+	if len(s.AdditionalProperties) == 0 {
+		return nil, false
+	}
+	var allowed bool
+	if json.Unmarshal(s.AdditionalProperties, &allowed) == nil {
+		return nil, !allowed
+	}
+	schema = &schemaNode{}
+	if json.Unmarshal(s.AdditionalProperties, schema) != nil {
+		return nil, false
+	}
+	return schema, false
+}
+
+// rootSchema parses the embedded schema
+func rootSchema() (*schemaNode, error) {
+	var root schemaNode
+	if err := json.Unmarshal(Schema, &root); err != nil {
+		return nil, fmt.Errorf("invalid built-in schema: %w", err)
+	}
+	return &root, nil
+}
+
+// resolve follows $ref to a definition in the root schema
+func (s *schemaNode) resolve(node *schemaNode) *schemaNode {
+	for node != nil && node.Ref != "" {
+		name, ok := strings.CutPrefix(node.Ref, "#/$defs/")
+		if !ok {
+			return nil
+		}
+		node = s.Defs[name]
+	}
+	return node
+}
+
+type validator struct {
+	file     string
+	root     *schemaNode
+	problems []Problem
+}
+
+func (v *validator) report(node *yaml.Node, path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{
+		File:    v.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validate checks node against schema and records problems
+func (v *validator) validate(node *yaml.Node, schema *schemaNode, path string) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	schema = v.root.resolve(schema)
+	if schema == nil {
+		return
+	}
+
+	if len(schema.AnyOf) > 0 && !v.matchesAny(node, schema.AnyOf, path) {
+		message := schema.ErrorMessage
+		if message == "" {
+			message = "doesn't match any allowed form"
+		}
+		v.report(node, path, "%s", message)
+	}
+
+	kind := nodeType(node)
+	if types := schema.types(); len(types) > 0 && !typeAllowed(kind, types) {
+		v.report(node, path, "expected %s, got %s", strings.Join(types, " or "), describeNode(node, kind))
+		return
+	}
+
+	if len(schema.Enum) > 0 {
+		if !enumContains(schema.Enum, node) {
+			v.report(node, path, "invalid value %s: want %s", describeNode(node, kind), enumList(schema.Enum))
+		}
+		return
+	}
+
+	switch kind {
+	case "object":
+		v.validateObject(node, schema, path)
+	case "array":
+		if schema.Items != nil {
+			for i, item := range node.Content {
+				v.validate(item, schema.Items, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case "integer", "number":
+		if schema.Minimum != nil {
+			if value, err := strconv.ParseFloat(node.Value, 64); err == nil && value < *schema.Minimum {
+				v.report(node, path, "must be at least %s", strconv.FormatFloat(*schema.Minimum, 'f', -1, 64))
+			}
+		}
+	case "string":
+		v.validateFormat(node, schema.Format, path)
+	}
+}
+
+// validateObject checks the keys of a mapping
+func (v *validator) validateObject(node *yaml.Node, schema *schemaNode, path string) {
+	additional, closed := schema.additional()
+	seen := make(map[string]bool, len(node.Content)/2)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := keyNode.Value
+		keyPath := joinPath(path, key)
+
+		if seen[key] {
+			v.report(keyNode, keyPath, "duplicate key")
+			continue
+		}
+		seen[key] = true
+
+		if property, ok := schema.Properties[key]; ok {
+			v.validate(valueNode, property, keyPath)
+			continue
+		}
+		if closed {
+			message := fmt.Sprintf("unknown key %q", key)
+			if suggestion := closestKey(key, schema.Properties); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			v.report(keyNode, keyPath, "%s", message)
+			continue
+		}
+		if additional != nil {
+			v.validate(valueNode, additional, keyPath)
+		}
+	}
+
+	for _, key := range schema.Required {
+		if !seen[key] {
+			v.report(node, path, "missing %s", key)
+		}
+	}
+
+	count := len(seen)
+	if (schema.MinProperties != nil && count < *schema.MinProperties) ||
+		(schema.MaxProperties != nil && count > *schema.MaxProperties) {
+		message := schema.ErrorMessage
+		if message == "" {
+			message = "wrong number of keys"
+		}
+		v.report(node, path, "%s", message)
+	}
+}
+
+// validateFormat checks string formats the schema uses: regex and duration
+func (v *validator) validateFormat(node *yaml.Node, format, path string) {
+	switch format {
+	case "regex":
+		if _, err := regexp.Compile(node.Value); err != nil {
+			v.report(node, path, "invalid regex %q: %v", node.Value, errors.Unwrap(err))
+		}
+	case "duration":
+		if _, err := time.ParseDuration(node.Value); err != nil {
+			v.report(node, path, "invalid duration %q: want a duration like 10s or 1m30s", node.Value)
+		}
+	}
+}
+
+// matchesAny reports whether node is valid against at least one schema
+func (v *validator) matchesAny(node *yaml.Node, schemas []*schemaNode, path string) bool {
+	for _, schema := range schemas {
+		probe := &validator{file: v.file, root: v.root}
+		probe.validate(node, schema, path)
+		if len(probe.problems) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeType returns the JSON type of a YAML node
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			return "null"
+		case "!!bool":
+			return "boolean"
+		case "!!int":
+			return "integer"
+		case "!!float":
+			return "number"
+		}
+		return "string"
+	}
+	return "unknown"
+}
+
+// typeAllowed reports whether a node type satisfies the schema types
+// (an integer is also a number)
+func typeAllowed(kind string, types []string) bool {
+	return slices.Contains(types, kind) || (kind == "integer" && slices.Contains(types, "number"))
+}
+
+// describeNode names a value for messages: `"yes"`, `10`, a list
+func describeNode(node *yaml.Node, kind string) string {
+	switch kind {
+	case "object":
+		return "a mapping"
+	case "array":
+		return "a list"
+	case "null":
+		return "nothing"
+	case "string":
+		return strconv.Quote(node.Value)
+	}
+	return node.Value
+}
+
+// enumContains reports whether a scalar node equals one of the allowed values
+func enumContains(values []any, node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
+	for _, value := range values {
+		if fmt.Sprint(value) == node.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// enumList formats allowed values: "json, log or both"
+func enumList(values []any) string {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = fmt.Sprint(value)
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// closestKey suggests a known key for a typo, or "" if none is close
+func closestKey(key string, properties map[string]*schemaNode) string {
+	best, bestDistance := "", math.MaxInt
+	for candidate := range properties {
+		distance := editDistance(strings.ToLower(key), candidate)
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	// Close enough to be a typo, or the key is a prefix of a longer one (max_file_size → max_file_size_mb)
+	if bestDistance <= max(2, len(key)/4) || strings.HasPrefix(best, key) {
+		return best
+	}
+	return ""
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string // Expected problems, as "line: message substring"
+	}{
+		{
+			name: "valid config",
+			yaml: `
+safety:
+  enabled: true
+  custom_rules:
+    - id: no-prod
+      severity: critical
+      patterns: ["deploy.*prod"]
+      confirmation:
+        type: target
+        countdown: 5s
+export:
+  format: both
+  max_file_size_mb: 10
+performance:
+  regression_threshold: 2.5
+`,
+		},
+		{
+			name: "empty file",
+			yaml: "",
+		},
+		{
+			name: "misspelled section",
+			yaml: "safty:\n  enabled: false\n",
+			want: []string{`1: unknown key "safty" (did you mean "safety"?)`},
+		},
+		{
+			name: "key missing a suffix",
+			yaml: "export:\n  max_file_size: 10\n",
+			want: []string{`2: unknown key "max_file_size" (did you mean "max_file_size_mb"?)`},
+		},
+		{
+			name: "invalid enum value",
+			yaml: "export:\n  format: xml\n",
+			want: []string{`2: invalid value "xml": want json, log or both`},
+		},
+		{
+			name: "wrong type",
+			yaml: "safety:\n  enabled: \"maybe\"\n  exclude_targets: deploy\n",
+			want: []string{
+				`2: expected boolean, got "maybe"`,
+				`3: expected array, got "deploy"`,
+			},
+		},
+		{
+			name: "negative number",
+			yaml: "performance:\n  min_samples: -1\n",
+			want: []string{"2: must be at least 0"},
+		},
+		{
+			name: "invalid rule",
+			yaml: `
+safety:
+  custom_rules:
+    - id: bad
+      severity: fatal
+      patterns: ["rm -rf (unclosed"]
+    - severity: info
+`,
+			want: []string{
+				`5: invalid value "fatal": want critical, warning or info`,
+				`6: invalid regex "rm -rf (unclosed"`,
+				"7: needs patterns or a command",
+				"7: missing id",
+			},
+		},
+		{
+			name: "invalid countdown",
+			yaml: "safety:\n  target_confirmation:\n    deploy:\n      countdown: soon\n",
+			want: []string{"4: want seconds or a duration like 10s"},
+		},
+		{
+			name: "args with two conditions",
+			yaml: `
+safety:
+  custom_rules:
+    - id: kubectl-delete
+      command: kubectl
+      args:
+        - has: delete
+          flag: --all
+`,
+			want: []string{"7: set exactly one of has, flag, matches or without"},
+		},
+		{
+			name: "duplicate key",
+			yaml: "export:\n  enabled: true\n  enabled: false\n",
+			want: []string{`3: duplicate key`},
+		},
+		{
+			name: "YAML syntax error",
+			yaml: "export:\n  enabled: true\n  format: json: log\n",
+			want: []string{"3: invalid YAML: mapping values are not allowed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := Validate(".lazymake.yaml", []byte(tt.yaml))
+			if len(problems) != len(tt.want) {
+				t.Fatalf("got %d problems, want %d: %v", len(problems), len(tt.want), problems)
+			}
+			for i, want := range tt.want {
+				line, message, _ := strings.Cut(want, ": ")
+				got := problems[i]
+				if gotLine := strings.TrimSpace(line); gotLine != strconv.Itoa(got.Line) {
+					t.Errorf("problem %d line = %d, want %s (%v)", i, got.Line, line, got)
+				}
+				if !strings.Contains(got.Message, message) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, got.Message, message)
+				}
+			}
+		})
+	}
+}
+
+func TestProblem_Error(t *testing.T) {
+	problem := Problem{File: ".lazymake.yaml", Line: 3, Column: 5, Path: "export.format", Message: "invalid value"}
+	if got, want := problem.Error(), ".lazymake.yaml:3:5: export.format: invalid value"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestSchemaIsValidJSON(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("embedded schema: %v", err)
+	}
+}
+
+// The documented example and the init template must pass validation
+func TestExampleConfigsAreValid(t *testing.T) {
+	files := map[string][]byte{"template.yaml": Template}
+
+	example, err := os.ReadFile(filepath.Join("..", ".lazymake.example.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files[".lazymake.example.yaml"] = example
+
+	for name, data := range files {
+		for _, problem := range Validate(name, data) {
+			t.Errorf("%v", problem)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package audit checks every Makefile in a repository for dangerous targets
+// and reports the results for code review and CI.
+package audit
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/variables"
+	"github.com/rshelekhov/lazymake/internal/workspace"
+)
+
+// Options configures an audit
+type Options struct {
+	Root      string                     // Directory to search for Makefiles
+	Discovery workspace.DiscoveryOptions // Depth, excluded directories and timeout
+	Safety    *safety.Config             // Rules, exclusions and custom rules (nil = defaults)
+	Profile   *safety.Profile            // Severity adjustment policy (nil = built-in default)
+}
+
+// Finding is a safety rule matched by a target's recipe
+type Finding struct {
+	Severity     safety.Severity
+	RuleID       string
+	Makefile     string // Path relative to the audit root
+	Target       string
+	TargetLine   int      // Line of the target definition (0 if unknown)
+	Line         int      // Line of the matched recipe line (0 if unknown)
+	MatchedLine  string   // Recipe line that matched (expanded, if variables produced the match)
+	Command      string   // Command within the line that matched
+	ExpandedFrom string   // Recipe line as written, when the match needed variable expansion
+	Variables    []string // Variables whose values produced the match
+	Description  string
+	Suggestion   string
+
+	// Set when a "# lazymake:allow" annotation allows the match
+	Suppressed        bool
+	SuppressionReason string
+	SuppressionLine   int // Line of the annotation
+}
+
+// FileError is a Makefile that couldn't be audited
+type FileError struct {
+	Makefile string // Path relative to the audit root
+	Err      error
+}
+
+// Report is the result of auditing a directory tree
+type Report struct {
+	Root      string      // Absolute audit root
+	Profile   string      // Name of the profile used to adjust severities
+	Makefiles []string    // Audited Makefiles, relative to Root
+	Findings  []Finding   // Most severe first, then by Makefile and line; allowed findings last
+	Errors    []FileError // Makefiles that couldn't be parsed
+}
+
+// Run discovers Makefiles under opts.Root and checks every target
+// Each target is checked on its own recipe (no propagation through prerequisites),
+// so every finding points at the line that causes it. Variables are expanded
+// from their values in the Makefile; make itself is never run.
+func Run(opts Options) (*Report, error) {
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", opts.Root, err)
+	}
+
+	profile := opts.Profile
+	if profile == nil {
+		profile = safety.DefaultProfile()
+	}
+
+	discovered, err := workspace.DiscoverMakefiles(root, opts.Discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover Makefiles: %w", err)
+	}
+	sort.Slice(discovered, func(i, j int) bool {
+		return discovered[i].RelPath < discovered[j].RelPath
+	})
+
+	report := &Report{Root: root, Profile: profile.Name}
+	for _, result := range discovered {
+		findings, err := auditMakefile(result.Path, result.RelPath, opts.Safety, profile)
+		if err != nil {
+			report.Errors = append(report.Errors, FileError{Makefile: result.RelPath, Err: err})
+			continue
+		}
+		report.Makefiles = append(report.Makefiles, result.RelPath)
+		report.Findings = append(report.Findings, findings...)
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Suppressed != b.Suppressed {
+			return !a.Suppressed
+		}
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Makefile != b.Makefile {
+			return a.Makefile < b.Makefile
+		}
+		return a.Line < b.Line
+	})
+
+	return report, nil
+}
+
+// auditMakefile checks the targets of a single Makefile
+func auditMakefile(path, relPath string, cfg *safety.Config, profile *safety.Profile) ([]Finding, error) {
+	targets, err := makefile.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	checker, err := safety.NewChecker(cfg)
+	if err != nil {
+		return nil, err
+	}
+	checker.UseProfile(profile)
+
+	// Values are taken as written: running make would run $(shell ...) in
+	// every Makefile of the repository
+	// Graceful degradation: without variables, recipes are checked as written.
+	if vars, err := variables.ParseVariables(path); err == nil {
+		checker.SetVariables(safety.VariableValues(vars))
+	}
+
+	results := checker.CheckAllTargets(targets)
+
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		result := results[target.Name]
+		if result == nil || seen[target.Name] {
+			continue
+		}
+		seen[target.Name] = true
+
+		for _, match := range result.Matches {
+			findings = append(findings, newFinding(relPath, target, match))
+		}
+		for _, match := range result.Suppressed {
+			findings = append(findings, newFinding(relPath, target, match))
+		}
+	}
+
+	return findings, nil
+}
+
+// newFinding describes a rule matched by a target
+func newFinding(relPath string, target makefile.Target, match safety.MatchResult) Finding {
+	finding := Finding{
+		Severity:     match.Severity,
+		RuleID:       match.Rule.ID,
+		Makefile:     relPath,
+		Target:       target.Name,
+		TargetLine:   target.Line,
+		Line:         match.Line,
+		MatchedLine:  match.MatchedLine,
+		Command:      match.Command,
+		ExpandedFrom: match.ExpandedFrom,
+		Variables:    match.Variables,
+		Description:  match.Rule.Description,
+		Suggestion:   match.Rule.Suggestion,
+	}
+	if match.Suppression != nil {
+		finding.Suppressed = true
+		finding.SuppressionReason = match.Suppression.Reason
+		finding.SuppressionLine = match.Suppression.Line
+	}
+	return finding
+}
+
+// Count returns the number of findings at a severity, not counting allowed ones
+func (r *Report) Count(severity safety.Severity) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == severity && !finding.Suppressed {
+			count++
+		}
+	}
+	return count
+}
+
+// CountSuppressed returns the number of findings allowed by annotations
+func (r *Report) CountSuppressed() int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Suppressed {
+			count++
+		}
+	}
+	return count
+}
+
+// Exceeds reports whether any finding that isn't allowed is at or above the threshold
+func (r *Report) Exceeds(threshold safety.Severity) bool {
+	for _, finding := range r.Findings {
+		if finding.Severity >= threshold && !finding.Suppressed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,325 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/sarif"
+	"github.com/rshelekhov/lazymake/internal/testutil"
+	"github.com/rshelekhov/lazymake/internal/workspace"
+)
+
+// newTestRepo creates a repository with a root and a nested Makefile
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+
+	testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"DEST := /etc\n"+ // 1
+			"\n"+ // 2
+			"build:\n"+ // 3
+			"\tgo build ./...\n"+ // 4
+			"\n"+ // 5
+			"wipe:\n"+ // 6
+			"\techo wiping\n"+ // 7
+			"\trm -rf $(DEST)\n") // 8
+
+	testutil.WriteFile(t, filepath.Join(root, "services", "api", "Makefile"),
+		"deploy:\n"+ // 1
+			"\tgit push --force origin main\n") // 2
+
+	return root
+}
+
+func TestRun(t *testing.T) {
+	root := newTestRepo(t)
+
+	report, err := Run(Options{Root: root, Discovery: workspace.DefaultDiscoveryOptions()})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(report.Makefiles) != 2 {
+		t.Fatalf("expected 2 Makefiles, got %v", report.Makefiles)
+	}
+	if report.Profile != safety.DefaultProfileName {
+		t.Errorf("expected the built-in profile, got %q", report.Profile)
+	}
+	if len(report.Findings) < 2 {
+		t.Fatalf("expected findings in both Makefiles, got %+v", report.Findings)
+	}
+
+	// Most severe first
+	for i := 1; i < len(report.Findings); i++ {
+		if report.Findings[i].Severity > report.Findings[i-1].Severity {
+			t.Errorf("findings not sorted by severity: %+v", report.Findings)
+		}
+	}
+
+	var wipe *Finding
+	for i := range report.Findings {
+		if report.Findings[i].Target == "wipe" {
+			wipe = &report.Findings[i]
+		}
+	}
+	if wipe == nil || wipe.RuleID != "rm-rf-root" {
+		t.Fatalf("expected an rm-rf-root finding for wipe, got %+v", report.Findings)
+	}
+	if wipe.Makefile != "Makefile" || wipe.Line != 8 || wipe.TargetLine != 6 {
+		t.Errorf("expected Makefile:8 (target on line 6), got %s:%d (target on line %d)", wipe.Makefile, wipe.Line, wipe.TargetLine)
+	}
+	if wipe.MatchedLine != "rm -rf /etc" || wipe.ExpandedFrom != "rm -rf $(DEST)" {
+		t.Errorf("expected the match to come from variable expansion, got %q from %q", wipe.MatchedLine, wipe.ExpandedFrom)
+	}
+
+	var deploy *Finding
+	for i := range report.Findings {
+		if report.Findings[i].Target == "deploy" {
+			deploy = &report.Findings[i]
+		}
+	}
+	if deploy == nil || deploy.Makefile != filepath.Join("services", "api", "Makefile") || deploy.Line != 2 {
+		t.Errorf("expected a deploy finding at services/api/Makefile:2, got %+v", deploy)
+	}
+
+	if !report.Exceeds(safety.SeverityCritical) {
+		t.Error("expected the report to exceed the critical threshold")
+	}
+}
+
+func TestRun_RespectsConfig(t *testing.T) {
+	root := newTestRepo(t)
+
+	cfg := safety.DefaultConfig()
+	cfg.ExcludeTargets = []string{"wipe"}
+
+	report, err := Run(Options{Root: root, Discovery: workspace.DefaultDiscoveryOptions(), Safety: cfg})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for _, f := range report.Findings {
+		if f.Target == "wipe" {
+			t.Errorf("excluded target reported: %+v", f)
+		}
+	}
+	if len(report.Findings) == 0 {
+		t.Error("expected the deploy finding to remain")
+	}
+}
+
+func TestRun_Suppressions(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"# lazymake:allow git-force-push reason=\"release branch only\"\n"+ // 1
+			"release:\n"+ // 2
+			"\tgit push --force origin release\n") // 3
+
+	report, err := Run(Options{Root: root, Discovery: workspace.DefaultDiscoveryOptions()})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(report.Findings) != 1 || !report.Findings[0].Suppressed {
+		t.Fatalf("expected one allowed finding, got %+v", report.Findings)
+	}
+	if f := report.Findings[0]; f.SuppressionReason != "release branch only" || f.SuppressionLine != 1 || f.Line != 3 {
+		t.Errorf("unexpected allowed finding %+v", f)
+	}
+	if report.Count(safety.SeverityCritical) != 0 || report.CountSuppressed() != 1 {
+		t.Errorf("allowed findings should only be counted as allowed")
+	}
+	if report.Exceeds(safety.SeverityInfo) {
+		t.Error("allowed findings should not fail the audit")
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "ALLOWED (1)") || !strings.Contains(text.String(), "release branch only") {
+		t.Errorf("text report should list the allowed finding with its reason:\n%s", text.String())
+	}
+
+	var sarifOut bytes.Buffer
+	if err := report.WriteSARIF(&sarifOut); err != nil {
+		t.Fatal(err)
+	}
+	var log sarif.Log
+	if err := json.Unmarshal(sarifOut.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	suppressions := log.Runs[0].Results[0].Suppressions
+	if len(suppressions) != 1 || suppressions[0].Kind != "inSource" || suppressions[0].Justification != "release branch only" {
+		t.Errorf("expected an in-source suppression, got %+v", suppressions)
+	}
+}
+
+func TestRun_SuppressedAndUnsuppressedLines(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"push:\n"+ // 1
+			"\tgit push --force origin release # lazymake:allow git-force-push reason=\"release branch\"\n"+ // 2
+			"\techo pushed\n"+ // 3
+			"\tgit push --force origin main\n") // 4
+
+	report, err := Run(Options{Root: root, Discovery: workspace.DefaultDiscoveryOptions()})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(report.Findings) != 2 {
+		t.Fatalf("expected an allowed and a flagged finding, got %+v", report.Findings)
+	}
+	if f := report.Findings[0]; f.Suppressed || f.Line != 4 {
+		t.Errorf("expected line 4 flagged first, got %+v", f)
+	}
+	if f := report.Findings[1]; !f.Suppressed || f.Line != 2 || f.SuppressionReason != "release branch" {
+		t.Errorf("expected line 2 allowed with its reason, got %+v", f)
+	}
+}
+
+func TestRun_DoesNotRunMake(t *testing.T) {
+	root := t.TempDir()
+	pwned := filepath.Join(root, "PWNED")
+	testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"STAMP := $(shell touch "+pwned+")\n"+
+			"DEST := /etc\n"+
+			"\n"+
+			"wipe:\n"+
+			"\trm -rf $(DEST)\n")
+
+	report, err := Run(Options{Root: root, Discovery: workspace.DefaultDiscoveryOptions()})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if _, err := os.Stat(pwned); err == nil {
+		t.Error("audit ran $(shell ...) from the Makefile")
+	}
+	if len(report.Findings) != 1 || report.Findings[0].MatchedLine != "rm -rf /etc" {
+		t.Errorf("expected rm -rf $(DEST) expanded from the value as written, got %+v", report.Findings)
+	}
+}
+
+func TestRun_ExpansionOnlyMatch(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"DEST := /etc\n"+
+			"\n"+
+			"wipe:\n"+
+			"\trm -rf $(DEST)\n")
+
+	report, err := Run(Options{Root: root, Discovery: workspace.DefaultDiscoveryOptions()})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Findings) != 1 || !slices.Equal(report.Findings[0].Variables, []string{"DEST"}) {
+		t.Fatalf("expected one finding produced by DEST, got %+v", report.Findings)
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "variables: DEST") {
+		t.Errorf("text report should name the variable:\n%s", text.String())
+	}
+
+	var encoded bytes.Buffer
+	if err := report.WriteJSON(&encoded); err != nil {
+		t.Fatal(err)
+	}
+	var decoded jsonReport
+	if err := json.Unmarshal(encoded.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !slices.Equal(decoded.Findings[0].Variables, []string{"DEST"}) {
+		t.Errorf("JSON finding should name the variable: %+v", decoded.Findings[0])
+	}
+
+	var sarifOut bytes.Buffer
+	if err := report.WriteSARIF(&sarifOut); err != nil {
+		t.Fatal(err)
+	}
+	var log sarif.Log
+	if err := json.Unmarshal(sarifOut.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	result := log.Runs[0].Results[0]
+	if fmt.Sprint(result.Properties["variables"]) != "[DEST]" ||
+		!strings.Contains(result.Message.Text, "expanded from DEST") {
+		t.Errorf("SARIF result should name the variable: %+v", result)
+	}
+}
+
+func TestReport_Formats(t *testing.T) {
+	report := &Report{
+		Root:      "/repo",
+		Profile:   "default",
+		Makefiles: []string{"Makefile"},
+		Findings: []Finding{{
+			Severity:    safety.SeverityCritical,
+			RuleID:      "rm-rf-root",
+			Makefile:    "Makefile",
+			Target:      "wipe",
+			TargetLine:  6,
+			Line:        8,
+			MatchedLine: "sudo rm -rf /",
+			Description: "Recursive deletion of the root directory",
+			Suggestion:  "Double-check the path",
+		}},
+	}
+
+	var text bytes.Buffer
+	if err := report.Write(&text, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"CRITICAL (1)", "Makefile:8  wipe  [rm-rf-root]", "suggestion: Double-check the path", "1 critical, 0 warning, 0 info"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text report missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var encoded bytes.Buffer
+	if err := report.Write(&encoded, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var decoded jsonReport
+	if err := json.Unmarshal(encoded.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Summary["critical"] != 1 || len(decoded.Findings) != 1 || decoded.Findings[0].Severity != "critical" {
+		t.Errorf("unexpected JSON report: %+v", decoded)
+	}
+
+	var sarifOut bytes.Buffer
+	if err := report.Write(&sarifOut, FormatSARIF); err != nil {
+		t.Fatal(err)
+	}
+	var log sarif.Log
+	if err := json.Unmarshal(sarifOut.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("unexpected SARIF log: %+v", log)
+	}
+	result := log.Runs[0].Results[0]
+	if result.Level != "error" || result.RuleID != "rm-rf-root" || result.Locations[0].PhysicalLocation.Region.StartLine != 8 {
+		t.Errorf("unexpected SARIF result: %+v", result)
+	}
+	if rules := log.Runs[0].Tool.Driver.Rules; len(rules) != 1 || rules[0].Help == nil {
+		t.Errorf("expected one rule with help text, got %+v", rules)
+	}
+
+	if err := report.Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/sarif"
+)
+
+// Report formats
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// severities lists severities in report order
+var severities = []safety.Severity{safety.SeverityCritical, safety.SeverityWarning, safety.SeverityInfo}
+
+// Write writes the report in the given format: text, json or sarif
+func (r *Report) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return r.WriteText(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	case FormatSARIF:
+		return r.WriteSARIF(w)
+	default:
+		return fmt.Errorf("unknown format %q: want text, json or sarif", format)
+	}
+}
+
+// WriteText writes a human-readable report grouped by severity
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	for _, severity := range severities {
+		count := r.Count(severity)
+		if count == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s (%d)\n\n", severity, count)
+		for _, f := range r.Findings {
+			if f.Severity == severity && !f.Suppressed {
+				writeTextFinding(&b, f)
+			}
+		}
+	}
+
+	if suppressed := r.CountSuppressed(); suppressed > 0 {
+		fmt.Fprintf(&b, "ALLOWED (%d)\n\n", suppressed)
+		for _, f := range r.Findings {
+			if f.Suppressed {
+				writeTextFinding(&b, f)
+			}
+		}
+	}
+
+	for _, e := range r.Errors {
+		fmt.Fprintf(&b, "error: %s: %v\n", e.Makefile, e.Err)
+	}
+	if len(r.Errors) > 0 {
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "Audited %d Makefile(s): %d critical, %d warning, %d info, %d allowed\n",
+		len(r.Makefiles), r.Count(safety.SeverityCritical), r.Count(safety.SeverityWarning), r.Count(safety.SeverityInfo), r.CountSuppressed())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTextFinding writes one finding of the text report
+func writeTextFinding(b *strings.Builder, f Finding) {
+	fmt.Fprintf(b, "  %s  %s  [%s]\n", location(f.Makefile, f.Line), f.Target, f.RuleID)
+	fmt.Fprintf(b, "    %s\n", f.MatchedLine)
+	if f.ExpandedFrom != "" {
+		fmt.Fprintf(b, "    expanded from: %s\n", f.ExpandedFrom)
+	}
+	if len(f.Variables) > 0 {
+		fmt.Fprintf(b, "    variables: %s\n", strings.Join(f.Variables, ", "))
+	}
+	if f.Suppressed {
+		reason := f.SuppressionReason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		fmt.Fprintf(b, "    allowed (%s, line %d): %s\n", strings.ToLower(f.Severity.String()), f.SuppressionLine, reason)
+	} else {
+		if f.Description != "" {
+			fmt.Fprintf(b, "    %s\n", f.Description)
+		}
+		if f.Suggestion != "" {
+			fmt.Fprintf(b, "    suggestion: %s\n", f.Suggestion)
+		}
+	}
+	b.WriteString("\n")
+}
+
+// location formats file:line, or just the file when the line is unknown
+func location(file string, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return file
+}
+
+// jsonReport is the JSON form of a report
+type jsonReport struct {
+	Root      string         `json:"root"`
+	Profile   string         `json:"profile"`
+	Makefiles []string       `json:"makefiles"`
+	Summary   map[string]int `json:"summary"`
+	Findings  []jsonFinding  `json:"findings"`
+	Errors    []jsonError    `json:"errors,omitempty"`
+}
+
+type jsonFinding struct {
+	Severity     string   `json:"severity"`
+	RuleID       string   `json:"rule_id"`
+	Makefile     string   `json:"makefile"`
+	Target       string   `json:"target"`
+	TargetLine   int      `json:"target_line,omitempty"`
+	Line         int      `json:"line,omitempty"`
+	MatchedLine  string   `json:"matched_line"`
+	Command      string   `json:"command,omitempty"`
+	ExpandedFrom string   `json:"expanded_from,omitempty"`
+	Variables    []string `json:"variables,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Suggestion   string   `json:"suggestion,omitempty"`
+
+	Suppressed        bool   `json:"suppressed,omitempty"`
+	SuppressionReason string `json:"suppression_reason,omitempty"`
+	SuppressionLine   int    `json:"suppression_line,omitempty"`
+}
+
+type jsonError struct {
+	Makefile string `json:"makefile"`
+	Error    string `json:"error"`
+}
+
+// WriteJSON writes the report as a JSON document
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := jsonReport{
+		Root:      r.Root,
+		Profile:   r.Profile,
+		Makefiles: r.Makefiles,
+		Summary:   make(map[string]int),
+		Findings:  make([]jsonFinding, 0, len(r.Findings)),
+	}
+	if out.Makefiles == nil {
+		out.Makefiles = []string{}
+	}
+
+	for _, severity := range severities {
+		out.Summary[severityName(severity)] = r.Count(severity)
+	}
+	out.Summary["suppressed"] = r.CountSuppressed()
+	for _, f := range r.Findings {
+		out.Findings = append(out.Findings, jsonFinding{
+			Severity:     severityName(f.Severity),
+			RuleID:       f.RuleID,
+			Makefile:     filepath.ToSlash(f.Makefile),
+			Target:       f.Target,
+			TargetLine:   f.TargetLine,
+			Line:         f.Line,
+			MatchedLine:  f.MatchedLine,
+			Command:      f.Command,
+			ExpandedFrom: f.ExpandedFrom,
+			Variables:    f.Variables,
+			Description:  f.Description,
+			Suggestion:   f.Suggestion,
+
+			Suppressed:        f.Suppressed,
+			SuppressionReason: f.SuppressionReason,
+			SuppressionLine:   f.SuppressionLine,
+		})
+	}
+	for _, e := range r.Errors {
+		out.Errors = append(out.Errors, jsonError{Makefile: filepath.ToSlash(e.Makefile), Error: e.Err.Error()})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// severityName returns the lowercase severity name used in JSON and config
+func severityName(severity safety.Severity) string {
+	return strings.ToLower(severity.String())
+}
+
+// WriteSARIF writes the report as SARIF 2.1.0 for code scanning tools
+// Paths are relative to the audit root, so run the audit from the repository root.
+func (r *Report) WriteSARIF(w io.Writer) error {
+	findings := make([]sarif.Finding, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		message := fmt.Sprintf("Target %q runs `%s`", f.Target, f.MatchedLine)
+		if len(f.Variables) > 0 {
+			message += fmt.Sprintf(" (expanded from %s)", strings.Join(f.Variables, ", "))
+		}
+		if f.Description != "" {
+			message += ": " + f.Description
+		}
+
+		finding := sarif.Finding{
+			RuleID:          f.RuleID,
+			RuleDescription: f.Description,
+			RuleHelp:        f.Suggestion,
+			Level:           sarifLevel(f.Severity),
+			Message:         message,
+			File:            f.Makefile,
+			Line:            max(f.Line, f.TargetLine),
+			Suppressed:      f.Suppressed,
+			Justification:   f.SuppressionReason,
+		}
+		if len(f.Variables) > 0 {
+			finding.Properties = map[string]any{"variables": f.Variables}
+		}
+		findings = append(findings, finding)
+	}
+	return sarif.Write(w, findings)
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity safety.Severity) string {
+	switch severity {
+	case safety.SeverityCritical:
+		return sarif.LevelError
+	case safety.SeverityWarning:
+		return sarif.LevelWarning
+	default:
+		return sarif.LevelNote
+	}
+}
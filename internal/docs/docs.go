@@ -0,0 +1,229 @@
+// Package docs renders reference documentation for a Makefile: its targets
+// with descriptions, sections, dependencies, variables and safety warnings,
+// as Markdown, standalone HTML or a man page.
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/graph"
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/project"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/variables"
+)
+
+// Options configures the generated documentation
+type Options struct {
+	Title  string         // Page title (default: the Makefile's header comment, else its file name)
+	Safety *safety.Config // Rules used for warnings (nil = defaults; disabled = no warnings)
+}
+
+// Page is the documentation of one Makefile
+// Everything in it comes from the Makefile alone (make is never run, and the
+// environment's safety profile isn't applied), so the same Makefile always
+// renders the same page.
+type Page struct {
+	Title     string
+	Makefile  string // Base name of the Makefile
+	Sections  []Section
+	Variables []Variable
+}
+
+// Section is a group of targets: a "##@" section or an @group
+type Section struct {
+	Name    string // "" for targets outside any section
+	Targets []Target
+}
+
+// Target is a documented target
+type Target struct {
+	Name         string
+	Description  string
+	Dependencies []string // Prerequisites that are targets in the Makefile
+	Docs         makefile.Docs
+	Warnings     []Warning // Safety rules matched by the recipe or by prerequisites it runs
+}
+
+// Warning is a safety rule a target matches
+type Warning struct {
+	Severity    safety.Severity
+	RuleID      string
+	Description string
+	Command     string   // Recipe line that matched
+	Via         []string // Prerequisite chain to the dangerous target (nil for the target's own recipe)
+}
+
+// Variable is a variable defined in the Makefile or a file it includes
+type Variable struct {
+	Name     string
+	Operator string // =, :=, ?=, += or !=
+	Value    string // As written, appends included
+	Exported bool
+	UsedBy   []string // Targets that use it, directly or through other variables
+}
+
+// Build reads the Makefile at path and collects its documentation
+// Targets marked @hidden and pattern rules are left out.
+func Build(path string, opts Options) (*Page, error) {
+	targets, err := makefile.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	page := &Page{
+		Title:    opts.Title,
+		Makefile: filepath.Base(path),
+	}
+	if page.Title == "" {
+		page.Title = headerTitle(absPath)
+	}
+	if page.Title == "" {
+		page.Title = page.Makefile
+	}
+
+	// Checked as the TUI checks it, through prerequisites and sub-makes, but
+	// with variables as written: generating docs must not run the Makefile
+	// Without variables there's no variable table and recipes are checked as written.
+	p := project.NewStatic(absPath, targets, opts.Safety, nil)
+
+	page.Sections = buildSections(targets, p.Graph, collectWarnings(p.Results))
+	page.Variables = buildVariables(p.Variables)
+	return page, nil
+}
+
+// headerTitle returns the first line of the comment block that opens the
+// Makefile, or "" if it has none
+// The block must be followed by a blank line, so a target's description isn't
+// taken for it; "##@" sections, "#!" lines and lazymake annotations are skipped.
+func headerTitle(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	title := ""
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			if title != "" {
+				return title
+			}
+		case !strings.HasPrefix(line, "#"):
+			return ""
+		case title == "" && !strings.HasPrefix(line, "##@") && !strings.HasPrefix(line, "#!") &&
+			!strings.Contains(line, "lazymake:"):
+			title = strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+	}
+	return ""
+}
+
+// buildSections groups the documented targets into sections, in the order they
+// first appear in the Makefile
+func buildSections(targets []makefile.Target, g *graph.Graph, warnings map[string][]Warning) []Section {
+	var sections []Section
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+
+	for _, t := range targets {
+		if seen[t.Name] || t.Docs.Hidden || strings.Contains(t.Name, "%") {
+			continue
+		}
+		seen[t.Name] = true
+
+		name := t.Docs.Group
+		if name == "" {
+			name = t.Section
+		}
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			i = len(sections)
+			index[strings.ToLower(name)] = i
+			sections = append(sections, Section{Name: name})
+		}
+
+		target := Target{
+			Name:        t.Name,
+			Description: t.Description,
+			Docs:        t.Docs,
+			Warnings:    warnings[t.Name],
+		}
+		if node := g.Nodes[t.Name]; node != nil {
+			for _, dep := range node.Dependencies {
+				target.Dependencies = append(target.Dependencies, dep.Target.Name)
+			}
+		}
+		sections[i].Targets = append(sections[i].Targets, target)
+	}
+	return sections
+}
+
+// collectWarnings lists the rules matched by every target and by what it runs
+func collectWarnings(results map[string]*safety.CheckResult) map[string][]Warning {
+	warnings := make(map[string][]Warning)
+	for name, result := range results {
+		for _, match := range result.Matches {
+			warnings[name] = append(warnings[name], newWarning(match, nil))
+		}
+		for _, dep := range result.Dependencies {
+			for _, match := range dep.Result.Matches {
+				warnings[name] = append(warnings[name], newWarning(match, dep.Path[1:]))
+			}
+		}
+	}
+	return warnings
+}
+
+// newWarning describes a rule matched by a target or by one of its prerequisites
+func newWarning(match safety.MatchResult, via []string) Warning {
+	return Warning{
+		Severity:    match.Severity,
+		RuleID:      match.Rule.ID,
+		Description: match.Rule.Description,
+		Command:     match.MatchedLine,
+		Via:         via,
+	}
+}
+
+// buildVariables lists the variables in the order make reads them
+func buildVariables(vars []variables.Variable) []Variable {
+	result := make([]Variable, 0, len(vars))
+	for _, v := range vars {
+		usedBy := append(slices.Clone(v.UsedByTargets), v.IndirectTargets...)
+		slices.Sort(usedBy)
+		result = append(result, Variable{
+			Name:     v.Name,
+			Operator: v.Type.Symbol(),
+			Value:    v.RawValue,
+			Exported: v.IsExported,
+			UsedBy:   slices.Compact(usedBy),
+		})
+	}
+	return result
+}
+
+// Anchor returns the fragment that links to a target's heading in HTML output
+func (t Target) Anchor() string {
+	var b strings.Builder
+	b.WriteString("target-")
+	for _, r := range t.Name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,238 @@
+package docs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/safety"
+)
+
+const testMakefile = "DEST ?= /srv/app\n" +
+	"export GOFLAGS = -v\n" +
+	"\n" +
+	"help: ## Show help\n" +
+	"\t@echo help\n" +
+	"\n" +
+	"##@ Building\n" +
+	"\n" +
+	"build: gen ## Build the app\n" +
+	"\tgo build $(GOFLAGS)\n" +
+	"\n" +
+	"gen:\n" +
+	"\tgo generate\n" +
+	"\n" +
+	"##@ Ops\n" +
+	"\n" +
+	"## Deploy the app\n" +
+	"## @arg ENV environment (default: staging)\n" +
+	"## @example make deploy ENV=prod\n" +
+	"deploy: wipe\n" +
+	"\t./deploy.sh $(ENV)\n" +
+	"\n" +
+	"## @deprecated use deploy\n" +
+	"wipe: ## Wipe <everything>\n" +
+	"\trm -rf $(DEST)\n" +
+	"\n" +
+	"## @hidden\n" +
+	"ci:\n" +
+	"\techo ci\n" +
+	"\n" +
+	"%.o: %.c\n" +
+	"\tcc -c $<\n"
+
+func buildTestPage(t *testing.T, opts Options) *Page {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "api")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "Makefile")
+	if err := os.WriteFile(path, []byte(testMakefile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	page, err := Build(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+// findTarget returns a documented target by name
+func findTarget(page *Page, name string) (Target, bool) {
+	for _, s := range page.Sections {
+		for _, target := range s.Targets {
+			if target.Name == name {
+				return target, true
+			}
+		}
+	}
+	return Target{}, false
+}
+
+func TestBuild(t *testing.T) {
+	page := buildTestPage(t, Options{Title: "app"})
+
+	var sections []string
+	for _, s := range page.Sections {
+		sections = append(sections, s.Name)
+	}
+	if want := []string{"", "Building", "Ops"}; !slices.Equal(sections, want) {
+		t.Errorf("sections = %q, want %q in Makefile order", sections, want)
+	}
+
+	for _, name := range []string{"ci", "%.o"} {
+		if _, ok := findTarget(page, name); ok {
+			t.Errorf("%s should be left out", name)
+		}
+	}
+
+	build, _ := findTarget(page, "build")
+	if !slices.Equal(build.Dependencies, []string{"gen"}) {
+		t.Errorf("build dependencies = %q, want [gen]", build.Dependencies)
+	}
+
+	wipe, _ := findTarget(page, "wipe")
+	if len(wipe.Warnings) == 0 || wipe.Warnings[0].RuleID != "rm-rf-root" || wipe.Warnings[0].Via != nil {
+		t.Errorf("expected wipe's own rm-rf-root warning, got %+v", wipe.Warnings)
+	}
+	deploy, _ := findTarget(page, "deploy")
+	if len(deploy.Warnings) == 0 || !slices.Equal(deploy.Warnings[0].Via, []string{"wipe"}) {
+		t.Errorf("expected deploy to inherit wipe's warning, got %+v", deploy.Warnings)
+	}
+
+	var dest Variable
+	for _, v := range page.Variables {
+		if v.Name == "DEST" {
+			dest = v
+		}
+	}
+	if dest.Operator != "?=" || dest.Value != "/srv/app" || !slices.Equal(dest.UsedBy, []string{"wipe"}) {
+		t.Errorf("unexpected DEST variable %+v", dest)
+	}
+}
+
+func TestBuild_SafetyDisabled(t *testing.T) {
+	cfg := safety.DefaultConfig()
+	cfg.Enabled = false
+	page := buildTestPage(t, Options{Safety: cfg})
+
+	if wipe, _ := findTarget(page, "wipe"); len(wipe.Warnings) != 0 {
+		t.Errorf("expected no warnings with safety disabled, got %+v", wipe.Warnings)
+	}
+	if page.Title != "Makefile" {
+		t.Errorf("expected the Makefile's name as the default title without a header, got %q", page.Title)
+	}
+}
+
+func TestHeaderTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"header", "# Payments API\n# Build and deploy\n\nbuild:\n\tgo build\n", "Payments API"},
+		{"doc comment header", "## Payments API\n\nbuild:\n", "Payments API"},
+		{"after a section", "##@ General\n\n# Payments API\n\nbuild:\n", "Payments API"},
+		{"target description", "# Build the app\nbuild:\n\tgo build\n", ""},
+		{"annotation", "# lazymake:allow rm-rf-root\n\nwipe:\n", ""},
+		{"no comment", "build:\n\tgo build\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Makefile")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got := headerTitle(path); got != tt.want {
+				t.Errorf("headerTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPage_Write(t *testing.T) {
+	page := buildTestPage(t, Options{Title: "app"})
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{
+			format: FormatMarkdown,
+			want: []string{
+				"# app\n",
+				"| `build` | Build the app |",
+				"## Other\n",
+				"## Ops\n",
+				"### `deploy`",
+				"> **Deprecated**: use deploy",
+				"- **Depends on:** `wipe`",
+				"| `ENV` | environment | `staging` |",
+				"```sh\nmake deploy ENV=prod\n```",
+				"(via `wipe`)",
+				"| `GOFLAGS` (exported) | `= -v` | `build` |",
+			},
+		},
+		{
+			format: FormatHTML,
+			want: []string{
+				"<title>app</title>",
+				`<h3 id="target-deploy"><code>deploy</code></h3>`,
+				"Wipe &lt;everything&gt;",
+				`<span class="severity-WARNING">WARNING</span>`,
+			},
+		},
+		{
+			format: FormatMan,
+			want: []string{
+				`.TH "APP" 7`,
+				".SH \"OPS\"\n.TP\n.B \"deploy\"\nDeploy the app\n",
+				"Argument ENV: environment (default: staging)",
+				`rm\-rf\-root`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var first, second bytes.Buffer
+			if err := page.Write(&first, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(first.String(), want) {
+					t.Errorf("expected %q in:\n%s", want, first.String())
+				}
+			}
+
+			// Check mode compares bytes, so output must be stable
+			_ = page.Write(&second, tt.format)
+			if first.String() != second.String() {
+				t.Error("output differs between runs")
+			}
+		})
+	}
+
+	if err := page.Write(&bytes.Buffer{}, "pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]string{
+		"docs/make.md":    FormatMarkdown,
+		"site/index.HTML": FormatHTML,
+		"man/app.7":       FormatMan,
+		"out.txt":         "",
+		"":                "",
+	}
+	for path, want := range tests {
+		if got := FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package docs
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatMan      = "man"
+)
+
+// generatedNote marks generated files so nobody edits them by hand
+const generatedNote = "Generated by lazymake docs from %s. Do not edit; run lazymake docs to update."
+
+// FormatForPath guesses the format from an output file's extension ("" if unknown)
+func FormatForPath(path string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".md", ".markdown":
+		return FormatMarkdown
+	case ".html", ".htm":
+		return FormatHTML
+	case ".man", ".1", ".2", ".3", ".4", ".5", ".6", ".7", ".8", ".9":
+		return FormatMan
+	default:
+		return ""
+	}
+}
+
+// Write writes the page in the given format: markdown, html or man
+func (p *Page) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case FormatMarkdown, "md", "":
+		return p.WriteMarkdown(w)
+	case FormatHTML:
+		return p.WriteHTML(w)
+	case FormatMan:
+		return p.WriteMan(w)
+	default:
+		return fmt.Errorf("unknown format %q: want markdown, html or man", format)
+	}
+}
+
+// sectionTitle returns the heading of a section
+func (p *Page) sectionTitle(s Section) string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case len(p.Sections) == 1:
+		return "Targets"
+	default:
+		return "Other"
+	}
+}
+
+// WriteMarkdown writes the page as GitHub-flavored Markdown
+func (p *Page) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", p.Title)
+	fmt.Fprintf(&b, "<!-- "+generatedNote+" -->\n\n", p.Makefile)
+	fmt.Fprintf(&b, "Targets of `%s`. Run one with `make <target>`.\n\n", p.Makefile)
+
+	// Summary table, like `make help`
+	b.WriteString("| Target | Description |\n|--------|-------------|\n")
+	for _, s := range p.Sections {
+		for _, t := range s.Targets {
+			fmt.Fprintf(&b, "| %s | %s |\n", mdCode(t.Name), mdCell(t.Description))
+		}
+	}
+	b.WriteString("\n")
+
+	// Every block ends with a blank line; the last one is trimmed
+	for _, s := range p.Sections {
+		fmt.Fprintf(&b, "## %s\n\n", p.sectionTitle(s))
+		for _, t := range s.Targets {
+			writeMarkdownTarget(&b, t)
+		}
+	}
+
+	if len(p.Variables) > 0 {
+		b.WriteString("## Variables\n\n")
+		b.WriteString("| Variable | Value | Used by |\n|----------|-------|---------|\n")
+		for _, v := range p.Variables {
+			name := mdCode(v.Name)
+			if v.Exported {
+				name += " (exported)"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", name, mdCell(mdCode(v.Operator+" "+v.Value)), mdCodeList(v.UsedBy))
+		}
+	}
+
+	_, err := io.WriteString(w, strings.TrimRight(b.String(), "\n")+"\n")
+	return err
+}
+
+// writeMarkdownTarget writes one target's heading and details
+func writeMarkdownTarget(b *strings.Builder, t Target) {
+	fmt.Fprintf(b, "### %s\n\n", mdCode(t.Name))
+
+	if t.Docs.Deprecated {
+		b.WriteString("> **Deprecated**")
+		if t.Docs.DeprecationNote != "" {
+			b.WriteString(": " + t.Docs.DeprecationNote)
+		}
+		b.WriteString("\n\n")
+	}
+	if t.Description != "" {
+		b.WriteString(t.Description + "\n\n")
+	}
+
+	var facts []string
+	if len(t.Dependencies) > 0 {
+		facts = append(facts, "**Depends on:** "+mdCodeList(t.Dependencies))
+	}
+	if len(t.Docs.Tags) > 0 {
+		facts = append(facts, "**Tags:** "+strings.Join(t.Docs.Tags, ", "))
+	}
+	for _, fact := range facts {
+		b.WriteString("- " + fact + "\n")
+	}
+	if len(facts) > 0 {
+		b.WriteString("\n")
+	}
+
+	if len(t.Docs.Args) > 0 {
+		b.WriteString("| Argument | Description | Default |\n|----------|-------------|---------|\n")
+		for _, arg := range t.Docs.Args {
+			def := ""
+			if arg.Default != "" {
+				def = mdCode(arg.Default)
+			}
+			fmt.Fprintf(b, "| %s | %s | %s |\n", mdCode(arg.Name), mdCell(arg.Description), mdCell(def))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(t.Docs.Examples) > 0 {
+		b.WriteString("```sh\n")
+		for _, example := range t.Docs.Examples {
+			b.WriteString(example + "\n")
+		}
+		b.WriteString("```\n\n")
+	}
+
+	if len(t.Warnings) > 0 {
+		b.WriteString("**Safety:**\n\n")
+		for _, warning := range t.Warnings {
+			fmt.Fprintf(b, "- **%s** %s: %s", warning.Severity, mdCode(warning.RuleID), warning.Description)
+			if warning.Command != "" {
+				b.WriteString(" " + mdCode(warning.Command))
+			}
+			if len(warning.Via) > 0 {
+				b.WriteString(" (via " + mdCode(strings.Join(warning.Via, " → ")) + ")")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+}
+
+// mdCode renders text as a code span, with a longer fence when it contains backticks
+func mdCode(text string) string {
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
+
+// mdCodeList renders names as comma-separated code spans
+func mdCodeList(names []string) string {
+	spans := make([]string, len(names))
+	for i, name := range names {
+		spans[i] = mdCode(name)
+	}
+	return strings.Join(spans, ", ")
+}
+
+// mdCell escapes text for a Markdown table cell
+func mdCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// htmlTemplate is a standalone page: styles are inline and nothing is loaded
+var htmlTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="lazymake docs">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #1f2328; }
+code, pre { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.9em; }
+code { background: #f0f0f0; padding: 0.1em 0.3em; border-radius: 4px; }
+pre { background: #f6f8fa; padding: 0.75rem 1rem; border-radius: 6px; overflow-x: auto; }
+table { border-collapse: collapse; margin: 0.5rem 0 1rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.75rem; text-align: left; vertical-align: top; }
+h3 { margin-top: 2rem; }
+.deprecated { border-left: 4px solid #bf8700; padding-left: 0.75rem; color: #6e5400; }
+.severity-CRITICAL { color: #cf222e; font-weight: bold; }
+.severity-WARNING { color: #bf8700; font-weight: bold; }
+.severity-INFO { color: #0969da; font-weight: bold; }
+</style>
+</head>
+<body>
+<!-- {{.Note}} -->
+<h1>{{.Title}}</h1>
+<p>Targets of <code>{{.Makefile}}</code>. Run one with <code>make &lt;target&gt;</code>.</p>
+<table>
+<tr><th>Target</th><th>Description</th></tr>
+{{- range .Sections}}{{range .Targets}}
+<tr><td><a href="#{{.Anchor}}"><code>{{.Name}}</code></a></td><td>{{.Description}}</td></tr>
+{{- end}}{{end}}
+</table>
+{{- range .Sections}}
+<h2>{{.Title}}</h2>
+{{- range .Targets}}
+<h3 id="{{.Anchor}}"><code>{{.Name}}</code></h3>
+{{- if .Docs.Deprecated}}
+<p class="deprecated"><strong>Deprecated</strong>{{with .Docs.DeprecationNote}}: {{.}}{{end}}</p>
+{{- end}}
+{{- with .Description}}
+<p>{{.}}</p>
+{{- end}}
+{{- if or .Dependencies .Docs.Tags}}
+<ul>
+{{- with .Dependencies}}
+<li><strong>Depends on:</strong> {{range $i, $d := .}}{{if $i}}, {{end}}<code>{{$d}}</code>{{end}}</li>
+{{- end}}
+{{- with .Docs.Tags}}
+<li><strong>Tags:</strong> {{join . ", "}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Docs.Args}}
+<table>
+<tr><th>Argument</th><th>Description</th><th>Default</th></tr>
+{{- range .}}
+<tr><td><code>{{.Name}}</code></td><td>{{.Description}}</td><td>{{with .Default}}<code>{{.}}</code>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Docs.Examples}}
+<pre>{{range .}}{{.}}
+{{end}}</pre>
+{{- end}}
+{{- with .Warnings}}
+<p><strong>Safety:</strong></p>
+<ul>
+{{- range .}}
+<li><span class="severity-{{.Severity}}">{{.Severity}}</span> <code>{{.RuleID}}</code>: {{.Description}}{{with .Command}} <code>{{.}}</code>{{end}}{{with .Via}} (via <code>{{join . " → "}}</code>){{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- with .Variables}}
+<h2>Variables</h2>
+<table>
+<tr><th>Variable</th><th>Value</th><th>Used by</th></tr>
+{{- range .}}
+<tr><td><code>{{.Name}}</code>{{if .Exported}} (exported){{end}}</td><td><code>{{.Operator}} {{.Value}}</code></td><td>{{range $i, $t := .UsedBy}}{{if $i}}, {{end}}<code>{{$t}}</code>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// htmlSection is a section with its heading resolved for the template
+type htmlSection struct {
+	Title   string
+	Targets []Target
+}
+
+// WriteHTML writes the page as a standalone HTML document
+func (p *Page) WriteHTML(w io.Writer) error {
+	sections := make([]htmlSection, len(p.Sections))
+	for i, s := range p.Sections {
+		sections[i] = htmlSection{Title: p.sectionTitle(s), Targets: s.Targets}
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		*Page
+		Note     string
+		Sections []htmlSection
+	}{
+		Page:     p,
+		Note:     fmt.Sprintf(generatedNote, p.Makefile),
+		Sections: sections,
+	})
+}
+
+// WriteMan writes the page as a roff man page (section 7)
+func (p *Page) WriteMan(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ".\\\" "+generatedNote+"\n", p.Makefile)
+	fmt.Fprintf(&b, ".TH %s 7 \"\" \"lazymake\" \"Makefile Targets\"\n", manQuote(strings.ToUpper(p.Title)))
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- targets of %s\n", manText(p.Title), manText(p.Makefile))
+	b.WriteString(".SH SYNOPSIS\n.B make\n.RI [ VARIABLE = value ...]\n.I target\n")
+
+	for _, s := range p.Sections {
+		fmt.Fprintf(&b, ".SH %s\n", manQuote(strings.ToUpper(p.sectionTitle(s))))
+		for _, t := range s.Targets {
+			writeManTarget(&b, t)
+		}
+	}
+
+	if len(p.Variables) > 0 {
+		b.WriteString(".SH VARIABLES\n")
+		for _, v := range p.Variables {
+			fmt.Fprintf(&b, ".TP\n.B %s\n", manQuote(v.Name))
+			value := v.Operator + " " + v.Value
+			if v.Exported {
+				value += " (exported)"
+			}
+			b.WriteString(manText(value) + "\n")
+			if len(v.UsedBy) > 0 {
+				b.WriteString(".br\n" + manText("Used by: "+strings.Join(v.UsedBy, ", ")) + "\n")
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeManTarget writes one target as a tagged paragraph
+func writeManTarget(b *strings.Builder, t Target) {
+	fmt.Fprintf(b, ".TP\n.B %s\n", manQuote(t.Name))
+
+	var lines []string
+	if t.Docs.Deprecated {
+		note := "Deprecated."
+		if t.Docs.DeprecationNote != "" {
+			note = "Deprecated: " + t.Docs.DeprecationNote
+		}
+		lines = append(lines, note)
+	}
+	if t.Description != "" {
+		lines = append(lines, t.Description)
+	}
+	if len(t.Dependencies) > 0 {
+		lines = append(lines, "Depends on: "+strings.Join(t.Dependencies, ", "))
+	}
+	if len(t.Docs.Tags) > 0 {
+		lines = append(lines, "Tags: "+strings.Join(t.Docs.Tags, ", "))
+	}
+	for _, arg := range t.Docs.Args {
+		line := "Argument " + arg.Name
+		if arg.Description != "" {
+			line += ": " + arg.Description
+		}
+		if arg.Default != "" {
+			line += " (default: " + arg.Default + ")"
+		}
+		lines = append(lines, line)
+	}
+	for _, example := range t.Docs.Examples {
+		lines = append(lines, "Example: "+example)
+	}
+	for _, warning := range t.Warnings {
+		line := fmt.Sprintf("%s %s: %s", warning.Severity, warning.RuleID, warning.Description)
+		if len(warning.Via) > 0 {
+			line += " (via " + strings.Join(warning.Via, " -> ") + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString(".br\n")
+		}
+		b.WriteString(manText(line) + "\n")
+	}
+}
+
+// manText escapes text for a roff text line
+func manText(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\e`)
+	text = strings.ReplaceAll(text, "-", `\-`)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+// manQuote escapes text for a roff macro argument
+func manQuote(text string) string {
+	return `"` + strings.ReplaceAll(manText(text), `"`, `\(dq`) + `"`
+}
@@ -19,34 +19,37 @@ type Result struct {
 	EndTime   time.Time // When execution ended
 }
 
-func Execute(target, makefilePath string) Result {
+// Execute runs a make target and returns its combined output
+// args are passed to make after the target (e.g. VAR=value overrides)
+func Execute(target, makefilePath string, args ...string) Result {
 	start := time.Now()
-	cmd := exec.Command("make", "-f", makefilePath, target)
+	cmd := exec.Command("make", makeArgs("", target, makefilePath, args)...)
 	output, err := cmd.CombinedOutput()
 	end := time.Now()
 	duration := end.Sub(start)
 
-	// Extract exit code from error
-	exitCode := 0
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
-		} else {
-			// Non-exit error (e.g., command not found)
-			exitCode = -1
-		}
-	}
-
 	return Result{
 		Output:    string(output),
 		Err:       err,
 		Duration:  duration,
-		ExitCode:  exitCode,
+		ExitCode:  ExitCode(err),
 		StartTime: start,
 		EndTime:   end,
 	}
 }
 
+// ExitCode returns the exit code of a finished make command
+// 0 for success, -1 if make couldn't be run at all (e.g., command not found).
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitError, ok := err.(*exec.ExitError); ok {
+		return exitError.ExitCode()
+	}
+	return -1
+}
+
 // OutputChunk represents a piece of streamed output
 type OutputChunk struct {
 	Data string
@@ -55,15 +58,23 @@ type OutputChunk struct {
 }
 
 // ExecuteStreaming runs a make target and streams output via channel
+// args are passed to make after the target (e.g. VAR=value overrides)
 // Returns: channel for output chunks, cancel function
-func ExecuteStreaming(target, makefilePath string) (<-chan OutputChunk, func()) {
+func ExecuteStreaming(target, makefilePath string, args ...string) (<-chan OutputChunk, func()) {
+	return ExecuteStreamingIn("", target, makefilePath, args...)
+}
+
+// ExecuteStreamingIn is ExecuteStreaming with make started as make -C dir
+// Recipes then run in dir, as they would for a Makefile run from its own directory.
+// An empty dir runs make in the current directory.
+func ExecuteStreamingIn(dir, target, makefilePath string, args ...string) (<-chan OutputChunk, func()) {
 	chunks := make(chan OutputChunk, 100)
 	ctx, cancel := context.WithCancel(context.Background())
 
 	go func() {
 		defer close(chunks)
 
-		cmd := exec.CommandContext(ctx, "make", "-f", makefilePath, target)
+		cmd := exec.CommandContext(ctx, "make", makeArgs(dir, target, makefilePath, args)...)
 
 		// Create pipes for stdout and stderr
 		stdout, err := cmd.StdoutPipe()
@@ -112,6 +123,17 @@ func ExecuteStreaming(target, makefilePath string) (<-chan OutputChunk, func())
 	return chunks, cancel
 }
 
+// makeArgs builds the make command line: [-C <dir>] -f <makefile> <target> [args...]
+// make -C would announce the directory it enters; the caller already knows it.
+func makeArgs(dir, target, makefilePath string, args []string) []string {
+	var cmdline []string
+	if dir != "" {
+		cmdline = append(cmdline, "-C", dir, "--no-print-directory")
+	}
+	cmdline = append(cmdline, "-f", makefilePath, target)
+	return append(cmdline, args...)
+}
+
 // readPipe reads from a pipe and sends chunks to the channel
 func readPipe(pipe io.Reader, chunks chan<- OutputChunk, ctx context.Context) {
 	scanner := bufio.NewScanner(pipe)
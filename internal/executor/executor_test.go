@@ -2,6 +2,7 @@ package executor
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -141,6 +142,69 @@ echo:
 	}
 }
 
+func TestExecuteWithArgs(t *testing.T) {
+	tempDir := t.TempDir()
+	makefile := tempDir + "/Makefile"
+
+	makefileContent := `
+.PHONY: greet
+greet:
+	@echo "hello $(NAME)"
+`
+	if err := os.WriteFile(makefile, []byte(makefileContent), 0644); err != nil {
+		t.Fatalf("Failed to create test Makefile: %v", err)
+	}
+
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	// Arguments after the target are passed through to make
+	result := Execute("greet", makefile, "NAME=world")
+
+	if !contains(result.Output, "hello world") {
+		t.Errorf("Expected output to contain variable override, got: %q", result.Output)
+	}
+}
+
+func TestExecuteStreamingIn(t *testing.T) {
+	tempDir := t.TempDir()
+	serviceDir := filepath.Join(tempDir, "services", "api")
+	if err := os.MkdirAll(serviceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	makefile := filepath.Join(serviceDir, "Makefile")
+
+	makefileContent := `
+.PHONY: where
+where:
+	@pwd
+`
+	if err := os.WriteFile(makefile, []byte(makefileContent), 0644); err != nil {
+		t.Fatalf("Failed to create test Makefile: %v", err)
+	}
+
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	// The recipe runs in the Makefile's directory, not the current one
+	chunks, cancel := ExecuteStreamingIn(serviceDir, "where", makefile)
+	defer cancel()
+
+	var output string
+	for chunk := range chunks {
+		if chunk.Done && chunk.Err != nil {
+			t.Fatalf("Expected no error, got: %v", chunk.Err)
+		}
+		output += chunk.Data
+	}
+
+	if !contains(output, filepath.Join("services", "api")) {
+		t.Errorf("Expected recipe to run in %s, got: %q", serviceDir, output)
+	}
+}
+
 func TestExecuteTiming(t *testing.T) {
 	tempDir := t.TempDir()
 	makefile := tempDir + "/Makefile"
@@ -32,20 +32,8 @@ func NewExporter(config *Config) (*Exporter, error) {
 
 // Export exports an execution record to file(s) based on configuration
 func (e *Exporter) Export(record *ExecutionRecord) error {
-	if e.config == nil || !e.config.Enabled {
-		return nil // Export disabled
-	}
-
-	// Check if target is excluded
-	for _, excluded := range e.config.ExcludeTargets {
-		if record.TargetName == excluded {
-			return nil // Target excluded from export
-		}
-	}
-
-	// Check if we should only export successful executions
-	if e.config.SuccessOnly && !record.Success {
-		return nil // Skip failed executions
+	if !e.shouldExport(record) {
+		return nil
 	}
 
 	// Export based on format
@@ -71,6 +59,44 @@ func (e *Exporter) Export(record *ExecutionRecord) error {
 	return lastErr
 }
 
+// OutputPath returns the file Export will write the record's output to
+// Prefers the log file (readable as-is) when both formats are enabled.
+// Returns "" if the record would not be exported.
+func (e *Exporter) OutputPath(record *ExecutionRecord) string {
+	if !e.shouldExport(record) {
+		return ""
+	}
+
+	extension := "json"
+	if e.config.Format == "log" || e.config.Format == "both" {
+		extension = "log"
+	}
+
+	filename := record.GenerateFilename(e.config.NamingStrategy, extension)
+	return GenerateExportPath(e.config.OutputDir, filename)
+}
+
+// shouldExport applies the enabled switch, target exclusions and the success-only filter
+func (e *Exporter) shouldExport(record *ExecutionRecord) bool {
+	if e.config == nil || !e.config.Enabled {
+		return false // Export disabled
+	}
+
+	// Check if target is excluded
+	for _, excluded := range e.config.ExcludeTargets {
+		if record.TargetName == excluded {
+			return false // Target excluded from export
+		}
+	}
+
+	// Check if we should only export successful executions
+	if e.config.SuccessOnly && !record.Success {
+		return false // Skip failed executions
+	}
+
+	return true
+}
+
 // exportJSON exports the record as JSON
 func (e *Exporter) exportJSON(record *ExecutionRecord) error {
 	filename := record.GenerateFilename(e.config.NamingStrategy, "json")
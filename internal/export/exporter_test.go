@@ -150,6 +150,12 @@ func TestExportLog(t *testing.T) {
 	}
 
 	record := NewExecutionRecord("/tmp/Makefile", "build", result)
+	record.Confirmation = &Confirmation{
+		ConfirmedAt: result.StartTime,
+		DangerLevel: "critical",
+		Rules:       []string{"terraform-destroy"},
+		Reason:      "tear down the preview stack",
+	}
 
 	err = exporter.Export(record)
 	if err != nil {
@@ -173,7 +179,8 @@ func TestExportLog(t *testing.T) {
 	}
 
 	logStr := string(content)
-	if !containsAll(logStr, []string{"build", "SUCCESS", "test output", "Exit Code:     0"}) {
+	if !containsAll(logStr, []string{"build", "SUCCESS", "test output", "Exit Code:     0",
+		"Rules:         terraform-destroy", "Reason:        tear down the preview stack"}) {
 		t.Errorf("Log content missing expected strings")
 	}
 }
@@ -475,6 +482,61 @@ func TestExpandPath(t *testing.T) {
 	}
 }
 
+func TestOutputPathAndReadOutput(t *testing.T) {
+	tempDir := t.TempDir()
+
+	result := executor.Result{
+		Output:    "captured output",
+		Duration:  time.Second,
+		StartTime: time.Now().Add(-time.Second),
+		EndTime:   time.Now(),
+	}
+	record := NewExecutionRecord("/tmp/Makefile", "build", result)
+
+	tests := []struct {
+		name    string
+		config  *Config
+		wantExt string // "" = not exported
+	}{
+		{"json", &Config{Enabled: true, OutputDir: tempDir, Format: "json", NamingStrategy: "timestamp"}, ".json"},
+		{"both prefers log", &Config{Enabled: true, OutputDir: tempDir, Format: "both", NamingStrategy: "timestamp"}, ".log"},
+		{"disabled", &Config{Enabled: false, OutputDir: tempDir, Format: "json", NamingStrategy: "timestamp"}, ""},
+		{"excluded", &Config{Enabled: true, OutputDir: tempDir, Format: "json", NamingStrategy: "timestamp", ExcludeTargets: []string{"build"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, err := NewExporter(tt.config)
+			if err != nil {
+				t.Fatalf("Failed to create exporter: %v", err)
+			}
+
+			path := exporter.OutputPath(record)
+			if tt.wantExt == "" {
+				if path != "" {
+					t.Errorf("Expected no output path, got %s", path)
+				}
+				return
+			}
+			if filepath.Ext(path) != tt.wantExt {
+				t.Fatalf("Expected %s output path, got %s", tt.wantExt, path)
+			}
+
+			// The predicted path is the one Export writes
+			if err := exporter.Export(record); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			output, err := ReadOutput(path)
+			if err != nil {
+				t.Fatalf("ReadOutput failed: %v", err)
+			}
+			if !containsAll(output, []string{"captured output"}) {
+				t.Errorf("Expected captured output, got %q", output)
+			}
+		})
+	}
+}
+
 // Helper function
 func containsAll(str string, substrs []string) bool {
 	for _, substr := range substrs {
@@ -17,6 +17,7 @@ type ExecutionRecord struct {
 	Timestamp    time.Time `json:"timestamp"`
 	MakefilePath string    `json:"makefile_path"`
 	TargetName   string    `json:"target_name"`
+	Args         []string  `json:"args,omitempty"` // Extra make arguments after the target
 
 	// Timing data
 	StartTime  time.Time     `json:"start_time"`
@@ -35,6 +36,20 @@ type ExecutionRecord struct {
 	User            string `json:"user,omitempty"`
 	Hostname        string `json:"hostname,omitempty"`
 	LazymakeVersion string `json:"lazymake_version,omitempty"`
+
+	// Safety confirmation, if the target had to be confirmed before running
+	Confirmation *Confirmation `json:"confirmation,omitempty"`
+}
+
+// Confirmation records how a target was confirmed in the safety dialog
+type Confirmation struct {
+	ConfirmedAt      time.Time `json:"confirmed_at"`
+	DangerLevel      string    `json:"danger_level,omitempty"`      // critical, warning or info ("" for a safe target)
+	Rules            []string  `json:"rules,omitempty"`             // Matched rule IDs, including dependencies'
+	Profile          string    `json:"profile,omitempty"`           // Active safety profile
+	Typed            string    `json:"typed,omitempty"`             // Text typed to confirm (target or environment name)
+	CountdownSeconds int       `json:"countdown_seconds,omitempty"` // Countdown waited before confirming
+	Reason           string    `json:"reason,omitempty"`            // Reason given for running the target
 }
 
 // NewExecutionRecord creates an ExecutionRecord from execution data
@@ -106,6 +121,15 @@ func (r *ExecutionRecord) FormatLog() string {
 	if r.Hostname != "" {
 		fmt.Fprintf(&b, "Host:          %s\n", r.Hostname)
 	}
+	if c := r.Confirmation; c != nil {
+		fmt.Fprintf(&b, "Confirmed:     %s (%s)\n", c.ConfirmedAt.Format("2006-01-02 15:04:05"), c.DangerLevel)
+		if len(c.Rules) > 0 {
+			fmt.Fprintf(&b, "Rules:         %s\n", strings.Join(c.Rules, ", "))
+		}
+		if c.Reason != "" {
+			fmt.Fprintf(&b, "Reason:        %s\n", c.Reason)
+		}
+	}
 
 	// Output section
 	b.WriteString(strings.Repeat("=", 80))
@@ -49,6 +49,26 @@ func WriteLog(record *ExecutionRecord, path string) error {
 	return nil
 }
 
+// ReadOutput reads the captured command output back from an export file
+// JSON exports return the recorded output; log exports are returned as-is.
+func ReadOutput(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read export file: %w", err)
+	}
+
+	if filepath.Ext(path) != ".json" {
+		return string(data), nil
+	}
+
+	var record ExecutionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return "", fmt.Errorf("failed to parse export file: %w", err)
+	}
+
+	return record.Output, nil
+}
+
 // GenerateExportPath generates the full path for an export file
 func GenerateExportPath(outputDir, filename string) string {
 	// Expand ~ and environment variables
@@ -0,0 +1,82 @@
+// Package filelock provides cross-process file locking and atomic writes
+// for lazymake's cache files, which several instances may update at once.
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockSuffix names the sidecar file that guards a data file
+// The data file itself is replaced by rename on every write, so a lock held
+// on it would be lost; the sidecar file is never replaced.
+const lockSuffix = ".lock"
+
+// WithLock runs fn while holding an exclusive lock for path
+// Other processes calling WithLock for the same path block until fn returns.
+func WithLock(path string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path+lockSuffix, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := Lock(f); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	defer func() {
+		_ = Unlock(f) // Explicitly ignore unlock error
+	}()
+
+	return fn()
+}
+
+// WriteAtomic writes data to path so readers see either the old or the new content
+// The data is written to a temporary file in the same directory, synced, then renamed.
+func WriteAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// Remove the temporary file unless the rename succeeded
+	committed := false
+	defer func() {
+		if !committed {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	committed = true
+	return nil
+}
@@ -1,18 +1,18 @@
 //go:build unix
 
-package shell
+package filelock
 
 import (
 	"os"
 	"syscall"
 )
 
-// lockFile acquires an exclusive lock on the file
-func lockFile(f *os.File) error {
+// Lock acquires an exclusive lock on the file
+func Lock(f *os.File) error {
 	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX) //nolint:gosec // fd fits in int on supported platforms
 }
 
-// unlockFile releases the lock on the file
-func unlockFile(f *os.File) error {
+// Unlock releases the lock on the file
+func Unlock(f *os.File) error {
 	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN) //nolint:gosec // fd fits in int on supported platforms
 }
@@ -1,6 +1,6 @@
 //go:build windows
 
-package shell
+package filelock
 
 import (
 	"os"
@@ -18,8 +18,8 @@ const (
 	lockfileExclusiveLock = 0x00000002
 )
 
-// lockFile acquires an exclusive lock on the file using Windows API
-func lockFile(f *os.File) error {
+// Lock acquires an exclusive lock on the file using Windows API
+func Lock(f *os.File) error {
 	var overlapped syscall.Overlapped
 	
 	// LockFileEx parameters:
@@ -44,8 +44,8 @@ func lockFile(f *os.File) error {
 	return nil
 }
 
-// unlockFile releases the lock on the file using Windows API
-func unlockFile(f *os.File) error {
+// Unlock releases the lock on the file using Windows API
+func Unlock(f *os.File) error {
 	var overlapped syscall.Overlapped
 	
 	// UnlockFileEx parameters:
@@ -14,6 +14,10 @@ type Node struct {
 	Order       int  // Execution order number from topological sort (1, 2, 3...)
 	IsCritical  bool // Is this node on the critical path? (longest chain)
 	CanParallel bool // Can this run in parallel with its siblings?
+
+	// Cross-file edges, added by LinkSubMakes
+	SubMakes []*Node // Targets the recipe runs with recursive make ($(MAKE) -C dir target)
+	Source   *Source // Where the target is defined, if not in the graph's own Makefile
 }
 
 // Graph represents the complete dependency graph
@@ -360,47 +364,69 @@ func markCriticalPathDown(node *Node, depth map[string]int) {
 // Standalone targets (no dependencies) are NOT marked as parallel since the concept
 // of "parallelization" only makes sense when there are dependencies to coordinate.
 //
-// Targets can run in parallel if they:
-// 1. Have the same execution order (from topological sort)
-// 2. Have at least one dependency (part of a build chain)
-// 3. Don't depend on each other
+// Two targets can run in parallel if:
+// 1. Neither depends on the other, directly or transitively
+// 2. Both are pulled in by the same build (they share a root that depends on both)
+//
+// Equal topological level is not enough: it pairs unrelated targets from separate
+// trees, and misses independent targets that happen to sit at different levels.
 //
 // Example:
 //
-//	all → build → deps
+//	all → build → compile
 //	  ↓
-//	all → test → deps
+//	all → lint
 //
-// "build" and "test" both have order 2 and have deps, so they can run in parallel
+// "build", "compile" and "lint" are all marked: lint is independent of both,
+// even though it sits at a different level than build
 //
 // But:
 //	clean, lint, format (all independent, no deps) → NOT parallel (just standalone)
 //
 // This tells users where they can speed things up with parallel execution
 func identifyParallelOpportunities(g *Graph) {
-	// Group nodes by execution order
-	// All nodes with the same order number are at the same "level"
-	// and can potentially run in parallel
-	orderGroups := make(map[int][]*Node)
-	for _, node := range g.Nodes {
-		// Only consider nodes that have dependencies (part of a build chain)
-		if len(node.Dependencies) > 0 {
-			order := node.Order
-			orderGroups[order] = append(orderGroups[order], node)
-		}
+	reach := reachability(g)
+
+	related := func(a, b string) bool {
+		return reach[a][b] || reach[b][a]
 	}
 
-	// Mark parallel opportunities
-	// If there's more than one node at a given level, they can all run in parallel
-	for _, group := range orderGroups {
-		if len(group) > 1 {
-			for _, node := range group {
-				node.CanParallel = true
+	// Every common ancestor lies below some root, so checking each root's
+	// closure covers all builds that could schedule two nodes together
+	for name, node := range g.Nodes {
+		if len(node.Dependents) > 0 || len(node.Dependencies) == 0 {
+			continue // Not a root of a build chain
+		}
+
+		members := make([]string, 0, len(reach[name]))
+		for member := range reach[name] {
+			members = append(members, member)
+		}
+
+		for i := range members {
+			for j := i + 1; j < len(members); j++ {
+				if !related(members[i], members[j]) {
+					g.Nodes[members[i]].CanParallel = true
+					g.Nodes[members[j]].CanParallel = true
+				}
 			}
 		}
 	}
 }
 
+// reachability returns, for each node, the set of nodes it transitively depends on
+func reachability(g *Graph) map[string]map[string]bool {
+	reach := make(map[string]map[string]bool, len(g.Nodes))
+	for name, node := range g.Nodes {
+		set := make(map[string]bool)
+		for _, dep := range collectDescendants(node) {
+			set[dep.Target.Name] = true
+		}
+		reach[name] = set
+	}
+	return reach
+}
+
 // GetSubgraph extracts a portion of the graph centered on a specific target
 //
 // This is useful for viewing just one target's dependencies without showing
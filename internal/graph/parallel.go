@@ -0,0 +1,445 @@
+package graph
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// defaultNodeDuration is used for every recipe node when no timing data is known at all
+	defaultNodeDuration = time.Second
+
+	// recommendationTolerance is how close to the best makespan a -j value must be
+	// to be recommended (5% = "within 5% of the fastest schedule")
+	recommendationTolerance = 1.05
+)
+
+// JobsEstimate is the simulated result of running a target with a given -j value
+type JobsEstimate struct {
+	Jobs     int           // Number of parallel jobs (-jN)
+	Makespan time.Duration // Estimated wall-clock time
+	Speedup  float64       // Makespan(-j1) / Makespan(-jN)
+}
+
+// ParallelAnalysis describes how a target's subgraph can be parallelized
+//
+// Levels are computed "as soon as possible": a node sits one level above its
+// deepest dependency, so every node in a level could start at the same time.
+// Levels don't bound parallelism, though: independent chains of different
+// lengths put unrelated nodes on different levels that can still run together.
+// The maximum useful parallelism is the largest set of recipes of which none
+// depends on another - more jobs than that can never be kept busy.
+type ParallelAnalysis struct {
+	Target   string
+	Levels   [][]string // Node names per level, leaves first (sorted within a level)
+	MaxWidth int        // Size of the widest level
+	MaxJobs  int        // Most recipes that can run at once (maximum useful -j)
+
+	// Duration estimates used by the simulation
+	Durations      map[string]time.Duration // Estimated self-time per node
+	SerialDuration time.Duration            // Sum of all self-times (-j1)
+	CriticalPath   time.Duration            // Longest weighted chain (lower bound for any -j)
+	HasTimingData  bool                     // False when all durations are defaults
+
+	// Simulation results for -j1 .. -jMaxJobs
+	Estimates       []JobsEstimate
+	RecommendedJobs int // Smallest -j within 5% of the best makespan
+}
+
+// AnalyzeParallelism performs a level/width analysis of a target's subgraph and
+// simulates a list schedule for -j1 up to the maximum useful parallelism
+//
+// durations holds the estimated self-time of each target (time spent in its own
+// recipe, excluding dependencies). Nodes without a recipe cost nothing; recipe
+// nodes without a known duration get the mean of the known ones (or 1s if
+// nothing is known), so the curve stays meaningful for partially profiled builds.
+//
+// Returns nil if the target doesn't exist or the graph has a cycle.
+func (g *Graph) AnalyzeParallelism(targetName string, durations map[string]time.Duration) *ParallelAnalysis {
+	if g.HasCycle {
+		return nil
+	}
+	if _, exists := g.Nodes[targetName]; !exists {
+		return nil
+	}
+
+	sub := g.GetSubgraph(targetName, -1)
+
+	analysis := &ParallelAnalysis{
+		Target:    targetName,
+		Durations: resolveDurations(sub, durations),
+	}
+	for _, d := range durations {
+		if d > 0 {
+			analysis.HasTimingData = true
+			break
+		}
+	}
+
+	// Level/width analysis
+	analysis.Levels = computeLevels(sub)
+	for _, level := range analysis.Levels {
+		if len(level) > analysis.MaxWidth {
+			analysis.MaxWidth = len(level)
+		}
+	}
+
+	analysis.MaxJobs = maxAntichain(sub, analysis.Durations)
+
+	for _, d := range analysis.Durations {
+		analysis.SerialDuration += d
+	}
+
+	// Priority for list scheduling: longest weighted path from a node to the
+	// target (its "bottom level" in the reversed DAG). The target's own value
+	// is the critical path length.
+	rank := computeUpwardRank(sub, analysis.Durations)
+	for _, r := range rank {
+		if r > analysis.CriticalPath {
+			analysis.CriticalPath = r
+		}
+	}
+
+	maxJobs := max(analysis.MaxJobs, 1)
+	for jobs := 1; jobs <= maxJobs; jobs++ {
+		makespan := simulateListSchedule(sub, analysis.Durations, rank, jobs)
+		estimate := JobsEstimate{Jobs: jobs, Makespan: makespan, Speedup: 1}
+		if len(analysis.Estimates) > 0 && makespan > 0 {
+			estimate.Speedup = float64(analysis.Estimates[0].Makespan) / float64(makespan)
+		}
+		analysis.Estimates = append(analysis.Estimates, estimate)
+	}
+
+	analysis.RecommendedJobs = recommendJobs(analysis.Estimates)
+
+	return analysis
+}
+
+// EstimateSelfDurations converts total run times into per-node self-times
+//
+// History records how long `make <target>` took, which includes building the
+// target's dependencies. Assuming those runs were serial, a node's self-time is
+// its total minus the self-times of everything it transitively depends on.
+// Nodes without a recorded total are left out so AnalyzeParallelism can apply
+// its fallback. Results are clamped at zero (incremental builds may skip deps).
+func (g *Graph) EstimateSelfDurations(totals map[string]time.Duration) map[string]time.Duration {
+	self := make(map[string]time.Duration)
+	visiting := make(map[string]bool)
+
+	var resolve func(node *Node) time.Duration
+	resolve = func(node *Node) time.Duration {
+		name := node.Target.Name
+		if d, ok := self[name]; ok {
+			return d
+		}
+		if visiting[name] {
+			return 0 // Cycle guard
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+
+		total, known := totals[name]
+		if !known {
+			return 0
+		}
+
+		var depsTotal time.Duration
+		for _, dep := range collectDescendants(node) {
+			depsTotal += resolve(dep)
+		}
+
+		d := max(total-depsTotal, 0)
+		self[name] = d
+		return d
+	}
+
+	for _, node := range g.Nodes {
+		resolve(node)
+	}
+
+	return self
+}
+
+// resolveDurations fills in a self-time for every node in the subgraph
+func resolveDurations(sub *Graph, known map[string]time.Duration) map[string]time.Duration {
+	// Fallback for recipe nodes without data: mean of the known recipe nodes
+	fallback := defaultNodeDuration
+	var sum time.Duration
+	count := 0
+	for name, node := range sub.Nodes {
+		if d, ok := known[name]; ok && len(node.Target.Recipe) > 0 {
+			sum += d
+			count++
+		}
+	}
+	if count > 0 {
+		fallback = sum / time.Duration(count)
+	}
+
+	result := make(map[string]time.Duration, len(sub.Nodes))
+	for name, node := range sub.Nodes {
+		switch d, ok := known[name]; {
+		case ok:
+			result[name] = d
+		case len(node.Target.Recipe) == 0:
+			// Meta targets and file/external placeholders don't run anything
+			result[name] = 0
+		default:
+			result[name] = fallback
+		}
+	}
+
+	return result
+}
+
+// computeLevels groups subgraph nodes by ASAP level (leaves = level 0)
+func computeLevels(sub *Graph) [][]string {
+	level := make(map[string]int)
+
+	var levelOf func(node *Node) int
+	levelOf = func(node *Node) int {
+		name := node.Target.Name
+		if l, ok := level[name]; ok {
+			return l
+		}
+		l := 0
+		for _, dep := range node.Dependencies {
+			if _, inSub := sub.Nodes[dep.Target.Name]; !inSub {
+				continue
+			}
+			l = max(l, levelOf(dep)+1)
+		}
+		level[name] = l
+		return l
+	}
+
+	maxLevel := -1
+	for _, node := range sub.Nodes {
+		maxLevel = max(maxLevel, levelOf(node))
+	}
+
+	levels := make([][]string, maxLevel+1)
+	for name, l := range level {
+		levels[l] = append(levels[l], name)
+	}
+	for _, names := range levels {
+		sort.Strings(names)
+	}
+
+	return levels
+}
+
+// maxAntichain returns the size of the largest set of recipe nodes of which
+// none transitively depends on another
+//
+// By Dilworth's theorem this is the number of recipe nodes minus a maximum
+// matching in the bipartite graph of "a depends on b" pairs, found here with
+// augmenting paths. Zero-cost nodes never occupy a job slot, so they only
+// count as links between the recipes around them.
+func maxAntichain(sub *Graph, durations map[string]time.Duration) int {
+	var names []string
+	for name := range sub.Nodes {
+		if durations[name] > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	reach := reachability(sub)
+	matchedTo := make(map[string]string) // Dependency -> dependent it's matched with
+
+	var augment func(name string, visited map[string]bool) bool
+	augment = func(name string, visited map[string]bool) bool {
+		for _, dep := range names {
+			if !reach[name][dep] || visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			if owner, taken := matchedTo[dep]; !taken || augment(owner, visited) {
+				matchedTo[dep] = name
+				return true
+			}
+		}
+		return false
+	}
+
+	matching := 0
+	for _, name := range names {
+		if augment(name, make(map[string]bool)) {
+			matching++
+		}
+	}
+
+	return len(names) - matching
+}
+
+// computeUpwardRank returns, for every node, its own duration plus the longest
+// weighted chain of dependents leading up to the subgraph root
+//
+// Nodes with a larger rank sit on longer chains and should start first.
+func computeUpwardRank(sub *Graph, durations map[string]time.Duration) map[string]time.Duration {
+	rank := make(map[string]time.Duration)
+
+	var rankOf func(node *Node) time.Duration
+	rankOf = func(node *Node) time.Duration {
+		name := node.Target.Name
+		if r, ok := rank[name]; ok {
+			return r
+		}
+		var longest time.Duration
+		for _, dependent := range node.Dependents {
+			if _, inSub := sub.Nodes[dependent.Target.Name]; !inSub {
+				continue
+			}
+			longest = max(longest, rankOf(dependent))
+		}
+		r := durations[name] + longest
+		rank[name] = r
+		return r
+	}
+
+	for _, node := range sub.Nodes {
+		rankOf(node)
+	}
+
+	return rank
+}
+
+// simulateListSchedule simulates running the subgraph with a fixed number of
+// job slots and returns the estimated makespan
+//
+// This mirrors how make -jN behaves: whenever a slot is free, start the ready
+// target with the highest priority (longest remaining chain). Zero-cost nodes
+// complete instantly and don't occupy a slot.
+func simulateListSchedule(sub *Graph, durations, rank map[string]time.Duration, jobs int) time.Duration {
+	remaining := make(map[string]int, len(sub.Nodes))
+	for name, node := range sub.Nodes {
+		count := 0
+		for _, dep := range node.Dependencies {
+			if _, inSub := sub.Nodes[dep.Target.Name]; inSub {
+				count++
+			}
+		}
+		remaining[name] = count
+	}
+
+	var ready []string
+	for name, count := range remaining {
+		if count == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	type running struct {
+		name   string
+		finish time.Duration
+	}
+
+	var (
+		now     time.Duration
+		active  []running
+		done    int
+		release func(name string)
+	)
+
+	release = func(name string) {
+		done++
+		for _, dependent := range sub.Nodes[name].Dependents {
+			if _, inSub := sub.Nodes[dependent.Target.Name]; !inSub {
+				continue
+			}
+			remaining[dependent.Target.Name]--
+			if remaining[dependent.Target.Name] == 0 {
+				ready = append(ready, dependent.Target.Name)
+			}
+		}
+	}
+
+	for done < len(sub.Nodes) {
+		// Highest rank first; name as a tie-breaker for deterministic results
+		sort.Slice(ready, func(i, j int) bool {
+			if rank[ready[i]] != rank[ready[j]] {
+				return rank[ready[i]] > rank[ready[j]]
+			}
+			return ready[i] < ready[j]
+		})
+
+		// Start as many ready nodes as there are free slots
+		progressed := false
+		for len(ready) > 0 {
+			name := ready[0]
+			if durations[name] == 0 {
+				ready = ready[1:]
+				release(name)
+				progressed = true
+				continue
+			}
+			if len(active) >= jobs {
+				break
+			}
+			ready = ready[1:]
+			active = append(active, running{name: name, finish: now + durations[name]})
+			progressed = true
+		}
+		if progressed {
+			continue // Zero-cost releases may have made more nodes ready
+		}
+
+		if len(active) == 0 {
+			break // Nothing runnable (should not happen in a DAG)
+		}
+
+		// Advance time to the next completion
+		sort.Slice(active, func(i, j int) bool { return active[i].finish < active[j].finish })
+		now = active[0].finish
+		for len(active) > 0 && active[0].finish == now {
+			finished := active[0]
+			active = active[1:]
+			release(finished.name)
+		}
+	}
+
+	return now
+}
+
+// recommendJobs picks the smallest -j whose makespan is within tolerance of the best
+func recommendJobs(estimates []JobsEstimate) int {
+	if len(estimates) == 0 {
+		return 1
+	}
+
+	best := estimates[0].Makespan
+	for _, e := range estimates {
+		best = min(best, e.Makespan)
+	}
+
+	limit := time.Duration(float64(best) * recommendationTolerance)
+	for _, e := range estimates {
+		if e.Makespan <= limit {
+			return e.Jobs
+		}
+	}
+
+	return estimates[len(estimates)-1].Jobs
+}
+
+// collectDescendants returns every node reachable through Dependencies (excluding node itself)
+func collectDescendants(node *Node) []*Node {
+	var result []*Node
+	visited := map[string]bool{node.Target.Name: true}
+
+	stack := append([]*Node(nil), node.Dependencies...)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if visited[current.Target.Name] {
+			continue
+		}
+		visited[current.Target.Name] = true
+		result = append(result, current)
+		stack = append(stack, current.Dependencies...)
+	}
+
+	return result
+}
@@ -0,0 +1,275 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+)
+
+// recipe is a helper that gives a target a non-empty recipe so it counts as real work
+var recipe = []string{"echo work"}
+
+// TestParallelOpportunitiesDifferentLevels tests that independent targets at
+// different levels are still marked as parallel
+// CONCEPT: lint (level 1) can run alongside compile (level 1) and build (level 2)
+func TestParallelOpportunitiesDifferentLevels(t *testing.T) {
+	targets := []makefile.Target{
+		{Name: "all", Dependencies: []string{"build", "lint"}},
+		{Name: "build", Dependencies: []string{"compile"}},
+		{Name: "compile", Dependencies: nil},
+		{Name: "lint", Dependencies: nil},
+	}
+
+	g := BuildGraph(targets)
+
+	for _, name := range []string{"build", "compile", "lint"} {
+		if !g.Nodes[name].CanParallel {
+			t.Errorf("%s should be parallelizable", name)
+		}
+	}
+	if g.Nodes["all"].CanParallel {
+		t.Error("all should NOT be parallelizable (it depends on everything)")
+	}
+}
+
+// TestParallelOpportunitiesSeparateTrees tests that same-level targets from
+// unrelated trees are not marked as parallel
+func TestParallelOpportunitiesSeparateTrees(t *testing.T) {
+	targets := []makefile.Target{
+		{Name: "release", Dependencies: []string{"package"}},
+		{Name: "package", Dependencies: nil},
+		{Name: "docs", Dependencies: []string{"render"}},
+		{Name: "render", Dependencies: nil},
+	}
+
+	g := BuildGraph(targets)
+
+	for name, node := range g.Nodes {
+		if node.CanParallel {
+			t.Errorf("%s should NOT be parallelizable (separate linear chains)", name)
+		}
+	}
+}
+
+// TestAnalyzeParallelismLevels tests the level/width analysis of a subgraph
+func TestAnalyzeParallelismLevels(t *testing.T) {
+	targets := []makefile.Target{
+		{Name: "all", Dependencies: []string{"build", "test", "lint"}, Recipe: recipe},
+		{Name: "build", Dependencies: []string{"deps"}, Recipe: recipe},
+		{Name: "test", Dependencies: []string{"deps"}, Recipe: recipe},
+		{Name: "lint", Dependencies: nil, Recipe: recipe},
+		{Name: "deps", Dependencies: nil, Recipe: recipe},
+		{Name: "unrelated", Dependencies: nil, Recipe: recipe},
+	}
+
+	g := BuildGraph(targets)
+	analysis := g.AnalyzeParallelism("all", nil)
+	if analysis == nil {
+		t.Fatal("Expected analysis, got nil")
+	}
+
+	// Level 0: deps, lint; level 1: build, test; level 2: all
+	if len(analysis.Levels) != 3 {
+		t.Fatalf("Expected 3 levels, got %d: %v", len(analysis.Levels), analysis.Levels)
+	}
+	if got := analysis.Levels[0]; len(got) != 2 || got[0] != "deps" || got[1] != "lint" {
+		t.Errorf("Expected level 0 to be [deps lint], got %v", got)
+	}
+	if analysis.MaxWidth != 2 {
+		t.Errorf("Expected max width 2, got %d", analysis.MaxWidth)
+	}
+
+	// build, test and lint don't depend on each other, though lint sits on level 0
+	if analysis.MaxJobs != 3 {
+		t.Errorf("Expected max jobs 3, got %d", analysis.MaxJobs)
+	}
+
+	// Unrelated targets must not be part of the analysis
+	if _, ok := analysis.Durations["unrelated"]; ok {
+		t.Error("unrelated target should not be in the subgraph analysis")
+	}
+
+	// Without timing data every recipe costs the default duration
+	if analysis.HasTimingData {
+		t.Error("Expected HasTimingData to be false")
+	}
+	if analysis.SerialDuration != 5*defaultNodeDuration {
+		t.Errorf("Expected serial duration %v, got %v", 5*defaultNodeDuration, analysis.SerialDuration)
+	}
+}
+
+// TestAnalyzeParallelismSimulation tests the list schedule simulation and recommendation
+func TestAnalyzeParallelismSimulation(t *testing.T) {
+	// all (0s) → build (4s) → deps (2s)
+	//          → test (4s)  → deps
+	//          → lint (1s)
+	targets := []makefile.Target{
+		{Name: "all", Dependencies: []string{"build", "test", "lint"}},
+		{Name: "build", Dependencies: []string{"deps"}, Recipe: recipe},
+		{Name: "test", Dependencies: []string{"deps"}, Recipe: recipe},
+		{Name: "lint", Dependencies: nil, Recipe: recipe},
+		{Name: "deps", Dependencies: nil, Recipe: recipe},
+	}
+	durations := map[string]time.Duration{
+		"build": 4 * time.Second,
+		"test":  4 * time.Second,
+		"lint":  1 * time.Second,
+		"deps":  2 * time.Second,
+	}
+
+	g := BuildGraph(targets)
+	analysis := g.AnalyzeParallelism("all", durations)
+	if analysis == nil {
+		t.Fatal("Expected analysis, got nil")
+	}
+
+	if analysis.SerialDuration != 11*time.Second {
+		t.Errorf("Expected serial duration 11s, got %v", analysis.SerialDuration)
+	}
+	if analysis.CriticalPath != 6*time.Second {
+		t.Errorf("Expected critical path 6s, got %v", analysis.CriticalPath)
+	}
+
+	// build, test and lint can run together, so we simulate -j1 to -j3
+	if len(analysis.Estimates) != 3 {
+		t.Fatalf("Expected 3 estimates, got %d", len(analysis.Estimates))
+	}
+	if analysis.Estimates[0].Makespan != 11*time.Second {
+		t.Errorf("Expected -j1 makespan 11s, got %v", analysis.Estimates[0].Makespan)
+	}
+
+	// -j2: deps+lint (0-2s, lint done at 1s), build+test (2-6s) = 6s
+	if analysis.Estimates[1].Makespan != 6*time.Second {
+		t.Errorf("Expected -j2 makespan 6s, got %v", analysis.Estimates[1].Makespan)
+	}
+	// -j3 can't beat the 6s critical path
+	if analysis.Estimates[2].Makespan != 6*time.Second {
+		t.Errorf("Expected -j3 makespan 6s, got %v", analysis.Estimates[2].Makespan)
+	}
+	if analysis.RecommendedJobs != 2 {
+		t.Errorf("Expected recommended -j2, got -j%d", analysis.RecommendedJobs)
+	}
+	if speedup := analysis.Estimates[1].Speedup; speedup < 1.8 || speedup > 1.9 {
+		t.Errorf("Expected speedup ~1.83, got %.2f", speedup)
+	}
+}
+
+// TestAnalyzeParallelismStaggeredChains tests that independent chains of
+// different lengths are simulated beyond the widest level
+func TestAnalyzeParallelismStaggeredChains(t *testing.T) {
+	// all → a3 → a2 → a1
+	//     → c3 → a2
+	//     → b2 → b1
+	// Levels: [a1 b1] [a2 b2] [a3 c3] [all]: no level is wider than 2, but
+	// b2 (level 1) still runs while a3 and c3 (level 2) do
+	targets := []makefile.Target{
+		{Name: "all", Dependencies: []string{"a3", "c3", "b2"}},
+		{Name: "a3", Dependencies: []string{"a2"}, Recipe: recipe},
+		{Name: "c3", Dependencies: []string{"a2"}, Recipe: recipe},
+		{Name: "a2", Dependencies: []string{"a1"}, Recipe: recipe},
+		{Name: "a1", Recipe: recipe},
+		{Name: "b2", Dependencies: []string{"b1"}, Recipe: recipe},
+		{Name: "b1", Recipe: recipe},
+	}
+	durations := map[string]time.Duration{
+		"a1": 1 * time.Second,
+		"a2": 1 * time.Second,
+		"a3": 2 * time.Second,
+		"c3": 2 * time.Second,
+		"b1": 1 * time.Second,
+		"b2": 3 * time.Second,
+	}
+
+	g := BuildGraph(targets)
+	analysis := g.AnalyzeParallelism("all", durations)
+	if analysis == nil {
+		t.Fatal("Expected analysis, got nil")
+	}
+
+	if analysis.MaxWidth != 2 {
+		t.Errorf("Expected max width 2, got %d", analysis.MaxWidth)
+	}
+	if analysis.MaxJobs != 3 || len(analysis.Estimates) != 3 {
+		t.Fatalf("Expected 3 max jobs and estimates, got %d and %d", analysis.MaxJobs, len(analysis.Estimates))
+	}
+
+	// -j2: c3 waits for a slot until b2 and a3 finish at 4s = 6s
+	// -j3: a3, c3 and b2 all finish at 4s
+	if analysis.Estimates[1].Makespan != 6*time.Second {
+		t.Errorf("Expected -j2 makespan 6s, got %v", analysis.Estimates[1].Makespan)
+	}
+	if analysis.Estimates[2].Makespan != 4*time.Second {
+		t.Errorf("Expected -j3 makespan 4s, got %v", analysis.Estimates[2].Makespan)
+	}
+	if analysis.RecommendedJobs != 3 {
+		t.Errorf("Expected recommended -j3, got -j%d", analysis.RecommendedJobs)
+	}
+}
+
+// TestAnalyzeParallelismLinearChain tests that a linear chain recommends -j1
+func TestAnalyzeParallelismLinearChain(t *testing.T) {
+	targets := []makefile.Target{
+		{Name: "all", Dependencies: []string{"build"}, Recipe: recipe},
+		{Name: "build", Dependencies: []string{"deps"}, Recipe: recipe},
+		{Name: "deps", Dependencies: nil, Recipe: recipe},
+	}
+
+	g := BuildGraph(targets)
+	analysis := g.AnalyzeParallelism("all", nil)
+
+	if analysis.MaxWidth != 1 || analysis.MaxJobs != 1 {
+		t.Errorf("Expected max width and jobs 1, got %d and %d", analysis.MaxWidth, analysis.MaxJobs)
+	}
+	if analysis.RecommendedJobs != 1 {
+		t.Errorf("Expected recommended -j1, got -j%d", analysis.RecommendedJobs)
+	}
+}
+
+// TestAnalyzeParallelismInvalid tests nil results for missing targets and cycles
+func TestAnalyzeParallelismInvalid(t *testing.T) {
+	g := BuildGraph([]makefile.Target{{Name: "build"}})
+	if g.AnalyzeParallelism("missing", nil) != nil {
+		t.Error("Expected nil analysis for missing target")
+	}
+
+	cyclic := BuildGraph([]makefile.Target{
+		{Name: "A", Dependencies: []string{"B"}},
+		{Name: "B", Dependencies: []string{"A"}},
+	})
+	if cyclic.AnalyzeParallelism("A", nil) != nil {
+		t.Error("Expected nil analysis for cyclic graph")
+	}
+}
+
+// TestEstimateSelfDurations tests converting total run times into self-times
+func TestEstimateSelfDurations(t *testing.T) {
+	targets := []makefile.Target{
+		{Name: "all", Dependencies: []string{"build"}},
+		{Name: "build", Dependencies: []string{"deps"}},
+		{Name: "deps", Dependencies: nil},
+		{Name: "lint", Dependencies: nil},
+	}
+
+	g := BuildGraph(targets)
+	self := g.EstimateSelfDurations(map[string]time.Duration{
+		"all":   10 * time.Second,
+		"build": 7 * time.Second,
+		"deps":  2 * time.Second,
+	})
+
+	expected := map[string]time.Duration{
+		"deps":  2 * time.Second,
+		"build": 5 * time.Second,
+		"all":   3 * time.Second,
+	}
+	for name, want := range expected {
+		if self[name] != want {
+			t.Errorf("Expected self-time %v for %s, got %v", want, name, self[name])
+		}
+	}
+
+	if _, ok := self["lint"]; ok {
+		t.Error("Targets without recorded totals should be omitted")
+	}
+}
@@ -0,0 +1,268 @@
+package graph
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/variables"
+	"github.com/rshelekhov/lazymake/internal/workspace"
+)
+
+// Source is where a node from another Makefile than the graph's is defined
+type Source struct {
+	Makefile string // Absolute path of the Makefile
+	Dir      string // Prefix of the node's name: where the Makefile is, relative to the graph's Makefile
+	Target   string // Name of the target in that Makefile
+
+	// Variables of that Makefile, as written; its recipes are expanded with
+	// these rather than the graph's Makefile's values
+	Variables map[string]string
+}
+
+// variableReference matches $(NAME) and ${NAME}
+var variableReference = regexp.MustCompile(`\$[({]([A-Za-z0-9_.-]+)[)}]`)
+
+// subMakefile is a Makefile parsed while following recursive make
+type subMakefile struct {
+	targets []makefile.Target // In file order, for the default goal
+	graph   *Graph
+	values  map[string]string // Variables as written (make isn't run for sub-Makefiles)
+}
+
+// subMakeLinker follows recursive make invocations from one graph into other Makefiles
+type subMakeLinker struct {
+	g      *Graph
+	main   string            // Absolute path of the graph's Makefile
+	root   string            // Its directory, which sub-Makefile names are relative to
+	values map[string]string // Expanded variables of the graph's Makefile
+	parsed map[string]*subMakefile
+	queue  []*Node // Nodes whose recipes haven't been followed yet
+}
+
+// LinkSubMakes follows the recursive make invocations in recipes into the Makefiles they run
+//
+// Each Makefile is parsed once. The targets run by "$(MAKE) -C dir target", and their
+// prerequisites, are added to the graph named after their directory relative to
+// makefilePath ("services/api:build"), and linked to the calling target through
+// SubMakes. Order, critical path and parallel markers of added nodes are those of
+// their own Makefile. Invocations that can't be resolved, such as directories from
+// shell loops or directories without a Makefile, are skipped.
+//
+// values holds the Makefile's expanded variables, used for directories like
+// $(API_DIR). Nodes with a Source are followed relative to their own Makefile,
+// with its own variables (Source.Variables).
+//
+// Example:
+//
+//	build:
+//		$(MAKE) -C services/api build
+//
+// links build to services/api:build, which keeps its own prerequisites
+func (g *Graph) LinkSubMakes(makefilePath string, values map[string]string) {
+	main, err := filepath.Abs(makefilePath)
+	if err != nil {
+		return
+	}
+
+	l := &subMakeLinker{
+		g:      g,
+		main:   main,
+		root:   filepath.Dir(main),
+		values: values,
+		parsed: make(map[string]*subMakefile),
+	}
+
+	// Sorted for a stable order of added nodes
+	names := make([]string, 0, len(g.Nodes))
+	for name := range g.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		l.queue = append(l.queue, g.Nodes[name])
+	}
+
+	for len(l.queue) > 0 {
+		node := l.queue[0]
+		l.queue = l.queue[1:]
+		l.link(node)
+	}
+}
+
+// HasSubMakes reports whether any target runs another Makefile's target
+func (g *Graph) HasSubMakes() bool {
+	for _, node := range g.Nodes {
+		if len(node.SubMakes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// link adds the targets a node's recipe runs with recursive make
+func (l *subMakeLinker) link(node *Node) {
+	makefilePath, values := l.main, l.values
+	if node.Source != nil {
+		makefilePath, values = node.Source.Makefile, node.Source.Variables
+	}
+
+	for _, call := range node.Target.SubMakes() {
+		target := l.resolve(makefilePath, values, call)
+		if target != nil && target != node && !slices.Contains(node.SubMakes, target) {
+			node.SubMakes = append(node.SubMakes, target)
+		}
+	}
+}
+
+// resolve finds the node an invocation in makefilePath's recipes runs, adding it if needed
+// Returns nil if the invocation can't be resolved.
+func (l *subMakeLinker) resolve(makefilePath string, values map[string]string, call makefile.SubMake) *Node {
+	dir := filepath.Dir(makefilePath)
+	subDir := expandVariables(call.Dir, values, dir)
+	file := expandVariables(call.File, values, dir)
+	if strings.Contains(subDir+file, "$") {
+		return nil // Only known when the recipe runs
+	}
+	if !filepath.IsAbs(subDir) {
+		subDir = filepath.Join(dir, subDir)
+	}
+
+	var path string
+	switch {
+	case file != "":
+		path = file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(subDir, file)
+		}
+	case call.Dir == "":
+		path = makefilePath
+	default:
+		found, err := makefile.Find(subDir)
+		if err != nil {
+			return nil
+		}
+		path = found
+	}
+
+	sub := l.parse(path)
+	if sub == nil {
+		return nil
+	}
+
+	name := call.Target
+	if name == "" {
+		name = defaultGoal(sub.targets)
+		if name == "" {
+			return nil
+		}
+	}
+	return l.importNode(sub, path, l.prefix(path), name)
+}
+
+// parse reads a Makefile once
+// Returns nil if it can't be parsed.
+func (l *subMakeLinker) parse(path string) *subMakefile {
+	if sub, seen := l.parsed[path]; seen {
+		return sub
+	}
+
+	var sub *subMakefile
+	if targets, err := makefile.Parse(path); err == nil {
+		sub = &subMakefile{targets: targets, graph: BuildGraph(targets), values: make(map[string]string)}
+		// Graceful degradation: without variables, recipes are checked as written
+		if vars, err := variables.ParseVariables(path); err == nil {
+			for _, v := range vars {
+				sub.values[v.Name] = v.RawValue
+			}
+		}
+	}
+	l.parsed[path] = sub
+	return sub
+}
+
+// prefix returns the name prefix of a Makefile's targets
+// The graph's own Makefile has none; other Makefiles are named after their
+// directory, or after the file when it isn't the one make reads by default.
+func (l *subMakeLinker) prefix(path string) string {
+	if path == l.main {
+		return ""
+	}
+
+	named := path
+	if found, err := makefile.Find(filepath.Dir(path)); err == nil && found == path && filepath.Dir(path) != l.root {
+		named = filepath.Dir(path)
+	}
+	rel, err := filepath.Rel(l.root, named)
+	if err != nil {
+		return filepath.ToSlash(named)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// importNode returns the node of a sub-Makefile's target, adding it and its prerequisites if needed
+// Targets the sub-Makefile doesn't define are added as placeholders.
+func (l *subMakeLinker) importNode(sub *subMakefile, path, prefix, name string) *Node {
+	qualified := workspace.QualifiedName(prefix, name)
+	if node, exists := l.g.Nodes[qualified]; exists {
+		return node
+	}
+
+	node := &Node{
+		Dependencies: make([]*Node, 0),
+		Dependents:   make([]*Node, 0),
+		Source:       &Source{Makefile: path, Dir: prefix, Target: name, Variables: sub.values},
+	}
+	original, exists := sub.graph.Nodes[name]
+	if exists {
+		node.Target = original.Target
+		node.Order = original.Order
+		node.IsCritical = original.IsCritical
+		node.CanParallel = original.CanParallel
+	} else {
+		node.Target.Description = fmt.Sprintf("(not found in %s)", filepath.Base(path))
+	}
+	node.Target.Name = qualified
+	l.g.Nodes[qualified] = node
+
+	if exists {
+		for _, dep := range original.Dependencies {
+			depNode := l.importNode(sub, path, prefix, dep.Target.Name)
+			node.Dependencies = append(node.Dependencies, depNode)
+			depNode.Dependents = append(depNode.Dependents, node)
+		}
+	}
+
+	l.queue = append(l.queue, node)
+	return node
+}
+
+// defaultGoal returns the target make runs when none is given: the first one
+// that isn't special (.PHONY) or a pattern rule
+func defaultGoal(targets []makefile.Target) string {
+	for _, t := range targets {
+		if !strings.HasPrefix(t.Name, ".") && !strings.Contains(t.Name, "%") {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+// expandVariables replaces the variables in a directory or file name with their values
+// CURDIR is the directory of the Makefile; unknown variables are left as they are.
+func expandVariables(s string, values map[string]string, dir string) string {
+	return variableReference.ReplaceAllStringFunc(s, func(reference string) string {
+		name := variableReference.FindStringSubmatch(reference)[1]
+		if name == "CURDIR" {
+			return dir
+		}
+		if value, found := values[name]; found {
+			return value
+		}
+		return reference
+	})
+}
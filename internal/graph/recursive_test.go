@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/testutil"
+)
+
+// TestLinkSubMakes follows $(MAKE) -C into sub-Makefiles
+func TestLinkSubMakes(t *testing.T) {
+	root := t.TempDir()
+	main := testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"release: lint\n"+
+			"\t$(MAKE) -C services/api build\n"+
+			"\t$(MAKE) -C $(WEB_DIR)\n"+
+			"\tfor d in $(DIRS); do $(MAKE) -C $$d test; done\n"+
+			"\n"+
+			"lint:\n"+
+			"\t$(MAKE) -C services/api lint missing\n"+
+			"\t$(MAKE) -f tools.mk fmt\n")
+	testutil.WriteFile(t, filepath.Join(root, "services", "api", "Makefile"),
+		"build: deps\n"+
+			"\tgo build\n"+
+			"\n"+
+			"deps:\n"+
+			"\tgo mod download\n"+
+			"\n"+
+			"lint:\n"+
+			"\t$(MAKE) -C ../../lib check\n")
+	testutil.WriteFile(t, filepath.Join(root, "web", "Makefile"),
+		".PHONY: all\n"+
+			"all:\n"+
+			"\tnpm run build\n")
+	testutil.WriteFile(t, filepath.Join(root, "lib", "Makefile"),
+		"check:\n"+
+			"\tgo vet ./...\n")
+	testutil.WriteFile(t, filepath.Join(root, "tools.mk"),
+		"fmt:\n"+
+			"\tgofmt -w .\n")
+
+	targets, err := makefile.Parse(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := BuildGraph(targets)
+	g.LinkSubMakes(main, map[string]string{"WEB_DIR": "web"})
+
+	subMakes := func(name string) []string {
+		node := g.Nodes[name]
+		if node == nil {
+			t.Fatalf("%s not in graph", name)
+		}
+		var names []string
+		for _, sub := range node.SubMakes {
+			names = append(names, sub.Target.Name)
+		}
+		return names
+	}
+
+	if got := strings.Join(subMakes("release"), " "); got != "services/api:build web:all" {
+		t.Errorf("release runs %q, want the API build and web's default goal", got)
+	}
+	if got := strings.Join(subMakes("lint"), " "); got != "services/api:lint services/api:missing tools.mk:fmt" {
+		t.Errorf("lint runs %q", got)
+	}
+	if got := strings.Join(subMakes("services/api:lint"), " "); got != "lib:check" {
+		t.Errorf("sub-Makefiles should be followed too, services/api:lint runs %q", got)
+	}
+
+	build := g.Nodes["services/api:build"]
+	if build.Source == nil || build.Source.Dir != "services/api" || build.Source.Target != "build" {
+		t.Fatalf("unexpected source %+v", build.Source)
+	}
+	if build.Source.Makefile != filepath.Join(root, "services/api/Makefile") {
+		t.Errorf("source Makefile = %s", build.Source.Makefile)
+	}
+	if len(build.Dependencies) != 1 || build.Dependencies[0] != g.Nodes["services/api:deps"] {
+		t.Error("prerequisites of sub-Makefile targets should be added with them")
+	}
+	if build.Order != 2 {
+		t.Errorf("order should come from the sub-Makefile, got %d", build.Order)
+	}
+	if !strings.Contains(g.Nodes["services/api:missing"].Target.Description, "not found") {
+		t.Error("targets the sub-Makefile doesn't define should be placeholders")
+	}
+
+	// Added nodes hang off their callers instead of becoming roots
+	for _, root := range g.Roots {
+		if root.Source != nil {
+			t.Errorf("%s should not be a root", root.Target.Name)
+		}
+	}
+
+	output := g.RenderTree(TreeRenderer{})
+	if !strings.Contains(output, "├┄▶ services/api:build") || !strings.Contains(output, "└┄▶ lib:check") {
+		t.Errorf("recursive make should be drawn with dashed branches:\n%s", output)
+	}
+	if !g.HasSubMakes() {
+		t.Error("HasSubMakes() = false")
+	}
+}
@@ -17,6 +17,7 @@ type TreeRenderer struct {
 	FormatOrder    func(string) string // Format execution order [N]
 	FormatCritical func(string) string // Format critical path marker ★
 	FormatParallel func(string) string // Format parallel marker ||
+	FormatSubMake  func(string) string // Format the branch to a target run by recursive make
 }
 
 // RenderTree returns a string representation of the graph as an ASCII tree
@@ -34,6 +35,11 @@ type TreeRenderer struct {
 //	│   └── deps [1]
 //	└── test [2] ||
 //	    └── deps [1] (see above)
+//
+// Targets run by recursive make (see LinkSubMakes) hang off dashed branches:
+//
+//	release
+//	└┄▶ services/api:build
 func (g *Graph) RenderTree(renderer TreeRenderer) string {
 	var builder strings.Builder
 
@@ -58,7 +64,7 @@ func (g *Graph) RenderTree(renderer TreeRenderer) string {
 		if i > 0 {
 			util.WriteString(&builder, "\n") // Blank line between separate trees
 		}
-		renderNode(root, "", true, &builder, renderer, visited)
+		renderNode(root, "", true, false, &builder, renderer, visited)
 	}
 
 	return builder.String()
@@ -72,6 +78,7 @@ func renderNode(
 	node *Node, // The node to render
 	prefix string, // The string to print before this node (contains │ and spaces for indentation)
 	isLast bool, // Is this the last child of its parent? (affects which branch character to use)
+	subMake bool, // Is this node run by its parent's recipe with recursive make?
 	builder *strings.Builder, // Where to write the output
 	renderer TreeRenderer, // Controls which annotations to show
 	visited map[string]bool, // Tracks nodes we've already rendered (prevents infinite loops)
@@ -82,12 +89,9 @@ func renderNode(
 	// In graphs with shared dependencies (diamond pattern), we might encounter
 	// the same node multiple times. We show it once fully, then just reference it
 	// with "(see above)" for subsequent encounters.
+	connector := branch(isLast, subMake, renderer)
 	if visited[nodeName] {
 		// This node was already rendered - just show a reference
-		connector := "├── "
-		if isLast {
-			connector = "└── "
-		}
 		util.WriteString(builder, prefix+connector+nodeName+" (see above)\n")
 		return
 	}
@@ -95,12 +99,6 @@ func renderNode(
 	// Mark as visited
 	visited[nodeName] = true
 
-	// Determine which branch character to use
-	connector := "├── "
-	if isLast {
-		connector = "└── "
-	}
-
 	// Build the node display string with all requested annotations
 	nodeStr := buildNodeString(node, renderer)
 
@@ -113,12 +111,37 @@ func renderNode(
 		extension = "    "
 	}
 
-	// Recursively render dependencies (children)
+	// Recursively render dependencies (children), then the targets the recipe runs with recursive make
 	deps := node.Dependencies
+	children := len(deps) + len(node.SubMakes)
 	for i, dep := range deps {
-		isLastDep := i == len(deps)-1
-		renderNode(dep, prefix+extension, isLastDep, builder, renderer, visited)
+		isLastDep := i == children-1
+		renderNode(dep, prefix+extension, isLastDep, false, builder, renderer, visited)
+	}
+	for i, sub := range node.SubMakes {
+		isLastSub := len(deps)+i == children-1
+		renderNode(sub, prefix+extension, isLastSub, true, builder, renderer, visited)
+	}
+}
+
+// branch returns the connector drawn before a node: solid for prerequisites,
+// dashed for targets run by recursive make
+func branch(isLast, subMake bool, renderer TreeRenderer) string {
+	if !subMake {
+		if isLast {
+			return "└── "
+		}
+		return "├── "
+	}
+
+	connector := "├┄▶"
+	if isLast {
+		connector = "└┄▶"
+	}
+	if renderer.FormatSubMake != nil {
+		connector = renderer.FormatSubMake(connector)
 	}
+	return connector + " "
 }
 
 // buildNodeString creates the display string for a node with all annotations
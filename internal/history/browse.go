@@ -0,0 +1,220 @@
+package history
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status filters runs by outcome
+type Status int
+
+const (
+	StatusAny Status = iota
+	StatusSuccess
+	StatusFailed
+)
+
+// String returns the lowercase name used in queries ("all", "success", "failed")
+func (s Status) String() string {
+	switch s {
+	case StatusSuccess:
+		return "success"
+	case StatusFailed:
+		return "failed"
+	default:
+		return "all"
+	}
+}
+
+// Run is a single recorded execution together with where it ran
+type Run struct {
+	MakefilePath string
+	Target       string
+	ExecutionRecord
+
+	// Set by the SQLite backend
+	ID        int64 // Row ID for RunOutput
+	HasOutput bool  // Output was captured in the database
+}
+
+// Query selects runs for the history browser
+// Zero values mean "no filter" for every field.
+type Query struct {
+	Project string    // Case-insensitive substring of the Makefile path
+	Target  string    // Case-insensitive substring of the target name
+	Status  Status    // Outcome filter
+	Since   time.Time // Only runs at or after this time
+	Until   time.Time // Only runs before this time
+	Text    string    // Case-insensitive substring of project, target or arguments
+}
+
+// Runs returns all recorded runs across every Makefile that match the query
+// Results are sorted newest first. Runs come from the long-lived time series,
+// so they are subject to the configured retention.
+func (h *History) Runs(q Query) []Run {
+	// The database also sees runs recorded by other lazymake instances
+	if h.store != nil {
+		if runs, err := h.store.runs(q); err == nil {
+			return runs
+		}
+	}
+
+	project := strings.ToLower(q.Project)
+	target := strings.ToLower(q.Target)
+	text := strings.ToLower(q.Text)
+
+	var runs []Run
+	for makefilePath, targets := range h.Series {
+		if project != "" && !strings.Contains(strings.ToLower(makefilePath), project) {
+			continue
+		}
+
+		for name, samples := range targets {
+			if target != "" && !strings.Contains(strings.ToLower(name), target) {
+				continue
+			}
+
+			for _, sample := range samples {
+				run := Run{MakefilePath: makefilePath, Target: name, ExecutionRecord: sample}
+				if run.matches(q, text) {
+					runs = append(runs, run)
+				}
+			}
+		}
+	}
+
+	// Newest first; path and target break ties for a stable order
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].Timestamp.Equal(runs[j].Timestamp) {
+			return runs[i].Timestamp.After(runs[j].Timestamp)
+		}
+		if runs[i].MakefilePath != runs[j].MakefilePath {
+			return runs[i].MakefilePath < runs[j].MakefilePath
+		}
+		return runs[i].Target < runs[j].Target
+	})
+
+	return runs
+}
+
+// RunOutput returns the output captured for a run by the SQLite backend
+// Returns ErrNoOutput when nothing was captured (always for the JSON backend).
+func (h *History) RunOutput(run Run) (string, error) {
+	if h.store == nil || !run.HasOutput {
+		return "", ErrNoOutput
+	}
+	return h.store.output(run.ID)
+}
+
+// matches applies the per-run parts of a query (status, date range, free text)
+func (r Run) matches(q Query, text string) bool {
+	switch q.Status {
+	case StatusSuccess:
+		if !r.Success {
+			return false
+		}
+	case StatusFailed:
+		if r.Success {
+			return false
+		}
+	}
+
+	if !q.Since.IsZero() && r.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !r.Timestamp.Before(q.Until) {
+		return false
+	}
+
+	if text != "" {
+		haystack := strings.ToLower(r.MakefilePath + " " + r.Target + " " + strings.Join(r.Args, " "))
+		if !strings.Contains(haystack, text) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseQuery parses a filter string typed in the history browser
+//
+// Recognized tokens (anything else becomes free text):
+//
+//	project:api         Makefile path contains "api"
+//	target:test         target name contains "test"
+//	status:failed       success | ok | failed | fail
+//	since:7d            relative (30m, 24h, 7d) or a date (2026-01-31)
+//	until:2026-02-01    runs before this date (the whole day is included)
+//
+// now anchors relative durations so results are reproducible in tests.
+func ParseQuery(input string, now time.Time) Query {
+	var q Query
+	var text []string
+
+	for _, field := range strings.Fields(input) {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok || value == "" {
+			text = append(text, field)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "project", "p":
+			q.Project = value
+		case "target", "t":
+			q.Target = value
+		case "status", "s":
+			q.Status = parseStatus(value)
+		case "since":
+			if t, ok := parseTimeBound(value, now, false); ok {
+				q.Since = t
+			}
+		case "until":
+			if t, ok := parseTimeBound(value, now, true); ok {
+				q.Until = t
+			}
+		default:
+			text = append(text, field)
+		}
+	}
+
+	q.Text = strings.Join(text, " ")
+	return q
+}
+
+// parseStatus converts a status token into a Status (unknown values mean "any")
+func parseStatus(value string) Status {
+	switch strings.ToLower(value) {
+	case "success", "ok", "passed":
+		return StatusSuccess
+	case "failed", "fail", "error":
+		return StatusFailed
+	default:
+		return StatusAny
+	}
+}
+
+// parseTimeBound parses a relative duration (30m, 24h, 7d) or a YYYY-MM-DD date
+// Relative values count back from now. For an end bound a date includes the whole day.
+func parseTimeBound(value string, now time.Time, end bool) (time.Time, bool) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return now.AddDate(0, 0, -days), true
+		}
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), true
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, true
+	}
+
+	return time.Time{}, false
+}
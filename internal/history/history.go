@@ -2,18 +2,21 @@ package history
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"time"
+
+	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/filelock"
 )
 
 const (
-	maxRecentTargets     = 5
-	maxRecentExecutions  = 10
-	regressionMultiplier = 1.25
-	historyFileName      = "history.json"
+	maxRecentTargets    = 5
+	maxRecentExecutions = 10
+	historyFileName     = "history.json"
 )
 
 // ExecutionRecord represents a single target execution with timing
@@ -21,6 +24,17 @@ type ExecutionRecord struct {
 	Duration  time.Duration `json:"duration"`
 	Timestamp time.Time     `json:"timestamp"`
 	Success   bool          `json:"success"`
+
+	// Optional run details (shown in the history browser)
+	ExitCode   int      `json:"exit_code,omitempty"`   // Process exit code (0 = success, -1 = failed to start)
+	Args       []string `json:"args,omitempty"`        // Extra make arguments after the target (e.g. VAR=value)
+	ExportPath string   `json:"export_path,omitempty"` // Exported output file, if export was enabled
+
+	// How the target was confirmed in the safety dialog (nil if it ran without confirmation)
+	Confirmation *export.Confirmation `json:"confirmation,omitempty"`
+
+	// Captured output, persisted only by the SQLite backend (never kept in memory)
+	Output string `json:"-"`
 }
 
 // Entry represents a single target execution record
@@ -38,16 +52,27 @@ type PerformanceStats struct {
 	MinDuration    time.Duration
 	MaxDuration    time.Duration
 	ExecutionCount int
-	IsRegressed    bool // >25% slower than avg
+	IsRegressed    bool // Last run is statistically slower than earlier runs (see Config)
 }
 
 // History manages execution history across multiple Makefiles
 type History struct {
 	// Map of absolute Makefile path -> list of recent target entries
 	Entries map[string][]Entry `json:"entries"`
-	path    string             // Cache file path
+
+	// Map of absolute Makefile path -> target name -> long-lived performance time series
+	// Kept separately from Entries so it survives recents eviction (see Config for retention)
+	Series map[string]map[string][]ExecutionRecord `json:"series,omitempty"`
+
+	path    string           // Cache file path
+	config  *Config          // Performance settings (nil = defaults)
+	store   *sqliteStore     // SQLite backend (nil = JSON file)
+	pending []func(*History) // Changes since the last Save, replayed onto the file when saving
 }
 
+// errCorrupt is wrapped by readFile when a history file cannot be decoded
+var errCorrupt = errors.New("corrupt history file")
+
 // Load reads history from the cache directory
 // Returns an empty history on error (graceful degradation)
 func Load() (*History, error) {
@@ -56,54 +81,108 @@ func Load() (*History, error) {
 		return newEmptyHistory(), fmt.Errorf("failed to get cache path: %w", err)
 	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		// File doesn't exist yet - return empty history
-		if os.IsNotExist(err) {
-			h := newEmptyHistory()
-			h.path = path
-			return h, nil
-		}
-		return newEmptyHistory(), fmt.Errorf("failed to read history file: %w", err)
-	}
+	return loadFile(path)
+}
 
-	var h History
-	if err := json.Unmarshal(data, &h); err != nil {
+// loadFile reads history from path (see Load)
+func loadFile(path string) (*History, error) {
+	h, err := readFile(path)
+	switch {
+	case os.IsNotExist(err):
+		// File doesn't exist yet - return empty history
+		h = newEmptyHistory()
+	case errors.Is(err, errCorrupt):
 		// Corrupt JSON - return empty history and log warning
 		_, _ = fmt.Fprintf(os.Stderr, "Warning: corrupt history file, resetting: %v\n", err)
-		h = *newEmptyHistory()
-	}
-
-	if h.Entries == nil {
-		h.Entries = make(map[string][]Entry)
+		h = newEmptyHistory()
+	case err != nil:
+		return newEmptyHistory(), fmt.Errorf("failed to read history file: %w", err)
 	}
 
 	h.path = path
-	return &h, nil
+	return h, nil
 }
 
 // Save writes history to disk
+// Changes made by other lazymake instances since Load are kept: under a file lock,
+// this instance's changes are replayed onto the current file, which is then
+// replaced atomically. The in-memory history is updated to the merged result.
+// With the SQLite backend only this instance's new changes are written.
 func (h *History) Save() error {
+	if h.store != nil {
+		return h.store.flush(h.settings())
+	}
+
 	if h.path == "" {
 		return fmt.Errorf("history path not set")
 	}
 
-	// Ensure cache directory exists
-	dir := filepath.Dir(h.path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create cache directory: %w", err)
+	return filelock.WithLock(h.path, func() error {
+		merged, err := h.mergeWithDisk()
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(merged, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal history: %w", err)
+		}
+
+		if err := filelock.WriteAtomic(h.path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write history file: %w", err)
+		}
+
+		h.Entries = merged.Entries
+		h.Series = merged.Series
+		h.pending = nil
+		return nil
+	})
+}
+
+// mergeWithDisk replays this instance's unsaved changes onto the file's current content
+// A missing or corrupt file has nothing to merge, so the in-memory history is written as-is.
+func (h *History) mergeWithDisk() (*History, error) {
+	disk, err := readFile(h.path)
+	switch {
+	case os.IsNotExist(err), errors.Is(err, errCorrupt):
+		return h, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	// Apply this instance's retention before replaying its changes
+	disk.SetConfig(h.config)
+	for _, change := range h.pending {
+		change(disk)
 	}
 
-	data, err := json.MarshalIndent(h, "", "  ")
+	return disk, nil
+}
+
+// readFile reads and decodes a history file
+// Returns an error wrapping errCorrupt if the file is not valid JSON.
+func readFile(path string) (*History, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to marshal history: %w", err)
+		return nil, err
+	}
+
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorrupt, err)
 	}
 
-	if err := os.WriteFile(h.path, data, 0600); err != nil {
-		return fmt.Errorf("failed to write history file: %w", err)
+	if h.Entries == nil {
+		h.Entries = make(map[string][]Entry)
 	}
+	return &h, nil
+}
 
-	return nil
+// queueChange records a change to replay onto the file on the next Save
+func (h *History) queueChange(change func(*History)) {
+	if h.store == nil {
+		h.pending = append(h.pending, change)
+	}
 }
 
 // RecordExecution adds or updates a target execution record (legacy method without timing)
@@ -116,7 +195,35 @@ func (h *History) RecordExecution(makefilePath, targetName string) {
 // Implements LRU eviction: keeps only the maxRecentTargets most recent targets
 // Implements execution history LRU: keeps only the maxRecentExecutions most recent executions
 func (h *History) RecordExecutionWithTiming(makefilePath, targetName string, duration time.Duration, success bool) {
+	h.RecordRun(makefilePath, targetName, ExecutionRecord{
+		Duration: duration,
+		Success:  success,
+	})
+}
+
+// RecordRun adds or updates a target execution record with full run details
+// Behaves like RecordExecutionWithTiming; a zero Timestamp is set to now.
+func (h *History) RecordRun(makefilePath, targetName string, record ExecutionRecord) {
 	now := time.Now()
+	if record.Timestamp.IsZero() {
+		record.Timestamp = now
+	}
+
+	if h.store != nil {
+		h.store.queueRun(makefilePath, targetName, record, now)
+	}
+	record.Output = ""
+
+	h.applyRun(makefilePath, targetName, record, now)
+	h.queueChange(func(d *History) { d.applyRun(makefilePath, targetName, record, now) })
+}
+
+// applyRun updates the recents list and time series for a run used at now
+func (h *History) applyRun(makefilePath, targetName string, record ExecutionRecord, now time.Time) {
+	// Every timed run also goes into the long-lived time series
+	if record.Duration > 0 {
+		h.recordSample(makefilePath, targetName, record)
+	}
 
 	entries := h.Entries[makefilePath]
 
@@ -124,18 +231,15 @@ func (h *History) RecordExecutionWithTiming(makefilePath, targetName string, dur
 	found := false
 	for i := range entries {
 		if entries[i].Name == targetName {
-			// Update existing entry
-			entries[i].LastUsed = now
+			// Update existing entry (a replayed run may be older than another instance's)
+			if now.After(entries[i].LastUsed) {
+				entries[i].LastUsed = now
+			}
 			entries[i].UseCount++
 
 			// Add execution record if duration is provided
-			if duration > 0 {
-				exec := ExecutionRecord{
-					Duration:  duration,
-					Timestamp: now,
-					Success:   success,
-				}
-				entries[i].RecentExecutions = append(entries[i].RecentExecutions, exec)
+			if record.Duration > 0 {
+				entries[i].RecentExecutions = insertByTime(entries[i].RecentExecutions, record)
 
 				// Keep only the most recent maxRecentExecutions
 				if len(entries[i].RecentExecutions) > maxRecentExecutions {
@@ -157,12 +261,8 @@ func (h *History) RecordExecutionWithTiming(makefilePath, targetName string, dur
 		}
 
 		// Add execution record if duration is provided
-		if duration > 0 {
-			entry.RecentExecutions = []ExecutionRecord{{
-				Duration:  duration,
-				Timestamp: now,
-				Success:   success,
-			}}
+		if record.Duration > 0 {
+			entry.RecentExecutions = []ExecutionRecord{record}
 		}
 
 		entries = append(entries, entry)
@@ -229,11 +329,13 @@ func (h *History) GetPerformanceStats(makefilePath, targetName string) *Performa
 	// Get last execution (may be failed)
 	lastExec := entry.RecentExecutions[len(entry.RecentExecutions)-1]
 
-	// Detect regression: last run is >25% slower than average
+	// Detect regression: last run compared statistically against all earlier successful runs
 	isRegressed := false
-	if lastExec.Success && avgDuration > 0 {
-		threshold := time.Duration(float64(avgDuration) * regressionMultiplier)
-		isRegressed = lastExec.Duration > threshold
+	if lastExec.Success {
+		durations := h.successfulDurations(makefilePath, entry)
+		if len(durations) > 0 {
+			isRegressed = h.isRegression(durations[:len(durations)-1], durations[len(durations)-1])
+		}
 	}
 
 	return &PerformanceStats{
@@ -263,6 +365,16 @@ func (h *History) GetRecent(makefilePath string) []Entry {
 // FilterValid removes targets that no longer exist in the Makefile
 // This prevents showing stale targets that have been removed or renamed
 func (h *History) FilterValid(makefilePath string, validTargets []string) {
+	if h.store != nil {
+		h.store.queueFilterValid(makefilePath, validTargets)
+	}
+
+	h.applyFilterValid(makefilePath, validTargets)
+	h.queueChange(func(d *History) { d.applyFilterValid(makefilePath, validTargets) })
+}
+
+// applyFilterValid drops recents whose targets are not in validTargets
+func (h *History) applyFilterValid(makefilePath string, validTargets []string) {
 	entries := h.Entries[makefilePath]
 	if len(entries) == 0 {
 		return
@@ -285,6 +397,18 @@ func (h *History) FilterValid(makefilePath string, validTargets []string) {
 	h.Entries[makefilePath] = filtered
 }
 
+// insertByTime inserts a record keeping the slice ordered oldest first
+// Runs replayed from another instance's save may be older than the newest record.
+func insertByTime(records []ExecutionRecord, record ExecutionRecord) []ExecutionRecord {
+	i := sort.Search(len(records), func(i int) bool {
+		return records[i].Timestamp.After(record.Timestamp)
+	})
+	records = append(records, ExecutionRecord{})
+	copy(records[i+1:], records[i:])
+	records[i] = record
+	return records
+}
+
 // getCachePath returns the platform-appropriate cache file path
 // Prefers XDG cache directory, falls back to ~/.cache
 func getCachePath() (string, error) {
@@ -1,11 +1,16 @@
 package history
 
 import (
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/rshelekhov/lazymake/internal/export"
 )
 
 func TestLoad_NonExistentFile(t *testing.T) {
@@ -453,10 +458,10 @@ func TestGetPerformanceStats_RegressionDetection(t *testing.T) {
 		h.RecordExecutionWithTiming(makefile, "slow-build", 2*time.Second, true)
 	}
 
-	// Record a much slower execution (>25% slower than the new average)
-	// After 5x2s + 1x4s = 14s, average = 14/6 = 2.33s
-	// Threshold = 2.33s * 1.25 = 2.91s
-	// So 4s > 2.91s triggers regression
+	// Record a much slower execution
+	// Baseline: 5x2s → mean 2s, stddev 0 (floored to 10% of the mean = 0.2s)
+	// Threshold = 2s + 3 * 0.2s = 2.6s
+	// So 4s > 2.6s triggers regression
 	h.RecordExecutionWithTiming(makefile, "slow-build", 4*time.Second, true)
 
 	stats := h.GetPerformanceStats(makefile, "slow-build")
@@ -466,8 +471,8 @@ func TestGetPerformanceStats_RegressionDetection(t *testing.T) {
 	}
 
 	if !stats.IsRegressed {
-		t.Errorf("Expected regression to be detected: last=%v, avg=%v, threshold=%v",
-			stats.LastDuration, stats.AvgDuration, time.Duration(float64(stats.AvgDuration)*regressionMultiplier))
+		t.Errorf("Expected regression to be detected: last=%v, avg=%v",
+			stats.LastDuration, stats.AvgDuration)
 	}
 }
 
@@ -517,3 +522,819 @@ func TestGetPerformanceStats_IgnoreFailures(t *testing.T) {
 		t.Errorf("Expected 2 successful executions (ignoring failure), got %d", stats.ExecutionCount)
 	}
 }
+
+// ========== Performance Time Series Tests ==========
+
+func TestSeries_SurvivesRecentsEviction(t *testing.T) {
+	h := newEmptyHistory()
+	makefile := "/test/Makefile"
+
+	// 15 runs exceed maxRecentExecutions, but the series keeps all of them
+	for i := 0; i < 15; i++ {
+		h.RecordExecutionWithTiming(makefile, "build", time.Duration(i+1)*time.Second, true)
+	}
+
+	// Push "build" out of the recents list entirely
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		h.RecordExecutionWithTiming(makefile, name, time.Second, true)
+	}
+
+	for _, entry := range h.Entries[makefile] {
+		if entry.Name == "build" {
+			t.Fatal("Expected build to be evicted from recents")
+		}
+	}
+
+	trend := h.GetTrend(makefile, "build")
+	if trend == nil {
+		t.Fatal("Expected trend to survive recents eviction")
+		return
+	}
+	if trend.Runs != 15 {
+		t.Errorf("Expected 15 runs in series, got %d", trend.Runs)
+	}
+}
+
+func TestSeries_UntimedRunsNotRecorded(t *testing.T) {
+	h := newEmptyHistory()
+	h.RecordExecution("/test/Makefile", "build")
+
+	if trend := h.GetTrend("/test/Makefile", "build"); trend != nil {
+		t.Errorf("Expected no trend for untimed runs, got %+v", trend)
+	}
+}
+
+func TestSeries_MaxSamplesRetention(t *testing.T) {
+	h := newEmptyHistory()
+	h.SetConfig(&Config{MaxSamples: 3})
+	makefile := "/test/Makefile"
+
+	for i := 0; i < 5; i++ {
+		h.RecordExecutionWithTiming(makefile, "build", time.Duration(i+1)*time.Second, true)
+	}
+
+	samples := h.Series[makefile]["build"]
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 samples, got %d", len(samples))
+	}
+	if samples[0].Duration != 3*time.Second {
+		t.Errorf("Expected oldest kept sample to be 3s, got %v", samples[0].Duration)
+	}
+}
+
+func TestSeries_RetentionDaysPrunedOnSetConfig(t *testing.T) {
+	makefile := "/test/Makefile"
+	now := time.Now()
+
+	h := newEmptyHistory()
+	h.Series = map[string]map[string][]ExecutionRecord{
+		makefile: {
+			"build": {
+				{Duration: time.Second, Timestamp: now.AddDate(0, 0, -40), Success: true},
+				{Duration: 2 * time.Second, Timestamp: now.AddDate(0, 0, -1), Success: true},
+			},
+			"stale": {
+				{Duration: time.Second, Timestamp: now.AddDate(0, 0, -60), Success: true},
+			},
+		},
+	}
+
+	h.SetConfig(&Config{RetentionDays: 30})
+
+	if got := len(h.Series[makefile]["build"]); got != 1 {
+		t.Errorf("Expected 1 sample within retention, got %d", got)
+	}
+	if _, ok := h.Series[makefile]["stale"]; ok {
+		t.Error("Expected target with only expired samples to be removed")
+	}
+}
+
+func TestGetTrend_PercentilesAndFailureRate(t *testing.T) {
+	h := newEmptyHistory()
+	makefile := "/test/Makefile"
+
+	// 1s..10s successful, plus two failures
+	for i := 1; i <= 10; i++ {
+		h.RecordExecutionWithTiming(makefile, "test", time.Duration(i)*time.Second, true)
+	}
+	h.RecordExecutionWithTiming(makefile, "test", 30*time.Second, false)
+	h.RecordExecutionWithTiming(makefile, "test", 30*time.Second, false)
+
+	trend := h.GetTrend(makefile, "test")
+	if trend == nil {
+		t.Fatal("Expected non-nil trend")
+		return
+	}
+
+	if trend.Runs != 12 || trend.Failures != 2 {
+		t.Errorf("Expected 12 runs and 2 failures, got %d and %d", trend.Runs, trend.Failures)
+	}
+	if trend.FailureRate < 0.166 || trend.FailureRate > 0.167 {
+		t.Errorf("Expected failure rate ~0.167, got %.3f", trend.FailureRate)
+	}
+
+	// Linear interpolation over 1s..10s (failures excluded)
+	if trend.P50 != 5500*time.Millisecond {
+		t.Errorf("Expected p50 5.5s, got %v", trend.P50)
+	}
+	if trend.P90 != 9100*time.Millisecond {
+		t.Errorf("Expected p90 9.1s, got %v", trend.P90)
+	}
+	if trend.P99 != 9910*time.Millisecond {
+		t.Errorf("Expected p99 9.91s, got %v", trend.P99)
+	}
+	if trend.Last != 10*time.Second {
+		t.Errorf("Expected last successful run 10s, got %v", trend.Last)
+	}
+	if len(trend.Recent) != 10 || trend.Recent[0] != time.Second {
+		t.Errorf("Expected 10 sparkline samples starting at 1s, got %v", trend.Recent)
+	}
+	if trend.IsRegressed {
+		t.Error("Expected no regression when the last run failed")
+	}
+}
+
+func TestGetTrends_SlowestFirst(t *testing.T) {
+	h := newEmptyHistory()
+	makefile := "/test/Makefile"
+
+	h.RecordExecutionWithTiming(makefile, "lint", time.Second, true)
+	h.RecordExecutionWithTiming(makefile, "build", 10*time.Second, true)
+	h.RecordExecutionWithTiming(makefile, "test", 5*time.Second, true)
+
+	trends := h.GetTrends(makefile)
+	if len(trends) != 3 {
+		t.Fatalf("Expected 3 trends, got %d", len(trends))
+	}
+
+	expected := []string{"build", "test", "lint"}
+	for i, name := range expected {
+		if trends[i].Name != name {
+			t.Errorf("Expected trend %d to be %s, got %s", i, name, trends[i].Name)
+		}
+	}
+}
+
+func TestRegression_NoisyTargetNotFlagged(t *testing.T) {
+	h := newEmptyHistory()
+	makefile := "/test/Makefile"
+
+	// Highly variable target: a 30% slower run is within its normal spread
+	for _, d := range []time.Duration{2, 4, 2, 4, 2, 4} {
+		h.RecordExecutionWithTiming(makefile, "flaky", d*time.Second, true)
+	}
+	h.RecordExecutionWithTiming(makefile, "flaky", 4*time.Second, true)
+
+	stats := h.GetPerformanceStats(makefile, "flaky")
+	if stats.IsRegressed {
+		t.Error("Expected no regression within the target's normal variance")
+	}
+}
+
+func TestRegression_ConfigurableThreshold(t *testing.T) {
+	makefile := "/test/Makefile"
+
+	record := func(h *History) {
+		for i := 0; i < 5; i++ {
+			h.RecordExecutionWithTiming(makefile, "build", 2*time.Second, true)
+		}
+		h.RecordExecutionWithTiming(makefile, "build", 2500*time.Millisecond, true)
+	}
+
+	// Default threshold (3σ → 2.6s) tolerates 2.5s
+	h := newEmptyHistory()
+	record(h)
+	if h.GetPerformanceStats(makefile, "build").IsRegressed {
+		t.Error("Expected no regression with default threshold")
+	}
+
+	// A stricter threshold (1σ → 2.2s) flags it
+	strict := newEmptyHistory()
+	strict.SetConfig(&Config{RegressionThreshold: 1, MinSamples: 5})
+	record(strict)
+	if !strict.GetPerformanceStats(makefile, "build").IsRegressed {
+		t.Error("Expected regression with strict threshold")
+	}
+
+	// Not enough baseline samples → never flagged
+	sparse := newEmptyHistory()
+	sparse.SetConfig(&Config{RegressionThreshold: 1, MinSamples: 10})
+	record(sparse)
+	if sparse.GetPerformanceStats(makefile, "build").IsRegressed {
+		t.Error("Expected no regression below min_samples")
+	}
+}
+
+func TestSeries_RoundTrip(t *testing.T) {
+	h := newEmptyHistory()
+	h.path = filepath.Join(t.TempDir(), "history.json")
+	h.RecordExecutionWithTiming("/test/Makefile", "build", 2*time.Second, true)
+
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		t.Fatalf("Failed to read saved file: %v", err)
+	}
+
+	var loaded History
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("Failed to unmarshal saved data: %v", err)
+	}
+
+	samples := loaded.Series["/test/Makefile"]["build"]
+	if len(samples) != 1 || samples[0].Duration != 2*time.Second {
+		t.Errorf("Expected 1 sample of 2s after round trip, got %v", samples)
+	}
+}
+
+// ========== History Browser Tests ==========
+
+// newBrowseHistory builds a history with runs across two projects at fixed times
+func newBrowseHistory(now time.Time) *History {
+	h := newEmptyHistory()
+	h.RecordRun("/work/api/Makefile", "build", ExecutionRecord{
+		Duration: 2 * time.Second, Timestamp: now.Add(-48 * time.Hour), Success: true,
+	})
+	h.RecordRun("/work/api/Makefile", "test", ExecutionRecord{
+		Duration: 5 * time.Second, Timestamp: now.Add(-2 * time.Hour), Success: false, ExitCode: 2,
+		Args: []string{"VERBOSE=1"},
+	})
+	h.RecordRun("/work/web/Makefile", "build", ExecutionRecord{
+		Duration: 3 * time.Second, Timestamp: now.Add(-1 * time.Hour), Success: true,
+		ExportPath: "/tmp/exports/build.log",
+	})
+	return h
+}
+
+func TestRecordRun_StoresDetails(t *testing.T) {
+	now := time.Now()
+	h := newBrowseHistory(now)
+
+	runs := h.Runs(Query{Target: "test"})
+	if len(runs) != 1 {
+		t.Fatalf("Expected 1 test run, got %d", len(runs))
+	}
+
+	run := runs[0]
+	if run.ExitCode != 2 {
+		t.Errorf("Expected exit code 2, got %d", run.ExitCode)
+	}
+	if len(run.Args) != 1 || run.Args[0] != "VERBOSE=1" {
+		t.Errorf("Expected args [VERBOSE=1], got %v", run.Args)
+	}
+	if run.MakefilePath != "/work/api/Makefile" {
+		t.Errorf("Expected api Makefile, got %s", run.MakefilePath)
+	}
+
+	// RecordRun also keeps the recents list up to date
+	if len(h.GetRecent("/work/api/Makefile")) != 2 {
+		t.Errorf("Expected 2 recent targets for api")
+	}
+}
+
+func TestRuns_AllProjectsNewestFirst(t *testing.T) {
+	now := time.Now()
+	h := newBrowseHistory(now)
+
+	runs := h.Runs(Query{})
+	if len(runs) != 3 {
+		t.Fatalf("Expected 3 runs, got %d", len(runs))
+	}
+
+	expected := []string{"/work/web/Makefile:build", "/work/api/Makefile:test", "/work/api/Makefile:build"}
+	for i, want := range expected {
+		if got := runs[i].MakefilePath + ":" + runs[i].Target; got != want {
+			t.Errorf("Expected run %d to be %s, got %s", i, want, got)
+		}
+	}
+}
+
+func TestRuns_Filters(t *testing.T) {
+	now := time.Now()
+	h := newBrowseHistory(now)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  int
+	}{
+		{"project", Query{Project: "API"}, 2},
+		{"target", Query{Target: "build"}, 2},
+		{"failed", Query{Status: StatusFailed}, 1},
+		{"success", Query{Status: StatusSuccess}, 2},
+		{"since", Query{Since: now.Add(-3 * time.Hour)}, 2},
+		{"until", Query{Until: now.Add(-3 * time.Hour)}, 1},
+		{"text matches args", Query{Text: "verbose"}, 1},
+		{"combined", Query{Project: "api", Status: StatusSuccess}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(h.Runs(tt.query)); got != tt.want {
+				t.Errorf("Expected %d runs, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	q := ParseQuery("project:api target:test status:failed since:7d until:2026-03-14 flaky", now)
+
+	if q.Project != "api" || q.Target != "test" {
+		t.Errorf("Expected project=api target=test, got %q %q", q.Project, q.Target)
+	}
+	if q.Status != StatusFailed {
+		t.Errorf("Expected status failed, got %v", q.Status)
+	}
+	if want := now.AddDate(0, 0, -7); !q.Since.Equal(want) {
+		t.Errorf("Expected since %v, got %v", want, q.Since)
+	}
+	// until includes the whole day
+	if want := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC); !q.Until.Equal(want) {
+		t.Errorf("Expected until %v, got %v", want, q.Until)
+	}
+	if q.Text != "flaky" {
+		t.Errorf("Expected free text 'flaky', got %q", q.Text)
+	}
+
+	// Unknown keys and invalid bounds fall back gracefully
+	q = ParseQuery("foo:bar since:yesterday", now)
+	if q.Text != "foo:bar" {
+		t.Errorf("Expected unknown token as free text, got %q", q.Text)
+	}
+	if !q.Since.IsZero() {
+		t.Errorf("Expected invalid since to be ignored, got %v", q.Since)
+	}
+}
+
+// openTestSQLite opens a fresh (unshared) database handle, like a separate lazymake process
+func openTestSQLite(t *testing.T, dir, exportDir string) *History {
+	t.Helper()
+
+	dbPath := filepath.Join(dir, databaseFileName)
+	databasesMu.Lock()
+	delete(databases, dbPath)
+	databasesMu.Unlock()
+
+	h, err := openSQLite(dbPath, filepath.Join(dir, historyFileName), exportDir)
+	if err != nil {
+		t.Fatalf("openSQLite failed: %v", err)
+	}
+	t.Cleanup(func() { _ = h.store.db.Close() })
+	return h
+}
+
+func TestSQLite_SaveAndReload(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	h := openTestSQLite(t, dir, "")
+	h.RecordRun("/work/api/Makefile", "build", ExecutionRecord{
+		Duration: 2 * time.Second, Timestamp: now.Add(-time.Hour), Success: true, Output: "ok\n",
+	})
+	h.RecordRun("/work/api/Makefile", "test", ExecutionRecord{
+		Duration: 5 * time.Second, Timestamp: now, Success: false, ExitCode: 2,
+		Args: []string{"VERBOSE=1"}, Output: "FAIL\n",
+		Confirmation: &export.Confirmation{DangerLevel: "critical", Rules: []string{"rm-rf-root"}, Reason: "wipe stale fixtures"},
+	})
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reloaded := openTestSQLite(t, dir, "")
+
+	recent := reloaded.GetRecent("/work/api/Makefile")
+	if len(recent) != 2 || recent[0].Name != "test" {
+		t.Fatalf("Expected recents [test build], got %+v", recent)
+	}
+	if len(recent[0].RecentExecutions) != 1 || recent[0].RecentExecutions[0].ExitCode != 2 {
+		t.Errorf("Expected recent execution with exit code 2, got %+v", recent[0].RecentExecutions)
+	}
+
+	if trend := reloaded.GetTrend("/work/api/Makefile", "build"); trend == nil || trend.Runs != 1 {
+		t.Errorf("Expected build trend with 1 run, got %+v", trend)
+	}
+
+	runs := reloaded.Runs(Query{Status: StatusFailed})
+	if len(runs) != 1 {
+		t.Fatalf("Expected 1 failed run, got %d", len(runs))
+	}
+	if len(runs[0].Args) != 1 || runs[0].Args[0] != "VERBOSE=1" {
+		t.Errorf("Expected args [VERBOSE=1], got %v", runs[0].Args)
+	}
+	if c := runs[0].Confirmation; c == nil || c.Reason != "wipe stale fixtures" || len(c.Rules) != 1 {
+		t.Errorf("Expected the confirmation to be stored, got %+v", c)
+	}
+	if c := recent[0].RecentExecutions[0].Confirmation; c == nil || c.DangerLevel != "critical" {
+		t.Errorf("Expected the confirmation to be loaded with the series, got %+v", c)
+	}
+
+	output, err := reloaded.RunOutput(runs[0])
+	if err != nil || output != "FAIL\n" {
+		t.Errorf("Expected stored output %q, got %q (err %v)", "FAIL\n", output, err)
+	}
+}
+
+func TestSQLite_UpgradesSchema(t *testing.T) {
+	dir := t.TempDir()
+
+	// A version 1 database, created before runs had a confirmation column
+	db, err := sql.Open("sqlite", filepath.Join(dir, databaseFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT, makefile_path TEXT NOT NULL, target TEXT NOT NULL,
+			timestamp INTEGER NOT NULL, duration INTEGER NOT NULL, success INTEGER NOT NULL,
+			exit_code INTEGER NOT NULL DEFAULT 0, args TEXT NOT NULL DEFAULT '[]',
+			export_path TEXT NOT NULL DEFAULT '', output TEXT
+		);
+		INSERT INTO runs (makefile_path, target, timestamp, duration, success) VALUES ('/work/Makefile', 'build', 1, 1000, 1);
+		CREATE TABLE meta (key TEXT PRIMARY KEY, value TEXT NOT NULL);
+		INSERT INTO meta (key, value) VALUES ('schema_version', '1'), ('migrated_at', '2025-01-01T00:00:00Z');`); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	h := openTestSQLite(t, dir, "")
+	var version string
+	if err := h.store.db.QueryRow(`SELECT value FROM meta WHERE key = 'schema_version'`).Scan(&version); err != nil || version != schemaVersion {
+		t.Errorf("Expected schema version %s, got %q (err %v)", schemaVersion, version, err)
+	}
+	if runs := h.Runs(Query{}); len(runs) != 1 || runs[0].Confirmation != nil {
+		t.Fatalf("Expected the existing run without confirmation, got %+v", runs)
+	}
+
+	h.RecordRun("/work/Makefile", "deploy", ExecutionRecord{
+		Duration: time.Second, Timestamp: time.Now(), Success: true,
+		Confirmation: &export.Confirmation{DangerLevel: "critical", Typed: "deploy"},
+	})
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	runs := openTestSQLite(t, dir, "").Runs(Query{Target: "deploy"})
+	if len(runs) != 1 || runs[0].Confirmation == nil || runs[0].Confirmation.Typed != "deploy" {
+		t.Errorf("Expected the confirmation to be stored after the upgrade, got %+v", runs)
+	}
+}
+
+func TestSQLite_ConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	openTestSQLite(t, dir, "") // Create the schema up front
+
+	const writers = 4
+	const runsPerWriter = 10
+
+	instances := make([]*History, writers)
+	for i := range instances {
+		instances[i] = openTestSQLite(t, dir, "")
+	}
+
+	errs := make(chan error, writers*runsPerWriter)
+	done := make(chan struct{})
+	for i, h := range instances {
+		go func(i int, h *History) {
+			defer func() { done <- struct{}{} }()
+			for j := 0; j < runsPerWriter; j++ {
+				h.RecordRun("/work/api/Makefile", "build", ExecutionRecord{
+					Duration: time.Duration(i*100+j+1) * time.Millisecond, Success: true,
+				})
+				errs <- h.Save()
+			}
+		}(i, h)
+	}
+	for range instances {
+		<-done
+	}
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent save failed: %v", err)
+		}
+	}
+
+	reloaded := openTestSQLite(t, dir, "")
+	if runs := reloaded.Runs(Query{}); len(runs) != writers*runsPerWriter {
+		t.Errorf("Expected %d runs from all writers, got %d", writers*runsPerWriter, len(runs))
+	}
+	if recent := reloaded.GetRecent("/work/api/Makefile"); len(recent) != 1 || recent[0].UseCount != writers*runsPerWriter {
+		t.Errorf("Expected use count %d, got %+v", writers*runsPerWriter, recent)
+	}
+}
+
+func TestSQLite_Retention(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	h := openTestSQLite(t, dir, "")
+	h.SetConfig(&Config{RetentionDays: 30, MaxSamples: 3})
+
+	h.RecordRun("/work/api/Makefile", "build", ExecutionRecord{
+		Duration: time.Second, Timestamp: now.AddDate(0, 0, -60), Success: true,
+	})
+	for i := 0; i < 5; i++ {
+		h.RecordRun("/work/api/Makefile", "build", ExecutionRecord{
+			Duration: time.Second, Timestamp: now.Add(time.Duration(i-5) * time.Minute), Success: true,
+		})
+	}
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	runs := h.Runs(Query{})
+	if len(runs) != 3 {
+		t.Fatalf("Expected 3 runs after retention, got %d", len(runs))
+	}
+	if !runs[2].Timestamp.After(now.Add(-4 * time.Minute)) {
+		t.Errorf("Expected the newest runs to be kept, oldest kept is %v", runs[2].Timestamp)
+	}
+}
+
+func TestSQLite_FilterValid(t *testing.T) {
+	dir := t.TempDir()
+
+	h := openTestSQLite(t, dir, "")
+	h.RecordExecution("/work/api/Makefile", "build")
+	h.RecordExecution("/work/api/Makefile", "removed")
+	h.FilterValid("/work/api/Makefile", []string{"build"})
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	recent := openTestSQLite(t, dir, "").GetRecent("/work/api/Makefile")
+	if len(recent) != 1 || recent[0].Name != "build" {
+		t.Errorf("Expected only build in recents, got %+v", recent)
+	}
+}
+
+func TestSQLite_FailedWriteDoesNotBlockLaterSaves(t *testing.T) {
+	dir := t.TempDir()
+
+	h := openTestSQLite(t, dir, "")
+	h.RecordExecution("/work/api/Makefile", "build")
+	h.store.queue(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO missing_table VALUES (1)`)
+		return err
+	})
+	h.RecordExecution("/work/api/Makefile", "test")
+
+	if err := h.Save(); err == nil {
+		t.Fatal("Expected the failing write to be reported")
+	}
+	if err := h.Save(); err != nil {
+		t.Fatalf("Expected the next save to succeed without the failed write, got %v", err)
+	}
+
+	recent := openTestSQLite(t, dir, "").GetRecent("/work/api/Makefile")
+	if len(recent) != 2 {
+		t.Errorf("Expected the other queued writes to be saved, got %+v", recent)
+	}
+}
+
+func TestSQLite_CloseKeepsSharedDatabaseOpen(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, databaseFileName)
+	jsonPath := filepath.Join(dir, historyFileName)
+
+	// Like the TUI's history and the tracker's in one process
+	first, err := openSQLite(dbPath, jsonPath, "")
+	if err != nil {
+		t.Fatalf("openSQLite failed: %v", err)
+	}
+	second, err := openSQLite(dbPath, jsonPath, "")
+	if err != nil {
+		t.Fatalf("openSQLite failed: %v", err)
+	}
+	if first.store.db != second.store.db {
+		t.Fatal("Expected both histories to share the connection pool")
+	}
+
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Second Close failed: %v", err)
+	}
+	second.RecordExecution("/work/api/Makefile", "build")
+	if err := second.Save(); err != nil {
+		t.Fatalf("Expected the other history to keep writing, got %v", err)
+	}
+
+	if err := second.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	databasesMu.Lock()
+	_, open := databases[dbPath]
+	databasesMu.Unlock()
+	if open {
+		t.Error("Expected the pool to be closed after its last user")
+	}
+	if err := second.store.db.Ping(); err == nil {
+		t.Error("Expected the connection pool to be closed")
+	}
+}
+
+func TestSQLite_BusyWriteIsRetried(t *testing.T) {
+	dir := t.TempDir()
+	busy := lockedDatabaseError(t)
+	if !isTransient(busy) {
+		t.Fatalf("Expected %v to be transient", busy)
+	}
+
+	h := openTestSQLite(t, dir, "")
+	attempts := 0
+	h.store.queue(func(tx *sql.Tx) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("failed to record run: %w", busy)
+		}
+		return upsertRecent(tx, "/work/api/Makefile", "build", time.Now(), 1)
+	})
+
+	if err := h.Save(); err == nil {
+		t.Fatal("Expected the busy write to be reported")
+	}
+	if err := h.Save(); err != nil {
+		t.Fatalf("Expected the retried write to succeed, got %v", err)
+	}
+
+	recent := openTestSQLite(t, dir, "").GetRecent("/work/api/Makefile")
+	if attempts != 2 || len(recent) != 1 {
+		t.Errorf("Expected the busy write to be retried and saved, got %d attempts and %+v", attempts, recent)
+	}
+}
+
+// lockedDatabaseError returns the error SQLite reports for a write to a database another connection is writing to
+func lockedDatabaseError(t *testing.T) error {
+	t.Helper()
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "locked.db") + "?_txlock=immediate"
+	holder, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = holder.Close() }()
+	tx, err := holder.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	other, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = other.Close() }()
+	if _, err := other.Begin(); err != nil {
+		return err
+	}
+	t.Fatal("Expected the second writer to be locked out")
+	return nil
+}
+
+func TestSQLite_MigratesJSONAndExports(t *testing.T) {
+	dir := t.TempDir()
+	exportDir := filepath.Join(dir, "exports")
+	now := time.Now().Truncate(time.Second)
+
+	// Existing JSON history with one run that was exported and one that wasn't
+	legacy := newEmptyHistory()
+	legacy.path = filepath.Join(dir, historyFileName)
+	legacy.RecordRun("/work/api/Makefile", "build", ExecutionRecord{
+		Duration: 2 * time.Second, Timestamp: now.Add(-time.Hour), Success: true,
+	})
+	legacy.RecordRun("/work/api/Makefile", "test", ExecutionRecord{
+		Duration: 3 * time.Second, Timestamp: now, Success: true,
+	})
+	if err := legacy.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Export of the test run (timestamp taken a moment earlier) and of a run no longer in history
+	if err := export.WriteJSON(&export.ExecutionRecord{
+		Timestamp: now.Add(-200 * time.Millisecond), MakefilePath: "/work/api/Makefile", TargetName: "test",
+		Duration: 3 * time.Second, Success: true, Output: "PASS\n",
+	}, filepath.Join(exportDir, "test_1.json")); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if err := export.WriteJSON(&export.ExecutionRecord{
+		Timestamp: now.AddDate(0, 0, -1), MakefilePath: "/work/api/Makefile", TargetName: "lint",
+		Duration: time.Second, Success: false, ExitCode: 1, Output: "lint error\n",
+	}, filepath.Join(exportDir, "lint_1.json")); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	h := openTestSQLite(t, dir, exportDir)
+
+	runs := h.Runs(Query{})
+	if len(runs) != 3 {
+		t.Fatalf("Expected 3 migrated runs, got %d", len(runs))
+	}
+	if recent := h.GetRecent("/work/api/Makefile"); len(recent) != 2 {
+		t.Errorf("Expected 2 migrated recents, got %d", len(recent))
+	}
+
+	outputs := make(map[string]string)
+	for _, run := range runs {
+		if output, err := h.RunOutput(run); err == nil {
+			outputs[run.Target] = output
+		}
+	}
+	if outputs["test"] != "PASS\n" {
+		t.Errorf("Expected export output attached to the test run, got %q", outputs["test"])
+	}
+	if outputs["lint"] != "lint error\n" {
+		t.Errorf("Expected export-only run imported with output, got %q", outputs["lint"])
+	}
+	if _, ok := outputs["build"]; ok {
+		t.Error("Expected no output for the unexported build run")
+	}
+
+	// Migration runs once
+	if runs := openTestSQLite(t, dir, exportDir).Runs(Query{}); len(runs) != 3 {
+		t.Errorf("Expected migration not to repeat, got %d runs", len(runs))
+	}
+}
+
+// historyHelperEnv makes the test binary act as a separate lazymake process
+const historyHelperEnv = "LAZYMAKE_HISTORY_HELPER_PATH"
+
+func TestSave_MultipleProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFileName)
+
+	const processes = 4
+	const runsPerProcess = 10
+
+	cmds := make([]*exec.Cmd, processes)
+	for i := range cmds {
+		cmds[i] = exec.Command(os.Args[0], "-test.run=^TestSave_HelperProcess$")
+		cmds[i].Env = append(os.Environ(), historyHelperEnv+"="+path, fmt.Sprintf("LAZYMAKE_HELPER_ID=%d", i))
+		if err := cmds[i].Start(); err != nil {
+			t.Fatalf("Failed to start helper process: %v", err)
+		}
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("Helper process failed: %v", err)
+		}
+	}
+
+	h, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+
+	samples := h.Series["/work/api/Makefile"]["build"]
+	if len(samples) != processes*runsPerProcess {
+		t.Errorf("Expected %d runs from all processes, got %d", processes*runsPerProcess, len(samples))
+	}
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Timestamp.Before(samples[i-1].Timestamp) {
+			t.Fatalf("Expected series ordered by time, sample %d is older than %d", i, i-1)
+		}
+	}
+
+	recent := h.GetRecent("/work/api/Makefile")
+	if len(recent) != processes+1 {
+		t.Fatalf("Expected shared target plus one per process in recents, got %d", len(recent))
+	}
+	for _, entry := range recent {
+		want := runsPerProcess
+		if entry.Name == "build" {
+			want = processes * runsPerProcess
+		}
+		if entry.UseCount != want {
+			t.Errorf("Expected use count %d for %s, got %d", want, entry.Name, entry.UseCount)
+		}
+	}
+}
+
+// TestSave_HelperProcess is run as a child process by TestSave_MultipleProcesses
+func TestSave_HelperProcess(t *testing.T) {
+	path := os.Getenv(historyHelperEnv)
+	if path == "" {
+		t.Skip("helper process for TestSave_MultipleProcesses")
+	}
+
+	h, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+
+	own := "process-" + os.Getenv("LAZYMAKE_HELPER_ID")
+	for i := 0; i < 10; i++ {
+		h.RecordRun("/work/api/Makefile", "build", ExecutionRecord{Duration: time.Second, Success: true})
+		h.RecordExecution("/work/api/Makefile", own)
+		if err := h.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+}
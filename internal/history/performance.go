@@ -0,0 +1,278 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// sparklineSamples is how many of the most recent successful runs feed a trend sparkline
+	sparklineSamples = 20
+
+	// minRelativeSpread is the noise floor for regression detection, as a fraction of the mean
+	//
+	// Perfectly stable targets have a standard deviation of ~0, which would flag
+	// every run that is a few milliseconds slower. Treating the spread as at least
+	// 10% of the mean keeps the threshold meaningful (3σ ≈ 30% slower).
+	minRelativeSpread = 0.10
+)
+
+// Config controls the long-lived performance time series
+type Config struct {
+	// Retention (0 = unlimited)
+	RetentionDays int `yaml:"retention_days"` // Drop samples older than this
+	MaxSamples    int `yaml:"max_samples"`    // Keep at most this many samples per target
+
+	// Regression detection: flag a run slower than mean + RegressionThreshold × stddev
+	// of the earlier successful runs, once at least MinSamples of them exist
+	RegressionThreshold float64 `yaml:"regression_threshold"`
+	MinSamples          int     `yaml:"min_samples"`
+}
+
+// Defaults returns a Config with sensible default values
+func Defaults() *Config {
+	return &Config{
+		RetentionDays:       90,
+		MaxSamples:          500,
+		RegressionThreshold: 3.0,
+		MinSamples:          5,
+	}
+}
+
+// TargetTrend summarizes the performance time series of a single target
+type TargetTrend struct {
+	Name string
+
+	Runs        int     // All recorded runs (successful and failed)
+	Failures    int     // Failed runs
+	FailureRate float64 // Failures / Runs (0..1)
+
+	// Distribution of successful run durations
+	P50    time.Duration
+	P90    time.Duration
+	P99    time.Duration
+	Mean   time.Duration
+	StdDev time.Duration
+	Last   time.Duration // Most recent successful run
+
+	// Most recent successful durations, oldest first (for sparklines)
+	Recent []time.Duration
+
+	FirstRun    time.Time
+	LastRun     time.Time
+	IsRegressed bool
+}
+
+// SetConfig applies performance settings and prunes series that exceed the new retention
+// A nil config restores the defaults.
+func (h *History) SetConfig(cfg *Config) {
+	h.config = cfg
+
+	now := time.Now()
+	for makefilePath, targets := range h.Series {
+		for name, samples := range targets {
+			pruned := pruneSamples(samples, now, h.settings())
+			if len(pruned) == 0 {
+				delete(targets, name)
+				continue
+			}
+			targets[name] = pruned
+		}
+		if len(targets) == 0 {
+			delete(h.Series, makefilePath)
+		}
+	}
+}
+
+// GetTrend returns the time series summary for a target
+// Returns nil if the target has no recorded runs
+func (h *History) GetTrend(makefilePath, targetName string) *TargetTrend {
+	samples := h.Series[makefilePath][targetName]
+	if len(samples) == 0 {
+		return nil
+	}
+
+	trend := &TargetTrend{
+		Name:     targetName,
+		Runs:     len(samples),
+		FirstRun: samples[0].Timestamp,
+		LastRun:  samples[len(samples)-1].Timestamp,
+	}
+
+	var durations []time.Duration
+	for _, sample := range samples {
+		if !sample.Success {
+			trend.Failures++
+			continue
+		}
+		durations = append(durations, sample.Duration)
+	}
+	trend.FailureRate = float64(trend.Failures) / float64(trend.Runs)
+
+	if len(durations) == 0 {
+		return trend
+	}
+
+	trend.Last = durations[len(durations)-1]
+	trend.Recent = append([]time.Duration(nil), durations[max(len(durations)-sparklineSamples, 0):]...)
+	trend.Mean, trend.StdDev = meanStdDev(durations)
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	trend.P50 = percentile(sorted, 50)
+	trend.P90 = percentile(sorted, 90)
+	trend.P99 = percentile(sorted, 99)
+
+	// Regression is judged on the latest run only, against everything before it
+	if samples[len(samples)-1].Success {
+		trend.IsRegressed = h.isRegression(durations[:len(durations)-1], trend.Last)
+	}
+
+	return trend
+}
+
+// GetTrends returns the trends of every recorded target in a Makefile
+// Sorted slowest first (by p50, then name) so the dashboard leads with the worst offenders
+func (h *History) GetTrends(makefilePath string) []TargetTrend {
+	targets := h.Series[makefilePath]
+	if len(targets) == 0 {
+		return nil
+	}
+
+	trends := make([]TargetTrend, 0, len(targets))
+	for name := range targets {
+		if trend := h.GetTrend(makefilePath, name); trend != nil {
+			trends = append(trends, *trend)
+		}
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].P50 != trends[j].P50 {
+			return trends[i].P50 > trends[j].P50
+		}
+		return trends[i].Name < trends[j].Name
+	})
+
+	return trends
+}
+
+// recordSample appends a run to the target's time series and applies retention
+func (h *History) recordSample(makefilePath, targetName string, record ExecutionRecord) {
+	if h.Series == nil {
+		h.Series = make(map[string]map[string][]ExecutionRecord)
+	}
+	if h.Series[makefilePath] == nil {
+		h.Series[makefilePath] = make(map[string][]ExecutionRecord)
+	}
+
+	samples := insertByTime(h.Series[makefilePath][targetName], record)
+	h.Series[makefilePath][targetName] = pruneSamples(samples, record.Timestamp, h.settings())
+}
+
+// successfulDurations returns the durations of successful runs in the target's series
+// Falls back to the recent executions list for histories recorded before series existed.
+func (h *History) successfulDurations(makefilePath string, entry *Entry) []time.Duration {
+	samples := h.Series[makefilePath][entry.Name]
+	if len(samples) == 0 {
+		samples = entry.RecentExecutions
+	}
+
+	var durations []time.Duration
+	for _, sample := range samples {
+		if sample.Success {
+			durations = append(durations, sample.Duration)
+		}
+	}
+	return durations
+}
+
+// isRegression reports whether last is statistically slower than the baseline runs
+//
+// Example with the defaults (threshold 3σ, at least 5 baseline runs):
+//
+//	baseline: 2.0s 2.1s 1.9s 2.0s 2.0s  → mean 2.0s, σ floored to 0.2s
+//	limit:    2.0s + 3 × 0.2s = 2.6s    → a 4s run is a regression, 2.4s is not
+func (h *History) isRegression(baseline []time.Duration, last time.Duration) bool {
+	cfg := h.settings()
+	if len(baseline) < max(cfg.MinSamples, 1) {
+		return false
+	}
+
+	mean, stddev := meanStdDev(baseline)
+	if mean <= 0 {
+		return false
+	}
+
+	spread := math.Max(float64(stddev), float64(mean)*minRelativeSpread)
+	limit := float64(mean) + cfg.RegressionThreshold*spread
+
+	return float64(last) > limit
+}
+
+// settings returns the active performance config (defaults if none was set)
+func (h *History) settings() *Config {
+	if h.config == nil {
+		return Defaults()
+	}
+	return h.config
+}
+
+// pruneSamples drops samples outside the retention window and caps the series length
+func pruneSamples(samples []ExecutionRecord, now time.Time, cfg *Config) []ExecutionRecord {
+	if cfg.RetentionDays > 0 {
+		cutoff := now.AddDate(0, 0, -cfg.RetentionDays)
+		first := sort.Search(len(samples), func(i int) bool {
+			return !samples[i].Timestamp.Before(cutoff)
+		})
+		samples = samples[first:]
+	}
+
+	if cfg.MaxSamples > 0 && len(samples) > cfg.MaxSamples {
+		samples = samples[len(samples)-cfg.MaxSamples:]
+	}
+
+	return samples
+}
+
+// meanStdDev returns the mean and population standard deviation of durations
+func meanStdDev(durations []time.Duration) (time.Duration, time.Duration) {
+	if len(durations) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, d := range durations {
+		sum += float64(d)
+	}
+	mean := sum / float64(len(durations))
+
+	var variance float64
+	for _, d := range durations {
+		diff := float64(d) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(durations))
+
+	return time.Duration(mean), time.Duration(math.Sqrt(variance))
+}
+
+// percentile returns the p-th percentile of sorted durations using linear interpolation
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+
+	fraction := rank - float64(lower)
+	return sorted[lower] + time.Duration(math.Round(fraction*float64(sorted[upper]-sorted[lower])))
+}
@@ -0,0 +1,723 @@
+package history
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rshelekhov/lazymake/internal/export"
+
+	"modernc.org/sqlite" // Pure-Go SQLite driver (no cgo)
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	// schemaVersion is stored in the meta table for future migrations
+	schemaVersion = "2"
+
+	// busyTimeout is how long a writer waits for another process's transaction
+	busyTimeout = 5 * time.Second
+
+	// migrationWindow matches an exported run to its history record
+	// Export and history timestamps are taken moments apart for the same run.
+	migrationWindow = 5 * time.Second
+)
+
+// ErrNoOutput is returned when no output was captured for a run
+var ErrNoOutput = errors.New("no output stored for this run")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS runs (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	makefile_path TEXT    NOT NULL,
+	target        TEXT    NOT NULL,
+	timestamp     INTEGER NOT NULL, -- Unix nanoseconds
+	duration      INTEGER NOT NULL, -- Nanoseconds
+	success       INTEGER NOT NULL,
+	exit_code     INTEGER NOT NULL DEFAULT 0,
+	args          TEXT    NOT NULL DEFAULT '[]', -- JSON array
+	export_path   TEXT    NOT NULL DEFAULT '',
+	output        TEXT,
+	confirmation  TEXT -- JSON object, NULL if the run wasn't confirmed
+);
+
+CREATE INDEX IF NOT EXISTS runs_target_time ON runs (makefile_path, target, timestamp);
+CREATE INDEX IF NOT EXISTS runs_time ON runs (timestamp);
+
+CREATE TABLE IF NOT EXISTS recents (
+	makefile_path TEXT    NOT NULL,
+	target        TEXT    NOT NULL,
+	last_used     INTEGER NOT NULL, -- Unix nanoseconds
+	use_count     INTEGER NOT NULL,
+	PRIMARY KEY (makefile_path, target)
+);
+`
+
+var (
+	// databases shares one connection pool per file across workspace switches
+	// and between the histories open in one process (TUI and tracker)
+	databases   = make(map[string]*sharedDatabase)
+	databasesMu sync.Mutex
+)
+
+// sharedDatabase is a connection pool with the number of open stores using it
+type sharedDatabase struct {
+	db    *sql.DB
+	users int
+}
+
+// sqliteStore persists history in an embedded SQLite database
+// Changes are queued by RecordRun/FilterValid and written by Save in a single
+// transaction. Each process only inserts its own runs, so concurrent instances
+// never overwrite each other.
+type sqliteStore struct {
+	db     *sql.DB
+	path   string // Database file, the key of the shared pool
+	closed bool
+
+	mu      sync.Mutex
+	pending []func(tx *sql.Tx) error
+}
+
+// openSQLite opens (or creates) the database and loads it into a History
+// The first time a database is created, history.json and exported runs are imported.
+func openSQLite(dbPath, jsonPath, exportDir string) (*History, error) {
+	db, err := openDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &sqliteStore{db: db, path: dbPath}
+	if err := store.migrate(jsonPath, exportDir); err != nil {
+		_ = store.close()
+		return nil, err
+	}
+
+	h := newEmptyHistory()
+	h.path = jsonPath
+	h.store = store
+	if err := store.load(h); err != nil {
+		_ = store.close()
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// openDatabase returns the shared connection pool for a database file
+// Every call must be paired with a close of the store using it.
+func openDatabase(path string) (*sql.DB, error) {
+	databasesMu.Lock()
+	defer databasesMu.Unlock()
+
+	if shared, ok := databases[path]; ok {
+		shared.users++
+		return shared.db, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// WAL lets readers run alongside a writer; immediate transactions take the
+	// write lock up front so concurrent writers queue on busy_timeout instead of failing
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate",
+		path, busyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create history schema: %w", err)
+	}
+
+	if err := upgradeSchema(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	databases[path] = &sharedDatabase{db: db, users: 1}
+	return db, nil
+}
+
+// upgradeSchema adds columns introduced after a database was created
+// Version 2 added runs.confirmation. Runs in an immediate transaction, so
+// instances starting at the same time don't both alter the table.
+func upgradeSchema(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start schema upgrade: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var columns int
+	if err := tx.QueryRow(`SELECT count(*) FROM pragma_table_info('runs') WHERE name = 'confirmation'`).Scan(&columns); err != nil {
+		return fmt.Errorf("failed to read history schema: %w", err)
+	}
+	if columns > 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE runs ADD COLUMN confirmation TEXT`); err != nil {
+		return fmt.Errorf("failed to upgrade history schema: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO meta (key, value) VALUES ('schema_version', ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, schemaVersion); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Close releases the database connection (no-op for the JSON backend)
+// The connection pool is closed once no other history in the process uses it.
+func (h *History) Close() error {
+	if h.store == nil {
+		return nil
+	}
+	return h.store.close()
+}
+
+// close releases the store's use of the shared connection pool, closing it
+// after the last user
+func (s *sqliteStore) close() error {
+	databasesMu.Lock()
+	defer databasesMu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	// A pool no longer in the map was replaced (tests open fresh handles)
+	shared, ok := databases[s.path]
+	if !ok || shared.db != s.db {
+		return s.db.Close()
+	}
+	if shared.users--; shared.users > 0 {
+		return nil
+	}
+	delete(databases, s.path)
+	return s.db.Close()
+}
+
+// migrate records the schema version and performs the one-time import
+// Runs in one immediate transaction, so only the first of several starting
+// instances imports the data.
+func (s *sqliteStore) migrate(jsonPath, exportDir string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start migration: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var migrated string
+	err = tx.QueryRow(`SELECT value FROM meta WHERE key = 'migrated_at'`).Scan(&migrated)
+	if err == nil {
+		return nil // Already migrated
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read migration state: %w", err)
+	}
+
+	if err := importJSON(tx, jsonPath); err != nil {
+		return err
+	}
+	if exportDir != "" {
+		if err := importExports(tx, exportDir); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO meta (key, value) VALUES ('schema_version', ?), ('migrated_at', ?)`,
+		schemaVersion, time.Now().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// importJSON copies recents and runs from an existing history.json
+// A missing or corrupt file imports nothing (the JSON file is left untouched).
+func importJSON(tx *sql.Tx, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var legacy History
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil
+	}
+
+	for makefilePath, entries := range legacy.Entries {
+		for _, entry := range entries {
+			if err := upsertRecent(tx, makefilePath, entry.Name, entry.LastUsed, entry.UseCount); err != nil {
+				return err
+			}
+
+			// Histories recorded before series existed only have recent executions
+			if len(legacy.Series[makefilePath][entry.Name]) == 0 {
+				for _, record := range entry.RecentExecutions {
+					if err := insertRun(tx, makefilePath, entry.Name, record); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	for makefilePath, targets := range legacy.Series {
+		for name, samples := range targets {
+			for _, record := range samples {
+				if err := insertRun(tx, makefilePath, name, record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// importExports attaches the output of exported JSON records to their runs
+// Exports without a matching run (e.g. evicted from history) are imported as runs.
+func importExports(tx *sql.Tx, dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var record export.ExecutionRecord
+		if err := json.Unmarshal(data, &record); err != nil || record.MakefilePath == "" || record.TargetName == "" {
+			continue // Not an export record
+		}
+
+		timestamp := record.Timestamp.UnixNano()
+		result, err := tx.Exec(`
+			UPDATE runs SET output = ?, export_path = ?
+			WHERE id = (
+				SELECT id FROM runs
+				WHERE makefile_path = ? AND target = ? AND output IS NULL
+				  AND timestamp BETWEEN ? AND ?
+				ORDER BY abs(timestamp - ?)
+				LIMIT 1
+			)`,
+			record.Output, path, record.MakefilePath, record.TargetName,
+			timestamp-int64(migrationWindow), timestamp+int64(migrationWindow), timestamp)
+		if err != nil {
+			return fmt.Errorf("failed to import export %s: %w", path, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			continue
+		}
+
+		if err := insertRun(tx, record.MakefilePath, record.TargetName, ExecutionRecord{
+			Duration:   record.Duration,
+			Timestamp:  record.Timestamp,
+			Success:    record.Success,
+			ExitCode:   record.ExitCode,
+			Args:       record.Args,
+			ExportPath: path,
+			Output:     record.Output,
+
+			Confirmation: record.Confirmation,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// load reads recents and the performance series into the in-memory history
+func (s *sqliteStore) load(h *History) error {
+	rows, err := s.db.Query(`
+		SELECT makefile_path, target, timestamp, duration, success, exit_code, args, export_path, confirmation
+		FROM runs
+		ORDER BY makefile_path, target, timestamp, id`)
+	if err != nil {
+		return fmt.Errorf("failed to load runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	h.Series = make(map[string]map[string][]ExecutionRecord)
+	for rows.Next() {
+		var makefilePath, target string
+		record, err := scanRecord(rows, &makefilePath, &target)
+		if err != nil {
+			return err
+		}
+
+		if h.Series[makefilePath] == nil {
+			h.Series[makefilePath] = make(map[string][]ExecutionRecord)
+		}
+		h.Series[makefilePath][target] = append(h.Series[makefilePath][target], record)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load runs: %w", err)
+	}
+
+	recents, err := s.db.Query(`
+		SELECT makefile_path, target, last_used, use_count
+		FROM recents
+		ORDER BY makefile_path, last_used DESC`)
+	if err != nil {
+		return fmt.Errorf("failed to load recent targets: %w", err)
+	}
+	defer func() { _ = recents.Close() }()
+
+	for recents.Next() {
+		var makefilePath string
+		var lastUsed int64
+		var entry Entry
+		if err := recents.Scan(&makefilePath, &entry.Name, &lastUsed, &entry.UseCount); err != nil {
+			return fmt.Errorf("failed to load recent targets: %w", err)
+		}
+		entry.LastUsed = time.Unix(0, lastUsed)
+
+		// Recent executions are the tail of the series
+		samples := h.Series[makefilePath][entry.Name]
+		if len(samples) > 0 {
+			entry.RecentExecutions = append([]ExecutionRecord(nil), samples[max(len(samples)-maxRecentExecutions, 0):]...)
+		}
+
+		h.Entries[makefilePath] = append(h.Entries[makefilePath], entry)
+	}
+
+	return recents.Err()
+}
+
+// queueRun schedules a run and its recents update for the next Save
+func (s *sqliteStore) queueRun(makefilePath, targetName string, record ExecutionRecord, now time.Time) {
+	s.queue(func(tx *sql.Tx) error {
+		if record.Duration > 0 {
+			if err := insertRun(tx, makefilePath, targetName, record); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO recents (makefile_path, target, last_used, use_count) VALUES (?, ?, ?, 1)
+			ON CONFLICT (makefile_path, target) DO UPDATE SET
+				last_used = max(last_used, excluded.last_used),
+				use_count = use_count + 1`,
+			makefilePath, targetName, now.UnixNano()); err != nil {
+			return fmt.Errorf("failed to record recent target: %w", err)
+		}
+
+		// LRU eviction across every instance's recents
+		if _, err := tx.Exec(`
+			DELETE FROM recents
+			WHERE makefile_path = ? AND target NOT IN (
+				SELECT target FROM recents WHERE makefile_path = ?
+				ORDER BY last_used DESC LIMIT ?
+			)`,
+			makefilePath, makefilePath, maxRecentTargets); err != nil {
+			return fmt.Errorf("failed to evict recent targets: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// queueFilterValid schedules removal of recents for targets that no longer exist
+func (s *sqliteStore) queueFilterValid(makefilePath string, validTargets []string) {
+	s.queue(func(tx *sql.Tx) error {
+		valid, err := json.Marshal(validTargets)
+		if err != nil {
+			return fmt.Errorf("failed to encode targets: %w", err)
+		}
+
+		if _, err := tx.Exec(`
+			DELETE FROM recents
+			WHERE makefile_path = ? AND target NOT IN (SELECT value FROM json_each(?))`,
+			makefilePath, string(valid)); err != nil {
+			return fmt.Errorf("failed to filter recent targets: %w", err)
+		}
+		return nil
+	})
+}
+
+// queue adds a write to the next Save
+func (s *sqliteStore) queue(op func(tx *sql.Tx) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, op)
+}
+
+// flush writes queued changes and applies retention in one transaction
+// A change that fails for good (a constraint or encoding error) is dropped, so
+// it can't keep later saves from writing. One that failed because the database
+// was busy or the disk full is kept and retried with the others by the next save.
+func (s *sqliteStore) flush(cfg *Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, op := range s.pending {
+		if err := op(tx); err != nil {
+			if !isTransient(err) {
+				s.pending = slices.Delete(s.pending, i, i+1)
+			}
+			return err
+		}
+	}
+
+	if err := applyRetention(tx, cfg, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+
+	s.pending = nil
+	return nil
+}
+
+// isTransient reports whether a failed write may succeed when retried: the
+// database was locked, memory or disk ran out, or the file couldn't be read or written
+func isTransient(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code() & 0xff { // Primary result code, without the extended bits
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_NOMEM, sqlite3.SQLITE_IOERR,
+		sqlite3.SQLITE_FULL, sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_PROTOCOL, sqlite3.SQLITE_INTERRUPT,
+		sqlite3.SQLITE_READONLY:
+		return true
+	}
+	return false
+}
+
+// applyRetention drops runs (and their output) outside the retention window
+// and caps every target's series at MaxSamples
+func applyRetention(tx *sql.Tx, cfg *Config, now time.Time) error {
+	if cfg.RetentionDays > 0 {
+		cutoff := now.AddDate(0, 0, -cfg.RetentionDays).UnixNano()
+		if _, err := tx.Exec(`DELETE FROM runs WHERE timestamp < ?`, cutoff); err != nil {
+			return fmt.Errorf("failed to apply retention: %w", err)
+		}
+	}
+
+	if cfg.MaxSamples > 0 {
+		if _, err := tx.Exec(`
+			DELETE FROM runs WHERE id IN (
+				SELECT id FROM (
+					SELECT id, row_number() OVER (
+						PARTITION BY makefile_path, target
+						ORDER BY timestamp DESC, id DESC
+					) AS position
+					FROM runs
+				)
+				WHERE position > ?
+			)`, cfg.MaxSamples); err != nil {
+			return fmt.Errorf("failed to apply retention: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// runs queries the database for the history browser
+// Project, target, status and date filters use the indexes; free text is matched in Go.
+func (s *sqliteStore) runs(q Query) ([]Run, error) {
+	var conditions []string
+	var args []any
+
+	if q.Project != "" {
+		conditions = append(conditions, `makefile_path LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(q.Project))
+	}
+	if q.Target != "" {
+		conditions = append(conditions, `target LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(q.Target))
+	}
+	switch q.Status {
+	case StatusSuccess:
+		conditions = append(conditions, `success = 1`)
+	case StatusFailed:
+		conditions = append(conditions, `success = 0`)
+	}
+	if !q.Since.IsZero() {
+		conditions = append(conditions, `timestamp >= ?`)
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		conditions = append(conditions, `timestamp < ?`)
+		args = append(args, q.Until.UnixNano())
+	}
+
+	query := `SELECT id, makefile_path, target, timestamp, duration, success, exit_code, args, export_path, confirmation, output IS NOT NULL FROM runs`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+	query += ` ORDER BY timestamp DESC, makefile_path, target`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	text := strings.ToLower(q.Text)
+	var runs []Run
+	for rows.Next() {
+		var run Run
+		var timestamp, duration int64
+		var encodedArgs string
+		var confirmation sql.NullString
+		if err := rows.Scan(&run.ID, &run.MakefilePath, &run.Target, &timestamp, &duration,
+			&run.Success, &run.ExitCode, &encodedArgs, &run.ExportPath, &confirmation, &run.HasOutput); err != nil {
+			return nil, fmt.Errorf("failed to read run: %w", err)
+		}
+		run.Timestamp = time.Unix(0, timestamp)
+		run.Duration = time.Duration(duration)
+		run.Args = decodeArgs(encodedArgs)
+		run.Confirmation = decodeConfirmation(confirmation)
+
+		if run.matches(Query{}, text) {
+			runs = append(runs, run)
+		}
+	}
+
+	return runs, rows.Err()
+}
+
+// output returns the captured output of a run
+func (s *sqliteStore) output(id int64) (string, error) {
+	var output sql.NullString
+	err := s.db.QueryRow(`SELECT output FROM runs WHERE id = ?`, id).Scan(&output)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !output.Valid) {
+		return "", ErrNoOutput
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read output: %w", err)
+	}
+	return output.String, nil
+}
+
+// insertRun inserts a single run; an empty output is stored as NULL
+func insertRun(tx *sql.Tx, makefilePath, targetName string, record ExecutionRecord) error {
+	args, err := json.Marshal(record.Args)
+	if err != nil {
+		return fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	if record.Args == nil {
+		args = []byte("[]")
+	}
+
+	var output sql.NullString
+	if record.Output != "" {
+		output = sql.NullString{String: record.Output, Valid: true}
+	}
+
+	var confirmation sql.NullString
+	if record.Confirmation != nil {
+		encoded, err := json.Marshal(record.Confirmation)
+		if err != nil {
+			return fmt.Errorf("failed to encode confirmation: %w", err)
+		}
+		confirmation = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO runs (makefile_path, target, timestamp, duration, success, exit_code, args, export_path, output, confirmation)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		makefilePath, targetName, record.Timestamp.UnixNano(), int64(record.Duration),
+		record.Success, record.ExitCode, string(args), record.ExportPath, output, confirmation); err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	return nil
+}
+
+// upsertRecent inserts or replaces a recents row (used by the migration)
+func upsertRecent(tx *sql.Tx, makefilePath, targetName string, lastUsed time.Time, useCount int) error {
+	if _, err := tx.Exec(`
+		INSERT INTO recents (makefile_path, target, last_used, use_count) VALUES (?, ?, ?, ?)
+		ON CONFLICT (makefile_path, target) DO UPDATE SET
+			last_used = excluded.last_used,
+			use_count = excluded.use_count`,
+		makefilePath, targetName, lastUsed.UnixNano(), useCount); err != nil {
+		return fmt.Errorf("failed to import recent target: %w", err)
+	}
+	return nil
+}
+
+// scanRecord reads one runs row selected by load
+func scanRecord(rows *sql.Rows, makefilePath, target *string) (ExecutionRecord, error) {
+	var record ExecutionRecord
+	var timestamp, duration int64
+	var encodedArgs string
+	var confirmation sql.NullString
+	if err := rows.Scan(makefilePath, target, &timestamp, &duration,
+		&record.Success, &record.ExitCode, &encodedArgs, &record.ExportPath, &confirmation); err != nil {
+		return record, fmt.Errorf("failed to read run: %w", err)
+	}
+
+	record.Timestamp = time.Unix(0, timestamp)
+	record.Duration = time.Duration(duration)
+	record.Args = decodeArgs(encodedArgs)
+	record.Confirmation = decodeConfirmation(confirmation)
+	return record, nil
+}
+
+// decodeConfirmation decodes the confirmation column; NULL or invalid JSON decodes to nil
+func decodeConfirmation(encoded sql.NullString) *export.Confirmation {
+	if !encoded.Valid {
+		return nil
+	}
+	var confirmation export.Confirmation
+	if err := json.Unmarshal([]byte(encoded.String), &confirmation); err != nil {
+		return nil
+	}
+	return &confirmation
+}
+
+// decodeArgs decodes the args column; no arguments decode to nil like the JSON backend
+func decodeArgs(encoded string) []string {
+	var args []string
+	if err := json.Unmarshal([]byte(encoded), &args); err != nil || len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// likePattern builds a case-insensitive substring pattern for LIKE
+func likePattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return "%" + escaped + "%"
+}
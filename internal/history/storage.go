@@ -0,0 +1,51 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Storage backends
+const (
+	BackendJSON   = "json"   // Single history.json file rewritten on every save
+	BackendSQLite = "sqlite" // Embedded database, safe for several lazymake instances at once
+
+	databaseFileName = "history.db"
+)
+
+// StorageConfig selects where history, performance samples and run output are stored
+type StorageConfig struct {
+	// Backend: "json" or "sqlite"
+	Backend string `yaml:"backend"`
+}
+
+// StorageDefaults returns a StorageConfig with sensible default values
+func StorageDefaults() *StorageConfig {
+	return &StorageConfig{
+		Backend: BackendJSON,
+	}
+}
+
+// Open loads history from the configured backend
+// exportDir is scanned once for exported runs when the SQLite database is first created.
+// Falls back to the JSON history on error (graceful degradation): the history
+// returned is usable, and the error says why the database isn't.
+func Open(cfg *StorageConfig, exportDir string) (*History, error) {
+	if cfg == nil || cfg.Backend != BackendSQLite {
+		return Load()
+	}
+
+	jsonPath, err := getCachePath()
+	if err != nil {
+		h, _ := Load()
+		return h, fmt.Errorf("failed to get cache path: %w", err)
+	}
+
+	h, err := openSQLite(filepath.Join(filepath.Dir(jsonPath), databaseFileName), jsonPath, exportDir)
+	if err != nil {
+		fallback, _ := Load()
+		return fallback, fmt.Errorf("history database unavailable, using %s: %w", historyFileName, err)
+	}
+
+	return h, nil
+}
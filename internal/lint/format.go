@@ -0,0 +1,168 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/sarif"
+)
+
+// Report formats
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// severities lists severities in report order
+var severities = []Severity{SeverityError, SeverityWarning, SeverityInfo}
+
+// Write writes the report in the given format: text, json or sarif
+func (r *Report) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return r.WriteText(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	case FormatSARIF:
+		return r.WriteSARIF(w)
+	default:
+		return fmt.Errorf("unknown format %q: want text, json or sarif", format)
+	}
+}
+
+// WriteText writes one line per diagnostic, like a compiler:
+//
+//	Makefile:12: warning: "build" doesn't create a file but isn't declared .PHONY [missing-phony]
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	for _, d := range r.Diagnostics {
+		if !d.Suppressed {
+			fmt.Fprintf(&b, "%s: %s: %s [%s]\n", d.Location(), d.Severity, d.Message, d.RuleID)
+		}
+	}
+	for _, d := range r.Diagnostics {
+		if d.Suppressed {
+			reason := d.SuppressionReason
+			if reason == "" {
+				reason = "no reason given"
+			}
+			fmt.Fprintf(&b, "%s: allowed: %s [%s]: %s\n", d.Location(), d.Message, d.RuleID, reason)
+		}
+	}
+
+	for _, e := range r.Errors {
+		fmt.Fprintf(&b, "error: %s: %v\n", e.Makefile, e.Err)
+	}
+	if len(r.Diagnostics)+len(r.Errors) > 0 {
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "Linted %d Makefile(s): %d error, %d warning, %d info, %d allowed\n",
+		len(r.Makefiles), r.Count(SeverityError), r.Count(SeverityWarning), r.Count(SeverityInfo), r.CountSuppressed())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// jsonReport is the JSON form of a report
+type jsonReport struct {
+	Makefiles   []string         `json:"makefiles"`
+	Summary     map[string]int   `json:"summary"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+	Errors      []jsonError      `json:"errors,omitempty"`
+}
+
+type jsonDiagnostic struct {
+	Severity string `json:"severity"`
+	RuleID   string `json:"rule_id"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Target   string `json:"target,omitempty"`
+	Variable string `json:"variable,omitempty"`
+	Message  string `json:"message"`
+
+	Suppressed        bool   `json:"suppressed,omitempty"`
+	SuppressionReason string `json:"suppression_reason,omitempty"`
+}
+
+type jsonError struct {
+	Makefile string `json:"makefile"`
+	Error    string `json:"error"`
+}
+
+// WriteJSON writes the report as a JSON document
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := jsonReport{
+		Makefiles:   r.Makefiles,
+		Summary:     make(map[string]int),
+		Diagnostics: make([]jsonDiagnostic, 0, len(r.Diagnostics)),
+	}
+	if out.Makefiles == nil {
+		out.Makefiles = []string{}
+	}
+
+	for _, severity := range severities {
+		out.Summary[severity.String()] = r.Count(severity)
+	}
+	out.Summary["suppressed"] = r.CountSuppressed()
+	for _, d := range r.Diagnostics {
+		out.Diagnostics = append(out.Diagnostics, jsonDiagnostic{
+			Severity: d.Severity.String(),
+			RuleID:   d.RuleID,
+			File:     filepath.ToSlash(d.File),
+			Line:     d.Line,
+			Target:   d.Target,
+			Variable: d.Variable,
+			Message:  d.Message,
+
+			Suppressed:        d.Suppressed,
+			SuppressionReason: d.SuppressionReason,
+		})
+	}
+	for _, e := range r.Errors {
+		out.Errors = append(out.Errors, jsonError{Makefile: filepath.ToSlash(e.Makefile), Error: e.Err.Error()})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// WriteSARIF writes the report as SARIF 2.1.0 for code scanning tools
+// Paths are as given to Run, so lint from the repository root.
+func (r *Report) WriteSARIF(w io.Writer) error {
+	findings := make([]sarif.Finding, 0, len(r.Diagnostics))
+	for _, d := range r.Diagnostics {
+		finding := sarif.Finding{
+			RuleID:        d.RuleID,
+			Level:         sarifLevel(d.Severity),
+			Message:       d.Message,
+			File:          d.File,
+			Line:          d.Line,
+			Suppressed:    d.Suppressed,
+			Justification: d.SuppressionReason,
+		}
+		if catalog, found := LookupRule(d.RuleID); found {
+			finding.RuleDescription, finding.RuleHelp = catalog.Description, catalog.Help
+		}
+		findings = append(findings, finding)
+	}
+	return sarif.Write(w, findings)
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return sarif.LevelError
+	case SeverityWarning:
+		return sarif.LevelWarning
+	default:
+		return sarif.LevelNote
+	}
+}
@@ -0,0 +1,310 @@
+// Package lint checks Makefiles for common mistakes: missing .PHONY
+// declarations, undocumented targets, recipes indented with spaces, undefined
+// and unused variables, and more. Each rule has an ID and a severity that can be
+// changed or turned off in the lint section of .lazymake.yaml.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/graph"
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/variables"
+)
+
+// Severity is how serious a diagnostic is
+type Severity int
+
+const (
+	SeverityInfo    Severity = iota // Style and documentation
+	SeverityWarning                 // Likely a mistake
+	SeverityError                   // make fails or misbehaves
+)
+
+// String returns the lowercase severity name used in reports and config
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSeverity parses "error", "warning" or "info"
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "error":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "info":
+		return SeverityInfo, nil
+	default:
+		return SeverityInfo, fmt.Errorf("invalid severity %q", s)
+	}
+}
+
+// Off turns a rule off in Config.Rules
+const Off = "off"
+
+// Config configures which rules run and how severe their diagnostics are
+type Config struct {
+	Enabled        bool              // Show diagnostics in the TUI
+	Rules          map[string]string // Rule ID -> severity ("error", "warning", "info") or "off"
+	ExcludeTargets []string          // Targets (names or globs) skipped by target rules
+}
+
+// Defaults returns the default configuration: every rule at its default severity
+func Defaults() *Config {
+	return &Config{
+		Enabled:        true,
+		Rules:          nil,
+		ExcludeTargets: nil,
+	}
+}
+
+// Severity returns a rule's configured severity, or false if it's turned off
+// Invalid severities keep the rule's default.
+func (c *Config) Severity(rule Rule) (Severity, bool) {
+	if c == nil {
+		return rule.Severity, true
+	}
+	value, ok := c.Rules[rule.ID]
+	if !ok {
+		return rule.Severity, true
+	}
+	if strings.EqualFold(value, Off) {
+		return 0, false
+	}
+	severity, err := ParseSeverity(value)
+	if err != nil {
+		return rule.Severity, true
+	}
+	return severity, true
+}
+
+// excludes reports whether target rules skip a target
+func (c *Config) excludes(target string) bool {
+	if c == nil {
+		return false
+	}
+	for _, pattern := range c.ExcludeTargets {
+		if matched, _ := path.Match(pattern, target); matched || pattern == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagnostic is a problem found in a Makefile
+type Diagnostic struct {
+	RuleID   string
+	Severity Severity
+	File     string // Makefile or included file
+	Line     int    // 1-based (0 if unknown)
+	Target   string // Target the problem is in ("" for variables and files)
+	Variable string // Variable the problem is about ("" if none)
+	Message  string
+
+	// Set when a "# lazymake:allow" annotation on the target allows the rule
+	Suppressed        bool
+	SuppressionReason string
+}
+
+// Location formats file:line, or just the file when the line is unknown
+func (d Diagnostic) Location() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	return d.File
+}
+
+// FileError is a Makefile that couldn't be linted
+type FileError struct {
+	Makefile string
+	Err      error
+}
+
+// Report is the result of linting one or more Makefiles
+type Report struct {
+	Makefiles   []string
+	Diagnostics []Diagnostic // By file and line; allowed diagnostics last
+	Errors      []FileError  // Makefiles that couldn't be read
+}
+
+// Run lints each Makefile
+func Run(paths []string, cfg *Config) *Report {
+	report := &Report{}
+	for _, path := range paths {
+		diagnostics, err := Check(path, cfg)
+		if err != nil {
+			report.Errors = append(report.Errors, FileError{Makefile: path, Err: err})
+			continue
+		}
+		report.Makefiles = append(report.Makefiles, path)
+		report.Diagnostics = append(report.Diagnostics, diagnostics...)
+	}
+
+	sort.SliceStable(report.Diagnostics, func(i, j int) bool {
+		a, b := report.Diagnostics[i], report.Diagnostics[j]
+		if a.Suppressed != b.Suppressed {
+			return !a.Suppressed
+		}
+		return false // Check already sorted each file
+	})
+	return report
+}
+
+// Check lints a single Makefile with the rules cfg leaves on
+// Variables are analyzed as written (make itself is never run), across the
+// files the Makefile includes.
+func Check(path string, cfg *Config) ([]Diagnostic, error) {
+	src, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	for _, rule := range Rules() {
+		severity, on := cfg.Severity(rule)
+		if !on {
+			continue
+		}
+		for _, d := range rule.check(src) {
+			if d.Target != "" && cfg.excludes(d.Target) {
+				continue
+			}
+			d.RuleID, d.Severity = rule.ID, severity
+			if d.File == "" {
+				d.File = path
+			}
+			src.suppress(&d)
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.File != b.File {
+			return a.File == path // The Makefile before its includes
+		}
+		return a.Line < b.Line
+	})
+	return diagnostics, nil
+}
+
+// source is a parsed Makefile and what the rules need to know about it
+type source struct {
+	path      string
+	lines     []string
+	targets   []makefile.Target
+	variables []variables.Variable
+	analyzed  bool // Variables were parsed; the variable rules are skipped otherwise
+	graph     *graph.Graph
+
+	phony         map[string]bool // Targets declared .PHONY
+	doubleColon   map[string]bool // Targets with double-colon rules (target::)
+	nameUses      map[string]bool // Variables referred to outside recipes and values: target names, conditionals, includes
+	spaceIndented []spaceIndented // Lines in a rule indented with spaces
+	oneShell      bool            // .ONESHELL: a recipe runs in one shell
+	errExit       bool            // .SHELLFLAGS has -e: recipes stop at the first failing command
+}
+
+// load parses a Makefile for linting
+func load(path string) (*source, error) {
+	targets, err := makefile.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &source{
+		path:        path,
+		lines:       lines,
+		targets:     targets,
+		graph:       graph.BuildGraph(targets),
+		phony:       make(map[string]bool),
+		doubleColon: make(map[string]bool),
+		nameUses:    make(map[string]bool),
+	}
+
+	// Graceful degradation: without variables, the variable rules find nothing
+	if vars, err := variables.ParseVariables(path); err == nil {
+		variables.AnalyzeUsage(vars, targets)
+		src.variables, src.analyzed = vars, true
+	}
+
+	src.scanLines()
+	return src, nil
+}
+
+// suppress marks a target diagnostic allowed by a "# lazymake:allow" annotation
+func (s *source) suppress(d *Diagnostic) {
+	if d.Target == "" {
+		return
+	}
+	for _, target := range s.targets {
+		if target.Name != d.Target {
+			continue
+		}
+		recipeLine := slices.Index(target.RecipeLines, d.Line)
+		if suppression, ok := target.Allows(d.RuleID, recipeLine); ok {
+			d.Suppressed, d.SuppressionReason = true, suppression.Reason
+			return
+		}
+	}
+}
+
+// Count returns the number of diagnostics at a severity, not counting allowed ones
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, d := range r.Diagnostics {
+		if d.Severity == severity && !d.Suppressed {
+			count++
+		}
+	}
+	return count
+}
+
+// CountSuppressed returns the number of diagnostics allowed by annotations
+func (r *Report) CountSuppressed() int {
+	count := 0
+	for _, d := range r.Diagnostics {
+		if d.Suppressed {
+			count++
+		}
+	}
+	return count
+}
+
+// Exceeds reports whether any diagnostic that isn't allowed is at or above the threshold
+func (r *Report) Exceeds(threshold Severity) bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity >= threshold && !d.Suppressed {
+			return true
+		}
+	}
+	return false
+}
+
+// readLines reads a file's lines
+func readLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Makefile: %w", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
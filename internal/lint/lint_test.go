@@ -0,0 +1,203 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/sarif"
+	"github.com/rshelekhov/lazymake/internal/testutil"
+)
+
+// ruleLines returns the lines a rule reported, allowed diagnostics included
+func ruleLines(diagnostics []Diagnostic, ruleID string) []int {
+	var lines []int
+	for _, d := range diagnostics {
+		if d.RuleID == ruleID {
+			lines = append(lines, d.Line)
+		}
+	}
+	return lines
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		makefile string
+		rule     string
+		want     []int // Reported lines
+	}{
+		{
+			name:     "recipe indented with spaces",
+			makefile: ".PHONY: build\n## Build\nbuild:\n\tgo build\n    go vet\n",
+			rule:     "recipe-spaces",
+			want:     []int{5},
+		},
+		{
+			name:     "dependency cycle",
+			makefile: ".PHONY: a b\n## A\na: b\n## B\nb: a\n",
+			rule:     "dependency-cycle",
+			want:     []int{3},
+		},
+		{
+			name:     "duplicate recipe",
+			makefile: ".PHONY: build\n## Build\nbuild:\n\tgo build\n\nbuild:\n\techo again\n",
+			rule:     "duplicate-target",
+			want:     []int{6},
+		},
+		{
+			name:     "double-colon rules aren't duplicates",
+			makefile: ".PHONY: build\n## Build\nbuild::\n\tgo build\nbuild::\n\techo again\n",
+			rule:     "duplicate-target",
+			want:     nil,
+		},
+		{
+			name:     "undefined variable",
+			makefile: ".PHONY: deploy\n## Deploy\ndeploy:\n\tscp app $(LZ_HOST):/srv\n",
+			rule:     "undefined-variable",
+			want:     []int{4},
+		},
+		{
+			name:     "missing .PHONY",
+			makefile: "## Test\ntest:\n\tgo test ./...\n\nbin/app: main.go\n\tgo build -o $@\n",
+			rule:     "missing-phony",
+			want:     []int{2},
+		},
+		{
+			name:     "cd with ; and on its own line",
+			makefile: ".PHONY: build\n## Build\nbuild:\n\tcd src; go build\n\tcd docs\n\tmake html\n\tcd web && npm run build\n",
+			rule:     "cd-without-and",
+			want:     []int{4, 5},
+		},
+		{
+			name:     "$(shell) in a recursive variable",
+			makefile: "LZ_VERSION = $(shell git describe)\nLZ_COMMIT := $(shell git rev-parse HEAD)\n.PHONY: v\n## Version\nv:\n\techo $(LZ_VERSION) $(LZ_COMMIT)\n",
+			rule:     "shell-in-recursive",
+			want:     []int{1},
+		},
+		{
+			name:     "unused variable",
+			makefile: "LZ_USED = 1\nLZ_UNUSED = 2\nexport LZ_EXPORTED = 3\n.PHONY: v\n## Version\nv:\n\techo $(LZ_USED)\n",
+			rule:     "unused-variable",
+			want:     []int{2},
+		},
+		{
+			name:     "missing description",
+			makefile: ".PHONY: build test lint ci\n## Build\nbuild:\n\tgo build\n# Test\ntest:\n\tgo test\nlint: ## Lint\n\tgo vet\n## @hidden\nci:\n\tgo test\n",
+			rule:     "missing-description",
+			want:     []int{6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics, err := Check(testutil.WriteFile(t, filepath.Join(t.TempDir(), "Makefile"), tt.makefile), Defaults())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ruleLines(diagnostics, tt.rule); !slices.Equal(got, tt.want) {
+				t.Errorf("%s reported lines %v, want %v\n%+v", tt.rule, got, tt.want, diagnostics)
+			}
+		})
+	}
+}
+
+func TestCheck_Config(t *testing.T) {
+	path := testutil.WriteFile(t, filepath.Join(t.TempDir(), "Makefile"), "## Build\nbuild:\n\tgo build\n\n## Release\nci-release:\n\tgoreleaser\n")
+
+	cfg := &Config{
+		Enabled:        true,
+		Rules:          map[string]string{"missing-phony": "error", "missing-description": Off},
+		ExcludeTargets: []string{"ci-*"},
+	}
+	diagnostics, err := Check(path, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected one missing-phony diagnostic for build, got %+v", diagnostics)
+	}
+	if d := diagnostics[0]; d.RuleID != "missing-phony" || d.Severity != SeverityError || d.Target != "build" {
+		t.Errorf("expected missing-phony raised to error for build, got %+v", d)
+	}
+}
+
+func TestConfig_SeverityKeepsDefaultForInvalidValues(t *testing.T) {
+	rule, _ := LookupRule("missing-phony")
+	cfg := &Config{Rules: map[string]string{"missing-phony": "loud"}}
+
+	if severity, on := cfg.Severity(rule); !on || severity != SeverityWarning {
+		t.Errorf("expected the default warning, got %v (on=%v)", severity, on)
+	}
+}
+
+func TestCheck_Suppression(t *testing.T) {
+	path := testutil.WriteFile(t, filepath.Join(t.TempDir(), "Makefile"), "## Build\n# lazymake:allow missing-phony reason=\"always rebuilt\"\nbuild:\n\tgo build\n")
+
+	report := Run([]string{path}, nil)
+	if len(report.Diagnostics) != 1 || !report.Diagnostics[0].Suppressed {
+		t.Fatalf("expected one allowed diagnostic, got %+v", report.Diagnostics)
+	}
+	if report.Diagnostics[0].SuppressionReason != "always rebuilt" {
+		t.Errorf("expected the annotation's reason, got %q", report.Diagnostics[0].SuppressionReason)
+	}
+	if report.Exceeds(SeverityInfo) {
+		t.Error("allowed diagnostics shouldn't count towards --fail-on")
+	}
+}
+
+func TestRun_MissingMakefile(t *testing.T) {
+	report := Run([]string{filepath.Join(t.TempDir(), "Makefile")}, Defaults())
+	if len(report.Errors) != 1 || len(report.Makefiles) != 0 {
+		t.Errorf("expected one file error, got %+v", report)
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	path := testutil.WriteFile(t, filepath.Join(t.TempDir(), "Makefile"), "build:\n\tgo build\n")
+	report := Run([]string{path}, Defaults())
+
+	var text bytes.Buffer
+	if err := report.Write(&text, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), path+":1: warning: ") || !strings.Contains(text.String(), "[missing-phony]") {
+		t.Errorf("expected file:line: severity: message [rule-id], got:\n%s", text.String())
+	}
+
+	var jsonOut bytes.Buffer
+	if err := report.Write(&jsonOut, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var decoded jsonReport
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Summary["warning"] != 1 || decoded.Summary["info"] != 1 {
+		t.Errorf("expected 1 warning and 1 info, got %v", decoded.Summary)
+	}
+
+	var sarifOut bytes.Buffer
+	if err := report.Write(&sarifOut, FormatSARIF); err != nil {
+		t.Fatal(err)
+	}
+	var log sarif.Log
+	if err := json.Unmarshal(sarifOut.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	run := log.Runs[0]
+	if len(run.Results) != 2 || len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("expected 2 results and 2 rules, got %+v", run)
+	}
+	if run.Results[0].Level != "warning" || run.Results[1].Level != "note" {
+		t.Errorf("expected warning and note levels, got %q and %q", run.Results[0].Level, run.Results[1].Level)
+	}
+
+	if err := report.Write(&text, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,534 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/util"
+	"github.com/rshelekhov/lazymake/internal/variables"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Rule is a check in the lint catalog
+type Rule struct {
+	ID          string   // Used in config, annotations and reports: "missing-phony"
+	Severity    Severity // Default severity
+	Description string   // What the rule finds
+	Help        string   // How to fix it
+
+	check func(*source) []Diagnostic
+}
+
+// Rules returns the rule catalog, most severe first
+func Rules() []Rule {
+	return []Rule{
+		{
+			ID:          "recipe-spaces",
+			Severity:    SeverityError,
+			Description: "Recipe line indented with spaces instead of a tab",
+			Help:        "Indent recipe lines with a tab; make stops with \"missing separator\" otherwise",
+			check:       checkRecipeSpaces,
+		},
+		{
+			ID:          "dependency-cycle",
+			Severity:    SeverityError,
+			Description: "Targets that depend on each other in a cycle",
+			Help:        "Remove one of the prerequisites; make drops it with a warning",
+			check:       checkDependencyCycle,
+		},
+		{
+			ID:          "duplicate-target",
+			Severity:    SeverityWarning,
+			Description: "Target with more than one recipe",
+			Help:        "Merge the recipes, or use double-colon rules (target::) to run each one",
+			check:       checkDuplicateTargets,
+		},
+		{
+			ID:          "undefined-variable",
+			Severity:    SeverityWarning,
+			Description: "Variable used but never defined",
+			Help:        "Define the variable, or escape shell variables as $$VAR",
+			check:       checkUndefinedVariables,
+		},
+		{
+			ID:          "missing-phony",
+			Severity:    SeverityWarning,
+			Description: "Target that doesn't create a file but isn't declared .PHONY",
+			Help:        "Add the target to .PHONY so a file with the same name doesn't stop it from running",
+			check:       checkMissingPhony,
+		},
+		{
+			ID:          "cd-without-and",
+			Severity:    SeverityWarning,
+			Description: "cd followed by ; or on its own recipe line",
+			Help:        "Use cd dir && cmd: each recipe line runs in its own shell, and a failed cd runs the rest in the wrong directory",
+			check:       checkCdWithoutAnd,
+		},
+		{
+			ID:          "shell-in-recursive",
+			Severity:    SeverityWarning,
+			Description: "$(shell) in a recursively expanded variable",
+			Help:        "Assign with := or != so the command runs once instead of on every use",
+			check:       checkShellInRecursive,
+		},
+		{
+			ID:          "unused-variable",
+			Severity:    SeverityInfo,
+			Description: "Variable defined but never used",
+			Help:        "Remove the variable, or export it if a command reads it from the environment",
+			check:       checkUnusedVariables,
+		},
+		{
+			ID:          "missing-description",
+			Severity:    SeverityInfo,
+			Description: "Target without a ## description",
+			Help:        "Add a ## comment above the target or after its prerequisites",
+			check:       checkMissingDescriptions,
+		},
+	}
+}
+
+// LookupRule returns the catalog rule with an ID
+func LookupRule(id string) (Rule, bool) {
+	for _, rule := range Rules() {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+var (
+	// plainNamePattern matches target names that look like commands rather than files
+	plainNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+	// assignmentPattern matches variable assignments, with or without directives
+	assignmentPattern = regexp.MustCompile(`^(?:(?:export|override|private)\s+)*[^:#=\s]+\s*(?::::=|::=|:=|[+?!]?=)`)
+
+	// directivePattern matches lines make reads as directives rather than rules
+	directivePattern = regexp.MustCompile(`^(?:ifeq|ifneq|ifdef|ifndef|else|endif|-?include|sinclude|export|unexport|override|vpath|define|endef|undefine)(?:\s|$)`)
+)
+
+// spaceIndented is a line inside a rule indented with spaces
+type spaceIndented struct {
+	line   int
+	target string
+}
+
+// scanLines reads what the parsers leave out: .PHONY declarations, double-colon
+// rules, variables used in target names and directives, and space-indented recipes
+func (s *source) scanLines() {
+	var rule []string // Targets of the rule whose recipe may follow
+	defineDepth := 0
+
+	for i := 0; i < len(s.lines); i++ {
+		lineNum := i + 1
+		line := s.lines[i]
+
+		// Join continuation lines
+		for strings.HasSuffix(line, "\\") && i+1 < len(s.lines) {
+			i++
+			line = strings.TrimSuffix(line, "\\") + " " + strings.TrimSpace(s.lines[i])
+		}
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case defineDepth > 0:
+			if trimmed == "endef" {
+				defineDepth--
+			} else if strings.HasPrefix(trimmed, "define ") {
+				defineDepth++
+			}
+			continue
+		case strings.HasPrefix(line, "\t"), trimmed == "", strings.HasPrefix(trimmed, "#"):
+			continue // Recipes, blank lines and comments don't end a rule
+		}
+
+		if directivePattern.MatchString(trimmed) {
+			if strings.HasPrefix(trimmed, "define ") {
+				defineDepth++
+				continue
+			}
+			s.useNames(trimmed)
+			continue
+		}
+		if assignmentPattern.MatchString(trimmed) {
+			if strings.HasPrefix(line, " ") {
+				continue // Indented assignments are fine, even inside a rule
+			}
+			rule = nil
+			continue
+		}
+
+		if strings.HasPrefix(line, " ") && rule != nil {
+			s.spaceIndented = append(s.spaceIndented, spaceIndented{line: lineNum, target: rule[0]})
+			continue
+		}
+
+		names, rest, isRule := strings.Cut(stripComment(line), ":")
+		if !isRule {
+			rule = nil
+			continue
+		}
+		s.useNames(names)
+		rule = strings.Fields(names)
+
+		switch strings.TrimSpace(names) {
+		case ".PHONY":
+			for _, name := range strings.Fields(rest) {
+				s.phony[name] = true
+			}
+		case ".ONESHELL":
+			s.oneShell = true
+		}
+		if strings.HasPrefix(rest, ":") {
+			for _, name := range rule {
+				s.doubleColon[name] = true
+			}
+		}
+	}
+
+	// .SHELLFLAGS is an assignment the variables parser doesn't read (its name starts with a dot)
+	for _, line := range s.lines {
+		if name, value, ok := strings.Cut(line, "="); ok && strings.TrimRight(strings.TrimSpace(name), ":+?") == ".SHELLFLAGS" {
+			s.errExit = strings.Contains(value, "-e")
+		}
+	}
+}
+
+// useNames records the variables referred to in target names and directives
+func (s *source) useNames(text string) {
+	if directive, name, found := strings.Cut(text, " "); found && (directive == "ifdef" || directive == "ifndef") {
+		s.nameUses[strings.TrimSpace(name)] = true
+	}
+	for _, name := range variables.References(text, s.variables) {
+		s.nameUses[name] = true
+	}
+}
+
+// stripComment removes a trailing # comment from a rule line
+func stripComment(line string) string {
+	if i := strings.Index(line, "#"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// definitions groups the parsed targets by name, in Makefile order
+func (s *source) definitions() (names []string, defs map[string][]makefile.Target) {
+	defs = make(map[string][]makefile.Target)
+	for _, target := range s.targets {
+		if _, seen := defs[target.Name]; !seen {
+			names = append(names, target.Name)
+		}
+		defs[target.Name] = append(defs[target.Name], target)
+	}
+	return names, defs
+}
+
+func checkRecipeSpaces(s *source) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, indented := range s.spaceIndented {
+		diagnostics = append(diagnostics, Diagnostic{
+			Line:    indented.line,
+			Target:  indented.target,
+			Message: fmt.Sprintf("recipe line of %q is indented with spaces; make needs a tab", indented.target),
+		})
+	}
+	return diagnostics
+}
+
+func checkDependencyCycle(s *source) []Diagnostic {
+	if !s.graph.HasCycle || len(s.graph.CycleNodes) == 0 {
+		return nil
+	}
+
+	// The graph finds the cycle from whichever node it visits first; report it
+	// from the target defined first so the diagnostic doesn't move between runs
+	cycle := s.graph.CycleNodes
+	if len(cycle) > 1 && cycle[0] == cycle[len(cycle)-1] {
+		nodes := cycle[:len(cycle)-1]
+		start := 0
+		for i, name := range nodes {
+			if s.targetLine(name) < s.targetLine(nodes[start]) {
+				start = i
+			}
+		}
+		cycle = append(slices.Concat(nodes[start:], nodes[:start]), nodes[start])
+	}
+
+	return []Diagnostic{{
+		Line:    s.targetLine(cycle[0]),
+		Target:  cycle[0],
+		Message: "circular dependency: " + strings.Join(cycle, " → "),
+	}}
+}
+
+// targetLine returns the line a target is defined at (0 if it isn't)
+func (s *source) targetLine(name string) int {
+	if node, ok := s.graph.Nodes[name]; ok {
+		return node.Target.Line
+	}
+	return 0
+}
+
+func checkDuplicateTargets(s *source) []Diagnostic {
+	var diagnostics []Diagnostic
+	names, defs := s.definitions()
+	for _, name := range names {
+		if s.doubleColon[name] || strings.Contains(name, "%") {
+			continue
+		}
+		first := -1
+		for _, target := range defs[name] {
+			if len(target.Recipe) == 0 {
+				continue // Extra prerequisites without a recipe are fine
+			}
+			if first < 0 {
+				first = target.Line
+				continue
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Line:    target.Line,
+				Target:  name,
+				Message: fmt.Sprintf("recipe for %q overrides the one at line %d", name, first),
+			})
+		}
+	}
+	return diagnostics
+}
+
+func checkUndefinedVariables(s *source) []Diagnostic {
+	if !s.analyzed {
+		return nil
+	}
+
+	var diagnostics []Diagnostic
+	for _, undefined := range variables.UndefinedReferences(s.variables, s.targets) {
+		for _, name := range undefined.Targets {
+			diagnostics = append(diagnostics, Diagnostic{
+				Line:     s.referenceLine(name, undefined.Name),
+				Target:   name,
+				Variable: undefined.Name,
+				Message:  fmt.Sprintf("$(%s) is never defined and expands to nothing", undefined.Name),
+			})
+		}
+		for _, name := range undefined.Variables {
+			file, line := s.definitionLine(name, undefined.Name)
+			diagnostics = append(diagnostics, Diagnostic{
+				File:     file,
+				Line:     line,
+				Variable: undefined.Name,
+				Message:  fmt.Sprintf("%s refers to $(%s), which is never defined", name, undefined.Name),
+			})
+		}
+	}
+	return diagnostics
+}
+
+// referenceLine returns the first line of a target that refers to a variable
+func (s *source) referenceLine(target, variable string) int {
+	for _, t := range s.targets {
+		if t.Name != target {
+			continue
+		}
+		for i, line := range t.Recipe {
+			if slices.Contains(variables.References(line, s.variables), variable) && i < len(t.RecipeLines) {
+				return t.RecipeLines[i]
+			}
+		}
+		if slices.Contains(variables.References(t.Prerequisites, s.variables), variable) {
+			return t.Line
+		}
+	}
+	return 0
+}
+
+// definitionLine returns where a variable's value refers to another variable
+func (s *source) definitionLine(name, variable string) (file string, line int) {
+	for _, v := range s.variables {
+		if v.Name != name {
+			continue
+		}
+		for _, a := range v.Assignments {
+			if a.Final && slices.Contains(variables.References(a.Value, s.variables), variable) {
+				return a.File, a.Line
+			}
+		}
+		return v.DefinedIn, v.DefinedAt
+	}
+	return "", 0
+}
+
+func checkMissingPhony(s *source) []Diagnostic {
+	var diagnostics []Diagnostic
+	names, defs := s.definitions()
+	for _, name := range names {
+		if s.phony[name] || !plainNamePattern.MatchString(name) {
+			continue
+		}
+		target, ok := recipeDefinition(defs[name])
+		if !ok || writesTarget(target) {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Line:    target.Line,
+			Target:  name,
+			Message: fmt.Sprintf("%q doesn't create a file but isn't declared .PHONY", name),
+		})
+	}
+	return diagnostics
+}
+
+// recipeDefinition returns the definition of a target that has a recipe
+func recipeDefinition(defs []makefile.Target) (makefile.Target, bool) {
+	for _, target := range defs {
+		if len(target.Recipe) > 0 {
+			return target, true
+		}
+	}
+	return makefile.Target{}, false
+}
+
+// writesTarget reports whether a recipe refers to its target file with $@
+func writesTarget(target makefile.Target) bool {
+	return slices.ContainsFunc(target.Recipe, func(line string) bool {
+		return strings.Contains(strings.ReplaceAll(line, "$$", ""), "$@") ||
+			strings.Contains(line, "$(@") || strings.Contains(line, "${@")
+	})
+}
+
+func checkCdWithoutAnd(s *source) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, target := range s.targets {
+		lines, starts := makefile.JoinContinuations(target.Recipe)
+		for i, line := range lines {
+			if strings.Contains(line, "set -e") {
+				continue
+			}
+			file, err := makefile.ParseShell(line)
+			if err != nil {
+				continue // Make functions the shell parser can't read
+			}
+
+			message := ""
+			switch {
+			case !s.errExit && hasCdBeforeCommand(file):
+				message = "a failed cd runs the rest of the line in the wrong directory; use cd dir && cmd"
+			case !s.oneShell && i < len(lines)-1 && len(file.Stmts) == 1 && isCd(file.Stmts[0]):
+				message = "cd on its own line has no effect: each recipe line runs in its own shell"
+			default:
+				continue
+			}
+
+			d := Diagnostic{Target: target.Name, Message: message, Line: target.Line}
+			if starts[i] < len(target.RecipeLines) {
+				d.Line = target.RecipeLines[starts[i]]
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return diagnostics
+}
+
+// hasCdBeforeCommand reports whether a cd is followed by another command
+// with ; or a newline instead of &&
+func hasCdBeforeCommand(file *syntax.File) bool {
+	found := false
+	syntax.Walk(file, func(node syntax.Node) bool {
+		var stmts []*syntax.Stmt
+		switch n := node.(type) {
+		case *syntax.File:
+			stmts = n.Stmts
+		case *syntax.Block:
+			stmts = n.Stmts
+		case *syntax.Subshell:
+			stmts = n.Stmts
+		}
+		for i, stmt := range stmts {
+			if i < len(stmts)-1 && isCd(stmt) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// isCd reports whether a statement is a plain cd command
+func isCd(stmt *syntax.Stmt) bool {
+	call, ok := stmt.Cmd.(*syntax.CallExpr)
+	return ok && !stmt.Background && len(call.Args) > 0 && call.Args[0].Lit() == "cd"
+}
+
+func checkShellInRecursive(s *source) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, v := range s.variables {
+		for _, a := range v.Assignments {
+			recursive := a.Operator == "=" || a.Operator == "?=" || (a.Operator == "+=" && v.Flavor == "recursive")
+			if a.File == "" || !recursive {
+				continue
+			}
+			if !strings.Contains(a.Value, "$(shell ") && !strings.Contains(a.Value, "${shell ") {
+				continue
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				File:     a.File,
+				Line:     a.Line,
+				Variable: v.Name,
+				Message:  fmt.Sprintf("%s runs $(shell) every time it's used; assign it with := to run the command once", v.Name),
+			})
+		}
+	}
+	return diagnostics
+}
+
+func checkUnusedVariables(s *source) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, v := range s.variables {
+		if v.Origin != "file" && v.Origin != "override" {
+			continue // From the environment, or exported without a value
+		}
+		if v.IsExported || variables.IsBuiltin(v.Name) || s.nameUses[v.Name] || !util.SamePath(v.DefinedIn, s.path) {
+			continue // Read by commands, by make itself, or by the Makefiles including this one
+		}
+		if len(v.UsedByTargets)+len(v.IndirectTargets)+len(v.UsedByVariables) > 0 {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			File:     v.DefinedIn,
+			Line:     v.DefinedAt,
+			Variable: v.Name,
+			Message:  fmt.Sprintf("%s is defined but never used", v.Name),
+		})
+	}
+	return diagnostics
+}
+
+func checkMissingDescriptions(s *source) []Diagnostic {
+	var diagnostics []Diagnostic
+	names, defs := s.definitions()
+	for _, name := range names {
+		if !plainNamePattern.MatchString(name) {
+			continue // Files and pattern rules
+		}
+		// @hidden targets are left out of the list, so they don't need a description
+		described := slices.ContainsFunc(defs[name], func(t makefile.Target) bool {
+			return t.CommentType == makefile.CommentDouble || t.Docs.Hidden
+		})
+		if described {
+			continue
+		}
+
+		first := defs[name][0]
+		message := fmt.Sprintf("%q has no ## description", name)
+		if first.CommentType == makefile.CommentSingle {
+			message = fmt.Sprintf("%q is described with # instead of ##", name)
+		}
+		diagnostics = append(diagnostics, Diagnostic{Line: first.Line, Target: name, Message: message})
+	}
+	return diagnostics
+}
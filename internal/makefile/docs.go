@@ -0,0 +1,102 @@
+package makefile
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Docs is the structured documentation of a target, from "@" tags on the ##
+// comment lines above it:
+//
+//	## Deploy the application
+//	## @group Deployment
+//	## @tag release, prod
+//	## @arg ENV environment to deploy to (default: staging)
+//	## @example make deploy ENV=production
+//	## @deprecated use release
+//	deploy:
+type Docs struct {
+	Group           string   // @group: section the target is listed under
+	Tags            []string // @tag: labels to filter by
+	Args            []Arg    // @arg: variables the target reads
+	Examples        []string // @example: sample invocations
+	Deprecated      bool     // @deprecated
+	DeprecationNote string   // Text after @deprecated ("use release")
+	Hidden          bool     // @hidden: left out of the target list
+}
+
+// Arg is a variable documented with @arg
+type Arg struct {
+	Name        string
+	Description string
+	Default     string // From "(default: x)"; "" if not given
+}
+
+// IsZero reports whether the target has no structured documentation
+func (d Docs) IsZero() bool {
+	return d.Group == "" && len(d.Tags) == 0 && len(d.Args) == 0 && len(d.Examples) == 0 &&
+		!d.Deprecated && !d.Hidden
+}
+
+// HasTag reports whether the target is tagged with tag (case-insensitive)
+func (d Docs) HasTag(tag string) bool {
+	return slices.ContainsFunc(d.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// docTagPattern matches a doc tag at the start of a ## comment: "@arg ENV ..."
+var docTagPattern = regexp.MustCompile(`^@(group|tag|tags|arg|example|deprecated|hidden)\b\s*(.*)$`)
+
+// argDefaultPattern matches a trailing "(default: x)" in an @arg description
+var argDefaultPattern = regexp.MustCompile(`\s*\(default:\s*(.*?)\)\s*$`)
+
+// parseDocTag applies a doc tag in a ## comment's text to docs
+// found is false when the text isn't a doc tag, so it's an ordinary description.
+func parseDocTag(text string, docs *Docs) (found bool) {
+	matches := docTagPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return false
+	}
+
+	tag, value := matches[1], strings.TrimSpace(matches[2])
+	switch tag {
+	case "group":
+		docs.Group = value
+	case "tag", "tags":
+		for _, t := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			if !docs.HasTag(t) {
+				docs.Tags = append(docs.Tags, t)
+			}
+		}
+	case "arg":
+		if arg, ok := parseArg(value); ok {
+			docs.Args = append(docs.Args, arg)
+		}
+	case "example":
+		if value != "" {
+			docs.Examples = append(docs.Examples, value)
+		}
+	case "deprecated":
+		docs.Deprecated = true
+		docs.DeprecationNote = value
+	case "hidden":
+		docs.Hidden = true
+	}
+	return true
+}
+
+// parseArg parses "NAME description (default: x)"
+func parseArg(value string) (Arg, bool) {
+	name, description, _ := strings.Cut(value, " ")
+	name = strings.TrimSuffix(name, "=")
+	if name == "" {
+		return Arg{}, false
+	}
+
+	arg := Arg{Name: name, Description: strings.TrimSpace(description)}
+	if m := argDefaultPattern.FindStringSubmatchIndex(arg.Description); m != nil {
+		arg.Default = arg.Description[m[2]:m[3]]
+		arg.Description = arg.Description[:m[0]]
+	}
+	return arg, true
+}
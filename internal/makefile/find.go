@@ -0,0 +1,21 @@
+package makefile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Find returns the Makefile make would read in dir
+// From the GNU make manual, the order is:
+// GNUmakefile, makefile and Makefile.
+func Find(dir string) (string, error) {
+	possibleNames := []string{"GNUmakefile", "makefile", "Makefile"}
+	for _, name := range possibleNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("no Makefile found in " + dir)
+}
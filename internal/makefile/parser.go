@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 )
 
@@ -18,11 +19,25 @@ const (
 
 // Target represents a Makefile target
 type Target struct {
-	Name         string
-	Description  string
-	CommentType  CommentType
-	Dependencies []string // List of target names this target depends on
-	Recipe       []string // Recipe lines (commands to execute)
+	Name          string
+	Description   string
+	CommentType   CommentType
+	Dependencies  []string // List of target names this target depends on
+	Prerequisites string   // Prerequisite list as written, variables included
+	Recipe        []string // Recipe lines (commands to execute)
+
+	// Source positions (1-based), for reports that point at the Makefile
+	Line        int   // Line of the target definition
+	RecipeLines []int // Line of each entry in Recipe
+
+	// Safety rules allowed by "# lazymake:allow" annotations
+	Suppressions []Suppression
+
+	// Group, tags, arguments and more from "## @tag" lines (see Docs)
+	Docs Docs
+
+	// Title of the "##@ Section" marker above the target ("" before the first one)
+	Section string
 }
 
 // commentInfo holds information about a comment
@@ -42,18 +57,31 @@ func Parse(filename string) ([]Target, error) {
 	var lastComment commentInfo
 	var currentTargets []*Target
 	var recipeLines []string
+	var recipeLineNumbers []int
+	var recipeSuppressions []Suppression // Annotations inside the current recipe
+	var lineAllows []Suppression         // Recipe comment annotations waiting for the next recipe line
+	var targetAllows []Suppression       // Annotations before the next target
+	var docs Docs                        // Doc tags before the next target
+	var section string                   // Title of the last ##@ section marker
 	var defineDepth int
+	lineNum := 0
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
+		lineNum++
 		trimmed := strings.TrimSpace(line)
 
 		// Handle define/endef blocks
 		if isDefineStart(line) {
-			commitCurrentTargets(currentTargets, recipeLines)
+			commitCurrentTargets(currentTargets, recipeLines, recipeLineNumbers, recipeSuppressions)
 			currentTargets = nil
 			recipeLines = nil
+			recipeLineNumbers = nil
+			recipeSuppressions = nil
+			lineAllows = nil
+			targetAllows = nil
+			docs = Docs{}
 			lastComment = commentInfo{}
 			defineDepth++
 			continue
@@ -70,9 +98,14 @@ func Parse(filename string) ([]Target, error) {
 
 		// Empty line: commit and reset
 		if trimmed == "" {
-			commitCurrentTargets(currentTargets, recipeLines)
+			commitCurrentTargets(currentTargets, recipeLines, recipeLineNumbers, recipeSuppressions)
 			currentTargets = nil
 			recipeLines = nil
+			recipeLineNumbers = nil
+			recipeSuppressions = nil
+			lineAllows = nil
+			targetAllows = nil
+			docs = Docs{}
 			lastComment = commentInfo{}
 			continue
 		}
@@ -80,16 +113,53 @@ func Parse(filename string) ([]Target, error) {
 		// Recipe line (starts with tab)
 		if after, ok := strings.CutPrefix(line, "\t"); ok {
 			if len(currentTargets) > 0 {
+				index := len(recipeLines)
 				recipeLines = append(recipeLines, after)
+				recipeLineNumbers = append(recipeLineNumbers, lineNum)
+
+				if allow, before, found := parseAllow(after, lineNum, index); found {
+					if strings.TrimSpace(before) == "" {
+						// Comment line: applies to the next recipe line
+						lineAllows = append(lineAllows, allow)
+						continue
+					}
+					recipeSuppressions = append(recipeSuppressions, allow)
+				}
+				for _, allow := range lineAllows {
+					allow.RecipeLine = index
+					recipeSuppressions = append(recipeSuppressions, allow)
+				}
+				lineAllows = nil
 			}
 			continue
 		}
 
 		// Check for comment
 		if comment, commentType, found := parseCommentLine(trimmed); found {
-			commitCurrentTargets(currentTargets, recipeLines)
+			commitCurrentTargets(currentTargets, recipeLines, recipeLineNumbers, recipeSuppressions)
 			currentTargets = nil
 			recipeLines = nil
+			recipeLineNumbers = nil
+			recipeSuppressions = nil
+			lineAllows = nil
+
+			// ##@ starts a section that lasts until the next marker
+			if title, ok := parseSectionMarker(trimmed); ok {
+				section = title
+				targetAllows = nil
+				docs = Docs{}
+				lastComment = commentInfo{}
+				continue
+			}
+			// Annotations don't replace the target's description
+			if allow, _, found := parseAllow(trimmed, lineNum, -1); found {
+				targetAllows = append(targetAllows, allow)
+				continue
+			}
+			// Doc tags (## @group ...) don't replace it either
+			if commentType == CommentDouble && parseDocTag(comment, &docs) {
+				continue
+			}
 			lastComment = commentInfo{
 				text:        comment,
 				commentType: commentType,
@@ -100,15 +170,20 @@ func Parse(filename string) ([]Target, error) {
 		// Check for target definition
 		// Skip variable assignments (e.g., VAR := value, VAR = value, VAR ?= value, VAR += value)
 		if strings.Contains(line, ":") && !strings.HasPrefix(line, "\t") && !isVariableAssignment(line) {
-			currentTargets = processTargetLine(
-				line, &targets, currentTargets, recipeLines, lastComment)
+			commitCurrentTargets(currentTargets, recipeLines, recipeLineNumbers, recipeSuppressions)
+			currentTargets = processTargetLine(line, lineNum, &targets, lastComment, targetAllows, docs, section)
 			recipeLines = nil
+			recipeLineNumbers = nil
+			recipeSuppressions = nil
+			lineAllows = nil
+			targetAllows = nil
+			docs = Docs{}
 			lastComment = commentInfo{}
 		}
 	}
 
 	// Commit final targets
-	commitCurrentTargets(currentTargets, recipeLines)
+	commitCurrentTargets(currentTargets, recipeLines, recipeLineNumbers, recipeSuppressions)
 
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("error reading Makefile: %w", err)
@@ -130,15 +205,26 @@ func isVariableAssignment(line string) bool {
 		(strings.Contains(line, "=") && strings.Index(line, "=") < strings.Index(line, ":"))
 }
 
-// commitCurrentTargets commits recipe lines to current targets
-func commitCurrentTargets(currentTargets []*Target, recipeLines []string) {
+// commitCurrentTargets commits recipe lines, their line numbers and annotations to current targets
+func commitCurrentTargets(currentTargets []*Target, recipeLines []string, lineNumbers []int, suppressions []Suppression) {
 	if len(currentTargets) > 0 {
 		for _, target := range currentTargets {
 			target.Recipe = recipeLines
+			target.RecipeLines = lineNumbers
+			if len(suppressions) > 0 {
+				target.Suppressions = slices.Concat(target.Suppressions, suppressions)
+			}
 		}
 	}
 }
 
+// parseSectionMarker checks if a line is a "##@ Section" marker and extracts its title
+// A marker without a title ends the current section.
+func parseSectionMarker(trimmed string) (title string, found bool) {
+	title, found = strings.CutPrefix(trimmed, "##@")
+	return strings.TrimSpace(title), found
+}
+
 // parseCommentLine checks if a line is a comment and extracts it
 func parseCommentLine(trimmed string) (text string, commentType CommentType, found bool) {
 	// Check for ## comment (takes priority)
@@ -155,11 +241,10 @@ func parseCommentLine(trimmed string) (text string, commentType CommentType, fou
 }
 
 // processTargetLine processes a target definition line
-func processTargetLine(line string, targets *[]Target, currentTargets []*Target,
-	recipeLines []string, lastComment commentInfo) []*Target {
-	// Commit previous targets
-	commitCurrentTargets(currentTargets, recipeLines)
-
+// The previous targets' recipe must be committed first. allows and docs are the
+// annotations and doc tags on the comment lines above the target, and section
+// the title of the ##@ marker it's under.
+func processTargetLine(line string, lineNum int, targets *[]Target, lastComment commentInfo, allows []Suppression, docs Docs, section string) []*Target {
 	parts := strings.SplitN(line, ":", 2)
 	targetName := strings.TrimSpace(parts[0])
 
@@ -168,8 +253,14 @@ func processTargetLine(line string, targets *[]Target, currentTargets []*Target,
 		return nil
 	}
 
-	// Extract inline comment and dependencies
+	// An annotation in the inline comment applies to the whole recipe
 	dependencies := parts[1]
+	if allow, before, found := parseAllow(dependencies, lineNum, -1); found {
+		allows = append(slices.Clip(allows), allow)
+		dependencies = before
+	}
+
+	// Extract inline comment and dependencies
 	inlineComment := extractInlineComment(dependencies)
 
 	// Clean dependencies string
@@ -192,11 +283,16 @@ func processTargetLine(line string, targets *[]Target, currentTargets []*Target,
 	names := strings.FieldsSeq(targetName)
 	for name := range names {
 		*targets = append(*targets, Target{
-			Name:         name,
-			Description:  finalDesc,
-			CommentType:  finalType,
-			Dependencies: depList,
-			Recipe:       nil,
+			Name:          name,
+			Description:   finalDesc,
+			CommentType:   finalType,
+			Dependencies:  depList,
+			Prerequisites: strings.TrimSpace(cleanDeps),
+			Recipe:        nil,
+			Line:          lineNum,
+			Suppressions:  slices.Clone(allows),
+			Docs:          docs,
+			Section:       section,
 		})
 	}
 
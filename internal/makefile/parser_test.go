@@ -3,6 +3,8 @@ package makefile
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -1144,3 +1146,250 @@ another-real: ## Another real target
 		}
 	}
 }
+
+// TestParseLineNumbers tests that targets and recipe lines record their source lines
+func TestParseLineNumbers(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "Makefile")
+
+	content := "VERSION := 1.0\n" + // 1
+		"\n" + // 2
+		"## Build and install\n" + // 3
+		"build install: deps\n" + // 4
+		"\tgo build -o app\n" + // 5
+		"\t# not a recipe comment\n" + // 6
+		"\tcp app /usr/local/bin\n" + // 7
+		"clean:\n" + // 8
+		"\trm -rf build\n" // 9
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	targets, err := Parse(testFile)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := map[string]struct {
+		line        int
+		recipeLines []int
+	}{
+		"build":   {4, []int{5, 6, 7}},
+		"install": {4, []int{5, 6, 7}},
+		"clean":   {8, []int{9}},
+	}
+
+	for _, target := range targets {
+		want, ok := expected[target.Name]
+		if !ok {
+			t.Errorf("unexpected target %q", target.Name)
+			continue
+		}
+		if target.Line != want.line {
+			t.Errorf("%s: expected line %d, got %d", target.Name, want.line, target.Line)
+		}
+		if len(target.RecipeLines) != len(target.Recipe) {
+			t.Fatalf("%s: %d recipe lines but %d line numbers", target.Name, len(target.Recipe), len(target.RecipeLines))
+		}
+		for i, line := range want.recipeLines {
+			if target.RecipeLines[i] != line {
+				t.Errorf("%s: recipe[%d] expected line %d, got %d", target.Name, i, line, target.RecipeLines[i])
+			}
+		}
+	}
+}
+
+func TestParseSuppressions(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "Makefile")
+
+	content := "## Publish a release\n" + // 1
+		"# lazymake:allow git-force-push reason=\"release branch only\"\n" + // 2
+		"release:\n" + // 3
+		"\tgit push --force origin release\n" + // 4
+		"\n" + // 5
+		"reset: ## Reset the database # lazymake:allow db-drop,db-truncate\n" + // 6
+		"\tpsql -c 'DROP DATABASE app;'\n" + // 7
+		"\n" + // 8
+		"clean:\n" + // 9
+		"\t# lazymake:allow rm-rf-root reason=sandbox\n" + // 10
+		"\tsudo rm -rf /srv/sandbox\n" + // 11
+		"\trm -rf ~/.cache/app # lazymake:allow rm-rf-root reason='user cache'\n" + // 12
+		"\t# lazymake:allow\n" // 13: no rules, ignored
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	targets, err := Parse(testFile)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	byName := make(map[string]Target)
+	for _, target := range targets {
+		byName[target.Name] = target
+	}
+
+	release := byName["release"]
+	if release.Description != "Publish a release" {
+		t.Errorf("annotation should not replace the description, got %q", release.Description)
+	}
+	if len(release.Suppressions) != 1 {
+		t.Fatalf("release: expected 1 suppression, got %+v", release.Suppressions)
+	}
+	if s := release.Suppressions[0]; s.Reason != "release branch only" || s.Line != 2 || s.RecipeLine != -1 {
+		t.Errorf("release: unexpected suppression %+v", s)
+	}
+	if _, ok := release.Allows("git-force-push", 0); !ok {
+		t.Error("release should allow git-force-push")
+	}
+
+	reset := byName["reset"]
+	if reset.Description != "Reset the database" {
+		t.Errorf("reset: expected description without the annotation, got %q", reset.Description)
+	}
+	if len(reset.Suppressions) != 1 || strings.Join(reset.Suppressions[0].Rules, " ") != "db-drop db-truncate" {
+		t.Errorf("reset: expected db-drop and db-truncate allowed, got %+v", reset.Suppressions)
+	}
+
+	clean := byName["clean"]
+	if len(clean.Recipe) != 4 {
+		t.Errorf("annotation comments stay in the recipe, got %q", clean.Recipe)
+	}
+	if len(clean.Suppressions) != 2 {
+		t.Fatalf("clean: expected 2 line suppressions, got %+v", clean.Suppressions)
+	}
+	if s := clean.Suppressions[0]; s.RecipeLine != 1 || s.Reason != "sandbox" || s.Line != 10 {
+		t.Errorf("comment line should apply to the next recipe line, got %+v", s)
+	}
+	if s := clean.Suppressions[1]; s.RecipeLine != 2 || s.Reason != "user cache" {
+		t.Errorf("trailing comment should apply to its own line, got %+v", s)
+	}
+	if _, ok := clean.Allows("rm-rf-root", 0); ok {
+		t.Error("line suppressions should not cover other lines")
+	}
+}
+
+func TestParseDocs(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "Makefile")
+
+	content := "## Deploy the application\n" +
+		"## @group Deployment\n" +
+		"## @tag release, prod\n" +
+		"## @arg ENV environment to deploy to (default: staging)\n" +
+		"## @arg VERSION= release to deploy\n" +
+		"## @example make deploy ENV=production\n" +
+		"# @hidden is only a tag on ## lines\n" +
+		"deploy:\n" +
+		"\t./deploy.sh $(ENV) $(VERSION)\n" +
+		"\n" +
+		"## @deprecated use deploy\n" +
+		"## @hidden\n" +
+		"push: ## Old deploy\n" +
+		"\t./deploy.sh\n" +
+		"\n" +
+		"## @maintainer is not a doc tag\n" +
+		"build:\n" +
+		"\tgo build\n"
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	targets, err := Parse(testFile)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(targets))
+	}
+
+	deploy := targets[0].Docs
+	if targets[0].Description != "@hidden is only a tag on ## lines" || targets[0].CommentType != CommentSingle {
+		t.Errorf("deploy: expected the last plain comment as description, got %q", targets[0].Description)
+	}
+	if deploy.Group != "Deployment" || !slices.Equal(deploy.Tags, []string{"release", "prod"}) || deploy.Hidden {
+		t.Errorf("deploy: unexpected group or tags %+v", deploy)
+	}
+	wantArgs := []Arg{
+		{Name: "ENV", Description: "environment to deploy to", Default: "staging"},
+		{Name: "VERSION", Description: "release to deploy"},
+	}
+	if !slices.Equal(deploy.Args, wantArgs) {
+		t.Errorf("deploy: args = %+v, want %+v", deploy.Args, wantArgs)
+	}
+	if !slices.Equal(deploy.Examples, []string{"make deploy ENV=production"}) {
+		t.Errorf("deploy: examples = %q", deploy.Examples)
+	}
+	if !deploy.HasTag("PROD") {
+		t.Error("tags should match case-insensitively")
+	}
+
+	push := targets[1]
+	if push.Description != "Old deploy" || !push.Docs.Deprecated || push.Docs.DeprecationNote != "use deploy" || !push.Docs.Hidden {
+		t.Errorf("push: expected a hidden, deprecated target described inline, got %+v", push)
+	}
+
+	build := targets[2]
+	if build.Description != "@maintainer is not a doc tag" || !build.Docs.IsZero() {
+		t.Errorf("unknown tags should stay in the description, got %q %+v", build.Description, build.Docs)
+	}
+}
+
+func TestParseSections(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "Makefile")
+
+	content := "help: ## Show help\n" +
+		"\t@awk ...\n" +
+		"\n" +
+		"##@ Building\n" +
+		"\n" +
+		"build: ## Build the app\n" +
+		"\tgo build\n" +
+		"\n" +
+		"## Generate code\n" +
+		"generate:\n" +
+		"\tgo generate\n" +
+		"\n" +
+		"##@   Testing  \n" +
+		"test:\n" +
+		"\tgo test\n" +
+		"\n" +
+		"##@\n" +
+		"clean:\n" +
+		"\trm -rf bin\n"
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	targets, err := Parse(testFile)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := map[string]string{
+		"help":     "",
+		"build":    "Building",
+		"generate": "Building",
+		"test":     "Testing",
+		"clean":    "",
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("expected %d targets, got %d", len(want), len(targets))
+	}
+	for _, target := range targets {
+		if target.Section != want[target.Name] {
+			t.Errorf("%s: expected section %q, got %q", target.Name, want[target.Name], target.Section)
+		}
+	}
+
+	// The marker isn't a description of the target after it
+	if targets[3].Description != "" || targets[3].CommentType != CommentNone {
+		t.Errorf("test: expected no description, got %q", targets[3].Description)
+	}
+}
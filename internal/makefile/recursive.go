@@ -0,0 +1,157 @@
+package makefile
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// SubMake is a recursive make invocation in a recipe, e.g. "$(MAKE) -C services/api build"
+type SubMake struct {
+	Dir        string // Directory from -C or a preceding "cd", as written ("" = the Makefile's own)
+	File       string // Makefile from -f, as written ("" = the default one in Dir)
+	Target     string // Target to run ("" = the sub-Makefile's default goal)
+	RecipeLine int    // Index of the line in Target.Recipe
+}
+
+// makeCommands are the words that start a recursive make invocation
+var makeCommands = []string{"$(MAKE)", "${MAKE}", "make"}
+
+// makeOptionsWithValue are the make options that take the next word as their value
+var makeOptionsWithValue = map[string]bool{
+	"-C": true, "--directory": true,
+	"-f": true, "--file": true, "--makefile": true,
+	"-I": true, "--include-dir": true,
+	"-o": true, "--old-file": true, "--assume-old": true,
+	"-W": true, "--what-if": true, "--new-file": true, "--assume-new": true,
+}
+
+// SubMakes returns the recursive make invocations in the target's recipe
+// One is returned for each target given on the command line, or one with an
+// empty Target when make runs the default goal. $@ is replaced by the target's
+// name; other variables, and directories only known at run time ($dir in a
+// loop), are returned as written.
+func (t Target) SubMakes() []SubMake {
+	var calls []SubMake
+	lines, starts := JoinContinuations(t.Recipe)
+	for i, line := range lines {
+		for _, call := range parseSubMakes(line) {
+			if call.Target == "$@" || call.Target == "$(@)" {
+				call.Target = t.Name
+			}
+			call.RecipeLine = starts[i]
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// parseSubMakes finds the make invocations in one recipe line
+// A "cd dir" earlier in the line applies to the commands after it. Lines the
+// shell parser can't read have none.
+func parseSubMakes(line string) []SubMake {
+	file, err := ParseShell(line)
+	if err != nil {
+		return nil
+	}
+
+	var calls []SubMake
+	cwd := ""
+	syntax.Walk(file, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+
+		words := make([]string, len(call.Args))
+		for i, arg := range call.Args {
+			words[i] = WordText(arg)
+		}
+		if words[0] == "exec" || words[0] == "command" {
+			words = words[1:]
+		}
+
+		switch {
+		case len(words) == 0:
+		case words[0] == "cd":
+			if len(words) > 1 {
+				cwd = joinDir(cwd, words[1])
+			}
+		case slices.Contains(makeCommands, words[0]):
+			calls = append(calls, parseMakeArgs(cwd, words[1:])...)
+		}
+		return true
+	})
+	return calls
+}
+
+// parseMakeArgs reads the directory, Makefile and targets from make's arguments
+func parseMakeArgs(dir string, args []string) []SubMake {
+	call := SubMake{Dir: dir}
+	var targets []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		option, value, hasValue := strings.Cut(arg, "=")
+		switch {
+		case arg == "--" || arg == "$(MAKEFLAGS)" || arg == "$(MFLAGS)":
+			continue
+		case strings.HasPrefix(arg, "--"):
+			if !hasValue && makeOptionsWithValue[option] && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			applyMakeOption(&call, option, value)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			option, value = arg[:2], arg[2:]
+			if value == "" && makeOptionsWithValue[option] && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			// -j and -l take an optional number
+			if (option == "-j" || option == "-l") && value == "" && i+1 < len(args) && isNumber(args[i+1]) {
+				i++
+			}
+			applyMakeOption(&call, option, value)
+		case hasValue:
+			continue // Variable override: VAR=value
+		default:
+			targets = append(targets, arg)
+		}
+	}
+
+	if len(targets) == 0 {
+		return []SubMake{call}
+	}
+	calls := make([]SubMake, len(targets))
+	for i, target := range targets {
+		calls[i] = call
+		calls[i].Target = target
+	}
+	return calls
+}
+
+// applyMakeOption records the options that choose the sub-Makefile
+func applyMakeOption(call *SubMake, option, value string) {
+	switch option {
+	case "-C", "--directory":
+		// Several -C options are relative to each other
+		call.Dir = joinDir(call.Dir, value)
+	case "-f", "--file", "--makefile":
+		call.File = value
+	}
+}
+
+func isNumber(word string) bool {
+	return word != "" && strings.Trim(word, "0123456789") == ""
+}
+
+// joinDir resolves dir against base the way make -C and cd do
+func joinDir(base, dir string) string {
+	if base == "" || path.IsAbs(dir) {
+		return dir
+	}
+	return path.Join(base, dir)
+}
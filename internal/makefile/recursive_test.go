@@ -0,0 +1,93 @@
+package makefile
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSubMakes(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []SubMake
+	}{
+		{
+			name: "directory and target",
+			line: "$(MAKE) -C services/api build",
+			want: []SubMake{{Dir: "services/api", Target: "build"}},
+		},
+		{
+			name: "braces, attached directory and flags",
+			line: "@${MAKE} --no-print-directory -Cservices/web -j 4 -k test",
+			want: []SubMake{{Dir: "services/web", Target: "test"}},
+		},
+		{
+			name: "long options and variable overrides",
+			line: "$(MAKE) --directory=lib --file=build.mk ENV=prod VERBOSE=1 all",
+			want: []SubMake{{Dir: "lib", File: "build.mk", Target: "all"}},
+		},
+		{
+			name: "several targets",
+			line: "$(MAKE) -C api clean build",
+			want: []SubMake{{Dir: "api", Target: "clean"}, {Dir: "api", Target: "build"}},
+		},
+		{
+			name: "default goal",
+			line: "-$(MAKE) -C docs",
+			want: []SubMake{{Dir: "docs"}},
+		},
+		{
+			name: "cd before make",
+			line: "cd services && $(MAKE) -C api lint 2>&1 | tee lint.log",
+			want: []SubMake{{Dir: "services/api", Target: "lint"}},
+		},
+		{
+			name: "same Makefile",
+			line: "$(MAKE) $(MFLAGS) clean",
+			want: []SubMake{{Target: "clean"}},
+		},
+		{
+			name: "loop",
+			line: "for d in $(DIRS); do $(MAKE) -C $$d test; done",
+			want: []SubMake{{Dir: "$d", Target: "test"}},
+		},
+		{
+			name: "automatic variable",
+			line: "$(MAKE) -C api $@",
+			want: []SubMake{{Dir: "api", Target: "release"}},
+		},
+		{
+			name: "quoted separators",
+			line: `echo "a; b | c" && $(MAKE) -C "my dir" 'build'`,
+			want: []SubMake{{Dir: "my dir", Target: "build"}},
+		},
+		{
+			name: "command substitution",
+			line: "VERSION=$$(git describe; echo dev) $(MAKE) -C api release",
+			want: []SubMake{{Dir: "api", Target: "release"}},
+		},
+		{
+			name: "redirection",
+			line: "$(MAKE) -C api test >test.log",
+			want: []SubMake{{Dir: "api", Target: "test"}},
+		},
+		{
+			name: "not make",
+			line: "echo make -C api build && cmake --build .",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := Target{Name: "release", Recipe: []string{"echo start", tt.line}}
+			got := target.SubMakes()
+			for i := range tt.want {
+				tt.want[i].RecipeLine = 1
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SubMakes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package makefile
+
+import (
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// JoinContinuations merges recipe lines ending in a backslash with the line that follows
+// Also returns the index in recipe where each joined line starts.
+func JoinContinuations(recipe []string) (lines []string, starts []int) {
+	var current strings.Builder
+	continued := false
+
+	for i, line := range recipe {
+		if !continued {
+			starts = append(starts, i)
+		} else {
+			current.WriteString(" ")
+			line = strings.TrimLeft(line, " \t")
+		}
+		if body, ok := strings.CutSuffix(line, "\\"); ok {
+			current.WriteString(strings.TrimRight(body, " \t"))
+			continued = true
+			continue
+		}
+		current.WriteString(line)
+		lines = append(lines, current.String())
+		current.Reset()
+		continued = false
+	}
+
+	// A trailing backslash on the last line
+	if continued {
+		lines = append(lines, current.String())
+	}
+
+	return lines, starts
+}
+
+// Make references are masked with private use characters before shell parsing,
+// so $(RM) is one literal word instead of a command substitution running RM.
+// WordText and PrintNode put them back.
+const (
+	maskParen     = '\uE000' // $(
+	maskParenEnd  = '\uE001' // )
+	maskBrace     = '\uE002' // ${
+	maskBraceEnd  = '\uE003' // }
+	maskAutomatic = '\uE004' // $ of $@, $^, ...
+	maskInput     = '\uE005' // $<
+	maskOrderOnly = '\uE006' // $|
+)
+
+var unmaskReferences = strings.NewReplacer(
+	string(maskParen), "$(", string(maskParenEnd), ")",
+	string(maskBrace), "${", string(maskBraceEnd), "}",
+	string(maskAutomatic), "$", string(maskInput), "$<", string(maskOrderOnly), "$|",
+)
+
+// ShellSource converts a recipe line into the script make hands to the shell:
+// leading @, - and + modifiers are removed and $$ becomes $. Make references
+// left in the line ($(DIR), ${RM}, $@) are masked; function calls with
+// arguments like $(if ...) are not.
+func ShellSource(line string) string {
+	line = strings.TrimLeft(line, "@-+ \t")
+
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] != '$' || i+1 == len(line) {
+			sb.WriteByte(line[i])
+			continue
+		}
+
+		next := line[i+1]
+		switch {
+		case next == '$':
+			sb.WriteByte('$')
+			i++
+		case next == '(' || next == '{':
+			end := byte(')')
+			openMask, closeMask := maskParen, maskParenEnd
+			if next == '{' {
+				end, openMask, closeMask = '}', maskBrace, maskBraceEnd
+			}
+			length := strings.IndexFunc(line[i+2:], func(r rune) bool {
+				return !isReferenceChar(r)
+			})
+			if length > 0 && line[i+2+length] == end {
+				sb.WriteRune(openMask)
+				sb.WriteString(line[i+2 : i+2+length])
+				sb.WriteRune(closeMask)
+				i += 2 + length
+				continue
+			}
+			sb.WriteByte('$')
+		case next == '<':
+			sb.WriteRune(maskInput)
+			i++
+		case next == '|':
+			sb.WriteRune(maskOrderOnly)
+			i++
+		case strings.IndexByte("@^+*?%", next) >= 0:
+			sb.WriteRune(maskAutomatic)
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String()
+}
+
+// isReferenceChar reports whether r can be part of a masked variable reference,
+// including substitution references like $(SRC:.c=.o) and $(@D)
+func isReferenceChar(r rune) bool {
+	return !strings.ContainsRune(" \t$(){}\"'#,;&|<>`\\", r)
+}
+
+// ParseShell parses a recipe line as the shell will run it
+// Fails on lines the shell parser can't read, such as unexpanded make
+// functions like $(if ...).
+func ParseShell(line string) (*syntax.File, error) {
+	return syntax.NewParser().Parse(strings.NewReader(ShellSource(line)), "")
+}
+
+// WordText returns a shell word's value with quotes removed
+// Expansions the parser can't resolve ($VAR, $(cmd)) are kept as written.
+func WordText(word *syntax.Word) string {
+	var sb strings.Builder
+	for _, part := range word.Parts {
+		writeWordPart(&sb, part)
+	}
+	return unmaskReferences.Replace(sb.String())
+}
+
+// writeWordPart appends the unquoted text of one part of a word
+func writeWordPart(sb *strings.Builder, part syntax.WordPart) {
+	switch part := part.(type) {
+	case *syntax.Lit:
+		sb.WriteString(part.Value)
+	case *syntax.SglQuoted:
+		sb.WriteString(part.Value)
+	case *syntax.DblQuoted:
+		for _, inner := range part.Parts {
+			writeWordPart(sb, inner)
+		}
+	default:
+		sb.WriteString(PrintNode(part))
+	}
+}
+
+// PrintNode prints a shell syntax node back to source on a single line
+func PrintNode(node syntax.Node) string {
+	var sb strings.Builder
+	if err := syntax.NewPrinter(syntax.SingleLine(true)).Print(&sb, node); err != nil {
+		return ""
+	}
+	return unmaskReferences.Replace(strings.TrimSpace(sb.String()))
+}
@@ -0,0 +1,85 @@
+package makefile
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Suppression is a "# lazymake:allow" annotation that silences safety rules
+//
+// On comment lines before a target (or in the target line's comment), it applies
+// to the whole recipe:
+//
+//	# lazymake:allow git-force-push reason="release branch only"
+//	release:
+//
+// In a recipe, a trailing comment applies to its own line and a comment line
+// applies to the next recipe line:
+//
+//	release:
+//		git push --force origin release # lazymake:allow git-force-push
+type Suppression struct {
+	Rules      []string // Rule IDs to allow
+	Reason     string   // Why the rules are allowed ("" if not given)
+	Line       int      // Makefile line of the annotation
+	RecipeLine int      // Index into Target.Recipe the suppression applies to; -1 for the whole target
+}
+
+// allowPattern finds an annotation in a comment: "# lazymake:allow id1,id2 reason=..."
+var allowPattern = regexp.MustCompile(`#+\s*lazymake:allow(?:\s+(.*))?$`)
+
+// reasonPattern extracts reason="...", reason='...' or reason=word
+var reasonPattern = regexp.MustCompile(`reason=(?:"([^"]*)"|'([^']*)'|(\S+))`)
+
+// Allows returns the suppression covering a rule on a recipe line, if any
+// Rule IDs are compared case-insensitively.
+func (t Target) Allows(ruleID string, recipeLine int) (Suppression, bool) {
+	for _, suppression := range t.Suppressions {
+		if suppression.RecipeLine >= 0 && suppression.RecipeLine != recipeLine {
+			continue
+		}
+		for _, id := range suppression.Rules {
+			if strings.EqualFold(id, ruleID) {
+				return suppression, true
+			}
+		}
+	}
+	return Suppression{}, false
+}
+
+// parseAllow finds an annotation in text and returns it with the text before it
+// found is false when there is no annotation or it names no rules.
+func parseAllow(text string, lineNum, recipeLine int) (suppression Suppression, before string, found bool) {
+	loc := allowPattern.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return Suppression{}, text, false
+	}
+
+	args := ""
+	if loc[2] >= 0 {
+		args = text[loc[2]:loc[3]]
+	}
+
+	reason := ""
+	if m := reasonPattern.FindStringSubmatchIndex(args); m != nil {
+		for group := 1; group <= 3; group++ {
+			if m[2*group] >= 0 {
+				reason = args[m[2*group]:m[2*group+1]]
+				break
+			}
+		}
+		args = args[:m[0]] + args[m[1]:]
+	}
+
+	rules := strings.FieldsFunc(args, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(rules) == 0 {
+		return Suppression{}, text, false
+	}
+
+	return Suppression{
+		Rules:      rules,
+		Reason:     strings.TrimSpace(reason),
+		Line:       lineNum,
+		RecipeLine: recipeLine,
+	}, text[:loc[0]], true
+}
@@ -0,0 +1,141 @@
+// Package project loads a Makefile the way lazymake shows and runs it: its
+// targets, variables and dependency graph, checked against the safety rules.
+// The TUI and the list, run and docs commands share it, so they reach the
+// same verdict for the same Makefile.
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/rshelekhov/lazymake/internal/graph"
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/variables"
+)
+
+// Project is a parsed Makefile with its safety results
+type Project struct {
+	Path      string               // Absolute path, as history is keyed by it
+	Targets   []makefile.Target    // In file order
+	Variables []variables.Variable // Expanded (unless NewStatic), with their usage analyzed
+	Graph     *graph.Graph         // Followed into the Makefiles recursive make runs
+
+	Environment  safety.Environment      // Context the profile was picked for (set by Load)
+	Profile      safety.ProfileSelection // Active safety profile (set by Load)
+	ProfileError error                   // Why the requested profile wasn't used (set by Load)
+
+	Checker *safety.Checker                // nil if safety checks are disabled
+	Results map[string]*safety.CheckResult // By target name, after propagation (nil if disabled)
+}
+
+// Load parses the Makefile at path and checks its targets under the profile
+// picked for its directory (flag, environment variable or context detection)
+// An unknown profile name falls back to the built-in default and is reported
+// in ProfileError.
+func Load(path string, safetyCfg *safety.Config) (*Project, error) {
+	targets, err := makefile.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	environment := safety.DetectEnvironment(filepath.Dir(absPath))
+	selection, profileErr := safety.SelectProfile(safetyCfg, environment)
+
+	p := New(absPath, targets, safetyCfg, selection.Profile)
+	p.Environment = environment
+	p.Profile = selection
+	p.ProfileError = profileErr
+	return p, nil
+}
+
+// New checks the parsed targets of the Makefile at path under profile
+// Variables are expanded with make; recursive make invocations are followed
+// into the Makefiles they run, so a target inherits the dangers of the
+// sub-make targets it calls.
+func New(path string, targets []makefile.Target, safetyCfg *safety.Config, profile *safety.Profile) *Project {
+	return build(path, targets, safetyCfg, profile, true)
+}
+
+// NewStatic is New without running make: variables keep their values as
+// written, so $(shell ...) in the Makefile never runs
+// Used where lazymake reads Makefiles it wasn't asked to run (docs).
+func NewStatic(path string, targets []makefile.Target, safetyCfg *safety.Config, profile *safety.Profile) *Project {
+	return build(path, targets, safetyCfg, profile, false)
+}
+
+// build loads the project, expanding variables with make if expand is set
+func build(path string, targets []makefile.Target, safetyCfg *safety.Config, profile *safety.Profile, expand bool) *Project {
+	p := &Project{
+		Path:      path,
+		Targets:   targets,
+		Variables: loadVariables(path, targets, expand),
+		Graph:     graph.BuildGraph(targets),
+	}
+
+	// Follow $(MAKE) -C dir target into the Makefiles it runs
+	values := safety.VariableValues(p.Variables)
+	p.Graph.LinkSubMakes(path, values)
+
+	p.Checker = newChecker(safetyCfg, profile, values)
+	if p.Checker != nil {
+		p.Results = p.Checker.CheckGraph(p.Graph)
+	}
+	return p
+}
+
+// Target returns a target of the Makefile by name
+func (p *Project) Target(name string) (makefile.Target, bool) {
+	for _, t := range p.Targets {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return makefile.Target{}, false
+}
+
+// loadVariables parses the Makefile's variables, expands them if expand is set
+// and analyzes their usage
+func loadVariables(path string, targets []makefile.Target, expand bool) []variables.Variable {
+	vars, err := variables.ParseVariables(path)
+	if err != nil {
+		// Graceful degradation: without variables, recipes are checked as written
+		return []variables.Variable{}
+	}
+
+	// Expand variables using make
+	if expand {
+		_ = variables.ExpandVariables(path, vars)
+	}
+	// Analyze usage across targets
+	variables.AnalyzeUsage(vars, targets)
+
+	return vars
+}
+
+// newChecker creates a checker that applies the profile and also matches
+// recipes with variables expanded
+// Returns nil if safety checks are disabled.
+func newChecker(safetyCfg *safety.Config, profile *safety.Profile, values map[string]string) *safety.Checker {
+	if safetyCfg == nil {
+		safetyCfg = safety.DefaultConfig()
+	}
+	if !safetyCfg.Enabled {
+		return nil
+	}
+
+	checker, err := safety.NewChecker(safetyCfg)
+	if err != nil {
+		return nil
+	}
+
+	checker.SetVariables(values)
+	checker.UseProfile(profile)
+
+	return checker
+}
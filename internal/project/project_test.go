@@ -0,0 +1,103 @@
+package project
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/testutil"
+)
+
+// TestLoad flags targets that run a dangerous target of another Makefile
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	main := testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"INFRA := infra\n"+
+			"\n"+
+			"delegate:\n"+
+			"\t$(MAKE) -C $(INFRA) destroy\n"+
+			"\n"+
+			"build:\n"+
+			"\tgo build\n")
+	testutil.WriteFile(t, filepath.Join(root, "infra", "Makefile"),
+		"destroy:\n"+
+			"\tterraform destroy -auto-approve\n")
+
+	p, err := Load(main, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Path != main || p.Checker == nil || p.Profile.Profile == nil {
+		t.Fatalf("Load() = %+v, want an absolute path, a checker and a profile", p)
+	}
+	if _, ok := p.Target("delegate"); !ok {
+		t.Error("Target(delegate) not found")
+	}
+
+	result := p.Results["delegate"]
+	if result == nil || !result.IsDangerous || result.DangerLevel != safety.SeverityCritical {
+		t.Fatalf("delegate = %+v, want critical through its sub-make", result)
+	}
+	if len(result.Dependencies) != 1 || result.Dependencies[0].Target() != "infra:destroy" {
+		t.Errorf("delegate dependencies = %+v, want infra:destroy", result.Dependencies)
+	}
+	if p.Results["build"] != nil {
+		t.Errorf("build = %+v, want no result", p.Results["build"])
+	}
+}
+
+// TestLoad_SubMakeVariables expands a sub-Makefile's recipes with its own
+// variables, not the calling Makefile's
+func TestLoad_SubMakeVariables(t *testing.T) {
+	root := t.TempDir()
+	main := testutil.WriteFile(t, filepath.Join(root, "Makefile"),
+		"DEST := /var/lib/app\n"+
+			"\n"+
+			"deploy:\n"+
+			"\t$(MAKE) -C infra wipe\n"+
+			"\n"+
+			"site:\n"+
+			"\t$(MAKE) -C web reset\n")
+	testutil.WriteFile(t, filepath.Join(root, "infra", "Makefile"),
+		"DEST := /etc\n"+
+			"\n"+
+			"wipe:\n"+
+			"\trm -rf $(DEST)\n")
+	testutil.WriteFile(t, filepath.Join(root, "web", "Makefile"),
+		"DEST := dist\n"+
+			"\n"+
+			"reset:\n"+
+			"\trm -rf $(DEST)\n")
+
+	p, err := Load(main, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wipe := p.Results["infra:wipe"]
+	if wipe == nil || len(wipe.Matches) != 1 || wipe.Matches[0].MatchedLine != "rm -rf /etc" {
+		t.Fatalf("infra:wipe = %+v, want rm -rf /etc from its own DEST", wipe)
+	}
+	if p.Results["deploy"] == nil || !p.Results["deploy"].IsDangerous {
+		t.Errorf("deploy = %+v, want dangerous through infra:wipe", p.Results["deploy"])
+	}
+	if p.Results["web:reset"] != nil || p.Results["site"] != nil {
+		t.Errorf("web:reset = %+v, site = %+v, want neither: web's DEST is relative",
+			p.Results["web:reset"], p.Results["site"])
+	}
+}
+
+// TestLoad_Disabled leaves out the checker when safety checks are off
+func TestLoad_Disabled(t *testing.T) {
+	main := testutil.WriteFile(t, filepath.Join(t.TempDir(), "Makefile"), "wipe:\n\trm -rf /\n")
+
+	cfg := safety.DefaultConfig()
+	cfg.Enabled = false
+	p, err := Load(main, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Checker != nil || p.Results != nil {
+		t.Errorf("Load() checker = %v, results = %v, want neither", p.Checker, p.Results)
+	}
+}
@@ -8,8 +8,13 @@ import (
 
 // Checker performs safety checks on Makefile targets
 type Checker struct {
-	rules  []Rule
-	config *Config
+	rules   []Rule
+	config  *Config
+	profile *Profile // Severity adjustment policy (built-in default unless UseProfile is called)
+
+	// Known variable values (Makefile values plus overrides) used to expand recipes
+	variables map[string]string
+	overrides []string // Command-line assignments given to WithOverrides, which sub-makes inherit
 }
 
 // NewChecker creates a new safety checker with the given configuration
@@ -22,11 +27,25 @@ func NewChecker(config *Config) (*Checker, error) {
 	rules := collectRules(config)
 
 	return &Checker{
-		rules:  rules,
-		config: config,
+		rules:   rules,
+		config:  config,
+		profile: DefaultProfile(),
 	}, nil
 }
 
+// UseProfile sets the profile used to adjust severities
+func (c *Checker) UseProfile(profile *Profile) {
+	if profile == nil {
+		profile = DefaultProfile()
+	}
+	c.profile = profile
+}
+
+// Profile returns the active profile
+func (c *Checker) Profile() *Profile {
+	return c.profile
+}
+
 // collectRules gathers all rules from config (built-in + custom)
 // Only includes rules that compile successfully
 func collectRules(config *Config) []Rule {
@@ -61,7 +80,8 @@ func collectRules(config *Config) []Rule {
 }
 
 // CheckTarget performs safety check on a single target
-// Returns nil if target is safe or excluded
+// Returns nil if target is safe or excluded. A target whose matches are all
+// allowed by annotations gets a result with IsDangerous false and Suppressed set.
 func (c *Checker) CheckTarget(target makefile.Target) *CheckResult {
 	// Skip if safety checks disabled
 	if !c.config.Enabled {
@@ -78,45 +98,127 @@ func (c *Checker) CheckTarget(target makefile.Target) *CheckResult {
 		return nil
 	}
 
-	var matches []MatchResult
+	var matches, suppressed []MatchResult
+	var confirmation Confirmation
 	highestSeverity := SeverityInfo
 
+	// Parse the recipe once for all rules
+	lines := c.prepareRecipe(target.Recipe)
+
 	// Check each rule against target's recipe
+	// Allowed lines don't hide other lines of the same rule, and are kept apart
 	for _, rule := range c.rules {
-		if matched, matchedLine := rule.Matches(target.Recipe); matched {
-			// Adjust severity based on context
-			adjustedSeverity := adjustSeverity(target, rule, matchedLine)
-
-			match := MatchResult{
-				Target:      target.Name,
-				Rule:        rule,
-				MatchedLine: matchedLine,
-				Severity:    adjustedSeverity,
+		for _, match := range c.matchRule(target, rule, lines) {
+			if match.Suppression != nil {
+				suppressed = append(suppressed, match)
+				continue
 			}
 			matches = append(matches, match)
+			confirmation = confirmation.Merge(rule.Confirmation)
 
 			// Track highest severity
-			if adjustedSeverity > highestSeverity {
-				highestSeverity = adjustedSeverity
+			if match.Severity > highestSeverity {
+				highestSeverity = match.Severity
 			}
 		}
 	}
 
 	// Return nil if no matches
-	if len(matches) == 0 {
+	if len(matches) == 0 && len(suppressed) == 0 {
 		return nil
 	}
 
+	// Only allowed matches: reported, but the target isn't dangerous
+	if len(matches) == 0 {
+		return &CheckResult{
+			TargetName: target.Name,
+			Suppressed: suppressed,
+		}
+	}
+
 	return &CheckResult{
-		TargetName:  target.Name,
-		IsDangerous: true,
-		DangerLevel: highestSeverity,
-		Matches:     matches,
+		TargetName:   target.Name,
+		IsDangerous:  true,
+		DangerLevel:  highestSeverity,
+		Matches:      matches,
+		Suppressed:   suppressed,
+		Confirmation: confirmation,
+	}
+}
+
+// Confirmation returns how a target must be confirmed: the setting configured
+// for the target merged with its rules' (own and inherited). result may be nil.
+func (c *Checker) Confirmation(name string, result *CheckResult) Confirmation {
+	if !c.config.Enabled {
+		return Confirmation{}
+	}
+
+	confirmation := c.config.TargetConfirmation(name)
+	if result != nil {
+		confirmation = confirmation.Merge(result.Confirmation)
+	}
+	return confirmation
+}
+
+// matchRule checks one rule against every line of a target's recipe, as written
+// and else with variables expanded so `$(RM) -rf $(DEST)` is caught too
+// Every matching line is returned in recipe order; a match allowed by a
+// "# lazymake:allow" annotation has Suppression set.
+func (c *Checker) matchRule(target makefile.Target, rule Rule, lines []preparedLine) []MatchResult {
+	var matches []MatchResult
+
+	for _, line := range lines {
+		for _, expanded := range []bool{false, true} {
+			parsed := line.raw
+			if expanded {
+				parsed = line.expanded
+			}
+			if parsed == nil {
+				continue
+			}
+
+			command, ok := rule.match(parsed)
+			if !ok {
+				continue
+			}
+
+			match := MatchResult{
+				Target:      target.Name,
+				Rule:        rule,
+				MatchedLine: parsed.line,
+				Command:     command,
+				Line:        recipeLineNumber(target, line.index),
+			}
+			if expanded {
+				match.ExpandedFrom = line.raw.line
+				match.Variables = c.responsibleVariables(&rule, line.raw.line, line.variables)
+			}
+			// Adjust severity based on context and the active profile
+			// The whole line is read for context: in `cd /srv/production && rm -rf data`
+			// the production keyword sits in another command than the match.
+			match.Severity = c.profile.adjustSeverity(target, rule, parsed.line)
+
+			if suppression, allowed := target.Allows(rule.ID, line.index); allowed {
+				match.Suppression = &suppression
+			}
+			matches = append(matches, match)
+			break // The line as written is enough
+		}
+	}
+
+	return matches
+}
+
+// recipeLineNumber returns the Makefile line of a target's recipe entry (0 if unknown)
+func recipeLineNumber(target makefile.Target, index int) int {
+	if index < len(target.RecipeLines) {
+		return target.RecipeLines[index]
 	}
+	return 0
 }
 
 // CheckAllTargets performs safety check on all targets
-// Returns map of target name -> result (only includes dangerous targets)
+// Returns map of target name -> result (only includes targets with matches, allowed or not)
 func (c *Checker) CheckAllTargets(targets []makefile.Target) map[string]*CheckResult {
 	results := make(map[string]*CheckResult)
 
@@ -2,10 +2,21 @@ package safety
 
 // Config represents safety checker configuration
 type Config struct {
-	Enabled        bool     // Master switch for safety checks
-	EnabledRules   []string // Which built-in rules to enable (empty = all)
-	ExcludeTargets []string // Targets to skip checking
-	CustomRules    []Rule   // User-defined rules
+	Enabled        bool        // Master switch for safety checks
+	EnabledRules   []string    // Which built-in rules to enable (empty = all)
+	ExcludeTargets []string    // Targets to skip checking
+	CustomRules    []Rule      // User-defined rules (inline and from rule packs)
+	RulePaths      []string    // Rule pack files or directories of *.yaml files, as absolute paths
+	RuleErrors     []RuleError // Custom rules that couldn't be loaded (reported by "lazymake rules test")
+
+	// Safety profiles (see SelectProfile)
+	Profile       string              // Profile to use when no detect rule matches, or the one requested explicitly
+	ProfileSource string              // Set when Profile was requested by flag or env var: detection is skipped
+	Profiles      map[string]*Profile // Named profiles, keyed by lowercase name
+	Detect        []DetectRule        // Ordered rules that pick a profile from the environment
+
+	// Target name or glob -> stronger confirmation for matching targets (see TargetConfirmation)
+	TargetConfirmations map[string]Confirmation
 }
 
 // DefaultConfig returns the default configuration
@@ -15,5 +26,12 @@ func DefaultConfig() *Config {
 		EnabledRules:   nil,  // nil = all built-in rules enabled
 		ExcludeTargets: nil,
 		CustomRules:    nil,
+		RulePaths:      nil,
+		RuleErrors:     nil,
+		Profile:        "", // "" = built-in default profile
+		Profiles:       nil,
+		Detect:         nil,
+
+		TargetConfirmations: nil,
 	}
 }
@@ -0,0 +1,99 @@
+package safety
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// What the user must type before a target can be confirmed
+const (
+	ConfirmTypeTarget      = "target"      // The target name
+	ConfirmTypeEnvironment = "environment" // The environment name (see Environment.Name)
+)
+
+// Confirmation is how a target must be confirmed, beyond a single keypress
+// Set per rule (Rule.Confirmation) or per target (Config.TargetConfirmations).
+// The zero value is the plain confirmation dialog.
+type Confirmation struct {
+	Type          string        // Text to type before confirming: "", "target" or "environment"
+	Countdown     time.Duration // Wait before the confirm key becomes active
+	RequireReason bool          // Ask for a free-text reason, recorded in history and exports
+}
+
+// IsZero reports whether no stronger confirmation is configured
+func (c Confirmation) IsZero() bool {
+	return c.Type == "" && c.Countdown == 0 && !c.RequireReason
+}
+
+// Merge combines two confirmations, keeping the stricter setting of each field
+// When both set a type, the receiver's type wins.
+func (c Confirmation) Merge(other Confirmation) Confirmation {
+	if c.Type == "" {
+		c.Type = other.Type
+	}
+	c.Countdown = max(c.Countdown, other.Countdown)
+	c.RequireReason = c.RequireReason || other.RequireReason
+	return c
+}
+
+// Phrase returns the text the user must type to confirm target, or "" if typing isn't required
+// An environment confirmation falls back to the target name when no environment was detected.
+func (c Confirmation) Phrase(target, environment string) string {
+	switch c.Type {
+	case ConfirmTypeTarget:
+		return target
+	case ConfirmTypeEnvironment:
+		if environment != "" {
+			return environment
+		}
+		return target
+	}
+	return ""
+}
+
+// ParseConfirmType parses a confirmation type: target, environment or "" (no typing)
+func ParseConfirmType(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return "", nil
+	case ConfirmTypeTarget:
+		return ConfirmTypeTarget, nil
+	case ConfirmTypeEnvironment:
+		return ConfirmTypeEnvironment, nil
+	default:
+		return "", fmt.Errorf("invalid confirmation type %q: want target or environment", s)
+	}
+}
+
+// TargetConfirmation returns the confirmation configured for a target
+// Keys of Config.TargetConfirmations are target names or glob patterns
+// (path.Match syntax), matched case-insensitively; every matching entry is merged.
+func (c *Config) TargetConfirmation(name string) Confirmation {
+	// Sorted so the type picked among several matching patterns is stable
+	patterns := make([]string, 0, len(c.TargetConfirmations))
+	for pattern := range c.TargetConfirmations {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var confirmation Confirmation
+	lowerName := strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), lowerName); ok {
+			confirmation = confirmation.Merge(c.TargetConfirmations[pattern])
+		}
+	}
+	return confirmation
+}
+
+// Name returns the name of the deployment environment for typed confirmations:
+// the kube context, else the AWS profile. Returns "" if neither was detected.
+func (e Environment) Name() string {
+	if e.KubeContext != "" {
+		return e.KubeContext
+	}
+	return e.AWSProfile
+}
@@ -7,101 +7,90 @@ import (
 	"github.com/rshelekhov/lazymake/internal/makefile"
 )
 
-// adjustSeverity adjusts rule severity based on target context
+// interactivePattern matches interactive confirmation flags: -i, --interactive
+// Examples: rm -i, git add -i, docker rm -i
+var interactivePattern = regexp.MustCompile(`\s+-\w*i\w*(\s|$)|--interactive`)
+
+// adjustSeverity adjusts rule severity based on target context and the profile's policy
 // Returns the adjusted severity level
-func adjustSeverity(target makefile.Target, rule Rule, matchedLine string) Severity {
+func (p *Profile) adjustSeverity(target makefile.Target, rule Rule, matchedLine string) Severity {
 	severity := rule.Severity
+	if override, ok := p.SeverityOverrides[strings.ToLower(rule.ID)]; ok {
+		severity = override
+	}
 
 	// Clean targets are expected to be destructive
 	// BUT: don't downgrade if target affects databases or production systems
-	if isCleanTarget(target.Name) && !affectsCriticalSystems(target.Name, matchedLine) {
-		// Downgrade severity for clean targets
-		switch severity {
-		case SeverityCritical:
-			severity = SeverityWarning
-		case SeverityWarning:
-			severity = SeverityInfo
-		}
+	if p.downgrades(AdjustClean) && p.isCleanTarget(target.Name) && !p.affectsCriticalSystems(target.Name, matchedLine) {
+		severity = lower(severity)
 	}
 
 	// Commands with interactive confirmation flags are safer
-	if hasInteractiveFlag(matchedLine) {
-		switch severity {
-		case SeverityCritical:
-			severity = SeverityWarning
-		case SeverityWarning:
-			severity = SeverityInfo
-		}
+	if p.downgrades(AdjustInteractive) && hasInteractiveFlag(matchedLine) {
+		severity = lower(severity)
 	}
 
 	// Development/test environments are less risky
-	if isDevelopmentTarget(target.Name) && !containsProductionKeywords(matchedLine) {
+	if p.downgrades(AdjustDevelopment) && p.isDevelopmentTarget(target.Name) && !p.containsProductionKeywords(matchedLine) {
 		// Only downgrade if not explicitly targeting production
 		if severity == SeverityCritical {
 			severity = SeverityWarning
 		}
 	}
 
-	// Note: Removed auto-escalation from Warning to Critical based on production keywords
-	// Rules should be defined with appropriate base severity instead
+	// Escalation is opt-in per profile (e.g. for on-call): by default rules
+	// should be defined with the appropriate base severity instead
+	if p.escalates(AdjustProduction) && p.containsProductionKeywords(matchedLine) {
+		severity = raise(severity)
+	}
 
 	return severity
 }
 
-// isCleanTarget checks if target name suggests cleanup/destructive operations
-func isCleanTarget(name string) bool {
-	cleanKeywords := []string{
-		"clean", "distclean", "purge", "reset", "nuke",
-		"remove", "delete", "wipe", "clear",
+// lower moves a severity one level down (critical → warning → info)
+func lower(severity Severity) Severity {
+	switch severity {
+	case SeverityCritical:
+		return SeverityWarning
+	case SeverityWarning:
+		return SeverityInfo
 	}
-	nameLower := strings.ToLower(name)
-	for _, keyword := range cleanKeywords {
-		if strings.Contains(nameLower, keyword) {
-			return true
-		}
+	return severity
+}
+
+// raise moves a severity one level up (info → warning → critical)
+func raise(severity Severity) Severity {
+	switch severity {
+	case SeverityInfo:
+		return SeverityWarning
+	case SeverityWarning:
+		return SeverityCritical
 	}
-	return false
+	return severity
+}
+
+// isCleanTarget checks if target name suggests cleanup/destructive operations
+func (p *Profile) isCleanTarget(name string) bool {
+	return containsAny(strings.ToLower(name), p.CleanKeywords)
 }
 
 // hasInteractiveFlag checks if command has interactive confirmation flag
 func hasInteractiveFlag(command string) bool {
-	// Common interactive flags: -i, --interactive
-	// Examples: rm -i, git add -i, docker rm -i
-	interactivePattern := regexp.MustCompile(`\s+-\w*i\w*(\s|$)|--interactive`)
 	return interactivePattern.MatchString(command)
 }
 
 // isDevelopmentTarget checks if target name suggests development/testing
-func isDevelopmentTarget(name string) bool {
-	devKeywords := []string{
-		"dev", "develop", "development",
-		"test", "testing",
-		"local", "localhost",
-		"docker", "compose",
-		"demo", "example", "sample",
-	}
-	nameLower := strings.ToLower(name)
-	for _, keyword := range devKeywords {
-		if strings.Contains(nameLower, keyword) {
-			return true
-		}
-	}
-	return false
+func (p *Profile) isDevelopmentTarget(name string) bool {
+	return containsAny(strings.ToLower(name), p.DevelopmentKeywords)
 }
 
 // containsProductionKeywords checks if command targets production
-func containsProductionKeywords(command string) bool {
-	prodKeywords := []string{
-		"prod", "production",
-		"master", "main", // git branches
-		"live",
-		"release",
-	}
+func (p *Profile) containsProductionKeywords(command string) bool {
 	cmdLower := strings.ToLower(command)
-	for _, keyword := range prodKeywords {
+	for _, keyword := range p.ProductionKeywords {
 		// Match whole words to avoid false positives like "produce"
-		pattern := regexp.MustCompile(`\b` + keyword + `\b`)
-		if pattern.MatchString(cmdLower) {
+		pattern := `\b` + regexp.QuoteMeta(strings.ToLower(keyword)) + `\b`
+		if matched, _ := regexp.MatchString(pattern, cmdLower); matched {
 			return true
 		}
 	}
@@ -110,18 +99,14 @@ func containsProductionKeywords(command string) bool {
 
 // affectsCriticalSystems checks if target affects databases or production systems
 // Used to prevent downgrading severity for "clean-like" targets that touch critical data
-func affectsCriticalSystems(targetName, command string) bool {
-	criticalKeywords := []string{
-		"db", "database",
-		"prod", "production",
-		"schema", "migration",
-		"backup", "restore",
-	}
-
-	combined := strings.ToLower(targetName + " " + command)
+func (p *Profile) affectsCriticalSystems(targetName, command string) bool {
+	return containsAny(strings.ToLower(targetName+" "+command), p.CriticalKeywords)
+}
 
-	for _, keyword := range criticalKeywords {
-		if strings.Contains(combined, keyword) {
+// containsAny reports whether text contains any of the keywords (case-insensitive keywords)
+func containsAny(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
 			return true
 		}
 	}
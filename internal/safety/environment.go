@@ -0,0 +1,122 @@
+package safety
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment is the deployment context used to pick a safety profile
+type Environment struct {
+	KubeContext string // Current kubectl context
+	AWSProfile  string // $AWS_PROFILE (or $AWS_DEFAULT_PROFILE)
+	GitBranch   string // Checked-out branch of the repository containing the Makefile
+
+	// Getenv reads environment variables for DetectRule.Env (os.Getenv outside tests)
+	Getenv func(string) string
+}
+
+// DetectEnvironment reads the current context without running external commands
+// dir is the Makefile's directory, used to find the git repository.
+// Anything that can't be read is left empty.
+func DetectEnvironment(dir string) Environment {
+	awsProfile := os.Getenv("AWS_PROFILE")
+	if awsProfile == "" {
+		awsProfile = os.Getenv("AWS_DEFAULT_PROFILE")
+	}
+
+	return Environment{
+		KubeContext: kubeCurrentContext(kubeconfigPath()),
+		AWSProfile:  awsProfile,
+		GitBranch:   gitBranch(dir),
+		Getenv:      os.Getenv,
+	}
+}
+
+// kubeconfigPath returns the first file in $KUBECONFIG, or ~/.kube/config
+func kubeconfigPath() string {
+	if paths := os.Getenv("KUBECONFIG"); paths != "" {
+		return filepath.SplitList(paths)[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+// kubeCurrentContext reads the top-level current-context key of a kubeconfig file
+func kubeCurrentContext(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "current-context:"); ok {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// gitBranch returns the branch checked out in the repository containing dir
+// Returns "" outside a repository or with a detached HEAD.
+func gitBranch(dir string) string {
+	gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return ""
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	branch, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: refs/heads/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+// findGitDir walks up from dir to the repository's git directory
+// Worktrees and submodules use a .git file pointing at the real directory.
+func findGitDir(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		candidate := filepath.Join(dir, ".git")
+		if info, err := os.Stat(candidate); err == nil {
+			if info.IsDir() {
+				return candidate
+			}
+			if data, err := os.ReadFile(candidate); err == nil {
+				if target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:"); ok {
+					target = strings.TrimSpace(target)
+					if !filepath.IsAbs(target) {
+						target = filepath.Join(dir, target)
+					}
+					return target
+				}
+			}
+			return ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
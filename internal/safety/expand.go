@@ -0,0 +1,209 @@
+package safety
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/variables"
+)
+
+// maxExpansionDepth bounds nested expansion so self-referencing values terminate
+const maxExpansionDepth = 10
+
+var (
+	// Matches $(VAR) and ${VAR} references, plus $$ so escaped dollars are left alone
+	variableRefPattern = regexp.MustCompile(`\$\$|\$\(([A-Za-z_][A-Za-z0-9_]*)\)|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+	// Matches command-line variable assignments: VAR=value, VAR:=value, VAR+=value
+	overridePattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(:{0,3}|\+|\?)=(.*)$`)
+)
+
+// makeDefaults holds make's built-in variables that commonly hide dangerous commands
+// Variables are read with --no-builtin-variables, so these apply only when
+// neither the Makefile nor an override defines them.
+var makeDefaults = map[string]string{
+	"RM": "rm -f",
+}
+
+// VariableValues maps the Makefile's variables to the values recipes are expanded with
+// ExpandedValue is empty when make couldn't expand the Makefile; RawValue is used then.
+func VariableValues(vars []variables.Variable) map[string]string {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		if v.ExpandedValue != "" {
+			values[v.Name] = v.ExpandedValue
+		} else {
+			values[v.Name] = v.RawValue
+		}
+	}
+	return values
+}
+
+// SetVariables sets the variable values used to expand recipes before matching
+// Values should be fully expanded (variables.Variable.ExpandedValue).
+func (c *Checker) SetVariables(values map[string]string) {
+	c.variables = maps.Clone(values)
+}
+
+// WithOverrides returns a copy of the checker that expands recipes with
+// command-line overrides from args (e.g. "DEST=/", "NS+=prod") taking precedence
+// over Makefile values. Arguments that aren't assignments are ignored.
+// Returns the checker itself if args contains no overrides.
+func (c *Checker) WithOverrides(args []string) *Checker {
+	overrides := ParseOverrides(args)
+	if len(overrides) == 0 {
+		return c
+	}
+
+	values := maps.Clone(c.variables)
+	if values == nil {
+		values = make(map[string]string)
+	}
+	maps.Copy(values, overrides)
+
+	// Append overrides (VAR+=value) extend the Makefile value
+	for _, arg := range args {
+		if m := overridePattern.FindStringSubmatch(arg); m != nil && m[2] == "+" {
+			if base := c.variables[m[1]]; base != "" {
+				values[m[1]] = base + " " + m[3]
+			}
+		}
+	}
+
+	return &Checker{
+		rules:     c.rules,
+		config:    c.config,
+		profile:   c.profile,
+		variables: values,
+		overrides: append(slices.Clip(c.overrides), args...),
+	}
+}
+
+// withVariables returns a copy of the checker that expands recipes with another
+// Makefile's variables, keeping the command-line overrides: make passes them
+// on to sub-makes
+func (c *Checker) withVariables(values map[string]string) *Checker {
+	sub := &Checker{
+		rules:     c.rules,
+		config:    c.config,
+		profile:   c.profile,
+		variables: maps.Clone(values),
+	}
+	return sub.WithOverrides(c.overrides)
+}
+
+// ParseOverrides extracts variable assignments from make command-line arguments
+// Returns map of variable name -> value (later assignments win)
+func ParseOverrides(args []string) map[string]string {
+	overrides := make(map[string]string)
+	for _, arg := range args {
+		if m := overridePattern.FindStringSubmatch(arg); m != nil {
+			overrides[m[1]] = m[3]
+		}
+	}
+	return overrides
+}
+
+// expansion is a recipe line after substituting known variable values
+type expansion struct {
+	line      string   // Line with known references replaced
+	variables []string // Variables referenced directly by the raw line, in order of first use
+}
+
+// expandLine substitutes known variable values into a recipe line
+// The variable named skip is left unexpanded, which lets callers find out
+// whether a match depends on it. Unknown variables are left as written.
+func (c *Checker) expandLine(line, skip string) expansion {
+	var exp expansion
+	seen := make(map[string]bool)
+
+	exp.line = c.expand(line, skip, 0, func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			exp.variables = append(exp.variables, name)
+		}
+	})
+
+	return exp
+}
+
+// expand replaces references in text, recursing into substituted values
+// onTopLevel is called for each known variable referenced at depth 0.
+func (c *Checker) expand(text, skip string, depth int, onTopLevel func(string)) string {
+	if depth >= maxExpansionDepth {
+		return text
+	}
+
+	return variableRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		if ref == "$$" {
+			return ref
+		}
+
+		name := strings.Trim(ref, "$(){}")
+		if name == skip {
+			return ref
+		}
+
+		value, ok := c.lookup(name)
+		if !ok {
+			return ref
+		}
+
+		if depth == 0 && onTopLevel != nil {
+			onTopLevel(name)
+		}
+		return c.expand(value, skip, depth+1, nil)
+	})
+}
+
+// lookup returns the value of a variable: overrides and Makefile values first, then make's defaults
+func (c *Checker) lookup(name string) (string, bool) {
+	if value, ok := c.variables[name]; ok {
+		return value, true
+	}
+	value, ok := makeDefaults[name]
+	return value, ok
+}
+
+// preparedLine is a recipe line parsed for matching, as written and with variables expanded
+type preparedLine struct {
+	raw       *parsedLine
+	expanded  *parsedLine // nil when expansion doesn't change the line
+	variables []string    // Variables substituted into the expanded line
+	index     int         // Index in the recipe of the line's first physical line
+}
+
+// prepareRecipe parses a recipe's logical lines and their variable expansions
+func (c *Checker) prepareRecipe(recipeLines []string) []preparedLine {
+	joined, starts := makefile.JoinContinuations(recipeLines)
+	lines := make([]preparedLine, len(joined))
+	for i, text := range joined {
+		line := parseLine(text)
+		lines[i].raw = line
+		lines[i].index = starts[i]
+		if exp := c.expandLine(line.line, ""); exp.line != line.line {
+			lines[i].expanded = parseLine(exp.line)
+			lines[i].variables = exp.variables
+		}
+	}
+	return lines
+}
+
+// responsibleVariables returns the variables without which the line would not match
+// When no single variable is decisive (the danger needs several together),
+// all substituted variables are returned.
+func (c *Checker) responsibleVariables(rule *Rule, line string, candidates []string) []string {
+	var responsible []string
+	for _, name := range candidates {
+		if _, matched := rule.match(parseLine(c.expandLine(line, name).line)); !matched {
+			responsible = append(responsible, name)
+		}
+	}
+	if len(responsible) == 0 {
+		return candidates
+	}
+	return responsible
+}
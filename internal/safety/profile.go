@@ -0,0 +1,251 @@
+package safety
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// DefaultProfileName names the built-in profile used when none is configured or selected
+const DefaultProfileName = "default"
+
+// Context adjustments a profile can turn on or off
+const (
+	AdjustClean       = "clean"       // Downgrade: cleanup targets (clean, purge, reset...) one level
+	AdjustInteractive = "interactive" // Downgrade: commands with -i / --interactive one level
+	AdjustDevelopment = "development" // Downgrade: dev/test targets from critical to warning
+	AdjustProduction  = "production"  // Escalate: commands mentioning production keywords one level
+)
+
+// Profile is a named safety policy: how severities are adjusted and when to confirm
+type Profile struct {
+	Name string
+
+	// Keyword sets used by context-aware adjustment
+	CleanKeywords       []string // Substrings of target names that mark cleanup targets
+	DevelopmentKeywords []string // Substrings of target names that mark dev/test targets
+	ProductionKeywords  []string // Whole words in a command that mark production
+	CriticalKeywords    []string // Substrings of target name or command that touch critical data
+
+	Downgrade []string // Enabled downgrades: clean, interactive, development
+	Escalate  []string // Enabled escalations: production
+
+	// Rule ID (lowercase) -> base severity, applied before context adjustment
+	SeverityOverrides map[string]Severity
+
+	Confirm ConfirmPolicy // Which targets need confirmation before running
+}
+
+// ConfirmPolicy decides which targets need confirmation before running
+type ConfirmPolicy struct {
+	MinSeverity Severity // Lowest severity that asks for confirmation
+	Never       bool     // Never ask (e.g. in CI, where nothing is interactive)
+}
+
+// Requires reports whether a target at the given danger level needs confirmation
+func (c ConfirmPolicy) Requires(level Severity) bool {
+	return !c.Never && level >= c.MinSeverity
+}
+
+// RequiresConfirmation reports whether a target must be confirmed before it runs
+// result is the target's safety result (nil if nothing matched). Targets and rules
+// with a stronger confirmation always ask, unless the policy never does.
+func (c ConfirmPolicy) RequiresConfirmation(result *CheckResult, confirmation Confirmation) bool {
+	if c.Never {
+		return false
+	}
+	if !confirmation.IsZero() {
+		return true
+	}
+	return result != nil && result.IsDangerous && c.Requires(result.DangerLevel)
+}
+
+// String returns the policy as written in config: critical, warning, info or never
+func (c ConfirmPolicy) String() string {
+	if c.Never {
+		return "never"
+	}
+	return strings.ToLower(c.MinSeverity.String())
+}
+
+// ParseConfirmPolicy parses a confirm setting: critical, warning, info or never
+func ParseConfirmPolicy(s string) (ConfirmPolicy, error) {
+	if strings.ToLower(s) == "never" {
+		return ConfirmPolicy{Never: true}, nil
+	}
+	severity, err := ParseSeverity(s)
+	if err != nil {
+		return ConfirmPolicy{}, fmt.Errorf("invalid confirm setting %q: want critical, warning, info or never", s)
+	}
+	return ConfirmPolicy{MinSeverity: severity}, nil
+}
+
+// ParseSeverity parses a severity name: critical, warning or info
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "critical":
+		return SeverityCritical, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "info":
+		return SeverityInfo, nil
+	default:
+		return SeverityInfo, fmt.Errorf("invalid severity %q", s)
+	}
+}
+
+// DefaultProfile returns the built-in profile
+// Configured profiles start from these values and replace the fields they set.
+func DefaultProfile() *Profile {
+	return &Profile{
+		Name: DefaultProfileName,
+		CleanKeywords: []string{
+			"clean", "distclean", "purge", "reset", "nuke",
+			"remove", "delete", "wipe", "clear",
+		},
+		DevelopmentKeywords: []string{
+			"dev", "develop", "development",
+			"test", "testing",
+			"local", "localhost",
+			"docker", "compose",
+			"demo", "example", "sample",
+		},
+		ProductionKeywords: []string{
+			"prod", "production",
+			"master", "main", // git branches
+			"live",
+			"release",
+		},
+		CriticalKeywords: []string{
+			"db", "database",
+			"prod", "production",
+			"schema", "migration",
+			"backup", "restore",
+		},
+		Downgrade: []string{AdjustClean, AdjustInteractive, AdjustDevelopment},
+		Escalate:  nil,
+		Confirm:   ConfirmPolicy{MinSeverity: SeverityCritical},
+	}
+}
+
+// downgrades reports whether the profile applies the named downgrade
+func (p *Profile) downgrades(adjustment string) bool {
+	return slices.Contains(p.Downgrade, adjustment)
+}
+
+// escalates reports whether the profile applies the named escalation
+func (p *Profile) escalates(adjustment string) bool {
+	return slices.Contains(p.Escalate, adjustment)
+}
+
+// DetectRule selects a profile when every condition it sets holds
+// Conditions are glob patterns (path.Match syntax) except Env, which names
+// an environment variable that must be set and non-empty.
+type DetectRule struct {
+	Profile     string
+	KubeContext string // e.g. "*prod*"
+	AWSProfile  string // e.g. "prod-*"
+	GitBranch   string // e.g. "release/*"
+	Env         string // e.g. "CI"
+}
+
+// matches reports whether the environment satisfies the rule and describes why
+func (r DetectRule) matches(env Environment) (bool, string) {
+	var reasons []string
+
+	check := func(pattern, value, label string) bool {
+		if pattern == "" {
+			return true
+		}
+		if value == "" {
+			return false
+		}
+		if ok, _ := path.Match(pattern, value); !ok {
+			return false
+		}
+		reasons = append(reasons, label+" "+value)
+		return true
+	}
+
+	if !check(r.KubeContext, env.KubeContext, "kube context") ||
+		!check(r.AWSProfile, env.AWSProfile, "AWS profile") ||
+		!check(r.GitBranch, env.GitBranch, "git branch") {
+		return false, ""
+	}
+
+	if r.Env != "" {
+		if env.Getenv == nil || env.Getenv(r.Env) == "" {
+			return false, ""
+		}
+		reasons = append(reasons, "$"+r.Env)
+	}
+
+	// A rule without conditions never matches: use safety.profile for a fallback
+	if len(reasons) == 0 {
+		return false, ""
+	}
+
+	return true, strings.Join(reasons, ", ")
+}
+
+// ProfileSelection is the active profile and why it was chosen
+type ProfileSelection struct {
+	Profile *Profile
+	Reason  string // "--safety-profile", "kube context prod-east", "config", "" for the built-in default
+}
+
+// ConfirmPolicy returns when the selected profile asks before running a target
+func (s ProfileSelection) ConfirmPolicy() ConfirmPolicy {
+	if s.Profile != nil {
+		return s.Profile.Confirm
+	}
+	return DefaultProfile().Confirm
+}
+
+// SelectProfile picks the active profile
+// Precedence: an explicitly requested profile (flag or env var, in
+// Config.ProfileSource), then the first matching detect rule, then the
+// configured safety.profile, then the built-in default. Returns an error
+// (and the built-in default) if the chosen name isn't defined.
+func SelectProfile(cfg *Config, env Environment) (ProfileSelection, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	name, reason := cfg.Profile, "config"
+	if cfg.ProfileSource != "" {
+		reason = cfg.ProfileSource
+	} else {
+		for _, rule := range cfg.Detect {
+			if ok, why := rule.matches(env); ok {
+				name, reason = rule.Profile, why
+				break
+			}
+		}
+	}
+
+	if name == "" {
+		return ProfileSelection{Profile: DefaultProfile()}, nil
+	}
+
+	profile, err := cfg.LookupProfile(name)
+	if err != nil {
+		return ProfileSelection{Profile: DefaultProfile()}, fmt.Errorf("%s (selected by %s)", err, reason)
+	}
+
+	return ProfileSelection{Profile: profile, Reason: reason}, nil
+}
+
+// LookupProfile returns the named profile
+// "default" resolves to the built-in profile unless the config redefines it.
+func (c *Config) LookupProfile(name string) (*Profile, error) {
+	key := strings.ToLower(name)
+	if profile, ok := c.Profiles[key]; ok {
+		return profile, nil
+	}
+	if key == DefaultProfileName {
+		return DefaultProfile(), nil
+	}
+	return nil, fmt.Errorf("unknown safety profile %q", name)
+}
@@ -0,0 +1,325 @@
+package safety
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rshelekhov/lazymake/internal/graph"
+	"github.com/rshelekhov/lazymake/internal/makefile"
+)
+
+func TestProfile_AdjustSeverity(t *testing.T) {
+	oncall := DefaultProfile()
+	oncall.Name = "prod-oncall"
+	oncall.Downgrade = nil
+	oncall.Escalate = []string{AdjustProduction}
+	oncall.ProductionKeywords = []string{"prod-east"}
+	oncall.SeverityOverrides = map[string]Severity{"docker-system-prune": SeverityInfo}
+
+	tests := []struct {
+		name       string
+		profile    *Profile
+		targetName string
+		ruleID     string
+		severity   Severity
+		command    string
+		expected   Severity
+	}{
+		{
+			name:       "no downgrades for clean targets",
+			profile:    oncall,
+			targetName: "clean",
+			severity:   SeverityCritical,
+			command:    "rm -rf build/",
+			expected:   SeverityCritical,
+		},
+		{
+			name:       "custom production keyword escalates warning",
+			profile:    oncall,
+			targetName: "rollout",
+			severity:   SeverityWarning,
+			command:    "kubectl rollout restart --context prod-east",
+			expected:   SeverityCritical,
+		},
+		{
+			name:       "built-in production keywords replaced",
+			profile:    oncall,
+			targetName: "rollout",
+			severity:   SeverityWarning,
+			command:    "kubectl rollout restart --context production",
+			expected:   SeverityWarning,
+		},
+		{
+			name:       "severity override replaces the rule's base severity",
+			profile:    oncall,
+			targetName: "prune",
+			ruleID:     "docker-system-prune",
+			severity:   SeverityWarning,
+			command:    "docker system prune -af",
+			expected:   SeverityInfo,
+		},
+		{
+			name:       "default profile still downgrades",
+			profile:    DefaultProfile(),
+			targetName: "clean",
+			severity:   SeverityCritical,
+			command:    "rm -rf build/",
+			expected:   SeverityWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{ID: tt.ruleID, Severity: tt.severity}
+			got := tt.profile.adjustSeverity(makefile.Target{Name: tt.targetName}, rule, tt.command)
+			if got != tt.expected {
+				t.Errorf("Expected severity %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	profiles := map[string]*Profile{
+		"local":       {Name: "local"},
+		"ci":          {Name: "ci"},
+		"prod-oncall": {Name: "prod-oncall"},
+	}
+	detect := []DetectRule{
+		{Profile: "prod-oncall", KubeContext: "*prod*"},
+		{Profile: "prod-oncall", GitBranch: "release/*", AWSProfile: "prod-*"},
+		{Profile: "ci", Env: "CI"},
+	}
+	getenv := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		env      Environment
+		expected string
+		reason   string
+		wantErr  bool
+	}{
+		{
+			name:     "nothing configured uses built-in default",
+			cfg:      Config{},
+			expected: DefaultProfileName,
+		},
+		{
+			name:     "configured fallback",
+			cfg:      Config{Profile: "local", Profiles: profiles, Detect: detect},
+			env:      Environment{KubeContext: "minikube", Getenv: getenv(nil)},
+			expected: "local",
+			reason:   "config",
+		},
+		{
+			name:     "kube context detected",
+			cfg:      Config{Profile: "local", Profiles: profiles, Detect: detect},
+			env:      Environment{KubeContext: "gke-prod-east", Getenv: getenv(map[string]string{"CI": "true"})},
+			expected: "prod-oncall",
+			reason:   "kube context gke-prod-east",
+		},
+		{
+			name:     "all conditions of a rule must hold",
+			cfg:      Config{Profile: "local", Profiles: profiles, Detect: detect},
+			env:      Environment{GitBranch: "release/2.0", AWSProfile: "dev", Getenv: getenv(nil)},
+			expected: "local",
+			reason:   "config",
+		},
+		{
+			name:     "env var rule",
+			cfg:      Config{Profiles: profiles, Detect: detect},
+			env:      Environment{Getenv: getenv(map[string]string{"CI": "1"})},
+			expected: "ci",
+			reason:   "$CI",
+		},
+		{
+			name:     "explicit request skips detection",
+			cfg:      Config{Profile: "ci", ProfileSource: "--safety-profile", Profiles: profiles, Detect: detect},
+			env:      Environment{KubeContext: "prod"},
+			expected: "ci",
+			reason:   "--safety-profile",
+		},
+		{
+			name:     "unknown profile falls back with an error",
+			cfg:      Config{Profile: "staging", ProfileSource: "$LAZYMAKE_SAFETY_PROFILE", Profiles: profiles},
+			expected: DefaultProfileName,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection, err := SelectProfile(&tt.cfg, tt.env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if selection.Profile.Name != tt.expected {
+				t.Errorf("profile = %q, want %q", selection.Profile.Name, tt.expected)
+			}
+			if !tt.wantErr && selection.Reason != tt.reason {
+				t.Errorf("reason = %q, want %q", selection.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestConfirmPolicy(t *testing.T) {
+	tests := []struct {
+		setting  string
+		level    Severity
+		expected bool
+	}{
+		{"critical", SeverityCritical, true},
+		{"critical", SeverityWarning, false},
+		{"warning", SeverityWarning, true},
+		{"warning", SeverityInfo, false},
+		{"never", SeverityCritical, false},
+	}
+
+	for _, tt := range tests {
+		policy, err := ParseConfirmPolicy(tt.setting)
+		if err != nil {
+			t.Fatalf("ParseConfirmPolicy(%q): %v", tt.setting, err)
+		}
+		if got := policy.Requires(tt.level); got != tt.expected {
+			t.Errorf("%s.Requires(%v) = %v, want %v", tt.setting, tt.level, got, tt.expected)
+		}
+		if policy.String() != tt.setting {
+			t.Errorf("String() = %q, want %q", policy.String(), tt.setting)
+		}
+	}
+
+	if _, err := ParseConfirmPolicy("sometimes"); err == nil {
+		t.Error("expected an error for an invalid setting")
+	}
+}
+
+func TestConfirmPolicy_RequiresConfirmation(t *testing.T) {
+	policy := ConfirmPolicy{MinSeverity: SeverityCritical}
+	warning := &CheckResult{IsDangerous: true, DangerLevel: SeverityWarning}
+	critical := &CheckResult{IsDangerous: true, DangerLevel: SeverityCritical}
+	typed := Confirmation{Type: ConfirmTypeTarget}
+
+	if policy.RequiresConfirmation(nil, Confirmation{}) || policy.RequiresConfirmation(warning, Confirmation{}) {
+		t.Error("targets below the policy's severity shouldn't ask")
+	}
+	if !policy.RequiresConfirmation(critical, Confirmation{}) {
+		t.Error("critical targets should ask")
+	}
+	if !policy.RequiresConfirmation(nil, typed) {
+		t.Error("targets with a stronger confirmation should always ask")
+	}
+	if (ConfirmPolicy{Never: true}).RequiresConfirmation(critical, typed) {
+		t.Error("a policy that never asks shouldn't ask")
+	}
+}
+
+func TestConfirmation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CustomRules = []Rule{{
+		ID:           "terraform-destroy",
+		Severity:     SeverityCritical,
+		Patterns:     []string{`terraform\s+destroy`},
+		Confirmation: Confirmation{Type: ConfirmTypeEnvironment, Countdown: 3 * time.Second},
+	}}
+	cfg.TargetConfirmations = map[string]Confirmation{
+		"*-prod":      {Countdown: 5 * time.Second, RequireReason: true},
+		"deploy-prod": {Type: ConfirmTypeTarget},
+	}
+
+	checker, err := NewChecker(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+
+	targets := []makefile.Target{
+		{Name: "release", Dependencies: []string{"destroy"}, Recipe: []string{"git tag v1"}},
+		{Name: "destroy", Recipe: []string{"terraform destroy -auto-approve"}},
+		{Name: "Deploy-Prod", Recipe: []string{"./deploy.sh"}},
+	}
+	results := checker.CheckGraph(graph.BuildGraph(targets))
+
+	// Inherited from the dependency's rule
+	release := checker.Confirmation("release", results["release"])
+	if release != (Confirmation{Type: ConfirmTypeEnvironment, Countdown: 3 * time.Second}) {
+		t.Errorf("release confirmation = %+v, want the rule's", release)
+	}
+
+	// Target settings apply to safe targets and merge field by field
+	deploy := checker.Confirmation("Deploy-Prod", results["Deploy-Prod"])
+	want := Confirmation{Type: ConfirmTypeTarget, Countdown: 5 * time.Second, RequireReason: true}
+	if deploy != want {
+		t.Errorf("deploy-prod confirmation = %+v, want %+v", deploy, want)
+	}
+
+	if !checker.Confirmation("build", nil).IsZero() {
+		t.Error("unconfigured target should use the plain dialog")
+	}
+
+	if phrase := release.Phrase("release", "prod-east"); phrase != "prod-east" {
+		t.Errorf("environment phrase = %q, want prod-east", phrase)
+	}
+	if phrase := release.Phrase("release", ""); phrase != "release" {
+		t.Errorf("environment phrase without an environment = %q, want the target name", phrase)
+	}
+	if phrase := (Confirmation{Countdown: time.Second}).Phrase("release", "prod"); phrase != "" {
+		t.Errorf("phrase = %q, want none when typing isn't required", phrase)
+	}
+}
+
+func TestDetectEnvironment(t *testing.T) {
+	home := t.TempDir()
+	kubeconfig := filepath.Join(home, "kubeconfig")
+	writeFile(t, kubeconfig, "apiVersion: v1\ncontexts:\n- name: prod-east\ncurrent-context: \"prod-east\"\n")
+
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/release/2.0\n")
+	sub := filepath.Join(repo, "services", "api")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("KUBECONFIG", kubeconfig)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_DEFAULT_PROFILE", "prod-admin")
+
+	env := DetectEnvironment(sub)
+	if env.KubeContext != "prod-east" {
+		t.Errorf("KubeContext = %q, want prod-east", env.KubeContext)
+	}
+	if env.AWSProfile != "prod-admin" {
+		t.Errorf("AWSProfile = %q, want prod-admin", env.AWSProfile)
+	}
+	if env.GitBranch != "release/2.0" {
+		t.Errorf("GitBranch = %q, want release/2.0", env.GitBranch)
+	}
+
+	// Worktrees point to the git directory through a .git file
+	worktree := t.TempDir()
+	writeFile(t, filepath.Join(worktree, "gitdir", "HEAD"), "ref: refs/heads/feature\n")
+	writeFile(t, filepath.Join(worktree, "checkout", ".git"), "gitdir: ../gitdir\n")
+	if branch := gitBranch(filepath.Join(worktree, "checkout")); branch != "feature" {
+		t.Errorf("worktree branch = %q, want feature", branch)
+	}
+
+	// Detached HEAD has no branch
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "3f2a9c1e\n")
+	if branch := gitBranch(repo); branch != "" {
+		t.Errorf("detached HEAD should have no branch, got %q", branch)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
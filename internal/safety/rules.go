@@ -3,6 +3,10 @@ package safety
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
 )
 
 // Severity represents the danger level of a matched rule
@@ -36,13 +40,40 @@ type Rule struct {
 	Description string   // User-friendly explanation of the danger
 	Suggestion  string   // Optional: safer alternative command
 
+	// Command rules match invocations of Command whose arguments satisfy every predicate
+	Command string         // Command name: "helm", "kubectl" (also found behind sudo, xargs, sh -c)
+	Args    []ArgPredicate // Conditions on the command's arguments
+
+	// Stronger confirmation for targets matching this rule (typed name, countdown, reason)
+	Confirmation Confirmation
+
+	// Example commands checked by "lazymake rules test"
+	Tests RuleTests
+	// Where the rule was defined: a config or rule pack file ("" for built-in rules)
+	Source string
+
 	// Compiled patterns (cached for performance)
 	compiledPatterns []*regexp.Regexp
 }
 
+// ArgPredicate is a condition on a command's arguments
+// Exactly one field should be set.
+type ArgPredicate struct {
+	Has     string // Some argument equals this: "uninstall", "--force"
+	Flag    string // A short flag is present, alone or combined: "f" matches -f and -rf
+	Matches string // Some argument matches this regex: "^--namespace=prod"
+	Without string // No argument equals this: "--dry-run"
+
+	compiled *regexp.Regexp
+}
+
 // Compile compiles the regex patterns for this rule
 // Returns error if any pattern is invalid
 func (r *Rule) Compile() error {
+	if len(r.Patterns) == 0 && r.Command == "" {
+		return fmt.Errorf("rule %s: needs patterns or a command", r.ID)
+	}
+
 	r.compiledPatterns = make([]*regexp.Regexp, len(r.Patterns))
 	for i, pattern := range r.Patterns {
 		re, err := regexp.Compile(pattern)
@@ -51,20 +82,105 @@ func (r *Rule) Compile() error {
 		}
 		r.compiledPatterns[i] = re
 	}
+
+	for i := range r.Args {
+		if err := r.Args[i].compile(); err != nil {
+			return fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// compile validates the predicate and compiles its regex
+func (a *ArgPredicate) compile() error {
+	set := 0
+	for _, field := range []string{a.Has, a.Flag, a.Matches, a.Without} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("argument condition must set exactly one of has, flag, matches, without")
+	}
+
+	if a.Matches != "" {
+		re, err := regexp.Compile(a.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid argument pattern %q: %w", a.Matches, err)
+		}
+		a.compiled = re
+	}
 	return nil
 }
 
+// holds reports whether the arguments satisfy the predicate
+func (a *ArgPredicate) holds(args []string) bool {
+	if a.Without != "" {
+		return !slices.Contains(args, a.Without)
+	}
+
+	for _, arg := range args {
+		switch {
+		case a.Has != "" && arg == a.Has:
+			return true
+		case a.Flag != "" && isShortFlagCluster(arg) && strings.Contains(arg[1:], a.Flag):
+			return true
+		case a.compiled != nil && a.compiled.MatchString(arg):
+			return true
+		}
+	}
+	return false
+}
+
+// isShortFlagCluster reports whether arg is one or more short flags: -f, -rf
+func isShortFlagCluster(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && arg[1] != '-'
+}
+
 // Matches checks if any recipe line matches this rule
+// Lines are parsed as shell, so commands after &&, ;, in subshells or
+// behind sudo/xargs/sh -c are checked individually, while comments and
+// echo'd text are not.
 // Returns: (matched bool, matched line string)
 func (r *Rule) Matches(recipeLines []string) (bool, string) {
-	for _, line := range recipeLines {
+	for _, line := range parseRecipe(recipeLines) {
+		if _, matched := r.match(line); matched {
+			return true, line.line
+		}
+	}
+	return false, ""
+}
+
+// match checks one parsed recipe line against the rule
+// Returns the command text that matched.
+func (r *Rule) match(line *parsedLine) (string, bool) {
+	for _, segment := range line.segments {
 		for _, re := range r.compiledPatterns {
-			if re.MatchString(line) {
-				return true, line
+			if re.MatchString(segment) {
+				return segment, true
 			}
 		}
 	}
-	return false, ""
+
+	if r.Command != "" {
+		for _, command := range line.commands {
+			if command.Name == r.Command && r.argsMatch(command.Args) {
+				return command.text(), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// argsMatch reports whether the arguments satisfy all of the rule's predicates
+func (r *Rule) argsMatch(args []string) bool {
+	for i := range r.Args {
+		if !r.Args[i].holds(args) {
+			return false
+		}
+	}
+	return true
 }
 
 // MatchResult represents a rule match for a specific target
@@ -72,13 +188,50 @@ type MatchResult struct {
 	Target      string   // Target name that matched
 	Rule        Rule     // Matched rule
 	MatchedLine string   // Specific command line that triggered the rule
+	Command     string   // The command within the line that matched: "rm -rf /srv" in "cd /srv && rm -rf /srv"
 	Severity    Severity // Final severity (may be adjusted by context)
+	Line        int      // Makefile line of the matched recipe line (0 if unknown)
+
+	// Set when the rule only matched after variable expansion
+	ExpandedFrom string   // Recipe line as written, e.g. "$(RM) -rf $(DEST)"
+	Variables    []string // Variables whose values produced the dangerous command
+
+	// Set when a "# lazymake:allow" annotation in the Makefile allows the match
+	Suppression *makefile.Suppression
 }
 
 // CheckResult represents the complete safety check for a target
 type CheckResult struct {
 	TargetName  string
-	IsDangerous bool          // Has any matched safety rules
-	DangerLevel Severity      // Highest severity level
-	Matches     []MatchResult // All matched rules for this target
+	IsDangerous bool          // Has any matched safety rules (own or inherited)
+	DangerLevel Severity      // Highest severity level, including dependencies
+	Matches     []MatchResult // All matched rules for this target's own recipe
+	Suppressed  []MatchResult // Matches allowed by annotations (not counted as dangerous)
+
+	// Dangerous prerequisites this target runs transitively (set by CheckGraph)
+	Dependencies []DependencyMatch
+
+	// Strictest confirmation of the matched rules, including dependencies'
+	Confirmation Confirmation
+}
+
+// RuleIDs lists the IDs of the rules the target matched, once each: its own
+// rules first, then those of the dependencies it runs
+func (r *CheckResult) RuleIDs() []string {
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(matches []MatchResult) {
+		for _, match := range matches {
+			if !seen[match.Rule.ID] {
+				seen[match.Rule.ID] = true
+				ids = append(ids, match.Rule.ID)
+			}
+		}
+	}
+
+	add(r.Matches)
+	for _, dep := range r.Dependencies {
+		add(dep.Result.Matches)
+	}
+	return ids
 }
@@ -1,9 +1,15 @@
 package safety
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/rshelekhov/lazymake/internal/graph"
 	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/variables"
 )
 
 func TestRuleMatching(t *testing.T) {
@@ -442,6 +448,231 @@ func TestRuleMatching(t *testing.T) {
 	}
 }
 
+func TestRuleMatching_ShellAware(t *testing.T) {
+	tests := []struct {
+		name        string
+		ruleID      string
+		recipe      []string
+		shouldMatch bool
+	}{
+		{
+			name:        "command after &&",
+			ruleID:      "kubectl-delete",
+			recipe:      []string{"cd deploy && kubectl delete ns staging"},
+			shouldMatch: true,
+		},
+		{
+			name:        "command in a subshell",
+			ruleID:      "git-reset-hard",
+			recipe:      []string{"(cd vendor; git reset --hard)"},
+			shouldMatch: true,
+		},
+		{
+			name:        "sh -c script",
+			ruleID:      "database-drop",
+			recipe:      []string{`sh -c "psql -c 'DROP DATABASE app;'"`},
+			shouldMatch: true,
+		},
+		{
+			name:        "bash -ec script behind sudo",
+			ruleID:      "terraform-destroy",
+			recipe:      []string{"sudo -u deploy bash -ec 'terraform destroy -auto-approve'"},
+			shouldMatch: true,
+		},
+		{
+			name:        "line continuation",
+			ruleID:      "docker-system-prune",
+			recipe:      []string{"docker system \\", "\tprune -af"},
+			shouldMatch: true,
+		},
+		{
+			name:        "make modifiers and escaped dollar",
+			ruleID:      "rm-rf-root",
+			recipe:      []string{"@-rm -rf $$HOME"},
+			shouldMatch: true,
+		},
+		{
+			name:        "echo text is not run",
+			ruleID:      "rm-rf-root",
+			recipe:      []string{`@echo "never run rm -rf / on a server"`},
+			shouldMatch: false,
+		},
+		{
+			name:        "comment is not run",
+			ruleID:      "git-force-push",
+			recipe:      []string{"git push origin main # use git push -f only if you must"},
+			shouldMatch: false,
+		},
+		{
+			name:        "echo piped to a shell is run",
+			ruleID:      "database-drop",
+			recipe:      []string{`echo "DROP DATABASE app;" | psql`},
+			shouldMatch: true,
+		},
+		{
+			name:        "echo redirect still counts",
+			ruleID:      "env-file-overwrite",
+			recipe:      []string{"echo SECRET=1 > .env"},
+			shouldMatch: true,
+		},
+		{
+			name:        "pipeline rules see the whole pipeline",
+			ruleID:      "curl-pipe-shell",
+			recipe:      []string{"curl -fsSL https://example.com/install.sh | sudo bash"},
+			shouldMatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := GetBuiltinRuleByID(tt.ruleID)
+			if rule == nil {
+				t.Fatalf("Rule %s not found", tt.ruleID)
+			}
+			if err := rule.Compile(); err != nil {
+				t.Fatalf("Failed to compile rule: %v", err)
+			}
+
+			if matched, _ := rule.Matches(tt.recipe); matched != tt.shouldMatch {
+				t.Errorf("Expected match=%v, got=%v for recipe %q", tt.shouldMatch, matched, tt.recipe)
+			}
+		})
+	}
+}
+
+func TestParseLine_MakeReferences(t *testing.T) {
+	line := parseLine("$(RM) -rf $(DIR) && cp $< ${OUT}/$@")
+
+	want := []Command{
+		{Name: "$(RM)", Args: []string{"-rf", "$(DIR)"}},
+		{Name: "cp", Args: []string{"$<", "${OUT}/$@"}},
+	}
+	if !slices.EqualFunc(line.commands, want, func(a, b Command) bool {
+		return a.Name == b.Name && slices.Equal(a.Args, b.Args)
+	}) {
+		t.Errorf("commands = %+v, want %+v", line.commands, want)
+	}
+	if !slices.Equal(line.segments, []string{"$(RM) -rf $(DIR)", "cp $< ${OUT}/$@"}) {
+		t.Errorf("segments = %q", line.segments)
+	}
+
+	// Make references aren't command substitutions running RM or DIR
+	rule := Rule{ID: "dir", Command: "DIR"}
+	if err := rule.Compile(); err != nil {
+		t.Fatalf("Failed to compile rule: %v", err)
+	}
+	if matched, _ := rule.Matches([]string{"$(RM) -rf $(DIR)"}); matched {
+		t.Error("$(DIR) should not match a rule for the DIR command")
+	}
+}
+
+func TestCommandRule(t *testing.T) {
+	rule := Rule{
+		ID:       "rm-recursive-force",
+		Severity: SeverityWarning,
+		Command:  "rm",
+		Args: []ArgPredicate{
+			{Flag: "r"},
+			{Flag: "f"},
+			{Without: "--interactive"},
+		},
+	}
+	if err := rule.Compile(); err != nil {
+		t.Fatalf("Failed to compile rule: %v", err)
+	}
+
+	tests := []struct {
+		recipe      string
+		shouldMatch bool
+	}{
+		{"rm -rf build", true},
+		{"rm -r -f build", true},
+		{"find . -name '*.o' | xargs -n 10 rm -rf", true},
+		{"/bin/rm -fr tmp", true},
+		{"env LANG=C rm -rf out", true},
+		{"rm -f build/app", false},
+		{"rm -rf --interactive build", false},
+		{"echo rm -rf build", false},
+	}
+
+	for _, tt := range tests {
+		if matched, _ := rule.Matches([]string{tt.recipe}); matched != tt.shouldMatch {
+			t.Errorf("%q: expected match=%v, got=%v", tt.recipe, tt.shouldMatch, matched)
+		}
+	}
+
+	helm := Rule{ID: "helm-prod", Command: "helm", Args: []ArgPredicate{
+		{Has: "uninstall"},
+		{Matches: "^--namespace=prod"},
+	}}
+	if err := helm.Compile(); err != nil {
+		t.Fatalf("Failed to compile rule: %v", err)
+	}
+	if matched, _ := helm.Matches([]string{"helm uninstall api --namespace=production"}); !matched {
+		t.Error("expected helm uninstall in production to match")
+	}
+	if matched, _ := helm.Matches([]string{"helm uninstall api --namespace=staging"}); matched {
+		t.Error("staging namespace should not match")
+	}
+}
+
+func TestRuleCompile_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"no patterns or command", Rule{ID: "empty"}},
+		{"predicate with two conditions", Rule{ID: "two", Command: "rm", Args: []ArgPredicate{{Has: "-r", Flag: "f"}}}},
+		{"empty predicate", Rule{ID: "none", Command: "rm", Args: []ArgPredicate{{}}}},
+		{"invalid argument regex", Rule{ID: "regex", Command: "rm", Args: []ArgPredicate{{Matches: "("}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Compile(); err == nil {
+				t.Error("expected a compile error")
+			}
+		})
+	}
+}
+
+func TestRuleTest(t *testing.T) {
+	rule := Rule{
+		ID:       "prod-kubectl-delete",
+		Patterns: []string{`kubectl\s+delete\b.*--context[= ]prod`},
+		Tests: RuleTests{
+			Match: []string{
+				"kubectl delete ns app --context prod",
+				"cd infra && kubectl delete deploy api --context=prod",
+				"kubectl get pods --context prod", // wrong: not a delete
+			},
+			NoMatch: []string{
+				`echo "kubectl delete ns app --context prod"`,
+				"kubectl delete ns app --context prod-replica", // wrong: matches the prefix
+			},
+		},
+	}
+
+	failures, err := rule.Test()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 failed examples, got %+v", failures)
+	}
+	if !failures[0].WantMatch || failures[0].Line != "kubectl get pods --context prod" {
+		t.Errorf("unexpected first failure %+v", failures[0])
+	}
+	if failures[1].WantMatch || failures[1].Line != "kubectl delete ns app --context prod-replica" {
+		t.Errorf("unexpected second failure %+v", failures[1])
+	}
+
+	invalid := Rule{ID: "broken", Patterns: []string{"("}}
+	if _, err := invalid.Test(); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("expected an invalid pattern error, got %v", err)
+	}
+}
+
 func TestContextAwareSeverityAdjustment(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -504,7 +735,7 @@ func TestContextAwareSeverityAdjustment(t *testing.T) {
 				Severity: tt.originalSeverity,
 			}
 
-			adjusted := adjustSeverity(target, rule, tt.matchedLine)
+			adjusted := DefaultProfile().adjustSeverity(target, rule, tt.matchedLine)
 
 			if adjusted != tt.expectedSeverity {
 				t.Errorf("Expected severity %v, got %v",
@@ -514,6 +745,29 @@ func TestContextAwareSeverityAdjustment(t *testing.T) {
 	}
 }
 
+// TestContextAwareSeverityAdjustment_WholeLine reads context from the whole
+// recipe line, not only the command that matched
+func TestContextAwareSeverityAdjustment_WholeLine(t *testing.T) {
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+
+	result := checker.CheckTarget(makefile.Target{
+		Name:   "test-teardown",
+		Recipe: []string{"cd infra/production && terraform destroy -auto-approve"},
+	})
+	if result == nil || len(result.Matches) == 0 {
+		t.Fatal("Expected terraform destroy to match")
+	}
+	if result.Matches[0].Command != "terraform destroy -auto-approve" {
+		t.Errorf("Expected the terraform command to match, got %q", result.Matches[0].Command)
+	}
+	if result.DangerLevel != SeverityCritical {
+		t.Errorf("Expected critical (production in the cd), got %v", result.DangerLevel)
+	}
+}
+
 func TestCheckerIntegration(t *testing.T) {
 	// Create test targets
 	targets := []makefile.Target{
@@ -593,6 +847,399 @@ func TestExcludeTargets(t *testing.T) {
 	}
 }
 
+func TestCheckGraph_PropagatesThroughDependencies(t *testing.T) {
+	targets := []makefile.Target{
+		{Name: "deploy", Dependencies: []string{"release"}, Recipe: []string{"./deploy.sh"}},
+		{Name: "release", Dependencies: []string{"build", "db-reset"}, Recipe: []string{"git tag v1"}},
+		{Name: "build", Recipe: []string{"go build -o app"}},
+		{Name: "db-reset", Dependencies: []string{"clean"}, Recipe: []string{"psql -c 'DROP DATABASE app;'"}},
+		{Name: "clean", Recipe: []string{"rm -rf /tmp/build"}},
+	}
+
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+
+	results := checker.CheckGraph(graph.BuildGraph(targets))
+
+	if _, found := results["build"]; found {
+		t.Error("build should not be flagged")
+	}
+
+	release, found := results["release"]
+	if !found {
+		t.Fatal("release should inherit db-reset's danger")
+	}
+	if release.DangerLevel != SeverityCritical {
+		t.Errorf("release should be critical, got %v", release.DangerLevel)
+	}
+	if len(release.Matches) != 0 {
+		t.Errorf("release's own recipe is safe, got %d matches", len(release.Matches))
+	}
+	if len(release.Dependencies) != 2 {
+		t.Fatalf("expected db-reset and clean as dangerous dependencies, got %d", len(release.Dependencies))
+	}
+
+	// Most severe first, with the path that pulls it in
+	first := release.Dependencies[0]
+	if first.Target() != "db-reset" || strings.Join(first.Path, " ") != "release db-reset" {
+		t.Errorf("expected db-reset via [release db-reset], got %v", first.Path)
+	}
+	second := release.Dependencies[1]
+	if strings.Join(second.Path, " ") != "release db-reset clean" {
+		t.Errorf("expected clean via [release db-reset clean], got %v", second.Path)
+	}
+	if second.Result.DangerLevel != SeverityWarning {
+		t.Errorf("clean's own severity should stay warning, got %v", second.Result.DangerLevel)
+	}
+
+	deploy, found := results["deploy"]
+	if !found || deploy.DangerLevel != SeverityCritical {
+		t.Fatal("deploy should inherit critical severity through release")
+	}
+	if strings.Join(deploy.Dependencies[0].Path, " ") != "deploy release db-reset" {
+		t.Errorf("expected path [deploy release db-reset], got %v", deploy.Dependencies[0].Path)
+	}
+
+	// db-reset keeps its own matches and also lists clean
+	dbReset := results["db-reset"]
+	if dbReset == nil || len(dbReset.Matches) == 0 || len(dbReset.Dependencies) != 1 {
+		t.Errorf("db-reset should have own matches and one dangerous dependency, got %+v", dbReset)
+	}
+}
+
+func TestCheckResult_RuleIDs(t *testing.T) {
+	rmRoot := MatchResult{Rule: Rule{ID: "rm-rf-root"}}
+	forcePush := MatchResult{Rule: Rule{ID: "git-force-push"}}
+	dbDrop := MatchResult{Rule: Rule{ID: "database-drop"}}
+
+	result := &CheckResult{
+		Matches: []MatchResult{rmRoot, rmRoot},
+		Dependencies: []DependencyMatch{
+			{Path: []string{"release", "push"}, Result: &CheckResult{Matches: []MatchResult{forcePush, rmRoot}}},
+			{Path: []string{"release", "reset"}, Result: &CheckResult{Matches: []MatchResult{dbDrop}}},
+		},
+	}
+
+	got := strings.Join(result.RuleIDs(), " ")
+	if want := "rm-rf-root git-force-push database-drop"; got != want {
+		t.Errorf("RuleIDs() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckGraph_ExcludedAndCycles(t *testing.T) {
+	targets := []makefile.Target{
+		{Name: "all", Dependencies: []string{"release"}},
+		{Name: "release", Dependencies: []string{"teardown"}},
+		{Name: "teardown", Dependencies: []string{"release"}, Recipe: []string{"sudo rm -rf /var/lib/app"}},
+	}
+
+	config := &Config{Enabled: true, ExcludeTargets: []string{"release"}}
+	checker, err := NewChecker(config)
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+
+	results := checker.CheckGraph(graph.BuildGraph(targets))
+
+	if _, found := results["release"]; found {
+		t.Error("excluded target should not inherit")
+	}
+	if all, found := results["all"]; !found || all.DangerLevel != SeverityCritical {
+		t.Error("all should inherit teardown's danger through the excluded release target")
+	}
+	if teardown, found := results["teardown"]; !found || len(teardown.Dependencies) != 0 {
+		t.Errorf("teardown should be flagged for its own recipe only (cycle back to itself ignored), got %+v", teardown)
+	}
+}
+
+func TestCheckGraph_PropagatesThroughSubMakes(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "Makefile")
+	if err := os.WriteFile(main, []byte("build:\n\t$(MAKE) -C sub build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sub := "build: wipe\n\tgo build\n\nwipe:\n\trm -rf /srv/data\n"
+	if err := os.WriteFile(filepath.Join(dir, "sub", "Makefile"), []byte(sub), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := makefile.Parse(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := graph.BuildGraph(targets)
+	g.LinkSubMakes(main, nil)
+
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+	results := checker.CheckGraph(g)
+
+	build, found := results["build"]
+	if !found || !build.IsDangerous {
+		t.Fatal("build should inherit the danger of the sub-Makefile target it runs")
+	}
+	if len(build.Dependencies) != 1 || strings.Join(build.Dependencies[0].Path, " ") != "build sub:build sub:wipe" {
+		t.Errorf("expected sub:wipe via [build sub:build sub:wipe], got %+v", build.Dependencies)
+	}
+}
+
+func TestVariableValues(t *testing.T) {
+	values := VariableValues([]variables.Variable{
+		{Name: "DEST", RawValue: "$(ROOT)/data", ExpandedValue: "/srv/data"},
+		{Name: "OUT", RawValue: "build"},
+	})
+	if values["DEST"] != "/srv/data" {
+		t.Errorf("DEST = %q, want the expanded value", values["DEST"])
+	}
+	if values["OUT"] != "build" {
+		t.Errorf("OUT = %q, want the raw value when make couldn't expand it", values["OUT"])
+	}
+}
+
+func TestCheckTarget_ExpandsVariables(t *testing.T) {
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+	checker.SetVariables(map[string]string{
+		"DEST":    "/opt/app",
+		"KUBECTL": "kubectl",
+		"NS":      "payments",
+		"OUT":     "build",
+	})
+
+	tests := []struct {
+		name      string
+		recipe    string
+		ruleID    string // Empty if the recipe should stay safe
+		expanded  string
+		variables []string
+	}{
+		{
+			name:      "RM uses make's default, both variables are needed",
+			recipe:    "$(RM) -rf $(DEST)",
+			ruleID:    "rm-rf-root",
+			expanded:  "rm -f -rf /opt/app",
+			variables: []string{"RM", "DEST"},
+		},
+		{
+			name:      "only the decisive variable is reported",
+			recipe:    "rm -rf $(DEST)",
+			ruleID:    "rm-rf-root",
+			expanded:  "rm -rf /opt/app",
+			variables: []string{"DEST"},
+		},
+		{
+			name:      "command hidden in a variable",
+			recipe:    "${KUBECTL} delete ns $(NS)",
+			ruleID:    "kubectl-delete",
+			expanded:  "kubectl delete ns payments",
+			variables: []string{"KUBECTL"},
+		},
+		{
+			name:   "harmless expansion",
+			recipe: "$(RM) -rf $(OUT)",
+		},
+		{
+			name:   "escaped dollar is left to the shell",
+			recipe: "echo $$(DEST)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.CheckTarget(makefile.Target{Name: "release", Recipe: []string{tt.recipe}})
+			if tt.ruleID == "" {
+				if result != nil {
+					t.Fatalf("expected no matches, got %+v", result.Matches)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatalf("expected %s to match after expansion", tt.ruleID)
+			}
+
+			var match *MatchResult
+			for i := range result.Matches {
+				if result.Matches[i].Rule.ID == tt.ruleID {
+					match = &result.Matches[i]
+				}
+			}
+			if match == nil {
+				t.Fatalf("expected rule %s, got %+v", tt.ruleID, result.Matches)
+			}
+			if match.MatchedLine != tt.expanded {
+				t.Errorf("MatchedLine = %q, want %q", match.MatchedLine, tt.expanded)
+			}
+			if match.ExpandedFrom != tt.recipe {
+				t.Errorf("ExpandedFrom = %q, want %q", match.ExpandedFrom, tt.recipe)
+			}
+			if strings.Join(match.Variables, ",") != strings.Join(tt.variables, ",") {
+				t.Errorf("Variables = %v, want %v", match.Variables, tt.variables)
+			}
+		})
+	}
+}
+
+func TestCheckTarget_RawMatchIsNotAttributedToVariables(t *testing.T) {
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+	checker.SetVariables(map[string]string{"DIR": "/srv"})
+
+	result := checker.CheckTarget(makefile.Target{Name: "wipe-all", Recipe: []string{"sudo rm -rf $(DIR)"}})
+	if result == nil {
+		t.Fatal("expected sudo rm -rf to match")
+	}
+	if match := result.Matches[0]; match.ExpandedFrom != "" || len(match.Variables) != 0 {
+		t.Errorf("raw match should not report expansion, got %+v", match)
+	}
+}
+
+func TestCheckTarget_RecordsLineNumbers(t *testing.T) {
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+
+	// A continued line is reported at the line where it starts
+	target := makefile.Target{
+		Name:        "nuke",
+		Recipe:      []string{"echo start", "sudo rm -rf \\", "  /", "echo done"},
+		RecipeLines: []int{12, 13, 14, 15},
+	}
+
+	result := checker.CheckTarget(target)
+	if result == nil || len(result.Matches) == 0 {
+		t.Fatal("expected nuke to be flagged")
+	}
+	if line := result.Matches[0].Line; line != 13 {
+		t.Errorf("expected match on line 13, got %d", line)
+	}
+
+	// Targets built without positions report line 0
+	target.RecipeLines = nil
+	if line := checker.CheckTarget(target).Matches[0].Line; line != 0 {
+		t.Errorf("expected line 0 without positions, got %d", line)
+	}
+}
+
+func TestCheckTarget_Suppressions(t *testing.T) {
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+
+	allowPush := makefile.Suppression{Rules: []string{"git-force-push"}, Reason: "release branch only", Line: 3, RecipeLine: -1}
+
+	// Allowed on the whole target: reported, but not dangerous
+	target := makefile.Target{
+		Name:         "release",
+		Recipe:       []string{"git push --force origin release"},
+		Suppressions: []makefile.Suppression{allowPush},
+	}
+	result := checker.CheckTarget(target)
+	if result == nil {
+		t.Fatal("expected a result listing the allowed match")
+	}
+	if result.IsDangerous || len(result.Matches) != 0 {
+		t.Errorf("allowed target should not be dangerous, got %+v", result.Matches)
+	}
+	if len(result.Suppressed) != 1 || result.Suppressed[0].Suppression.Reason != "release branch only" {
+		t.Fatalf("expected the allowed match with its reason, got %+v", result.Suppressed)
+	}
+
+	// Other rules still apply
+	target.Recipe = append(target.Recipe, "sudo rm -rf /srv/app")
+	result = checker.CheckTarget(target)
+	if result == nil || !result.IsDangerous || len(result.Matches) != 1 || result.Matches[0].Rule.ID != "rm-rf-root" {
+		t.Errorf("expected rm-rf-root to still be flagged, got %+v", result)
+	}
+
+	// A line suppression covers only its line: a later line still matches,
+	// and the allowed line is still listed with its reason
+	target = makefile.Target{
+		Name:   "push",
+		Recipe: []string{"git push --force origin release", "git push --force origin main"},
+		Suppressions: []makefile.Suppression{
+			{Rules: []string{"GIT-FORCE-PUSH"}, Reason: "release branch only", RecipeLine: 0},
+		},
+	}
+	result = checker.CheckTarget(target)
+	if result == nil || !result.IsDangerous || len(result.Matches) != 1 || result.Matches[0].MatchedLine != "git push --force origin main" {
+		t.Fatalf("expected the unannotated line to match, got %+v", result)
+	}
+	if len(result.Suppressed) != 1 || result.Suppressed[0].MatchedLine != "git push --force origin release" ||
+		result.Suppressed[0].Suppression.Reason != "release branch only" {
+		t.Errorf("expected the annotated line as an allowed match, got %+v", result.Suppressed)
+	}
+}
+
+func TestCheckGraph_SuppressedDependenciesArentInherited(t *testing.T) {
+	targets := []makefile.Target{
+		{Name: "ship", Dependencies: []string{"release"}, Recipe: []string{"echo shipped"}},
+		{
+			Name:         "release",
+			Recipe:       []string{"git push --force origin release"},
+			Suppressions: []makefile.Suppression{{Rules: []string{"git-force-push"}, RecipeLine: -1}},
+		},
+	}
+
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+
+	results := checker.CheckGraph(graph.BuildGraph(targets))
+	if _, found := results["ship"]; found {
+		t.Error("ship should not inherit an allowed match")
+	}
+	if release := results["release"]; release == nil || release.IsDangerous || len(release.Suppressed) != 1 {
+		t.Errorf("release should list its allowed match without being dangerous, got %+v", release)
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	checker, err := NewChecker(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create checker: %v", err)
+	}
+	checker.SetVariables(map[string]string{"DEST": "build", "FLAGS": "-v"})
+
+	target := makefile.Target{Name: "install", Recipe: []string{"$(RM) -rf $(DEST)"}}
+	if result := checker.CheckTarget(target); result != nil {
+		t.Fatalf("DEST=build should be safe, got %+v", result.Matches)
+	}
+
+	overridden := checker.WithOverrides([]string{"-k", "DEST:=/usr/local", "FLAGS+=-x"})
+	result := overridden.CheckTarget(target)
+	if result == nil {
+		t.Fatal("DEST=/usr/local override should be flagged")
+	}
+	if match := result.Matches[0]; match.MatchedLine != "rm -f -rf /usr/local" || strings.Join(match.Variables, ",") != "RM,DEST" {
+		t.Errorf("unexpected match %+v", match)
+	}
+	if got := overridden.variables["FLAGS"]; got != "-v -x" {
+		t.Errorf("append override should extend the Makefile value, got %q", got)
+	}
+
+	// The original checker is unchanged
+	if result := checker.CheckTarget(target); result != nil {
+		t.Error("WithOverrides must not modify the original checker")
+	}
+	if checker.WithOverrides([]string{"-k"}) != checker {
+		t.Error("args without assignments should return the same checker")
+	}
+}
+
 func TestSeverityString(t *testing.T) {
 	tests := []struct {
 		severity Severity
@@ -629,7 +1276,7 @@ func TestIsCleanTarget(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := isCleanTarget(tt.name); got != tt.expected {
+			if got := DefaultProfile().isCleanTarget(tt.name); got != tt.expected {
 				t.Errorf("isCleanTarget(%q) = %v, want %v", tt.name, got, tt.expected)
 			}
 		})
@@ -653,7 +1300,7 @@ func TestIsDevelopmentTarget(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := isDevelopmentTarget(tt.name); got != tt.expected {
+			if got := DefaultProfile().isDevelopmentTarget(tt.name); got != tt.expected {
 				t.Errorf("isDevelopmentTarget(%q) = %v, want %v", tt.name, got, tt.expected)
 			}
 		})
@@ -675,7 +1322,7 @@ func TestContainsProductionKeywords(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.command, func(t *testing.T) {
-			if got := containsProductionKeywords(tt.command); got != tt.expected {
+			if got := DefaultProfile().containsProductionKeywords(tt.command); got != tt.expected {
 				t.Errorf("containsProductionKeywords(%q) = %v, want %v", tt.command, got, tt.expected)
 			}
 		})
@@ -0,0 +1,60 @@
+package safety
+
+import "fmt"
+
+// RuleTests are example recipe lines a rule must and must not match
+// They are checked by "lazymake rules test", so rule packs can be verified in CI.
+type RuleTests struct {
+	Match   []string // Lines the rule must flag
+	NoMatch []string // Lines the rule must not flag
+}
+
+// Count returns the number of examples
+func (t RuleTests) Count() int {
+	return len(t.Match) + len(t.NoMatch)
+}
+
+// RuleError is a custom rule that couldn't be loaded
+type RuleError struct {
+	Source string // Config or rule pack file
+	RuleID string // "" when the whole file couldn't be read
+	Err    error  // Names the rule, like Rule.Compile errors: "rule prod-deploy: invalid pattern ..."
+}
+
+func (e RuleError) Error() string {
+	if e.Source == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+func (e RuleError) Unwrap() error {
+	return e.Err
+}
+
+// RuleTestFailure is an example a rule got wrong
+type RuleTestFailure struct {
+	Line      string // The example recipe line
+	WantMatch bool   // true: the rule should have matched but didn't
+}
+
+// Test runs the rule against its examples
+// Returns an error if the rule doesn't compile, otherwise the failed examples.
+func (r *Rule) Test() ([]RuleTestFailure, error) {
+	if err := r.Compile(); err != nil {
+		return nil, err
+	}
+
+	var failures []RuleTestFailure
+	for _, line := range r.Tests.Match {
+		if matched, _ := r.Matches([]string{line}); !matched {
+			failures = append(failures, RuleTestFailure{Line: line, WantMatch: true})
+		}
+	}
+	for _, line := range r.Tests.NoMatch {
+		if matched, _ := r.Matches([]string{line}); matched {
+			failures = append(failures, RuleTestFailure{Line: line, WantMatch: false})
+		}
+	}
+	return failures, nil
+}
@@ -0,0 +1,216 @@
+package safety
+
+import (
+	"path"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// maxNestingDepth bounds recursion into `sh -c '...'` and `eval` scripts
+const maxNestingDepth = 5
+
+// Command is a single command invocation found in a recipe line
+type Command struct {
+	Name string   // Command name without directory: "rm", "kubectl"
+	Args []string // Arguments with shell quoting removed
+}
+
+// parsedLine is a logical recipe line broken into the commands it runs
+type parsedLine struct {
+	line string // Recipe line as make sees it (continuations joined)
+
+	// Text matched by pattern rules: each simple command and each pipeline,
+	// printed from the syntax tree, plus commands hidden behind sudo, xargs,
+	// env or sh -c. Comments are dropped and echo/printf arguments are
+	// left out unless their output is piped to another command.
+	segments []string
+
+	// Every command invocation, used by command rules
+	commands []Command
+}
+
+// printOnlyCommands only print their arguments, so text inside them is never run
+var printOnlyCommands = map[string]bool{
+	"echo":   true,
+	"printf": true,
+}
+
+// shellCommands run their -c argument as a script
+var shellCommands = map[string]bool{
+	"sh":   true,
+	"bash": true,
+	"zsh":  true,
+	"dash": true,
+	"ksh":  true,
+}
+
+// wrapperOptionsWithValue lists options that consume the next argument, per wrapper command
+// Wrappers run the command that follows their options: `sudo -u app rm -rf /srv`.
+var wrapperOptionsWithValue = map[string][]string{
+	"sudo":    {"-u", "-g", "-C", "-D", "-h", "-p", "-r", "-t", "-U"},
+	"doas":    {"-u", "-C"},
+	"env":     {"-u", "-C", "-S"},
+	"nice":    {"-n"},
+	"nohup":   nil,
+	"time":    {"-f", "-o"},
+	"exec":    {"-a"},
+	"command": nil,
+	"xargs":   {"-n", "-I", "-P", "-L", "-d", "-s", "-a", "-E"},
+	"timeout": {"-s", "-k"},
+	"stdbuf":  nil,
+}
+
+// parseRecipe joins continued lines and parses each logical line
+func parseRecipe(recipeLines []string) []*parsedLine {
+	lines, _ := makefile.JoinContinuations(recipeLines)
+	parsed := make([]*parsedLine, len(lines))
+	for i, line := range lines {
+		parsed[i] = parseLine(line)
+	}
+	return parsed
+}
+
+// parseLine parses a logical recipe line into commands
+// Lines the shell parser can't handle (e.g. unexpanded make functions like
+// $(if ...)) fall back to matching the whole line as one segment.
+func parseLine(line string) *parsedLine {
+	p := &parsedLine{line: line}
+	if !p.collectScript(makefile.ShellSource(line), 0) {
+		p.segments = []string{line}
+		p.commands = nil
+	}
+	return p
+}
+
+// collectScript parses a shell script and collects its segments and commands
+// Returns false if the script doesn't parse.
+func (p *parsedLine) collectScript(script string, depth int) bool {
+	file, err := syntax.NewParser().Parse(strings.NewReader(script), "")
+	if err != nil {
+		return false
+	}
+
+	syntax.Walk(file, func(node syntax.Node) bool {
+		stmt, ok := node.(*syntax.Stmt)
+		if !ok {
+			return true
+		}
+
+		switch cmd := stmt.Cmd.(type) {
+		case *syntax.CallExpr:
+			p.collectCall(stmt, cmd, depth)
+		case *syntax.BinaryCmd:
+			if cmd.Op == syntax.Pipe || cmd.Op == syntax.PipeAll {
+				// The whole pipeline, for rules like `curl ... | sh`
+				p.segments = append(p.segments, makefile.PrintNode(stmt))
+			}
+		}
+		return true
+	})
+
+	return true
+}
+
+// collectCall records a simple command and anything it runs on behalf of its arguments
+func (p *parsedLine) collectCall(stmt *syntax.Stmt, call *syntax.CallExpr, depth int) {
+	if len(call.Args) == 0 {
+		return // Plain assignment: FOO=bar
+	}
+
+	command := Command{Name: path.Base(makefile.WordText(call.Args[0]))}
+	for _, arg := range call.Args[1:] {
+		command.Args = append(command.Args, makefile.WordText(arg))
+	}
+
+	if printOnlyCommands[command.Name] {
+		// Keep redirections (`echo x > .env`) but not the printed text
+		printed := &syntax.Stmt{Cmd: &syntax.CallExpr{Args: call.Args[:1]}, Redirs: stmt.Redirs}
+		p.segments = append(p.segments, makefile.PrintNode(printed))
+	} else {
+		p.segments = append(p.segments, makefile.PrintNode(stmt))
+	}
+
+	p.collectCommand(command, depth)
+}
+
+// collectCommand records a command and looks through wrappers and nested scripts
+func (p *parsedLine) collectCommand(command Command, depth int) {
+	p.commands = append(p.commands, command)
+
+	if depth >= maxNestingDepth {
+		return
+	}
+
+	// sh -c 'script' and eval 'script' run their argument as shell code
+	if script, ok := nestedScript(command); ok {
+		p.collectScript(script, depth+1)
+		return
+	}
+
+	// sudo rm ..., xargs rm ..., env FOO=1 rm ...
+	if inner, ok := unwrapCommand(command); ok {
+		p.segments = append(p.segments, inner.text())
+		p.collectCommand(inner, depth+1)
+	}
+}
+
+// nestedScript returns the script run by `sh -c` or `eval`
+func nestedScript(command Command) (string, bool) {
+	if command.Name == "eval" && len(command.Args) > 0 {
+		return strings.Join(command.Args, " "), true
+	}
+
+	if shellCommands[command.Name] {
+		for i, arg := range command.Args {
+			// -c, or combined like -ec / -xc
+			if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") &&
+				strings.Contains(arg, "c") && i+1 < len(command.Args) {
+				return command.Args[i+1], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// unwrapCommand returns the command a wrapper like sudo or xargs runs
+func unwrapCommand(command Command) (Command, bool) {
+	optionsWithValue, isWrapper := wrapperOptionsWithValue[command.Name]
+	if !isWrapper {
+		return Command{}, false
+	}
+
+	args := command.Args
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--":
+			args = args[1:]
+		case strings.HasPrefix(arg, "-"):
+			args = args[1:]
+			if contains(optionsWithValue, arg) && len(args) > 0 {
+				args = args[1:]
+			}
+			continue
+		case command.Name == "env" && strings.Contains(arg, "="):
+			args = args[1:] // env FOO=bar cmd
+			continue
+		case command.Name == "timeout":
+			args = args[1:] // The duration: timeout 30 cmd
+		}
+		break
+	}
+
+	if len(args) == 0 {
+		return Command{}, false
+	}
+
+	return Command{Name: path.Base(args[0]), Args: args[1:]}, true
+}
+
+// text renders a command for pattern matching
+func (c Command) text() string {
+	return strings.TrimSpace(c.Name + " " + strings.Join(c.Args, " "))
+}
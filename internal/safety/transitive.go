@@ -0,0 +1,123 @@
+package safety
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/rshelekhov/lazymake/internal/graph"
+)
+
+// DependencyMatch is a dangerous target that another target runs through its prerequisites
+// or recursive make
+type DependencyMatch struct {
+	Path   []string     // Chain from the checked target to the dangerous one: [release build db-reset]
+	Result *CheckResult // The dependency's own safety result (its recipe only)
+}
+
+// Target returns the name of the dangerous dependency (the last element of Path)
+func (d DependencyMatch) Target() string {
+	return d.Path[len(d.Path)-1]
+}
+
+// CheckGraph performs safety checks on every target in the graph and propagates
+// the results along prerequisites and recursive make invocations
+//
+// A target inherits the highest severity of anything it will transitively run,
+// so `make release` is flagged when it depends on `db-reset`, or when its recipe
+// runs `$(MAKE) -C services/db reset` (see graph.Node.SubMakes). Inherited dangers are
+// listed in CheckResult.Dependencies with the shortest path that pulls them in.
+// Excluded targets don't inherit, but their prerequisites are still followed.
+//
+// Returns map of target name -> result (only includes targets with matches, allowed or not)
+func (c *Checker) CheckGraph(g *graph.Graph) map[string]*CheckResult {
+	// Each target's own recipe, before propagation
+	// Targets from sub-Makefiles are expanded with their own Makefile's variables.
+	own := make(map[string]*CheckResult)
+	sources := make(map[string]*Checker) // By sub-Makefile path
+	for name, node := range g.Nodes {
+		checker := c
+		if node.Source != nil {
+			if sources[node.Source.Makefile] == nil {
+				sources[node.Source.Makefile] = c.withVariables(node.Source.Variables)
+			}
+			checker = sources[node.Source.Makefile]
+		}
+		if result := checker.CheckTarget(node.Target); result != nil {
+			own[name] = result
+		}
+	}
+
+	results := make(map[string]*CheckResult)
+	for name, node := range g.Nodes {
+		if !c.config.Enabled || contains(c.config.ExcludeTargets, name) {
+			continue
+		}
+
+		dependencies := dangerousDependencies(node, own)
+		if own[name] == nil && len(dependencies) == 0 {
+			continue
+		}
+
+		result := &CheckResult{
+			TargetName:   name,
+			IsDangerous:  len(dependencies) > 0,
+			Dependencies: dependencies,
+		}
+		if self := own[name]; self != nil {
+			result.IsDangerous = result.IsDangerous || self.IsDangerous
+			result.DangerLevel = self.DangerLevel
+			result.Matches = self.Matches
+			result.Suppressed = self.Suppressed
+			result.Confirmation = self.Confirmation
+		}
+		for _, dep := range dependencies {
+			result.DangerLevel = max(result.DangerLevel, dep.Result.DangerLevel)
+			result.Confirmation = result.Confirmation.Merge(dep.Result.Confirmation)
+		}
+
+		results[name] = result
+	}
+
+	return results
+}
+
+// dangerousDependencies walks prerequisites and sub-makes breadth-first and collects flagged targets
+// Breadth-first order yields the shortest path to each dependency and lists
+// nearer dependencies first. Visited tracking makes cycles safe.
+func dangerousDependencies(root *graph.Node, own map[string]*CheckResult) []DependencyMatch {
+	type step struct {
+		node *graph.Node
+		path []string
+	}
+
+	visited := map[string]bool{root.Target.Name: true}
+	queue := []step{{node: root, path: []string{root.Target.Name}}}
+
+	var matches []DependencyMatch
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		runs := append(slices.Clip(current.node.Dependencies), current.node.SubMakes...)
+		for _, dep := range runs {
+			name := dep.Target.Name
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+
+			path := append(append([]string(nil), current.path...), name)
+			if result := own[name]; result != nil && result.IsDangerous {
+				matches = append(matches, DependencyMatch{Path: path, Result: result})
+			}
+			queue = append(queue, step{node: dep, path: path})
+		}
+	}
+
+	// Most severe first; breadth-first order is kept within a severity
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Result.DangerLevel > matches[j].Result.DangerLevel
+	})
+
+	return matches
+}
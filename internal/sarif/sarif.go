@@ -0,0 +1,169 @@
+// Package sarif writes SARIF 2.1.0 logs, the format GitHub code scanning and
+// other viewers read. The lint and audit reports share it.
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/rshelekhov/lazymake/version"
+)
+
+// Result levels
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Finding is one result to report, with the rule that produced it
+// The first finding of a rule describes the rule in the log.
+type Finding struct {
+	RuleID          string
+	RuleDescription string // Short description of the rule (the rule ID if empty)
+	RuleHelp        string // How to fix it ("" = none)
+	Level           string // LevelError, LevelWarning or LevelNote
+
+	Message string
+	File    string // Path relative to where the report is read from
+	Line    int    // 0 if unknown
+
+	// Set when an annotation in the Makefile allows the finding
+	Suppressed    bool
+	Justification string
+
+	Properties map[string]any // Extra details of the result (nil = none)
+}
+
+// SARIF 2.1.0 subset understood by GitHub code scanning and other viewers
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri"`
+	Rules          []Rule `json:"rules"`
+}
+
+type Rule struct {
+	ID                   string      `json:"id"`
+	ShortDescription     Message     `json:"shortDescription"`
+	Help                 *Message    `json:"help,omitempty"`
+	DefaultConfiguration RuleDefault `json:"defaultConfiguration"`
+}
+
+type RuleDefault struct {
+	Level string `json:"level"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Result struct {
+	RuleID       string         `json:"ruleId"`
+	RuleIndex    int            `json:"ruleIndex"`
+	Level        string         `json:"level"`
+	Message      Message        `json:"message"`
+	Locations    []Location     `json:"locations"`
+	Suppressions []Suppression  `json:"suppressions,omitempty"`
+	Properties   map[string]any `json:"properties,omitempty"`
+}
+
+// Suppression marks a result allowed by an annotation in the Makefile
+type Suppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation Artifact `json:"artifactLocation"`
+	Region           *Region  `json:"region,omitempty"`
+}
+
+type Artifact struct {
+	URI string `json:"uri"`
+}
+
+type Region struct {
+	StartLine int `json:"startLine"`
+}
+
+// Write writes the findings as a SARIF log with one run by lazymake
+func Write(w io.Writer, findings []Finding) error {
+	driver := Driver{
+		Name:           "lazymake",
+		Version:        version.Version,
+		InformationURI: "https://github.com/rshelekhov/lazymake",
+		Rules:          []Rule{},
+	}
+	ruleIndex := make(map[string]int)
+
+	results := make([]Result, 0, len(findings))
+	for _, f := range findings {
+		index, ok := ruleIndex[f.RuleID]
+		if !ok {
+			index = len(driver.Rules)
+			ruleIndex[f.RuleID] = index
+
+			rule := Rule{
+				ID:                   f.RuleID,
+				ShortDescription:     Message{Text: f.RuleDescription},
+				DefaultConfiguration: RuleDefault{Level: f.Level},
+			}
+			if rule.ShortDescription.Text == "" {
+				rule.ShortDescription.Text = f.RuleID
+			}
+			if f.RuleHelp != "" {
+				rule.Help = &Message{Text: f.RuleHelp}
+			}
+			driver.Rules = append(driver.Rules, rule)
+		}
+
+		location := PhysicalLocation{ArtifactLocation: Artifact{URI: filepath.ToSlash(f.File)}}
+		if f.Line > 0 {
+			location.Region = &Region{StartLine: f.Line}
+		}
+
+		result := Result{
+			RuleID:     f.RuleID,
+			RuleIndex:  index,
+			Level:      f.Level,
+			Message:    Message{Text: f.Message},
+			Locations:  []Location{{PhysicalLocation: location}},
+			Properties: f.Properties,
+		}
+		if f.Suppressed {
+			result.Suppressions = []Suppression{{Kind: "inSource", Justification: f.Justification}}
+		}
+		results = append(results, result)
+	}
+
+	out := Log{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []Run{{Tool: Tool{Driver: driver}, Results: results}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
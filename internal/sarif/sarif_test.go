@@ -0,0 +1,42 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	findings := []Finding{
+		{RuleID: "rm-rf-root", RuleDescription: "Recursive deletion", RuleHelp: "Check the path", Level: LevelError, Message: "first", File: "sub/Makefile", Line: 3},
+		{RuleID: "rm-rf-root", Level: LevelError, Message: "second", File: "Makefile", Suppressed: true, Justification: "cleanup"},
+		{RuleID: "missing-phony", Level: LevelNote, Message: "third", File: "Makefile", Line: 7},
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, findings); err != nil {
+		t.Fatal(err)
+	}
+	var log Log
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+	run := log.Runs[0]
+	if rules := run.Tool.Driver.Rules; len(rules) != 2 || rules[0].Help == nil ||
+		rules[0].ShortDescription.Text != "Recursive deletion" || rules[1].ShortDescription.Text != "missing-phony" {
+		t.Errorf("expected one rule per ID, described by its first finding, got %+v", rules)
+	}
+	if len(run.Results) != 3 || run.Results[1].RuleIndex != 0 || run.Results[2].RuleIndex != 1 {
+		t.Fatalf("unexpected results: %+v", run.Results)
+	}
+	if location := run.Results[1].Locations[0].PhysicalLocation; location.Region != nil {
+		t.Errorf("expected no region without a line, got %+v", location.Region)
+	}
+	if s := run.Results[1].Suppressions; len(s) != 1 || s[0].Kind != "inSource" || s[0].Justification != "cleanup" {
+		t.Errorf("expected an in-source suppression, got %+v", s)
+	}
+}
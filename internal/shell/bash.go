@@ -3,6 +3,8 @@ package shell
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/rshelekhov/lazymake/internal/filelock"
 )
 
 // BashWriter handles bash history writing
@@ -33,11 +35,11 @@ func (w *BashWriter) Append(entry string) error {
 	defer f.Close()
 
 	// Acquire exclusive lock to prevent corruption
-	if err := lockFile(f); err != nil {
+	if err := filelock.Lock(f); err != nil {
 		return err
 	}
 	defer func() {
-		_ = unlockFile(f) // Explicitly ignore unlock error
+		_ = filelock.Unlock(f) // Explicitly ignore unlock error
 	}()
 
 	// Write entry with newline
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/rshelekhov/lazymake/internal/filelock"
 )
 
 // FishWriter handles fish shell history writing
@@ -51,11 +53,11 @@ func (w *FishWriter) Append(entry string) error {
 	defer f.Close()
 
 	// Acquire exclusive lock to prevent corruption
-	if err := lockFile(f); err != nil {
+	if err := filelock.Lock(f); err != nil {
 		return err
 	}
 	defer func() {
-		_ = unlockFile(f) // Explicitly ignore unlock error
+		_ = filelock.Unlock(f) // Explicitly ignore unlock error
 	}()
 
 	// Write formatted entry (already includes trailing newline)
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/rshelekhov/lazymake/internal/filelock"
 )
 
 // ZshWriter handles zsh history writing
@@ -78,11 +80,11 @@ func (w *ZshWriter) Append(entry string) error {
 	defer f.Close()
 
 	// Acquire exclusive lock to prevent corruption
-	if err := lockFile(f); err != nil {
+	if err := filelock.Lock(f); err != nil {
 		return err
 	}
 	defer func() {
-		_ = unlockFile(f) // Explicitly ignore unlock error
+		_ = filelock.Unlock(f) // Explicitly ignore unlock error
 	}()
 
 	// Write entry with newline
@@ -0,0 +1,129 @@
+// Package tracking records target runs: in the execution history, as export
+// files and in the shell history. The TUI and "lazymake run" both record
+// through it, so a run looks the same wherever it was started from.
+package tracking
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/executor"
+	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/shell"
+)
+
+// Tracker records target runs
+// Exporter and Shell are nil when export or shell integration is disabled.
+type Tracker struct {
+	History  *history.History
+	Exporter *export.Exporter
+	Shell    *shell.Integration
+
+	// Why the configured history backend couldn't be used (nil if it could);
+	// History is then the JSON history, or an empty one
+	HistoryError error
+
+	pending sync.WaitGroup // Exports and shell history writes still running
+}
+
+// Run is a finished target run
+type Run struct {
+	MakefilePath string // Absolute path, as history is keyed by it
+	Target       string
+	Args         []string // Extra make arguments after the target (e.g. VAR=value)
+	Result       executor.Result
+
+	// How the target was confirmed (nil if it ran without confirmation)
+	Confirmation *export.Confirmation
+}
+
+// Open sets up tracking as configured
+// Every part degrades on its own: history falls back to an empty history, and
+// export or shell integration that can't be set up is disabled.
+func Open(cfg *config.Config) *Tracker {
+	// Exported runs are imported when the history database is first created
+	exportDir := ""
+	if cfg.Export != nil {
+		exportDir = export.GenerateExportPath(cfg.Export.OutputDir, "")
+	}
+
+	// Open degrades to the JSON history on error
+	hist, histErr := history.Open(cfg.Storage, exportDir)
+	if hist == nil {
+		hist = &history.History{Entries: make(map[string][]history.Entry)}
+	}
+
+	// Apply retention and regression settings before computing stats
+	hist.SetConfig(cfg.Performance)
+
+	t := &Tracker{History: hist, HistoryError: histErr}
+	if cfg.Export != nil && cfg.Export.Enabled {
+		t.Exporter, _ = export.NewExporter(cfg.Export)
+	}
+	if cfg.ShellIntegration != nil && cfg.ShellIntegration.Enabled {
+		t.Shell, _ = shell.NewIntegration(cfg.ShellIntegration)
+	}
+	return t
+}
+
+// Started records that a target is about to run, moving it up the recent targets
+func (t *Tracker) Started(makefilePath, target string) {
+	t.History.RecordExecution(makefilePath, target)
+	_ = t.History.Save()
+}
+
+// Finished records a finished run with its timing and details in the history,
+// then exports it and adds it to the shell history in the background (see Wait)
+func (t *Tracker) Finished(run Run) {
+	record := export.NewExecutionRecord(run.MakefilePath, run.Target, run.Result)
+	record.Args = run.Args
+	record.Confirmation = run.Confirmation
+
+	entry := history.ExecutionRecord{
+		Duration: run.Result.Duration,
+		Success:  run.Result.Err == nil,
+		ExitCode: run.Result.ExitCode,
+		Args:     run.Args,
+		Output:   run.Result.Output, // Kept by the SQLite backend only
+
+		Confirmation: run.Confirmation,
+	}
+	if t.Exporter != nil {
+		entry.ExportPath = t.Exporter.OutputPath(record)
+	}
+	t.History.RecordRun(run.MakefilePath, run.Target, entry)
+	_ = t.History.Save()
+
+	if t.Exporter != nil {
+		t.pending.Go(func() {
+			if err := t.Exporter.Export(record); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+			}
+		})
+	}
+
+	if t.Shell != nil {
+		t.pending.Go(func() {
+			if err := t.Shell.RecordExecution(shell.ExecutionInfo{
+				Target:       run.Target,
+				MakefilePath: run.MakefilePath,
+			}); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Shell integration failed: %v\n", err)
+			}
+		})
+	}
+}
+
+// Wait blocks until the exports and shell history writes of finished runs are done
+func (t *Tracker) Wait() {
+	t.pending.Wait()
+}
+
+// Close waits for pending writes and closes the history
+func (t *Tracker) Close() error {
+	t.Wait()
+	return t.History.Close()
+}
@@ -0,0 +1,99 @@
+package tracking
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/executor"
+	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/history"
+)
+
+func TestTracker_Finished(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	exportDir := t.TempDir()
+	exportCfg := export.Defaults()
+	exportCfg.Enabled = true
+	exportCfg.OutputDir = exportDir
+	exportCfg.Format = "json"
+
+	tracker := Open(&config.Config{Export: exportCfg})
+	if tracker.Exporter == nil || tracker.Shell != nil {
+		t.Fatalf("expected export on and shell integration off, got %+v", tracker)
+	}
+
+	const makefilePath = "/repo/Makefile"
+	start := time.Now()
+	tracker.Started(makefilePath, "deploy")
+	tracker.Finished(Run{
+		MakefilePath: makefilePath,
+		Target:       "deploy",
+		Args:         []string{"ENV=prod"},
+		Result: executor.Result{
+			Output:    "deploying\n",
+			Err:       errors.New("exit status 2"),
+			Duration:  time.Second,
+			ExitCode:  2,
+			StartTime: start,
+			EndTime:   start.Add(time.Second),
+		},
+		Confirmation: &export.Confirmation{Typed: "deploy"},
+	})
+	if err := tracker.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both the recorded run and the export must survive a reload
+	hist, err := history.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recent := hist.GetRecent(makefilePath)
+	if len(recent) != 1 || recent[0].Name != "deploy" || len(recent[0].RecentExecutions) != 1 {
+		t.Fatalf("expected one recorded deploy run, got %+v", recent)
+	}
+
+	run := recent[0].RecentExecutions[0]
+	if run.Success || run.ExitCode != 2 || !slices.Equal(run.Args, []string{"ENV=prod"}) {
+		t.Errorf("unexpected run details %+v", run)
+	}
+	if run.Confirmation == nil || run.Confirmation.Typed != "deploy" {
+		t.Errorf("expected the confirmation to be recorded, got %+v", run.Confirmation)
+	}
+	if filepath.Dir(run.ExportPath) != exportDir {
+		t.Fatalf("expected an export path in %s, got %q", exportDir, run.ExportPath)
+	}
+	if _, err := os.Stat(run.ExportPath); err != nil {
+		t.Errorf("export wasn't written: %v", err)
+	}
+}
+
+func TestOpen_HistoryDatabaseUnavailable(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+
+	// A directory where the database file should be
+	if err := os.MkdirAll(filepath.Join(cache, "lazymake", "history.db", "blocked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := Open(&config.Config{Storage: &history.StorageConfig{Backend: history.BackendSQLite}})
+	defer func() { _ = tracker.Close() }()
+
+	if tracker.HistoryError == nil {
+		t.Fatal("expected the database error to be reported")
+	}
+	if tracker.History == nil {
+		t.Fatal("expected the JSON history as a fallback")
+	}
+	tracker.Started("/repo/Makefile", "build")
+	if recent := tracker.History.GetRecent("/repo/Makefile"); len(recent) != 1 {
+		t.Errorf("expected the fallback history to record runs, got %+v", recent)
+	}
+}
@@ -20,7 +20,14 @@ type Target struct {
 	Name        string
 	Description string
 	CommentType makefile.CommentType
-	IsRecent    bool // Marks targets that appear in recent history
+	IsRecent    bool          // Marks targets that appear in recent history
+	Docs        makefile.Docs // Group, tags, arguments and examples from "## @tag" lines
+	Section     string        // Title of the "##@" section marker above the target
+
+	// Aggregate mode fields (empty otherwise), where Name is "<package>:<target>"
+	Package  string // Directory of the defining Makefile, relative to the workspace root
+	MakeName string // Name of the target in its own Makefile
+	Makefile string // Absolute path to the defining Makefile
 
 	// Recipe and safety fields
 	Recipe           []string             // Command lines to execute
@@ -28,11 +35,54 @@ type Target struct {
 	IsDangerous      bool                 // Whether target has dangerous commands
 	DangerLevel      safety.Severity      // Highest severity level
 	SafetyMatches    []safety.MatchResult // All matched safety rules
+	SafetySuppressed []safety.MatchResult // Matches allowed by "# lazymake:allow" annotations
+	// Dangerous prerequisites run by this target, with the path that pulls them in
+	SafetyDependencies []safety.DependencyMatch
+	// Stronger confirmation configured for the target or its rules (typed name, countdown, reason)
+	Confirmation safety.Confirmation
 
 	// Performance fields
 	PerfStats *history.PerformanceStats // nil if no data
 }
 
+// applySafety sets the safety fields from a check result (nil clears them)
+func (t *Target) applySafety(result *safety.CheckResult) {
+	if result == nil {
+		t.IsDangerous = false
+		t.DangerLevel = safety.SeverityInfo
+		t.SafetyMatches = nil
+		t.SafetySuppressed = nil
+		t.SafetyDependencies = nil
+		return
+	}
+
+	t.IsDangerous = result.IsDangerous
+	t.DangerLevel = result.DangerLevel
+	t.SafetyMatches = result.Matches
+	t.SafetySuppressed = result.Suppressed
+	t.SafetyDependencies = result.Dependencies
+}
+
+// safetyResult returns the target's own and inherited safety matches as a check result
+func (t Target) safetyResult() *safety.CheckResult {
+	return &safety.CheckResult{
+		TargetName:   t.Name,
+		IsDangerous:  t.IsDangerous,
+		DangerLevel:  t.DangerLevel,
+		Matches:      t.SafetyMatches,
+		Suppressed:   t.SafetySuppressed,
+		Dependencies: t.SafetyDependencies,
+	}
+}
+
+// makeName returns the name make knows the target by
+func (t Target) makeName() string {
+	if t.MakeName != "" {
+		return t.MakeName
+	}
+	return t.Name
+}
+
 // Implement list.Item interface
 func (t Target) FilterValue() string {
 	return t.Name + " " + t.Description
@@ -45,7 +95,10 @@ func (s SeparatorTarget) FilterValue() string { return "" }
 
 // HeaderTarget renders a section header (e.g., "RECENT", "ALL TARGETS")
 type HeaderTarget struct {
-	Label string
+	Label       string
+	Collapsible bool // Makefile section that space folds
+	Collapsed   bool // Its targets are hidden
+	Count       int  // Number of targets in the section
 }
 
 func (h HeaderTarget) FilterValue() string { return "" }
@@ -107,8 +160,7 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 
 	// Handle section header
 	if header, ok := listItem.(HeaderTarget); ok {
-		headerStr := SectionHeaderStyle.Render(header.Label)
-		_, _ = fmt.Fprint(w, headerStr)
+		_, _ = fmt.Fprint(w, renderSectionHeader(header, index == m.Index()))
 		return
 	}
 
@@ -190,8 +242,16 @@ func (d ItemDelegate) buildTitle(target Target, icon string, iconColor, titleCol
 		iconStyled := lipgloss.NewStyle().Foreground(iconColor).Render(icon)
 		titleParts = append(titleParts, iconStyled)
 	}
-	nameStyled := lipgloss.NewStyle().Foreground(titleColor).Render(target.Name)
-	titleParts = append(titleParts, nameStyled)
+	nameStyle := lipgloss.NewStyle().Foreground(titleColor)
+	if target.Docs.Deprecated {
+		nameStyle = nameStyle.Strikethrough(true)
+	}
+	titleParts = append(titleParts, nameStyle.Render(target.Name))
+
+	// @deprecated targets still run, but say so
+	if target.Docs.Deprecated {
+		titleParts = append(titleParts, lipgloss.NewStyle().Foreground(WarningColor).Render("deprecated"))
+	}
 	return strings.Join(titleParts, " ")
 }
 
@@ -217,6 +277,25 @@ func (d ItemDelegate) renderDescription(desc string, descStyle lipgloss.Style, l
 	}
 }
 
+// renderSectionHeader renders a section header, with a fold marker on Makefile sections
+// Collapsed sections show how many targets they hide and can be selected to expand them.
+func renderSectionHeader(header HeaderTarget, selected bool) string {
+	if !header.Collapsible {
+		return SectionHeaderStyle.Render(header.Label)
+	}
+
+	style := SectionHeaderStyle
+	if selected {
+		style = style.Foreground(PrimaryColor)
+	}
+	if !header.Collapsed {
+		return style.Render("▾ " + header.Label)
+	}
+
+	count := lipgloss.NewStyle().Foreground(TextMuted).Render(fmt.Sprintf(" (%d)", header.Count))
+	return style.Render("▸ "+header.Label) + count
+}
+
 // Modern icon constants - more consistent than emojis across terminals
 const (
 	IconDangerCritical = "○" // Empty circle (red outline)
@@ -228,6 +307,7 @@ const (
 	IconError          = "✗" // X mark
 	IconInfo           = "ℹ" // Info
 	IconArrowRight     = "▸" // Right arrow (selected)
+	IconProfile        = "◈" // Framed diamond (safety profile)
 )
 
 // shouldShowDurationBadge returns true if we should show duration badge for this target
@@ -2,8 +2,6 @@ package tui
 
 import (
 	"errors"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -19,9 +17,11 @@ import (
 	"github.com/rshelekhov/lazymake/internal/graph"
 	"github.com/rshelekhov/lazymake/internal/highlight"
 	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/lint"
 	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/project"
 	"github.com/rshelekhov/lazymake/internal/safety"
-	"github.com/rshelekhov/lazymake/internal/shell"
+	"github.com/rshelekhov/lazymake/internal/tracking"
 	"github.com/rshelekhov/lazymake/internal/variables"
 	"github.com/rshelekhov/lazymake/internal/workspace"
 )
@@ -37,6 +37,11 @@ const (
 	StateConfirmDangerous
 	StateVariables
 	StateWorkspace
+	StatePerformance
+	StateHistory
+	StateDiagnostics
+	StateArguments
+	StatePackages
 )
 
 type Model struct {
@@ -45,6 +50,7 @@ type Model struct {
 	Viewport          viewport.Model // Used for output view
 	RecipeViewport    viewport.Model // Used for recipe preview scrolling
 	VariablesViewport viewport.Model // Used for variables view scrolling
+	PerformanceViewport viewport.Model // Used for performance dashboard scrolling
 	Progress          progress.Model
 	Spinner           spinner.Model
 
@@ -54,11 +60,17 @@ type Model struct {
 	AllTargets     []Target // Original unfiltered targets
 	FilteredItems  []list.Item
 
+	// Section folding state
+	CollapsedSections map[string]bool // Labels of the sections folded with space
+
 	// State
 	State           AppState
 	ExecutingTarget string
+	ExecutingCommand string   // make command line shown in the execution and output headers
+	ExecutingArgs   []string // Extra make arguments for the executing target (e.g. from a re-run)
 	Output          string
 	ExecutionError  error
+	OutputReturnState AppState // State to return to when leaving the output view
 	Targets         []Target // Store targets for help view
 
 	// Graph state
@@ -68,17 +80,58 @@ type Model struct {
 	ShowOrder    bool   // Show execution order numbers
 	ShowCritical bool   // Show critical path markers
 	ShowParallel bool   // Show parallel markers
+	ShowJobs     bool   // Show -j simulation and speedup curve
+
+	ParallelAnalysis *graph.ParallelAnalysis // -j simulation for GraphTarget, run when the graph view opens
 
 	// Variable inspector state
-	Variables []variables.Variable
+	Variables          []variables.Variable
+	UndefinedVariables []variables.UndefinedReference // Referenced but never given a value
+	VariablesPath      string                         // Makefile the variables are defined in
+	VariableCursor     int                            // Index of the selected variable
+	VariableTrace      *variables.Trace               // Expansion trace of the selected variable (nil when not tracing)
+	TraceStep          int                            // Last trace step revealed
+
+	// Lint diagnostics state
+	Linted           bool              // Lint is enabled and the Makefile could be linted
+	Diagnostics      []lint.Diagnostic // Lint diagnostics for the current Makefile
+	DiagnosticCursor int               // Index of the selected diagnostic
 
 	// History state
 	History       *history.History
 	MakefilePath  string   // Absolute path to current Makefile
 	RecentTargets []Target // Cached recent targets for current Makefile
 
+	// History browser state
+	HistoryRuns        []history.Run  // Runs matching the current filters, newest first
+	HistoryCursor      int            // Index of the selected run
+	HistoryFilter      string         // Typed query (project:, target:, status:, since:, until:, free text)
+	HistoryFiltering   bool           // True while typing a query
+	HistoryStatus      history.Status // Quick status filter (s)
+	HistoryRange       int            // Index into historyRanges (d)
+	HistoryThisProject bool           // Only show runs of the current Makefile (c)
+	HistoryMessage     string         // Feedback for the last action (e.g. missing export)
+
 	// Confirmation state
-	PendingTarget *Target // Target awaiting dangerous command confirmation
+	PendingTarget     *Target                 // Target awaiting dangerous command confirmation
+	SafetyChecker     *safety.Checker         // Rechecks targets run with variable overrides (nil if disabled)
+	SafetyProfile     safety.ProfileSelection // Active safety profile and why it was chosen
+	ProfileError      error                   // Set when the requested profile doesn't exist (default is used)
+	RuleErrors        []safety.RuleError      // Custom rules that couldn't be loaded (left out)
+	ConfigProblems    []config.Problem        // Config file errors found at startup (valid settings still apply)
+	SafetyEnvironment safety.Environment      // Detected context, named in environment confirmations
+
+	// Argument prompt state (targets documented with "## @arg")
+	ArgumentsTarget *Target  // Target whose arguments are being entered
+	ArgumentValues  []string // One value per documented argument, prefilled with its default
+	ArgumentField   int      // Index of the focused argument
+
+	// Stronger confirmation input (see safety.Confirmation)
+	ConfirmInput          string               // Text typed to confirm (target or environment name)
+	ConfirmReason         string               // Reason for running the target
+	ConfirmField          int                  // Focused input: confirmFieldPhrase or confirmFieldReason
+	ConfirmReadyAt        time.Time            // End of the countdown, when confirming becomes possible
+	ExecutingConfirmation *export.Confirmation // How the executing target was confirmed (nil if it wasn't)
 
 	// Execution timing
 	ExecutionStartTime time.Time
@@ -91,12 +144,28 @@ type Model struct {
 	CancelExecution   func()                  // Function to cancel running command
 
 	// Export and shell integration
-	Exporter         *export.Exporter
-	ShellIntegration *shell.Integration
+	Tracker          *tracking.Tracker // Records runs in history, exports and shell history
 
 	// Workspace management
 	WorkspaceManager *workspace.Manager
-	WorkspaceList    list.Model // For workspace picker UI
+	WorkspaceList    list.Model                 // For workspace picker UI
+	DiscoveryOptions workspace.DiscoveryOptions // How the picker and aggregate mode find Makefiles
+
+	// Aggregate mode: every Makefile below WorkspaceRoot in one list (see model_aggregate.go)
+	WorkspaceRoot string             // Directory packages are named relative to ("" outside aggregate mode)
+	Packages      []aggregatePackage // Loaded Makefiles, the root first
+
+	// Targets '>' jumped from into the targets they run with recursive make, latest last
+	JumpStack []jumpOrigin
+
+	// All-packages view state (one make target across every package that defines it)
+	PackagesTarget    string         // Make target name
+	PackageTargets    []Target       // The target in each package, in package order
+	PackageCursor     int            // Index of the selected package
+	BatchQueue        []Target       // Packages still to run
+	BatchResults      []batchResult  // Finished runs, in order
+	BatchOutput       *strings.Builder // Output of the finished runs
+	BatchConfirmation *export.Confirmation // How the batch was confirmed (nil if it wasn't)
 
 	// Syntax highlighting
 	Highlighter *highlight.Highlighter
@@ -111,76 +180,44 @@ type Model struct {
 	Err error
 }
 
-// loadAndParseMakefile parses the makefile and related data
-func loadAndParseMakefile(makefilePath string) ([]makefile.Target, *graph.Graph, []variables.Variable, error) {
-	targets, err := makefile.Parse(makefilePath)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	depGraph := graph.BuildGraph(targets)
-
-	// Parse and analyze variables
-	vars, err := variables.ParseVariables(makefilePath)
-	if err != nil {
-		// Graceful degradation: continue without variables
-		vars = []variables.Variable{}
-	} else {
-		// Expand variables using make
-		_ = variables.ExpandVariables(makefilePath, vars)
-		// Analyze usage across targets
-		variables.AnalyzeUsage(vars, targets)
+// ruleErrors returns the custom rules that couldn't be loaded, if safety checks are on
+func ruleErrors(safetyCfg *safety.Config) []safety.RuleError {
+	if safetyCfg == nil || !safetyCfg.Enabled {
+		return nil
 	}
-
-	return targets, depGraph, vars, nil
+	return safetyCfg.RuleErrors
 }
 
 // convertAndEnrichWithSafety converts makefile targets to TUI targets and adds safety checks
-func convertAndEnrichWithSafety(targets []makefile.Target, safetyCfg *safety.Config) []Target {
-	if safetyCfg == nil {
-		safetyCfg = safety.DefaultConfig()
-	}
-
-	var safetyResults map[string]*safety.CheckResult
-	if safetyCfg.Enabled {
-		checker, err := safety.NewChecker(safetyCfg)
-		if err == nil {
-			safetyResults = checker.CheckAllTargets(targets)
-		}
-	}
-
+// Targets inherit the danger of prerequisites and sub-makes they run (see project.New).
+func convertAndEnrichWithSafety(p *project.Project) []Target {
 	// Convert targets to TUI format
-	tuiTargets := make([]Target, len(targets))
-	for i, t := range targets {
+	tuiTargets := make([]Target, len(p.Targets))
+	for i, t := range p.Targets {
 		tuiTargets[i] = Target{
 			Name:        t.Name,
 			Description: t.Description,
 			CommentType: t.CommentType,
 			Recipe:      t.Recipe,
+			Docs:        t.Docs,
+			Section:     t.Section,
 		}
 
 		// Populate safety fields if target was flagged
-		if safetyResults != nil {
-			if result, found := safetyResults[t.Name]; found {
-				tuiTargets[i].IsDangerous = result.IsDangerous
-				tuiTargets[i].DangerLevel = result.DangerLevel
-				tuiTargets[i].SafetyMatches = result.Matches
+		if p.Results != nil {
+			if result, found := p.Results[t.Name]; found {
+				tuiTargets[i].applySafety(result)
 			}
+			tuiTargets[i].Confirmation = p.Checker.Confirmation(t.Name, p.Results[t.Name])
 		}
 	}
 
 	return tuiTargets
 }
 
-// enrichWithHistory loads history and enriches targets with performance data
-// Returns the list of recent targets and the history object
-func enrichWithHistory(tuiTargets []Target, absPath string) ([]Target, *history.History) {
-	// Load history
-	hist, err := history.Load()
-	if err != nil {
-		hist = &history.History{Entries: make(map[string][]history.Entry)}
-	}
-
+// enrichWithHistory enriches targets with performance data from history
+// Returns the list of recent targets
+func enrichWithHistory(tuiTargets []Target, absPath string, hist *history.History) []Target {
 	// Filter valid targets from history
 	targetNames := extractTargetNames(tuiTargets)
 	hist.FilterValid(absPath, targetNames)
@@ -190,57 +227,145 @@ func enrichWithHistory(tuiTargets []Target, absPath string) ([]Target, *history.
 
 	// Get recent entries and build recent targets list
 	recentEntries := hist.GetRecent(absPath)
-	return buildRecentTargets(recentEntries, tuiTargets), hist
+	return buildRecentTargets(recentEntries, tuiTargets)
 }
 
 // buildItemsList creates the list items for display
-func buildItemsList(tuiTargets, recentTargets []Target) []list.Item {
+// Targets are listed under their sections, in the order the sections first
+// appear; hidden targets are left out. Sections in collapsed only show a header.
+func buildItemsList(tuiTargets, recentTargets []Target, collapsed map[string]bool) []list.Item {
 	items := make([]list.Item, 0, len(tuiTargets)+len(recentTargets)+3)
 
-	if len(recentTargets) > 0 {
+	if recent := visibleTargets(recentTargets); len(recent) > 0 {
 		items = append(items, HeaderTarget{Label: "RECENT"})
-		for _, t := range recentTargets {
+		for _, t := range recent {
 			items = append(items, t)
 		}
 		items = append(items, SeparatorTarget{})
 	}
 
-	items = append(items, HeaderTarget{Label: "ALL TARGETS"})
-	for _, t := range tuiTargets {
-		items = append(items, t)
+	sections := groupTargets(visibleTargets(tuiTargets))
+	collapsible := len(sections) > 1 || sections[0].label != allTargetsLabel
+	for _, section := range sections {
+		header := HeaderTarget{
+			Label:       section.label,
+			Collapsible: collapsible,
+			Collapsed:   collapsible && collapsed[section.label],
+			Count:       len(section.targets),
+		}
+		items = append(items, header)
+		if header.Collapsed {
+			continue
+		}
+		for _, t := range section.targets {
+			items = append(items, t)
+		}
 	}
 
 	return items
 }
 
-func NewModel(cfg *config.Config) Model {
-	if cfg.MakefilePath == "" {
-		path, err := findMakefileInCwd()
-		if err != nil {
-			return Model{Err: errors.New("no Makefile specified and none found in current directory")}
+// targetSection is a group of targets listed under one header
+type targetSection struct {
+	label   string
+	targets []Target
+}
+
+// Labels of the section for targets without one
+const (
+	allTargetsLabel = "ALL TARGETS" // No target has a section
+	otherLabel      = "OTHER"       // Some targets have one
+)
+
+// sectionName returns the section a target is listed under: its @group, or
+// else the "##@" section it's in ("" if neither)
+func sectionName(t Target) string {
+	if t.Docs.Group != "" {
+		return t.Docs.Group
+	}
+	return t.Section
+}
+
+// sectionLabel returns the header label of a target's section
+func sectionLabel(t Target) string {
+	if name := sectionName(t); name != "" {
+		return strings.ToUpper(name)
+	}
+	return otherLabel
+}
+
+// groupTargets splits targets into sections, in the order the sections first
+// appear in the Makefile, with targets without one under "OTHER"
+// Without any sections, every target is listed under "ALL TARGETS".
+func groupTargets(targets []Target) []targetSection {
+	var sections []targetSection
+	index := make(map[string]int)
+
+	for _, t := range targets {
+		key := sectionLabel(t)
+		i, ok := index[key]
+		if !ok {
+			i = len(sections)
+			index[key] = i
+			sections = append(sections, targetSection{label: key})
 		}
-		cfg.MakefilePath = path
+		sections[i].targets = append(sections[i].targets, t)
 	}
-	// Parse makefile and load data
-	targets, depGraph, vars, err := loadAndParseMakefile(cfg.MakefilePath)
-	if err != nil {
-		return Model{Err: err}
+
+	if len(sections) == 0 {
+		return []targetSection{{label: allTargetsLabel}}
+	}
+	if len(sections) == 1 && sections[0].label == otherLabel {
+		sections[0].label = allTargetsLabel
 	}
+	return sections
+}
 
-	// Convert to TUI targets and enrich with safety checks
-	tuiTargets := convertAndEnrichWithSafety(targets, cfg.Safety)
+// visibleTargets leaves out targets documented with @hidden
+func visibleTargets(targets []Target) []Target {
+	visible := make([]Target, 0, len(targets))
+	for _, t := range targets {
+		if !t.Docs.Hidden {
+			visible = append(visible, t)
+		}
+	}
+	return visible
+}
 
-	// Get absolute path for history lookups
-	absPath, err := filepath.Abs(cfg.MakefilePath)
-	if err != nil {
-		absPath = cfg.MakefilePath
+// NewModel loads the configured Makefile (or workspace) for the TUI
+// Call Close when the program exits.
+func NewModel(cfg *config.Config) Model {
+	return loadModel(cfg, nil)
+}
+
+// Close waits for the exports of finished runs and closes the history
+func (m Model) Close() error {
+	if m.Tracker == nil {
+		return nil
 	}
+	return m.Tracker.Close()
+}
 
-	// Enrich with history and performance data
-	recentTargets, hist := enrichWithHistory(tuiTargets, absPath)
+// loadModel loads a model that records runs with tracker, opening one if nil
+// Switching Makefiles keeps the session's tracker, so history is opened once.
+func loadModel(cfg *config.Config, tracker *tracking.Tracker) Model {
+	if tracker == nil {
+		tracker = tracking.Open(cfg)
+	}
+
+	var m Model
+	var err error
+	if cfg.Workspace != nil && cfg.Workspace.Aggregate {
+		m, err = loadWorkspace(cfg, tracker)
+	} else {
+		m, err = loadMakefile(cfg, tracker)
+	}
+	if err != nil {
+		return Model{Err: err, Tracker: tracker}
+	}
 
 	// Build items list for display
-	items := buildItemsList(tuiTargets, recentTargets)
+	items := buildItemsList(m.Targets, m.RecentTargets, nil)
 
 	// Define key bindings for both list and status bar display
 	keyBindings := []key.Binding{
@@ -269,6 +394,9 @@ func NewModel(cfg *config.Config) Model {
 	delegate := NewItemDelegate()
 	l := list.New(items, delegate, 0, 0)
 	l.Title = "Makefile Targets"
+	if m.aggregated() {
+		l.Title = "Workspace Targets"
+	}
 	l.SetShowStatusBar(false) // Disabled - we use custom status bar
 	l.SetShowHelp(false)      // Disabled - help text shown in custom status bar
 	l.SetFilteringEnabled(false) // Disabled - we use custom filtering
@@ -292,21 +420,6 @@ func NewModel(cfg *config.Config) Model {
 		}
 	}
 
-	// Initialize export if enabled
-	var exporter *export.Exporter
-	if cfg.Export != nil && cfg.Export.Enabled {
-		exporter, _ = export.NewExporter(cfg.Export)
-	}
-
-	// Initialize shell integration if enabled
-	var shellInteg *shell.Integration
-	if cfg.ShellIntegration != nil && cfg.ShellIntegration.Enabled {
-		shellInteg, _ = shell.NewIntegration(cfg.ShellIntegration)
-	}
-
-	// Initialize syntax highlighter
-	highlighter := highlight.NewHighlighter()
-
 	// Initialize modern progress bar
 	prog := progress.New(
 		progress.WithDefaultGradient(),
@@ -319,44 +432,78 @@ func NewModel(cfg *config.Config) Model {
 	spin.Spinner = spinner.Dot
 	spin.Style = lipgloss.NewStyle().Foreground(PrimaryColor)
 
-	return Model{
-		List:              l,
-		Progress:          prog,
-		Spinner:           spin,
-		State:             StateList,
-		Targets:           tuiTargets,
-		AllTargets:        tuiTargets,
-		FilterInput:       "",
-		IsFiltering:       false,
-		Graph:             depGraph,
-		GraphDepth:        -1,
-		ShowOrder:         true,
-		ShowCritical:      true,
-		ShowParallel:      true,
-		Variables:         vars,
-		History:           hist,
-		MakefilePath:      absPath,
-		RecentTargets:     recentTargets,
-		Exporter:          exporter,
-		ShellIntegration:  shellInteg,
-		Highlighter:       highlighter,
-		KeyBindings:       keyBindings,
-		StreamingOutput:   &strings.Builder{},
-	}
+	m.List = l
+	m.Progress = prog
+	m.Spinner = spin
+	m.State = StateList
+	m.AllTargets = m.Targets
+	m.GraphDepth = -1
+	m.ShowOrder = true
+	m.ShowCritical = true
+	m.ShowParallel = true
+	m.ShowJobs = true
+	m.RuleErrors = ruleErrors(cfg.Safety)
+	m.ConfigProblems = cfg.Problems
+	m.DiscoveryOptions = cfg.Workspace.DiscoveryOptions()
+	m.Highlighter = highlight.NewHighlighter() // Initialize syntax highlighter
+	m.KeyBindings = keyBindings
+	m.StreamingOutput = &strings.Builder{}
+
+	return m
 }
 
-// findMakefileInCwd searches for a Makefile in the current directory
-// From the GNU make manual, the order is:
-// GNUmakefile, makefile and Makefile.
-func findMakefileInCwd() (string, error) {
-	possibleNames := []string{"GNUmakefile", "makefile", "Makefile"}
-	for _, name := range possibleNames {
-		path := filepath.Join(".", name)
-		if _, err := os.Stat(path); err == nil {
-			return path, nil
+// loadMakefile parses the Makefile, checks its targets and loads their history
+func loadMakefile(cfg *config.Config, tracker *tracking.Tracker) (Model, error) {
+	if cfg.MakefilePath == "" {
+		path, err := makefile.Find(".")
+		if err != nil {
+			return Model{}, errors.New("no Makefile specified and none found in current directory")
 		}
+		cfg.MakefilePath = path
+	}
+	// Parse the Makefile, follow its sub-makes and check it under the profile
+	// picked from flag, env var or the Makefile's context (kube, AWS, git)
+	p, err := project.Load(cfg.MakefilePath, cfg.Safety)
+	if err != nil {
+		return Model{}, err
+	}
+	tuiTargets := convertAndEnrichWithSafety(p)
+
+	// Enrich with history and performance data
+	recentTargets := enrichWithHistory(tuiTargets, p.Path, tracker.History)
+
+	diagnostics, linted := lintMakefile(cfg)
+
+	return Model{
+		Targets:            tuiTargets,
+		Graph:              p.Graph,
+		Variables:          p.Variables,
+		UndefinedVariables: variables.UndefinedReferences(p.Variables, p.Targets),
+		VariablesPath:      p.Path,
+		Linted:             linted,
+		Diagnostics:        diagnostics,
+		SafetyChecker:      p.Checker,
+		SafetyProfile:      p.Profile,
+		SafetyEnvironment:  p.Environment,
+		ProfileError:       p.ProfileError,
+		History:            tracker.History,
+		MakefilePath:       p.Path,
+		RecentTargets:      recentTargets,
+		Tracker:            tracker,
+	}, nil
+}
+
+// lintMakefile runs the lint rules on the Makefile when lint is enabled
+// Diagnostics are advisory: a Makefile that can't be linted just has no panel.
+func lintMakefile(cfg *config.Config) ([]lint.Diagnostic, bool) {
+	if cfg.Lint == nil || !cfg.Lint.Enabled {
+		return nil, false
+	}
+	diagnostics, err := lint.Check(cfg.MakefilePath, cfg.Lint)
+	if err != nil {
+		return nil, false
 	}
-	return "", errors.New("no Makefile found in current directory")
+	return diagnostics, true
 }
 
 // extractTargetNames extracts just the names from a slice of targets
@@ -369,9 +516,14 @@ func extractTargetNames(targets []Target) []string {
 }
 
 // enrichTargetsWithPerformance populates PerfStats for all targets
+// Targets of aggregate packages are looked up under their own Makefile.
 func enrichTargetsWithPerformance(hist *history.History, makefilePath string, targets []Target) {
 	for i := range targets {
-		targets[i].PerfStats = hist.GetPerformanceStats(makefilePath, targets[i].Name)
+		path := makefilePath
+		if targets[i].Makefile != "" {
+			path = targets[i].Makefile
+		}
+		targets[i].PerfStats = hist.GetPerformanceStats(path, targets[i].makeName())
 	}
 }
 
@@ -402,23 +554,8 @@ func buildRecentTargets(entries []history.Entry, allTargets []Target) []Target {
 }
 
 // rebuildListItems reconstructs the list items from current targets and recent targets
-func rebuildListItems(recentTargets, allTargets []Target) []list.Item {
-	items := make([]list.Item, 0, len(allTargets)+len(recentTargets)+3)
-
-	if len(recentTargets) > 0 {
-		items = append(items, HeaderTarget{Label: "RECENT"})
-		for _, t := range recentTargets {
-			items = append(items, t)
-		}
-		items = append(items, SeparatorTarget{})
-	}
-
-	items = append(items, HeaderTarget{Label: "ALL TARGETS"})
-	for _, t := range allTargets {
-		items = append(items, t)
-	}
-
-	return items
+func rebuildListItems(recentTargets, allTargets []Target, collapsed map[string]bool) []list.Item {
+	return buildItemsList(allTargets, recentTargets, collapsed)
 }
 
 // SwitchWorkspace reinitializes the model with a new Makefile path
@@ -427,9 +564,23 @@ func (m Model) SwitchWorkspace(newMakefilePath string, cfg *config.Config) Model
 	// Create new config with updated Makefile path
 	newCfg := *cfg
 	newCfg.MakefilePath = newMakefilePath
+	newCfg.Workspace = withAggregate(cfg.Workspace, false)
+
+	newModel := m.switchTo(&newCfg)
+
+	// Record workspace access
+	if m.WorkspaceManager != nil {
+		m.WorkspaceManager.RecordAccess(newMakefilePath)
+		_ = m.WorkspaceManager.Save() // Async, ignore errors (non-critical)
+	}
+
+	return newModel
+}
 
-	// Create fresh model with new Makefile
-	newModel := NewModel(&newCfg)
+// switchTo creates a fresh model for cfg, keeping the window size and workspace manager
+func (m Model) switchTo(cfg *config.Config) Model {
+	// Create fresh model with new Makefile, recording runs with the same tracker
+	newModel := loadModel(cfg, m.Tracker)
 
 	// Preserve UI state
 	newModel.Width = m.Width
@@ -466,11 +617,5 @@ func (m Model) SwitchWorkspace(newMakefilePath string, cfg *config.Config) Model
 		}
 	}
 
-	// Record workspace access
-	if m.WorkspaceManager != nil {
-		m.WorkspaceManager.RecordAccess(newMakefilePath)
-		_ = m.WorkspaceManager.Save() // Async, ignore errors (non-critical)
-	}
-
 	return newModel
 }
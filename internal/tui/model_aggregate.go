@@ -0,0 +1,386 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/graph"
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/lint"
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/project"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/tracking"
+	"github.com/rshelekhov/lazymake/internal/variables"
+	"github.com/rshelekhov/lazymake/internal/workspace"
+)
+
+// maxAggregateRecent is how many recent targets are listed across all packages
+// (as many as history keeps for one Makefile)
+const maxAggregateRecent = 5
+
+// aggregatePackage is a Makefile of the workspace loaded in aggregate mode
+type aggregatePackage struct {
+	workspace.Package
+	Graph     *graph.Graph         // Dependency graph under the Makefile's own target names
+	Checker   *safety.Checker      // Rechecks targets run with variable overrides (nil if disabled)
+	Variables []variables.Variable // Variables of the Makefile, expanded
+}
+
+// loadWorkspace loads every Makefile below the workspace root into one list
+// The root is the directory of the configured Makefile, or else the working
+// directory. The safety profile is picked for the root.
+func loadWorkspace(cfg *config.Config, tracker *tracking.Tracker) (Model, error) {
+	root := "."
+	if cfg.MakefilePath != "" {
+		root = filepath.Dir(cfg.MakefilePath)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return Model{}, err
+	}
+
+	environment := safety.DetectEnvironment(root)
+	profileSelection, profileErr := safety.SelectProfile(cfg.Safety, environment)
+
+	packages, tuiTargets, depGraph, err := loadPackages(root, cfg, profileSelection.Profile)
+	if err != nil {
+		return Model{}, err
+	}
+
+	// Runs are recorded under each package's Makefile with the target's own name
+	for _, pkg := range packages {
+		names := make([]string, len(pkg.Targets))
+		for i, t := range pkg.Targets {
+			names[i] = t.Name
+		}
+		tracker.History.FilterValid(pkg.Path, names)
+	}
+	enrichTargetsWithPerformance(tracker.History, "", tuiTargets)
+
+	diagnostics, linted := lintPackages(cfg, root, packages)
+
+	m := Model{
+		Targets:           tuiTargets,
+		Graph:             depGraph,
+		Linted:            linted,
+		Diagnostics:       diagnostics,
+		SafetyProfile:     profileSelection,
+		SafetyEnvironment: environment,
+		ProfileError:      profileErr,
+		History:           tracker.History,
+		MakefilePath:      packages[0].Path, // The root Makefile, if there is one
+		Tracker:           tracker,
+		WorkspaceRoot:     root,
+		Packages:          packages,
+	}
+	m.useVariablesOf(&packages[0])
+	m.RecentTargets = m.recentTargets()
+
+	return m, nil
+}
+
+// loadPackages parses the Makefiles below root and checks their targets
+// Makefiles are parsed and checked in parallel, each under its own target
+// names (so target_confirmation and dependencies match as in the Makefile);
+// targets are then named by package: "services/api:test". Makefiles that can't
+// be parsed are left out.
+func loadPackages(root string, cfg *config.Config, profile *safety.Profile) ([]aggregatePackage, []Target, *graph.Graph, error) {
+	loaded, err := workspace.LoadPackages(root, cfg.Workspace.DiscoveryOptions())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var packages []aggregatePackage
+	for _, pkg := range loaded {
+		if pkg.Err == nil {
+			packages = append(packages, aggregatePackage{Package: pkg})
+		}
+	}
+	if len(packages) == 0 {
+		return nil, nil, nil, fmt.Errorf("no Makefiles found in %s", root)
+	}
+
+	// Expanding variables runs make, so bound it like parsing
+	packageTargets := make([][]Target, len(packages))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, runtime.NumCPU())
+	for i := range packages {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			pkg := &packages[i]
+			p := project.New(pkg.Path, pkg.Targets, cfg.Safety, profile)
+			pkg.Graph, pkg.Variables, pkg.Checker = p.Graph, p.Variables, p.Checker
+			packageTargets[i] = qualifyTargets(pkg.Package, convertAndEnrichWithSafety(p))
+		})
+	}
+	wg.Wait()
+
+	var tuiTargets []Target
+	var qualified []makefile.Target
+	for i, pkg := range packages {
+		tuiTargets = append(tuiTargets, packageTargets[i]...)
+		for _, t := range pkg.Targets {
+			t.Name = workspace.QualifiedName(pkg.Dir, t.Name)
+			deps := make([]string, len(t.Dependencies))
+			for j, dep := range t.Dependencies {
+				deps[j] = workspace.QualifiedName(pkg.Dir, dep)
+			}
+			t.Dependencies = deps
+			qualified = append(qualified, t)
+		}
+	}
+
+	return packages, tuiTargets, linkPackages(root, packages, graph.BuildGraph(qualified)), nil
+}
+
+// linkPackages follows recursive make in the combined graph
+// Targets of other packages are followed from their own Makefile, so
+// "$(MAKE) -C ../lib" resolves as it does when make runs it.
+func linkPackages(root string, packages []aggregatePackage, depGraph *graph.Graph) *graph.Graph {
+	rootMakefile := filepath.Join(root, "Makefile")
+	var values map[string]string
+	for _, pkg := range packages {
+		if pkg.Dir == workspace.RootPackage {
+			rootMakefile, values = pkg.Path, safety.VariableValues(pkg.Variables)
+			continue
+		}
+		for _, t := range pkg.Targets {
+			if node := depGraph.Nodes[workspace.QualifiedName(pkg.Dir, t.Name)]; node != nil {
+				node.Source = &graph.Source{Makefile: pkg.Path, Dir: pkg.Dir, Target: t.Name, Variables: safety.VariableValues(pkg.Variables)}
+			}
+		}
+	}
+
+	depGraph.LinkSubMakes(rootMakefile, values)
+	return depGraph
+}
+
+// qualifyTargets names a package's targets by package, keeping the name make knows them by
+func qualifyTargets(pkg workspace.Package, targets []Target) []Target {
+	for i := range targets {
+		t := &targets[i]
+		t.Package = pkg.Dir
+		t.MakeName = t.Name
+		t.Makefile = pkg.Path
+		t.Name = workspace.QualifiedName(pkg.Dir, t.Name)
+		t.SafetyDependencies = qualifyDependencies(pkg.Dir, t.SafetyDependencies)
+	}
+	return targets
+}
+
+// qualifyDependencies names the targets on dangerous prerequisite chains by package
+func qualifyDependencies(dir string, deps []safety.DependencyMatch) []safety.DependencyMatch {
+	if len(deps) == 0 {
+		return deps
+	}
+	qualified := make([]safety.DependencyMatch, len(deps))
+	for i, dep := range deps {
+		path := make([]string, len(dep.Path))
+		for j, name := range dep.Path {
+			path[j] = workspace.QualifiedName(dir, name)
+		}
+		qualified[i] = safety.DependencyMatch{Path: path, Result: dep.Result}
+	}
+	return qualified
+}
+
+// lintPackages lints every package, naming the targets in diagnostics by package
+// Files are shown relative to the workspace root.
+func lintPackages(cfg *config.Config, root string, packages []aggregatePackage) ([]lint.Diagnostic, bool) {
+	if cfg.Lint == nil || !cfg.Lint.Enabled {
+		return nil, false
+	}
+
+	var diagnostics []lint.Diagnostic
+	linted := false
+	for _, pkg := range packages {
+		found, err := lint.Check(pkg.Path, cfg.Lint)
+		if err != nil {
+			continue
+		}
+		linted = true
+		for _, d := range found {
+			if d.Target != "" {
+				d.Target = workspace.QualifiedName(pkg.Dir, d.Target)
+			}
+			if rel, err := filepath.Rel(root, d.File); err == nil {
+				d.File = rel
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return diagnostics, linted
+}
+
+// aggregated reports whether the model lists the targets of every Makefile in the workspace
+func (m Model) aggregated() bool {
+	return m.WorkspaceRoot != ""
+}
+
+// packageOf returns the package that defines a target (nil outside aggregate mode)
+func (m Model) packageOf(target Target) *aggregatePackage {
+	if target.Package == "" {
+		return nil
+	}
+	for i := range m.Packages {
+		if m.Packages[i].Dir == target.Package {
+			return &m.Packages[i]
+		}
+	}
+	return nil
+}
+
+// makefileFor returns the Makefile a target is run from and recorded under
+func (m Model) makefileFor(target Target) string {
+	if target.Makefile != "" {
+		return target.Makefile
+	}
+	return m.MakefilePath
+}
+
+// makeDir returns the directory make is started in for a target
+// Aggregate targets run in their Makefile's directory; "" runs make in the
+// current directory.
+func makeDir(target Target) string {
+	if target.Makefile == "" {
+		return ""
+	}
+	return filepath.Dir(target.Makefile)
+}
+
+// commandLine returns the make command a target runs, for headers
+func commandLine(target Target) string {
+	if target.Package == "" || target.Package == workspace.RootPackage {
+		return "make " + target.makeName()
+	}
+	return "make -C " + target.Package + " " + target.makeName()
+}
+
+// targetNamed returns the listed target with a name
+// A target that isn't listed (anymore) only has its name.
+func (m Model) targetNamed(name string) Target {
+	for _, t := range m.Targets {
+		if t.Name == name {
+			return t
+		}
+	}
+	return Target{Name: name}
+}
+
+// ownsMakefile reports whether runs recorded for a Makefile are runs of listed targets
+func (m Model) ownsMakefile(path string) bool {
+	if !m.aggregated() {
+		return path == m.MakefilePath
+	}
+	return slices.ContainsFunc(m.Packages, func(pkg aggregatePackage) bool { return pkg.Path == path })
+}
+
+// useVariablesOf shows a package's variables in the variable inspector
+func (m *Model) useVariablesOf(pkg *aggregatePackage) {
+	if pkg.Path == m.VariablesPath {
+		return
+	}
+	m.Variables = pkg.Variables
+	m.UndefinedVariables = variables.UndefinedReferences(pkg.Variables, pkg.Targets)
+	m.VariablesPath = pkg.Path
+	m.VariableCursor = 0
+}
+
+// recentTargets returns the recently run targets, most recent first
+// In aggregate mode the recent targets of every package are merged.
+func (m Model) recentTargets() []Target {
+	if !m.aggregated() {
+		return buildRecentTargets(m.History.GetRecent(m.MakefilePath), m.Targets)
+	}
+
+	var entries []history.Entry
+	for _, pkg := range m.Packages {
+		for _, entry := range m.History.GetRecent(pkg.Path) {
+			entry.Name = workspace.QualifiedName(pkg.Dir, entry.Name)
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b history.Entry) int {
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+	return buildRecentTargets(entries[:min(len(entries), maxAggregateRecent)], m.Targets)
+}
+
+// trends returns the performance trends of the listed Makefile(s), slowest first
+func (m Model) trends() []history.TargetTrend {
+	if m.History == nil {
+		return nil
+	}
+	if !m.aggregated() {
+		return m.History.GetTrends(m.MakefilePath)
+	}
+
+	var trends []history.TargetTrend
+	for _, pkg := range m.Packages {
+		for _, trend := range m.History.GetTrends(pkg.Path) {
+			trend.Name = workspace.QualifiedName(pkg.Dir, trend.Name)
+			trends = append(trends, trend)
+		}
+	}
+	slices.SortFunc(trends, func(a, b history.TargetTrend) int {
+		return cmp.Or(cmp.Compare(b.P50, a.P50), strings.Compare(a.Name, b.Name))
+	})
+	return trends
+}
+
+// recheck checks a target again with variable overrides, which change what
+// its recipe runs (e.g. DEST=/)
+func (m Model) recheck(target Target, overrides []string) Target {
+	checker, depGraph := m.SafetyChecker, m.Graph
+	if pkg := m.packageOf(target); pkg != nil {
+		checker, depGraph = pkg.Checker, pkg.Graph
+	}
+	if checker == nil || depGraph == nil {
+		return target
+	}
+
+	result := checker.WithOverrides(overrides).CheckGraph(depGraph)[target.makeName()]
+	target.applySafety(result)
+	target.SafetyDependencies = qualifyDependencies(target.Package, target.SafetyDependencies)
+	target.Confirmation = checker.Confirmation(target.makeName(), result)
+	return target
+}
+
+// SwitchToAggregate reinitializes the model with every Makefile below the
+// working directory in one list
+func (m Model) SwitchToAggregate(cfg *config.Config) Model {
+	newCfg := *cfg
+	newCfg.MakefilePath = ""
+	newCfg.Workspace = withAggregate(cfg.Workspace, true)
+	return m.switchTo(&newCfg)
+}
+
+// withAggregate returns a copy of the workspace settings with aggregate mode on or off
+func withAggregate(cfg *workspace.Config, aggregate bool) *workspace.Config {
+	if cfg == nil {
+		cfg = workspace.Defaults()
+	}
+	updated := *cfg
+	updated.Aggregate = aggregate
+	return &updated
+}
+
+// packagesDefining counts the packages with a target of the given make name
+func (m Model) packagesDefining(name string) int {
+	n := 0
+	for _, t := range m.Targets {
+		if t.Package != "" && t.makeName() == name {
+			n++
+		}
+	}
+	return n
+}
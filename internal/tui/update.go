@@ -1,9 +1,12 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,9 +18,13 @@ import (
 	"github.com/rshelekhov/lazymake/internal/executor"
 	"github.com/rshelekhov/lazymake/internal/export"
 	"github.com/rshelekhov/lazymake/internal/safety"
-	"github.com/rshelekhov/lazymake/internal/shell"
+	"github.com/rshelekhov/lazymake/internal/tracking"
+	"github.com/rshelekhov/lazymake/internal/variables"
 )
 
+// errExecutionCanceled is the error of a run stopped with ctrl+c
+var errExecutionCanceled = errors.New("execution canceled")
+
 func (m Model) Init() tea.Cmd {
 	return nil
 }
@@ -44,6 +51,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateVariables(msg)
 	case StateWorkspace:
 		return m.updateWorkspace(msg)
+	case StatePerformance:
+		return m.updatePerformance(msg)
+	case StateHistory:
+		return m.updateHistory(msg)
+	case StateDiagnostics:
+		return m.updateDiagnostics(msg)
+	case StateArguments:
+		return m.updateArguments(msg)
+	case StatePackages:
+		return m.updatePackages(msg)
 	default:
 		return m, nil
 	}
@@ -94,6 +111,12 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.State = StateHelp
 		return m, nil
 	case "v":
+		// Aggregate mode: the variables of the selected target's Makefile
+		if target, ok := m.List.SelectedItem().(Target); ok {
+			if pkg := m.packageOf(target); pkg != nil {
+				m.useVariablesOf(pkg)
+			}
+		}
 		m.State = StateVariables
 		m.initVariablesViewport()
 		return m, nil
@@ -101,10 +124,40 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.State = StateWorkspace
 		m.initWorkspacePicker()
 		return m, nil
+	case "p":
+		m.State = StatePerformance
+		m.initPerformanceViewport()
+		return m, nil
+	case "h":
+		m.State = StateHistory
+		m.HistoryCursor = 0
+		m.refreshHistoryRuns()
+		return m, nil
+	case "d":
+		if !m.Linted {
+			return m, nil // Lint disabled or the Makefile couldn't be linted
+		}
+		m.State = StateDiagnostics
+		m.DiagnosticCursor = 0
+		return m, nil
 	case "g":
 		return m.handleGraphView()
+	case ">":
+		return m.jumpToSubMake()
+	case "<":
+		return m.jumpBack()
+	case "a":
+		if target, ok := m.List.SelectedItem().(Target); ok && target.Package != "" {
+			return m.openPackages(target), nil
+		}
+		return m, nil
 	case "enter":
+		if header, ok := m.List.SelectedItem().(HeaderTarget); ok && header.Collapsed {
+			return toggleSection(m), nil
+		}
 		return m.handleTargetSelection()
+	case " ":
+		return toggleSection(m), nil
 	case "ctrl+d":
 		m.RecipeViewport.HalfPageDown()
 		return m, nil
@@ -133,6 +186,7 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 // navigateToTarget moves to next/previous Target, skipping headers and separators
+// Collapsed section headers are stops too, so they can be expanded.
 func navigateToTarget(m Model, down bool) Model {
 	items := m.List.Items()
 	currentIndex := m.List.Index()
@@ -140,7 +194,7 @@ func navigateToTarget(m Model, down bool) Model {
 	if down {
 		// Navigate down
 		for i := currentIndex + 1; i < len(items); i++ {
-			if _, ok := items[i].(Target); ok {
+			if isSelectable(items[i]) {
 				m.List.Select(i)
 				return m
 			}
@@ -148,7 +202,7 @@ func navigateToTarget(m Model, down bool) Model {
 	} else {
 		// Navigate up
 		for i := currentIndex - 1; i >= 0; i-- {
-			if _, ok := items[i].(Target); ok {
+			if isSelectable(items[i]) {
 				m.List.Select(i)
 				return m
 			}
@@ -158,6 +212,70 @@ func navigateToTarget(m Model, down bool) Model {
 	return m
 }
 
+// isSelectable reports whether the cursor can rest on a list item: a target or a collapsed section
+func isSelectable(item list.Item) bool {
+	switch item := item.(type) {
+	case Target:
+		return true
+	case HeaderTarget:
+		return item.Collapsed
+	}
+	return false
+}
+
+// toggleSection folds or unfolds the Makefile section the cursor is in
+// A folded section keeps the cursor on its header; an unfolded one moves it
+// to the section's first target.
+func toggleSection(m Model) Model {
+	items := m.List.Items()
+	for i := m.List.Index(); i >= 0; i-- {
+		header, ok := items[i].(HeaderTarget)
+		if !ok {
+			continue
+		}
+		if !header.Collapsible {
+			return m // RECENT or the only section
+		}
+
+		if m.CollapsedSections == nil {
+			m.CollapsedSections = make(map[string]bool)
+		}
+		m.CollapsedSections[header.Label] = !header.Collapsed
+		m.List.SetItems(buildItemsList(m.AllTargets, m.RecentTargets, m.CollapsedSections))
+
+		// Items above the header are unchanged, so it keeps its index
+		m.List.Select(i)
+		if header.Collapsed {
+			m = navigateToTarget(m, true)
+		}
+		return updateRecipeViewportContent(m)
+	}
+	return m
+}
+
+// selectTarget moves the cursor to a listed target
+// A target in a folded section is shown by unfolding it; targets hidden by
+// the filter aren't found.
+func (m Model) selectTarget(name string) (Model, bool) {
+	if !m.IsFiltering {
+		for _, target := range m.AllTargets {
+			if target.Name == name && m.CollapsedSections[sectionLabel(target)] {
+				delete(m.CollapsedSections, sectionLabel(target))
+				m.List.SetItems(buildItemsList(m.AllTargets, m.RecentTargets, m.CollapsedSections))
+				break
+			}
+		}
+	}
+
+	for i, item := range m.List.Items() {
+		if target, ok := item.(Target); ok && target.Name == name {
+			m.List.Select(i)
+			return updateRecipeViewportContent(m), true
+		}
+	}
+	return m, false
+}
+
 // handleFilteringKeys handles key input when filtering mode is active
 func (m Model) handleFilteringKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
@@ -179,6 +297,14 @@ func (m Model) handleFilteringKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case tea.KeySpace:
+		// Separates tag: tokens from the rest of the query
+		m.FilterInput += " "
+		m = applyCustomFilter(m)
+		m = ensureCursorOnTarget(m)
+		m = updateRecipeViewportContent(m)
+		return m, nil
+
 	case tea.KeyRunes:
 		// Check if it's "/" and filter is empty - close filter
 		if string(msg.Runes) == "/" && m.FilterInput == "" {
@@ -214,6 +340,7 @@ func (m Model) handleGraphView() (tea.Model, tea.Cmd) {
 		if target, ok := selected.(Target); ok {
 			m.State = StateGraph
 			m.GraphTarget = target.Name
+			m.ParallelAnalysis = m.buildParallelAnalysis()
 			return m, nil
 		}
 	}
@@ -228,24 +355,44 @@ func (m Model) handleTargetSelection() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Check if target is critical and requires confirmation
-	if target.IsDangerous && target.DangerLevel == safety.SeverityCritical {
-		targetCopy := target
-		m.PendingTarget = &targetCopy
-		m.State = StateConfirmDangerous
-		return m, nil
+	m.ExecutingArgs = nil
+
+	// Documented arguments are asked for first, prefilled with their defaults
+	if len(target.Docs.Args) > 0 {
+		return m.openArguments(target), nil
+	}
+	return m.executeTarget(target)
+}
+
+// executeTarget runs a target with m.ExecutingArgs, asking for confirmation first if the profile requires it
+func (m Model) executeTarget(target Target) (tea.Model, tea.Cmd) {
+	// Variable overrides (e.g. DEST=/) change what the recipe runs, so check it again
+	if len(m.ExecutingArgs) > 0 {
+		target = m.recheck(target, m.ExecutingArgs)
+	}
+
+	// Check if the active profile requires confirmation at this danger level
+	if m.requiresConfirmation(target) {
+		return m.openConfirmation(target)
 	}
 
 	// Safe or non-critical target - execute immediately
-	m.History.RecordExecution(m.MakefilePath, target.Name)
-	_ = m.History.Save()
+	return m.startExecution(target, nil)
+}
+
+// startExecution records the target in history and starts streaming its output
+// confirmation describes how it was confirmed (nil if it ran without confirmation).
+func (m Model) startExecution(target Target, confirmation *export.Confirmation) (tea.Model, tea.Cmd) {
+	makefilePath := m.makefileFor(target)
+	m.Tracker.Started(makefilePath, target.makeName())
 
 	// Refresh recent targets for next render
-	recentEntries := m.History.GetRecent(m.MakefilePath)
-	m.RecentTargets = buildRecentTargets(recentEntries, m.Targets)
+	m.RecentTargets = m.recentTargets()
 
 	m.State = StateExecuting
 	m.ExecutingTarget = target.Name
+	m.ExecutingCommand = commandLine(target)
+	m.ExecutingConfirmation = confirmation
 	m.ExecutionStartTime = time.Now()
 	m.ExecutionElapsed = 0
 
@@ -254,12 +401,18 @@ func (m Model) handleTargetSelection() (tea.Model, tea.Cmd) {
 	m.initExecutingViewport()
 
 	return m, tea.Batch(
-		executeTargetStreaming(target.Name, m.MakefilePath),
+		executeTargetStreaming(makeDir(target), target.makeName(), makefilePath, m.ExecutingArgs...),
 		tickTimer(),
 		m.Spinner.Tick,
 	)
 }
 
+// requiresConfirmation reports whether a target must be confirmed before it runs
+func (m Model) requiresConfirmation(target Target) bool {
+	result := &safety.CheckResult{IsDangerous: target.IsDangerous, DangerLevel: target.DangerLevel}
+	return m.SafetyProfile.ConfirmPolicy().RequiresConfirmation(result, target.Confirmation)
+}
+
 // handleWindowResize updates dimensions and layout when window size changes
 func (m Model) handleWindowResize(msg tea.WindowSizeMsg) Model {
 	m.Width = msg.Width
@@ -315,16 +468,17 @@ func (m Model) updateRecipeViewportForSelection(rightWidth int) {
 func applyCustomFilter(m Model) Model {
 	if m.FilterInput == "" {
 		// No filter, show all with headers
-		items := buildItemsList(m.AllTargets, m.RecentTargets)
+		items := buildItemsList(m.AllTargets, m.RecentTargets, m.CollapsedSections)
 		m.List.SetItems(items)
 		return m
 	}
 
-	// Fuzzy filter targets
+	// tag: tokens must all match; the rest of the query is matched against name and description
+	tags, query := parseTagFilter(m.FilterInput)
 	var filteredTargets []Target
-	for _, target := range m.AllTargets {
-		filterValue := target.Name + " " + target.Description
-		if fuzzyMatch(m.FilterInput, filterValue) {
+	for _, target := range visibleTargets(m.AllTargets) {
+		if !slices.ContainsFunc(tags, func(tag string) bool { return !target.Docs.HasTag(tag) }) &&
+			fuzzyMatch(query, target.Name+" "+target.Description) {
 			filteredTargets = append(filteredTargets, target)
 		}
 	}
@@ -344,6 +498,23 @@ func applyCustomFilter(m Model) Model {
 	return m
 }
 
+// parseTagFilter splits a filter query into its tag: tokens and the remaining text
+//
+//	"tag:release tag:prod deploy" -> ["release", "prod"], "deploy"
+func parseTagFilter(input string) (tags []string, query string) {
+	var rest []string
+	for _, field := range strings.Fields(input) {
+		if tag, ok := strings.CutPrefix(field, "tag:"); ok {
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+			continue
+		}
+		rest = append(rest, field)
+	}
+	return tags, strings.Join(rest, " ")
+}
+
 // fuzzyMatch performs simple case-insensitive substring matching
 func fuzzyMatch(pattern, text string) bool {
 	pattern = strings.ToLower(pattern)
@@ -359,8 +530,8 @@ func ensureCursorOnTarget(m Model) Model {
 		return m
 	}
 
-	// Check if current selection is a Target
-	if _, ok := selectedItem.(Target); ok {
+	// Check if current selection is a Target (or a collapsed section)
+	if isSelectable(selectedItem) {
 		return m // Already on a target
 	}
 
@@ -370,7 +541,7 @@ func ensureCursorOnTarget(m Model) Model {
 
 	// Try forward first
 	for i := currentIndex + 1; i < len(items); i++ {
-		if _, ok := items[i].(Target); ok {
+		if isSelectable(items[i]) {
 			m.List.Select(i)
 			return m
 		}
@@ -378,7 +549,7 @@ func ensureCursorOnTarget(m Model) Model {
 
 	// Try backward
 	for i := currentIndex - 1; i >= 0; i-- {
-		if _, ok := items[i].(Target); ok {
+		if isSelectable(items[i]) {
 			m.List.Select(i)
 			return m
 		}
@@ -411,6 +582,9 @@ func updateRecipeViewportContent(m Model) Model {
 			content := m.buildRecipeContent(&target, rightWidth)
 			m.RecipeViewport.SetContent(content)
 			m.RecipeViewport.GotoTop() // Auto-scroll to top on new selection
+		} else if header, ok := selectedItem.(HeaderTarget); ok {
+			m.RecipeViewport.SetContent(buildSectionContent(header))
+			m.RecipeViewport.GotoTop()
 		}
 	}
 
@@ -424,7 +598,7 @@ func (m Model) updateOutput(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		case "esc":
-			m.State = StateList
+			m.State = m.OutputReturnState
 			return m, nil
 		}
 		var cmd tea.Cmd
@@ -468,6 +642,7 @@ func (m Model) updateGraph(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Return to list view and clear graph target
 			m.State = StateList
 			m.GraphTarget = ""
+			m.ParallelAnalysis = nil
 			return m, nil
 
 		case "+", "=":
@@ -497,6 +672,10 @@ func (m Model) updateGraph(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "p", "P":
 			// Toggle parallel display
 			m.ShowParallel = !m.ShowParallel
+
+		case "j", "J":
+			// Toggle -j simulation display
+			m.ShowJobs = !m.ShowJobs
 		}
 
 	case tea.WindowSizeMsg:
@@ -545,7 +724,7 @@ func (m Model) handleExecutingKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.CancelExecution != nil {
 			m.CancelExecution()
 		}
-		return m.handleExecutionComplete(fmt.Errorf("execution canceled"))
+		return m.handleExecutionComplete(errExecutionCanceled)
 	case "j", "down":
 		m.ExecutingViewport.ScrollDown(1)
 	case "k", "up":
@@ -599,76 +778,50 @@ func (m Model) handleExecutionComplete(err error) (tea.Model, tea.Cmd) {
 	// Calculate execution duration
 	duration := time.Since(m.ExecutionStartTime)
 
-	// Record execution with timing data
-	success := err == nil
-	m.History.RecordExecutionWithTiming(m.MakefilePath, m.ExecutingTarget, duration, success)
-	_ = m.History.Save() // Async, ignore errors
-
-	// Build result for export
-	result := executor.Result{
-		Output:    m.StreamingOutput.String(),
-		Err:       err,
-		Duration:  duration,
-		StartTime: m.ExecutionStartTime,
-		EndTime:   time.Now(),
-	}
-
-	// Extract exit code from error
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitError.ExitCode()
-		} else {
-			result.ExitCode = -1
-		}
-	}
-
-	// Export execution result (async, non-blocking)
-	if m.Exporter != nil {
-		go func() {
-			record := export.NewExecutionRecord(
-				m.MakefilePath,
-				m.ExecutingTarget,
-				result,
-			)
-			if err := m.Exporter.Export(record); err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
-			}
-		}()
-	}
-
-	// Shell integration (async, non-blocking)
-	if m.ShellIntegration != nil {
-		go func() {
-			if err := m.ShellIntegration.RecordExecution(shell.ExecutionInfo{
-			Target:       m.ExecutingTarget,
-			MakefilePath: m.MakefilePath,
-		}); err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Shell integration failed: %v\n", err)
-			}
-		}()
-	}
+	// Record the run in history, exports and shell history
+	target := m.targetNamed(m.ExecutingTarget)
+	m.Tracker.Finished(tracking.Run{
+		MakefilePath: m.makefileFor(target),
+		Target:       target.makeName(),
+		Args:         m.ExecutingArgs,
+		Result: executor.Result{
+			Output:    m.StreamingOutput.String(),
+			Err:       err,
+			Duration:  duration,
+			ExitCode:  executor.ExitCode(err),
+			StartTime: m.ExecutionStartTime,
+			EndTime:   time.Now(),
+		},
+		Confirmation: m.ExecutingConfirmation,
+	})
 
 	// Refresh performance stats for all targets
 	enrichTargetsWithPerformance(m.History, m.MakefilePath, m.Targets)
 
 	// Refresh recent targets to show updated timing
-	recentEntries := m.History.GetRecent(m.MakefilePath)
-	m.RecentTargets = buildRecentTargets(recentEntries, m.Targets)
+	m.RecentTargets = m.recentTargets()
 
 	// Rebuild and update list items to reflect new performance stats
-	updatedItems := rebuildListItems(m.RecentTargets, m.Targets)
+	updatedItems := rebuildListItems(m.RecentTargets, m.Targets, m.CollapsedSections)
 	m.List.SetItems(updatedItems)
 
+	// Clean up
+	m.CancelExecution = nil
+	m.OutputChunks = nil
+	m.ExecutingConfirmation = nil
+
+	// Running a target in every package: on to the next one
+	if m.BatchOutput != nil {
+		return m.continueBatch(err, duration)
+	}
+
 	// Transition to output view
 	m.State = StateOutput
+	m.OutputReturnState = StateList
 	m.Output = m.StreamingOutput.String()
 	m.ExecutionError = err
 	m.initViewport(m.Output)
 
-	// Clean up
-	m.CancelExecution = nil
-	m.OutputChunks = nil
-
 	return m, nil
 }
 
@@ -739,12 +892,40 @@ func (m *Model) initVariablesViewport() {
 	m.VariablesViewport = viewport.New(contentWidth, contentHeight)
 	m.VariablesViewport.Style = lipgloss.NewStyle()
 
-	// Set content
-	content := m.buildVariablesContent()
-	m.VariablesViewport.SetContent(content)
-
 	// Start at top
 	m.VariablesViewport.YPosition = 0
+
+	if m.VariableTrace != nil {
+		m.VariablesViewport.SetContent(m.buildTraceContent())
+		return
+	}
+	m.refreshVariablesViewport()
+}
+
+// refreshVariablesViewport redraws the variable list and scrolls the selected variable into view
+func (m *Model) refreshVariablesViewport() {
+	content, start, end := m.buildVariablesContent()
+	m.VariablesViewport.SetContent(content)
+
+	if start < m.VariablesViewport.YOffset {
+		m.VariablesViewport.SetYOffset(start)
+	} else if end > m.VariablesViewport.YOffset+m.VariablesViewport.Height {
+		m.VariablesViewport.SetYOffset(end - m.VariablesViewport.Height)
+	}
+}
+
+func (m *Model) initPerformanceViewport() {
+	// Same layout as the variable inspector: bordered container + status bar
+	statusBarHeight := 3
+	availableHeight := m.Height - statusBarHeight
+
+	contentWidth := m.Width - 8
+	contentHeight := availableHeight - 6
+
+	m.PerformanceViewport = viewport.New(contentWidth, contentHeight)
+	m.PerformanceViewport.Style = lipgloss.NewStyle()
+	m.PerformanceViewport.SetContent(m.buildPerformanceContent(contentWidth))
+	m.PerformanceViewport.YPosition = 0
 }
 
 func computeViewportSize(winWidth, winHeight int) (int, int) {
@@ -774,60 +955,6 @@ func computeViewportSize(winWidth, winHeight int) (int, int) {
 	return viewportWidth, viewportHeight
 }
 
-// updateConfirmDangerous handles the dangerous command confirmation dialog
-func (m Model) updateConfirmDangerous(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-
-		case "esc":
-			// Cancel confirmation, return to list
-			m.State = StateList
-			m.PendingTarget = nil
-			return m, nil
-
-		case "enter":
-			// Proceed with execution of dangerous target
-			if m.PendingTarget != nil {
-				target := *m.PendingTarget
-
-				// Record execution in history
-				m.History.RecordExecution(m.MakefilePath, target.Name)
-				_ = m.History.Save()
-
-				// Refresh recent targets
-				recentEntries := m.History.GetRecent(m.MakefilePath)
-				m.RecentTargets = buildRecentTargets(recentEntries, m.Targets)
-
-				// Clear pending target and start execution
-				m.PendingTarget = nil
-				m.State = StateExecuting
-				m.ExecutingTarget = target.Name
-				m.ExecutionStartTime = time.Now()
-				m.ExecutionElapsed = 0
-
-				// Reset streaming output and initialize viewport
-				m.StreamingOutput = &strings.Builder{}
-				m.initExecutingViewport()
-
-				return m, tea.Batch(
-					executeTargetStreaming(target.Name, m.MakefilePath),
-					tickTimer(),   // Start timer
-					m.Spinner.Tick, // Start spinner animation
-				)
-			}
-		}
-
-	case tea.WindowSizeMsg:
-		m.Width = msg.Width
-		m.Height = msg.Height
-	}
-
-	return m, nil
-}
-
 // Custom message for timer ticks
 type timerTickMsg struct{}
 
@@ -850,10 +977,10 @@ func tickTimer() tea.Cmd {
 	})
 }
 
-// executeTargetStreaming starts streaming execution
-func executeTargetStreaming(target, makefilePath string) tea.Cmd {
+// executeTargetStreaming starts streaming execution, with make -C dir unless dir is empty
+func executeTargetStreaming(dir, target, makefilePath string, args ...string) tea.Cmd {
 	return func() tea.Msg {
-		chunks, cancel := executor.ExecuteStreaming(target, makefilePath)
+		chunks, cancel := executor.ExecuteStreamingIn(dir, target, makefilePath, args...)
 		return streamStartedMsg{chunks: chunks, cancel: cancel}
 	}
 }
@@ -878,6 +1005,10 @@ func waitForChunk(chunks <-chan executor.OutputChunk) tea.Cmd {
 func (m Model) updateVariables(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.VariableTrace != nil {
+			return m.handleTraceKeys(msg)
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
@@ -886,6 +1017,20 @@ func (m Model) updateVariables(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Return to list view
 			m.State = StateList
 			return m, nil
+
+		case "up", "k":
+			m.VariableCursor = max(m.VariableCursor-1, 0)
+			m.refreshVariablesViewport()
+			return m, nil
+
+		case "down", "j":
+			m.VariableCursor = min(m.VariableCursor+1, max(len(m.Variables)-1, 0))
+			m.refreshVariablesViewport()
+			return m, nil
+
+		case "enter", "t":
+			m.openVariableTrace()
+			return m, nil
 		}
 		// Pass other keys to viewport for scrolling
 		var cmd tea.Cmd
@@ -900,3 +1045,106 @@ func (m Model) updateVariables(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	return m, nil
 }
+
+// handleTraceKeys steps through the expansion trace of the selected variable
+func (m Model) handleTraceKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "t", "enter":
+		// Back to the variable list
+		m.VariableTrace = nil
+		m.initVariablesViewport()
+		return m, nil
+
+	case "right", "l", " ":
+		m.TraceStep = min(m.TraceStep+1, len(m.VariableTrace.Steps)-1)
+
+	case "left", "h":
+		m.TraceStep = max(m.TraceStep-1, 0)
+
+	case "end", "G":
+		m.TraceStep = len(m.VariableTrace.Steps) - 1
+
+	case "home", "g":
+		m.TraceStep = 0
+
+	default:
+		var cmd tea.Cmd
+		m.VariablesViewport, cmd = m.VariablesViewport.Update(msg)
+		return m, cmd
+	}
+
+	m.VariablesViewport.SetContent(m.buildTraceContent())
+	m.VariablesViewport.GotoBottom() // Keep the step just revealed in view
+	return m, nil
+}
+
+// openVariableTrace traces the expansion of the selected variable
+// $(shell) commands run in the Makefile's directory, with a timeout.
+func (m *Model) openVariableTrace() {
+	if m.VariableCursor >= len(m.Variables) {
+		return
+	}
+
+	dir := filepath.Dir(m.VariablesPath)
+	trace, ok := variables.TraceExpansion(m.Variables[m.VariableCursor].Name, m.Variables, variables.TraceOptions{
+		Shell: traceShell(dir),
+		Dir:   dir,
+	})
+	if !ok {
+		return
+	}
+
+	m.VariableTrace = &trace
+	m.TraceStep = 0
+	m.VariablesViewport.SetContent(m.buildTraceContent())
+	m.VariablesViewport.GotoTop()
+}
+
+// traceShellTimeout bounds each $(shell) command run while tracing
+const traceShellTimeout = 2 * time.Second
+
+// traceShell runs $(shell) commands for a trace the way make does: with sh, in dir
+func traceShell(dir string) func(string) (string, error) {
+	return func(command string) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), traceShellTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("timed out after %s", traceShellTimeout)
+		}
+		return string(output), err
+	}
+}
+
+// updatePerformance handles the performance dashboard view state
+func (m Model) updatePerformance(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+
+		case "esc", "p":
+			// Return to list view
+			m.State = StateList
+			return m, nil
+		}
+		// Pass other keys to viewport for scrolling
+		var cmd tea.Cmd
+		m.PerformanceViewport, cmd = m.PerformanceViewport.Update(msg)
+		return m, cmd
+
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+		m.initPerformanceViewport() // Reinitialize viewport with new dimensions
+	}
+
+	return m, nil
+}
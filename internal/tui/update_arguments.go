@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openArguments shows the argument prompt for a target documented with "## @arg"
+func (m Model) openArguments(target Target) Model {
+	m.ArgumentsTarget = &target
+	m.ArgumentValues = make([]string, len(target.Docs.Args))
+	for i, arg := range target.Docs.Args {
+		m.ArgumentValues[i] = arg.Default
+	}
+	m.ArgumentField = 0
+	m.State = StateArguments
+	return m
+}
+
+// updateArguments handles the argument prompt
+func (m Model) updateArguments(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleArgumentsKeyPress(msg)
+
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	}
+
+	return m, nil
+}
+
+// handleArgumentsKeyPress handles keys in the argument prompt
+// Letters are typed into the focused input, so only ctrl+c quits.
+func (m Model) handleArgumentsKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ArgumentsTarget == nil {
+		m.State = StateList
+		return m, nil
+	}
+	count := len(m.ArgumentValues)
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.State = StateList
+		m.ArgumentsTarget = nil
+		return m, nil
+
+	case tea.KeyEnter:
+		target := *m.ArgumentsTarget
+		m.ExecutingArgs = m.argumentOverrides()
+		m.ArgumentsTarget = nil
+		m.State = StateList
+		return m.executeTarget(target)
+
+	case tea.KeyTab, tea.KeyDown:
+		m.ArgumentField = (m.ArgumentField + 1) % count
+		return m, nil
+
+	case tea.KeyShiftTab, tea.KeyUp:
+		m.ArgumentField = (m.ArgumentField - 1 + count) % count
+		return m, nil
+
+	case tea.KeyBackspace:
+		if value := []rune(m.ArgumentValues[m.ArgumentField]); len(value) > 0 {
+			m.ArgumentValues[m.ArgumentField] = string(value[:len(value)-1])
+		}
+		return m, nil
+
+	case tea.KeyCtrlU:
+		m.ArgumentValues[m.ArgumentField] = ""
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.ArgumentValues[m.ArgumentField] += string(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// argumentOverrides returns the entered arguments as make variable overrides (NAME=value)
+// Arguments left empty aren't passed, so the Makefile's own value applies.
+func (m Model) argumentOverrides() []string {
+	var overrides []string
+	for i, arg := range m.ArgumentsTarget.Docs.Args {
+		if value := strings.TrimSpace(m.ArgumentValues[i]); value != "" {
+			overrides = append(overrides, arg.Name+"="+value)
+		}
+	}
+	return overrides
+}
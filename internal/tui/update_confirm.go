@@ -0,0 +1,194 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rshelekhov/lazymake/internal/export"
+)
+
+// Inputs of the confirmation dialog
+const (
+	confirmFieldPhrase = iota // Target or environment name
+	confirmFieldReason        // Free-text reason
+)
+
+// confirmTickMsg redraws the confirmation dialog while the countdown runs
+type confirmTickMsg struct{}
+
+// openConfirmation shows the confirmation dialog for a target
+func (m Model) openConfirmation(target Target) (tea.Model, tea.Cmd) {
+	m.PendingTarget = &target
+	m.State = StateConfirmDangerous
+	m.ConfirmInput = ""
+	m.ConfirmReason = ""
+	m.ConfirmField = confirmFieldPhrase
+	if m.confirmPhrase() == "" {
+		m.ConfirmField = confirmFieldReason
+	}
+	m.ConfirmReadyAt = time.Now().Add(target.Confirmation.Countdown)
+
+	if target.Confirmation.Countdown > 0 {
+		return m, tickConfirmation()
+	}
+	return m, nil
+}
+
+// updateConfirmDangerous handles the dangerous command confirmation dialog
+func (m Model) updateConfirmDangerous(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case confirmTickMsg:
+		if m.confirmCountdown() > 0 {
+			return m, tickConfirmation()
+		}
+
+	case tea.KeyMsg:
+		return m.handleConfirmKeyPress(msg)
+
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	}
+
+	return m, nil
+}
+
+// handleConfirmKeyPress handles keys in the confirmation dialog
+// While text is required, letters are typed into the focused input, so only
+// ctrl+c quits.
+func (m Model) handleConfirmKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	typing := m.confirmPhrase() != "" || m.confirmRequiresReason()
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		// Cancel confirmation, return to list (or to the packages of an all-packages run)
+		m.State = StateList
+		if m.BatchOutput != nil {
+			m.State = StatePackages
+			m.BatchQueue = nil
+			m.BatchOutput = nil
+		}
+		m.PendingTarget = nil
+		return m, nil
+
+	case tea.KeyEnter:
+		if m.PendingTarget == nil {
+			return m, nil
+		}
+		if m.confirmReady() {
+			target := *m.PendingTarget
+			confirmation := m.confirmationRecord()
+			m.PendingTarget = nil
+			if m.BatchOutput != nil {
+				// One confirmation for every package
+				m.BatchConfirmation = confirmation
+				return m.nextBatchRun()
+			}
+			return m.startExecution(target, confirmation)
+		}
+		// The name is typed correctly: move on to the reason
+		if m.ConfirmField == confirmFieldPhrase && m.ConfirmInput == m.confirmPhrase() && m.confirmRequiresReason() {
+			m.ConfirmField = confirmFieldReason
+		}
+		return m, nil
+
+	case tea.KeyTab, tea.KeyShiftTab:
+		if m.confirmPhrase() != "" && m.confirmRequiresReason() {
+			m.ConfirmField = 1 - m.ConfirmField
+		}
+		return m, nil
+
+	case tea.KeyBackspace:
+		field := m.focusedConfirmInput()
+		if field != nil && len(*field) > 0 {
+			runes := []rune(*field)
+			*field = string(runes[:len(runes)-1])
+		}
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		if !typing {
+			if msg.String() == "q" {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if field := m.focusedConfirmInput(); field != nil {
+			*field += string(msg.Runes)
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// focusedConfirmInput returns the input that receives typed text
+func (m *Model) focusedConfirmInput() *string {
+	switch {
+	case m.ConfirmField == confirmFieldPhrase && m.confirmPhrase() != "":
+		return &m.ConfirmInput
+	case m.ConfirmField == confirmFieldReason && m.confirmRequiresReason():
+		return &m.ConfirmReason
+	}
+	return nil
+}
+
+// confirmPhrase returns the text the pending target requires typing ("" if none)
+func (m Model) confirmPhrase() string {
+	if m.PendingTarget == nil {
+		return ""
+	}
+	return m.PendingTarget.Confirmation.Phrase(m.PendingTarget.Name, m.SafetyEnvironment.Name())
+}
+
+// confirmRequiresReason reports whether the pending target requires a reason
+func (m Model) confirmRequiresReason() bool {
+	return m.PendingTarget != nil && m.PendingTarget.Confirmation.RequireReason
+}
+
+// confirmCountdown returns how long until the pending target can be confirmed
+func (m Model) confirmCountdown() time.Duration {
+	return max(time.Until(m.ConfirmReadyAt), 0)
+}
+
+// confirmReady reports whether every requirement of the dialog is met
+func (m Model) confirmReady() bool {
+	if m.confirmCountdown() > 0 {
+		return false
+	}
+	if m.ConfirmInput != m.confirmPhrase() {
+		return false
+	}
+	return !m.confirmRequiresReason() || strings.TrimSpace(m.ConfirmReason) != ""
+}
+
+// confirmationRecord describes the confirmation for history and exports
+func (m Model) confirmationRecord() *export.Confirmation {
+	target := m.PendingTarget
+
+	record := &export.Confirmation{
+		ConfirmedAt:      time.Now(),
+		Rules:            target.safetyResult().RuleIDs(),
+		Typed:            m.ConfirmInput,
+		CountdownSeconds: int(target.Confirmation.Countdown.Round(time.Second).Seconds()),
+		Reason:           strings.TrimSpace(m.ConfirmReason),
+	}
+	if target.IsDangerous {
+		record.DangerLevel = strings.ToLower(target.DangerLevel.String())
+	}
+	if m.SafetyProfile.Profile != nil {
+		record.Profile = m.SafetyProfile.Profile.Name
+	}
+	return record
+}
+
+// tickConfirmation redraws the confirmation dialog while its countdown runs
+func tickConfirmation() tea.Cmd {
+	return tea.Tick(200*time.Millisecond, func(t time.Time) tea.Msg {
+		return confirmTickMsg{}
+	})
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rshelekhov/lazymake/internal/lint"
+)
+
+// updateDiagnostics handles the lint diagnostics panel
+func (m Model) updateDiagnostics(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+
+		case "esc", "d":
+			m.State = StateList
+			return m, nil
+
+		case "up", "k":
+			m.DiagnosticCursor = max(m.DiagnosticCursor-1, 0)
+
+		case "down", "j":
+			m.DiagnosticCursor = min(m.DiagnosticCursor+1, max(len(m.Diagnostics)-1, 0))
+
+		case "enter":
+			return m.jumpToDiagnosticTarget(), nil
+		}
+
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	}
+
+	return m, nil
+}
+
+// selectedDiagnostic returns the diagnostic under the cursor, or nil if there are none
+func (m Model) selectedDiagnostic() *lint.Diagnostic {
+	if m.DiagnosticCursor < 0 || m.DiagnosticCursor >= len(m.Diagnostics) {
+		return nil
+	}
+	return &m.Diagnostics[m.DiagnosticCursor]
+}
+
+// jumpToDiagnosticTarget returns to the list with the selected diagnostic's target selected
+// Diagnostics about variables or targets hidden by the filter stay in the panel.
+func (m Model) jumpToDiagnosticTarget() Model {
+	d := m.selectedDiagnostic()
+	if d == nil || d.Target == "" {
+		return m
+	}
+
+	if selected, found := m.selectTarget(d.Target); found {
+		selected.State = StateList
+		return selected
+	}
+	return m
+}
+
+// countLintProblems counts the errors and warnings that aren't allowed by an annotation
+func (m Model) countLintProblems() int {
+	count := 0
+	for _, d := range m.Diagnostics {
+		if d.Severity >= lint.SeverityWarning && !d.Suppressed {
+			count++
+		}
+	}
+	return count
+}
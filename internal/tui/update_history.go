@@ -0,0 +1,240 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/export"
+	"github.com/rshelekhov/lazymake/internal/history"
+)
+
+// historyRanges are the quick date ranges cycled with "d" in the history browser
+var historyRanges = []struct {
+	Label  string
+	Window time.Duration // 0 = all time
+}{
+	{"all time", 0},
+	{"last 24h", 24 * time.Hour},
+	{"last 7d", 7 * 24 * time.Hour},
+	{"last 30d", 30 * 24 * time.Hour},
+}
+
+// historyRerunMsg is sent when a re-run needs a workspace switch first
+type historyRerunMsg struct {
+	newModel Model
+	run      history.Run
+}
+
+// updateHistory handles the history browser view state
+func (m Model) updateHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case historyRerunMsg:
+		// Workspace switched - run the target in the new model
+		return msg.newModel.rerunInCurrentWorkspace(msg.run)
+
+	case tea.KeyMsg:
+		if m.HistoryFiltering {
+			return m.handleHistoryFilterKeys(msg), nil
+		}
+
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+
+		case "esc", "h":
+			m.State = StateList
+			m.HistoryMessage = ""
+			return m, nil
+
+		case "up", "k":
+			m.HistoryCursor = max(m.HistoryCursor-1, 0)
+			m.HistoryMessage = ""
+
+		case "down", "j":
+			m.HistoryCursor = min(m.HistoryCursor+1, max(len(m.HistoryRuns)-1, 0))
+			m.HistoryMessage = ""
+
+		case "/":
+			m.HistoryFiltering = true
+
+		case "s":
+			// Cycle all → success → failed
+			m.HistoryStatus = (m.HistoryStatus + 1) % 3
+			m.refreshHistoryRuns()
+
+		case "d":
+			m.HistoryRange = (m.HistoryRange + 1) % len(historyRanges)
+			m.refreshHistoryRuns()
+
+		case "c":
+			m.HistoryThisProject = !m.HistoryThisProject
+			m.refreshHistoryRuns()
+
+		case "enter":
+			return m.handleHistoryRerun()
+
+		case "o":
+			return m.handleHistoryOpenOutput(), nil
+		}
+
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	}
+
+	return m, nil
+}
+
+// handleHistoryFilterKeys handles typing a query in the history browser
+func (m Model) handleHistoryFilterKeys(msg tea.KeyMsg) Model {
+	switch msg.Type {
+	case tea.KeyEsc:
+		// Clear and stop filtering
+		m.HistoryFiltering = false
+		m.HistoryFilter = ""
+	case tea.KeyEnter:
+		// Keep the query, return to navigation
+		m.HistoryFiltering = false
+	case tea.KeyBackspace:
+		if len(m.HistoryFilter) > 0 {
+			m.HistoryFilter = m.HistoryFilter[:len(m.HistoryFilter)-1]
+		}
+	case tea.KeySpace:
+		m.HistoryFilter += " "
+	case tea.KeyRunes:
+		m.HistoryFilter += string(msg.Runes)
+	default:
+		return m
+	}
+
+	m.refreshHistoryRuns()
+	return m
+}
+
+// refreshHistoryRuns re-runs the history query with the typed filter and quick toggles
+// Typed tokens (status:, since:) take precedence over the quick toggles.
+func (m *Model) refreshHistoryRuns() {
+	if m.History == nil {
+		m.HistoryRuns = nil
+		return
+	}
+
+	now := time.Now()
+	query := history.ParseQuery(m.HistoryFilter, now)
+	if query.Status == history.StatusAny {
+		query.Status = m.HistoryStatus
+	}
+	if window := historyRanges[m.HistoryRange].Window; query.Since.IsZero() && window > 0 {
+		query.Since = now.Add(-window)
+	}
+
+	runs := m.History.Runs(query)
+	if m.HistoryThisProject {
+		filtered := runs[:0]
+		for _, run := range runs {
+			if m.ownsMakefile(run.MakefilePath) {
+				filtered = append(filtered, run)
+			}
+		}
+		runs = filtered
+	}
+
+	m.HistoryRuns = runs
+	m.HistoryCursor = min(m.HistoryCursor, max(len(runs)-1, 0))
+}
+
+// selectedHistoryRun returns the run under the cursor, or nil if the list is empty
+func (m Model) selectedHistoryRun() *history.Run {
+	if m.HistoryCursor < 0 || m.HistoryCursor >= len(m.HistoryRuns) {
+		return nil
+	}
+	return &m.HistoryRuns[m.HistoryCursor]
+}
+
+// handleHistoryRerun re-runs the selected target with its original arguments
+// Runs from another Makefile switch the workspace first.
+func (m Model) handleHistoryRerun() (tea.Model, tea.Cmd) {
+	run := m.selectedHistoryRun()
+	if run == nil {
+		return m, nil
+	}
+
+	if m.ownsMakefile(run.MakefilePath) {
+		return m.rerunInCurrentWorkspace(*run)
+	}
+
+	if _, err := os.Stat(run.MakefilePath); err != nil {
+		m.HistoryMessage = "Makefile no longer exists: " + run.MakefilePath
+		return m, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		m.HistoryMessage = "Failed to load config: " + err.Error()
+		return m, nil
+	}
+
+	selected := *run
+	oldModel := m
+	return m, func() tea.Msg {
+		return historyRerunMsg{
+			newModel: oldModel.SwitchWorkspace(selected.MakefilePath, cfg),
+			run:      selected,
+		}
+	}
+}
+
+// rerunInCurrentWorkspace executes a historical run's target in the current Makefile
+func (m Model) rerunInCurrentWorkspace(run history.Run) (tea.Model, tea.Cmd) {
+	for _, target := range m.Targets {
+		if target.makeName() == run.Target && m.makefileFor(target) == run.MakefilePath {
+			m.ExecutingArgs = run.Args
+			return m.executeTarget(target)
+		}
+	}
+
+	// Target was removed or renamed since the run was recorded
+	m.State = StateHistory
+	m.refreshHistoryRuns()
+	m.HistoryMessage = fmt.Sprintf("Target %q no longer exists in %s", run.Target, run.MakefilePath)
+	return m, nil
+}
+
+// handleHistoryOpenOutput shows the exported output of the selected run
+func (m Model) handleHistoryOpenOutput() Model {
+	run := m.selectedHistoryRun()
+	if run == nil {
+		return m
+	}
+
+	// Output captured in the history database, then the export file
+	output, err := m.History.RunOutput(*run)
+	if err != nil {
+		if run.ExportPath == "" {
+			m.HistoryMessage = "No output for this run (enable export or the sqlite storage backend to capture output)"
+			return m
+		}
+
+		output, err = export.ReadOutput(run.ExportPath)
+		if err != nil {
+			m.HistoryMessage = "Export not available: " + err.Error()
+			return m
+		}
+	}
+
+	m.ExecutingTarget = run.Target
+	m.ExecutingCommand = "make " + run.Target
+	m.ExecutionError = nil
+	if !run.Success {
+		m.ExecutionError = fmt.Errorf("exit code %d", run.ExitCode)
+	}
+	m.Output = output
+	m.initViewport(output)
+	m.State = StateOutput
+	m.OutputReturnState = StateHistory
+
+	return m
+}
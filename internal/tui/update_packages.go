@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rshelekhov/lazymake/internal/util"
+)
+
+// batchResult is the outcome of one package's run in an all-packages run
+type batchResult struct {
+	Target   Target
+	Err      error
+	Duration time.Duration
+}
+
+// openPackages shows every package that defines the target's make target
+func (m Model) openPackages(target Target) Model {
+	m.PackagesTarget = target.makeName()
+	m.PackageTargets = nil
+	m.PackageCursor = 0
+	for _, t := range m.Targets {
+		if t.Package != "" && t.makeName() == m.PackagesTarget {
+			if t.Name == target.Name {
+				m.PackageCursor = len(m.PackageTargets)
+			}
+			m.PackageTargets = append(m.PackageTargets, t)
+		}
+	}
+	m.State = StatePackages
+	return m
+}
+
+// updatePackages handles the all-packages view state
+func (m Model) updatePackages(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "esc", "a":
+			m.State = StateList
+		case "up", "k":
+			m.PackageCursor = max(m.PackageCursor-1, 0)
+		case "down", "j":
+			m.PackageCursor = min(m.PackageCursor+1, max(len(m.PackageTargets)-1, 0))
+		case "enter":
+			return m.runPackages()
+		}
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	}
+	return m, nil
+}
+
+// runPackages runs the target in every package that defines it, one after another
+// When any of them needs confirmation, the run is confirmed once, showing the
+// most dangerous package, with the strictest requirements of all of them.
+func (m Model) runPackages() (tea.Model, tea.Cmd) {
+	if len(m.PackageTargets) == 0 {
+		return m, nil
+	}
+
+	m.ExecutingArgs = nil
+	m.BatchQueue = slices.Clone(m.PackageTargets)
+	m.BatchResults = nil
+	m.BatchOutput = &strings.Builder{}
+	m.BatchConfirmation = nil
+
+	var pending *Target
+	for _, t := range m.BatchQueue {
+		if !m.requiresConfirmation(t) {
+			continue
+		}
+		if pending == nil {
+			pending = &t
+			continue
+		}
+		confirmation := pending.Confirmation.Merge(t.Confirmation)
+		if t.DangerLevel > pending.DangerLevel {
+			pending = &t
+		}
+		pending.Confirmation = confirmation
+	}
+	if pending != nil {
+		return m.openConfirmation(*pending)
+	}
+
+	return m.nextBatchRun()
+}
+
+// nextBatchRun starts the next queued package
+func (m Model) nextBatchRun() (tea.Model, tea.Cmd) {
+	target := m.BatchQueue[0]
+	m.BatchQueue = m.BatchQueue[1:]
+	return m.startExecution(target, m.BatchConfirmation)
+}
+
+// continueBatch adds a finished package's run to the batch and starts the next one
+// Canceling a run (ctrl+c) leaves the packages still queued out.
+func (m Model) continueBatch(err error, duration time.Duration) (tea.Model, tea.Cmd) {
+	target := m.targetNamed(m.ExecutingTarget)
+	m.BatchResults = append(m.BatchResults, batchResult{Target: target, Err: err, Duration: duration})
+	util.WriteString(m.BatchOutput, "── "+commandLine(target)+" ──\n"+m.StreamingOutput.String()+"\n")
+
+	if errors.Is(err, errExecutionCanceled) {
+		m.BatchQueue = nil
+	}
+	if len(m.BatchQueue) > 0 {
+		return m.nextBatchRun()
+	}
+	return m.finishBatch(), nil
+}
+
+// finishBatch shows the output of every package, followed by a summary
+func (m Model) finishBatch() Model {
+	var summary strings.Builder
+	failed := 0
+	for _, result := range m.BatchResults {
+		status := IconSuccess + " " + formatDuration(result.Duration)
+		if result.Err != nil {
+			failed++
+			status = IconError + " " + result.Err.Error()
+		}
+		util.WriteString(&summary, fmt.Sprintf("%-30s %s\n", result.Target.Package, status))
+	}
+	if skipped := len(m.PackageTargets) - len(m.BatchResults); skipped > 0 {
+		util.WriteString(&summary, fmt.Sprintf("%d not run\n", skipped))
+	}
+
+	m.State = StateOutput
+	m.OutputReturnState = StatePackages
+	m.ExecutingTarget = ""
+	m.ExecutingCommand = fmt.Sprintf("make %s in %d packages", m.PackagesTarget, len(m.BatchResults))
+	m.Output = m.BatchOutput.String() + summary.String()
+	m.ExecutionError = nil
+	if failed > 0 {
+		m.ExecutionError = fmt.Errorf("%d of %d packages failed", failed, len(m.BatchResults))
+	}
+	m.initViewport(m.Output)
+
+	m.BatchResults = nil
+	m.BatchOutput = nil
+	m.BatchConfirmation = nil
+	return m
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rshelekhov/lazymake/config"
+	"github.com/rshelekhov/lazymake/internal/graph"
+)
+
+// jumpOrigin is the target '>' jumped into a sub-Makefile from, for '<'
+type jumpOrigin struct {
+	MakefilePath string
+	Aggregate    bool
+	Target       string
+}
+
+// subMakesOf returns the targets a target's recipe runs with recursive make
+func (m Model) subMakesOf(target Target) []*graph.Node {
+	if m.Graph == nil {
+		return nil
+	}
+	if node := m.Graph.Nodes[target.Name]; node != nil {
+		return node.SubMakes
+	}
+	return nil
+}
+
+// jumpToSubMake opens the first target the selected target runs with recursive make
+// Listed targets are selected; targets of other Makefiles switch the workspace.
+func (m Model) jumpToSubMake() (tea.Model, tea.Cmd) {
+	target, ok := m.List.SelectedItem().(Target)
+	if !ok {
+		return m, nil
+	}
+	subMakes := m.subMakesOf(target)
+	if len(subMakes) == 0 {
+		return m, nil
+	}
+	sub := subMakes[0]
+	origin := jumpOrigin{MakefilePath: m.MakefilePath, Aggregate: m.aggregated(), Target: target.Name}
+
+	if selected, found := m.selectTarget(sub.Target.Name); found {
+		selected.JumpStack = append(slices.Clip(m.JumpStack), origin)
+		return selected, nil
+	}
+	if sub.Source == nil {
+		return m, nil // Not defined in this Makefile either
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		m.Err = err
+		return m, nil
+	}
+	switched, _ := m.SwitchWorkspace(sub.Source.Makefile, cfg).selectTarget(sub.Source.Target)
+	switched.JumpStack = append(slices.Clip(m.JumpStack), origin)
+	return switched, nil
+}
+
+// jumpBack returns to the target the last '>' jumped from
+func (m Model) jumpBack() (tea.Model, tea.Cmd) {
+	if len(m.JumpStack) == 0 {
+		return m, nil
+	}
+	origin := m.JumpStack[len(m.JumpStack)-1]
+	stack := m.JumpStack[:len(m.JumpStack)-1]
+
+	back := m
+	if origin.Aggregate != m.aggregated() || origin.MakefilePath != m.MakefilePath {
+		cfg, err := config.Load()
+		if err != nil {
+			m.Err = err
+			return m, nil
+		}
+		if origin.Aggregate {
+			back = m.SwitchToAggregate(cfg)
+		} else {
+			back = m.SwitchWorkspace(origin.MakefilePath, cfg)
+		}
+	}
+
+	back, _ = back.selectTarget(origin.Target)
+	back.JumpStack = stack
+	return back, nil
+}
@@ -48,6 +48,10 @@ func (m Model) updateWorkspace(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Switch to selected workspace
 			return m.handleWorkspaceSelection()
 
+		case "a":
+			// Every Makefile below the working directory in one list
+			return m.handleAggregateSelection()
+
 		case "up", "k":
 			// Navigate up, skip headers
 			var cmd tea.Cmd
@@ -131,6 +135,23 @@ func (m Model) handleWorkspaceSelection() (Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleAggregateSelection switches to aggregate mode
+func (m Model) handleAggregateSelection() (Model, tea.Cmd) {
+	cfg, err := config.Load()
+	if err != nil {
+		m.Err = err
+		m.State = StateList
+		return m, nil
+	}
+
+	oldModel := m
+	return m, func() tea.Msg {
+		newModel := oldModel.SwitchToAggregate(cfg)
+		newModel.State = StateList
+		return workspaceSwitchedMsg{newModel: newModel}
+	}
+}
+
 // handleToggleFavorite toggles favorite status for selected workspace
 func (m Model) handleToggleFavorite() Model {
 	selected := m.WorkspaceList.SelectedItem()
@@ -188,7 +209,7 @@ func (m *Model) discoverWorkspaces(cwd string) []workspace.DiscoveryResult {
 		searchRoot = "."
 	}
 
-	discovered, err := workspace.DiscoverMakefiles(searchRoot, workspace.DefaultDiscoveryOptions())
+	discovered, err := workspace.DiscoverMakefiles(searchRoot, m.DiscoveryOptions)
 	if err != nil {
 		return []workspace.DiscoveryResult{}
 	}
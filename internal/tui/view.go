@@ -31,6 +31,16 @@ func (m Model) View() string {
 		return m.renderVariablesView()
 	case StateWorkspace:
 		return m.renderWorkspaceView()
+	case StatePerformance:
+		return m.renderPerformanceView()
+	case StateHistory:
+		return m.renderHistoryView()
+	case StateDiagnostics:
+		return m.renderDiagnosticsView()
+	case StatePackages:
+		return m.renderPackagesView()
+	case StateArguments:
+		return m.renderArgumentsView()
 	case StateList:
 		return m.renderListView()
 	default:
@@ -78,22 +88,23 @@ func (m Model) renderHelpView() string {
 		Render("Available targets:\n")
 	helpContent += desc + "\n"
 
-	// List all targets with descriptions
-	if len(m.Targets) == 0 {
+	// List all targets with descriptions (@hidden ones are left out)
+	targets := visibleTargets(m.Targets)
+	if len(targets) == 0 {
 		helpContent += lipgloss.NewStyle().
 			Foreground(TextMuted).
 			Render("  No targets found\n")
 	} else {
 		// Find the longest target name for alignment
 		maxNameLen := 0
-		for _, target := range m.Targets {
+		for _, target := range targets {
 			if len(target.Name) > maxNameLen {
 				maxNameLen = len(target.Name)
 			}
 		}
 
 		// Render each target with aligned descriptions
-		for _, target := range m.Targets {
+		for _, target := range targets {
 			// Target name with color
 			targetName := lipgloss.NewStyle().
 				Foreground(PrimaryColor).
@@ -217,13 +228,22 @@ func (m Model) renderGraphContent(width int) string {
 		FormatParallel: func(s string) string {
 			return lipgloss.NewStyle().Foreground(SuccessColor).Bold(true).Render(s)
 		},
+		FormatSubMake: func(s string) string {
+			return lipgloss.NewStyle().Foreground(PrimaryColor).Render(s)
+		},
 	}
 
 	treeStr := graphToRender.RenderTree(renderer)
 	util.WriteString(&builder, treeStr)
 
-	// Add legend if any annotations are enabled
-	if m.ShowOrder || m.ShowCritical || m.ShowParallel {
+	// Parallelism simulation for the selected target
+	if m.ShowJobs {
+		util.WriteString(&builder, renderParallelismSection(m.ParallelAnalysis, width))
+	}
+
+	// Add legend if any annotations are enabled, or recursive make is shown
+	subMakes := graphToRender.HasSubMakes()
+	if m.ShowOrder || m.ShowCritical || m.ShowParallel || subMakes {
 		// Separator line
 		separator := lipgloss.NewStyle().
 			Foreground(BorderColor).
@@ -266,6 +286,20 @@ func (m Model) renderGraphContent(width int) string {
 					Render("Parallel Execution")
 			util.WriteString(&builder, item)
 		}
+
+		if subMakes {
+			if m.ShowParallel {
+				util.WriteString(&builder, "\n")
+			}
+			item := lipgloss.NewStyle().
+				Foreground(PrimaryColor).
+				Render("┄▶ ") +
+				"  " +
+				lipgloss.NewStyle().
+					Foreground(TextPrimary).
+					Render("Recursive Make ($(MAKE) -C dir target)")
+			util.WriteString(&builder, item)
+		}
 	}
 
 	// Apply border (matching main view pattern)
@@ -304,7 +338,7 @@ func (m Model) renderGraphStatusBar(width int) string {
 	leftWidth := lipgloss.Width(leftBar)
 
 	// Right side: shortcuts
-	helpText := "g/esc: return • +/-: depth • o: order • c: critical • p: parallel • q: quit"
+	helpText := "g/esc: return • +/-: depth • o: order • c: critical • p: parallel • j: jobs • q: quit"
 
 	// Right section with help text
 	right := lipgloss.NewStyle().
@@ -337,9 +371,9 @@ func (m Model) renderOutputView() string {
 	// Header inside the box
 	var header string
 	if m.ExecutionError != nil {
-		header = ErrorStyle.Render("❌ Failed: make " + m.ExecutingTarget)
+		header = ErrorStyle.Render("❌ Failed: " + m.ExecutingCommand)
 	} else {
-		header = SuccessStyle.Render("✓ Success: make " + m.ExecutingTarget)
+		header = SuccessStyle.Render("✓ Success: " + m.ExecutingCommand)
 	}
 	util.WriteString(&builder, header+"\n")
 
@@ -426,7 +460,7 @@ func (m Model) renderExecutingView() string {
 	title := lipgloss.NewStyle().
 		Foreground(PrimaryColor).
 		Bold(true).
-		Render(m.Spinner.View() + " Executing: make " + m.ExecutingTarget)
+		Render(m.Spinner.View() + " Executing: " + m.ExecutingCommand)
 	util.WriteString(&builder, title+"\n\n")
 
 	// Progress bar (if we have avg duration to estimate)
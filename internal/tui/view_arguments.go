@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rshelekhov/lazymake/internal/util"
+)
+
+// renderArgumentsView renders the argument prompt for a target documented with "## @arg"
+//
+// Example:
+//
+//	RUN WITH ARGUMENTS
+//
+//	Target: deploy
+//
+//	ENV  environment to deploy to
+//	> staging█
+//
+//	TAG  image tag
+//	> latest
+//
+//	Command: make deploy ENV=staging TAG=latest
+func (m Model) renderArgumentsView() string {
+	if m.ArgumentsTarget == nil {
+		return "Error: No target to run"
+	}
+
+	target := m.ArgumentsTarget
+
+	var builder strings.Builder
+
+	title := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Bold(true).
+		Render("RUN WITH ARGUMENTS")
+	util.WriteString(&builder, title+"\n\n")
+
+	targetLine := lipgloss.NewStyle().
+		Foreground(PrimaryColor).
+		Bold(true).
+		Render("Target: " + target.Name)
+	util.WriteString(&builder, targetLine+"\n\n")
+
+	nameStyle := lipgloss.NewStyle().Foreground(TextPrimary).Bold(true)
+	descStyle := lipgloss.NewStyle().Foreground(TextMuted)
+
+	for i, arg := range target.Docs.Args {
+		label := nameStyle.Render(arg.Name)
+		if arg.Description != "" {
+			label += "  " + descStyle.Render(arg.Description)
+		}
+		util.WriteString(&builder, label+"\n")
+
+		style := lipgloss.NewStyle().Foreground(TextMuted)
+		cursor := ""
+		if i == m.ArgumentField {
+			style = lipgloss.NewStyle().Foreground(PrimaryColor)
+			cursor = "█"
+		}
+		util.WriteString(&builder, style.Render("> "+m.ArgumentValues[i]+cursor)+"\n\n")
+	}
+
+	// The command that enter will run
+	command := strings.Join(append([]string{"make", target.Name}, m.argumentOverrides()...), " ")
+	util.WriteString(&builder, lipgloss.NewStyle().Foreground(TextSecondary).Render("Command: "+command)+"\n\n")
+
+	enterAction := lipgloss.NewStyle().
+		Foreground(SuccessColor).
+		Bold(true).
+		Render("[Enter]")
+	escAction := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Bold(true).
+		Render("[Esc]")
+	hint := lipgloss.NewStyle().Foreground(TextMuted).Render("     tab/↑↓: switch field")
+	util.WriteString(&builder, enterAction+" Run     "+escAction+" Cancel"+hint)
+
+	contentWidth := min(80, m.Width-10)
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder()).
+		BorderForeground(PrimaryColor).
+		Padding(2, 4).
+		Width(contentWidth).
+		Align(lipgloss.Left)
+
+	dialog := dialogStyle.Render(builder.String())
+
+	// Center the dialog on screen
+	verticalPadding := max((m.Height-strings.Count(dialog, "\n"))/2, 0)
+	paddingStyle := lipgloss.NewStyle().
+		PaddingTop(verticalPadding).
+		PaddingLeft((m.Width - contentWidth) / 2)
+
+	return paddingStyle.Render(dialog)
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/reflow/wordwrap"
@@ -21,10 +22,15 @@ func (m Model) renderConfirmView() string {
 	var builder strings.Builder
 
 	// Title without padding (like "RECENT" or "ALL TARGETS" but without left padding)
+	// Safe targets get here when their confirmation is configured per target
+	titleText := "DANGEROUS COMMAND"
+	if !target.IsDangerous {
+		titleText = "CONFIRM TARGET"
+	}
 	title := lipgloss.NewStyle().
 		Foreground(TextSecondary).
 		Bold(true).
-		Render("DANGEROUS COMMAND")
+		Render(titleText)
 	util.WriteString(&builder, title+"\n\n")
 
 	// Target name
@@ -34,6 +40,18 @@ func (m Model) renderConfirmView() string {
 		Render("Target: " + target.Name)
 	util.WriteString(&builder, targetLine+"\n\n")
 
+	// An all-packages run is confirmed once, for the most dangerous package
+	if m.BatchOutput != nil {
+		packages := make([]string, len(m.BatchQueue))
+		for i, t := range m.BatchQueue {
+			packages[i] = t.Package
+		}
+		batchLine := lipgloss.NewStyle().
+			Foreground(TextSecondary).
+			Render(fmt.Sprintf("Runs %s in %d packages: %s", m.PackagesTarget, len(packages), strings.Join(packages, ", ")))
+		util.WriteString(&builder, batchLine+"\n\n")
+	}
+
 	// Show all safety matches
 	if len(target.SafetyMatches) > 0 {
 		for i, match := range target.SafetyMatches {
@@ -92,6 +110,17 @@ func (m Model) renderConfirmView() string {
 				util.WriteString(&boxContent, wrappedMatched+"\n")
 			}
 
+			// The command within a compound line (a && b, sh -c '...') that matched
+			if match.Command != "" && match.Command != match.MatchedLine {
+				invocation := wordwrap.String("Invocation: "+match.Command, maxWidth)
+				util.WriteString(&boxContent, lipgloss.NewStyle().Foreground(TextMuted).Render(invocation)+"\n")
+			}
+
+			// Variable expansion that produced the dangerous command
+			if match.ExpandedFrom != "" {
+				util.WriteString(&boxContent, renderExpansionNote(match, maxWidth)+"\n")
+			}
+
 			// Description
 			if match.Rule.Description != "" {
 				util.WriteString(&boxContent, "\n")
@@ -123,25 +152,48 @@ func (m Model) renderConfirmView() string {
 		}
 	}
 
+	// Dangerous prerequisites that `make <target>` will also run
+	if len(target.SafetyDependencies) > 0 {
+		if len(target.SafetyMatches) > 0 {
+			util.WriteString(&builder, "\n")
+		}
+		util.WriteString(&builder, renderDependencyWarnings(target.SafetyDependencies, 60))
+	}
+
 	util.WriteString(&builder, "\n")
 
+	// Typed name and reason inputs
+	if inputs := m.renderConfirmInputs(); inputs != "" {
+		util.WriteString(&builder, inputs+"\n")
+	}
+
 	// Actions
 	actionsStyle := lipgloss.NewStyle().
 		Foreground(TextSecondary).
 		Align(lipgloss.Left)
 
+	// The confirm key stays inactive until the countdown ends and the inputs are filled
+	enterColor := ErrorColor
+	if !m.confirmReady() {
+		enterColor = TextMuted
+	}
 	enterAction := lipgloss.NewStyle().
-		Foreground(ErrorColor).
+		Foreground(enterColor).
 		Bold(true).
 		Render("[Enter]")
 
+	continueLabel := " Continue Anyway     "
+	if remaining := m.confirmCountdown(); remaining > 0 {
+		continueLabel = fmt.Sprintf(" Continue in %ds     ", int(remaining.Round(time.Second).Seconds()))
+	}
+
 	escAction := lipgloss.NewStyle().
 		Foreground(SuccessColor).
 		Bold(true).
 		Render("[Esc]")
 
 	actions := actionsStyle.Render(
-		enterAction + " Continue Anyway     " + escAction + " Cancel (Recommended)",
+		enterAction + continueLabel + escAction + " Cancel (Recommended)",
 	)
 	util.WriteString(&builder, actions)
 
@@ -168,3 +220,42 @@ func (m Model) renderConfirmView() string {
 
 	return paddingStyle.Render(dialog)
 }
+
+// renderConfirmInputs renders the typed-name and reason inputs of a stronger confirmation
+func (m Model) renderConfirmInputs() string {
+	phrase := m.confirmPhrase()
+	if phrase == "" && !m.confirmRequiresReason() {
+		return ""
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+	input := func(value string, focused bool) string {
+		style := lipgloss.NewStyle().Foreground(TextMuted)
+		cursor := ""
+		if focused {
+			style = lipgloss.NewStyle().Foreground(PrimaryColor)
+			cursor = "█"
+		}
+		return style.Render("> " + value + cursor)
+	}
+
+	var builder strings.Builder
+
+	if phrase != "" {
+		label := labelStyle.Render("Type ") +
+			lipgloss.NewStyle().Foreground(ErrorColor).Bold(true).Render(phrase) +
+			labelStyle.Render(" to confirm:")
+		util.WriteString(&builder, label+"\n")
+		util.WriteString(&builder, input(m.ConfirmInput, m.ConfirmField == confirmFieldPhrase)+"\n")
+	}
+
+	if m.confirmRequiresReason() {
+		if phrase != "" {
+			util.WriteString(&builder, "\n")
+		}
+		util.WriteString(&builder, labelStyle.Render("Reason (recorded in history):")+"\n")
+		util.WriteString(&builder, input(m.ConfirmReason, m.ConfirmField == confirmFieldReason)+"\n")
+	}
+
+	return builder.String()
+}
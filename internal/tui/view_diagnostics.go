@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rshelekhov/lazymake/internal/lint"
+	"github.com/rshelekhov/lazymake/internal/util"
+)
+
+// diagnosticDetailLines is the height reserved below the list for the selected diagnostic's rule
+const diagnosticDetailLines = 5
+
+// renderDiagnosticsView displays the lint diagnostics for the current Makefile
+func (m Model) renderDiagnosticsView() string {
+	if m.Width == 0 || m.Height == 0 {
+		return "Loading diagnostics..."
+	}
+
+	// Same layout as the other full-screen views: bordered container + status bar
+	statusBarHeight := 3
+	availableHeight := m.Height - statusBarHeight
+	contentWidth := m.Width - 8
+	contentHeight := availableHeight - 6
+
+	content := lipgloss.Place(
+		contentWidth,
+		contentHeight,
+		lipgloss.Left,
+		lipgloss.Top,
+		m.buildDiagnosticsContent(contentWidth, contentHeight),
+	)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(BorderColor).
+		Padding(2, 3).
+		Width(m.Width - 2)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		containerStyle.Render(content),
+		m.renderDiagnosticsStatusBar(),
+	)
+}
+
+// buildDiagnosticsContent renders the diagnostics list and the selected diagnostic's rule
+//
+// Example:
+//
+//	Lint Diagnostics
+//
+//	▶ allowed  Makefile:7   "build" doesn't create a file but isn't declared .PHONY
+//	  warning  Makefile:16  "deploy" doesn't create a file but isn't declared .PHONY
+//	  error    Makefile:18  recipe line of "deploy" is indented with spaces; make needs a tab
+func (m Model) buildDiagnosticsContent(width, height int) string {
+	var builder strings.Builder
+
+	util.WriteString(&builder, TitleStyle.Render("Lint Diagnostics")+"\n\n")
+
+	if len(m.Diagnostics) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(SuccessColor)
+		util.WriteString(&builder, emptyStyle.Render(IconSuccess+" No problems found")+"\n")
+		return builder.String()
+	}
+
+	// Title (1) + blank (1) + details
+	visibleRows := max(height-2-diagnosticDetailLines, 3)
+	util.WriteString(&builder, m.renderDiagnosticsList(visibleRows, width))
+
+	if d := m.selectedDiagnostic(); d != nil {
+		util.WriteString(&builder, "\n"+renderDiagnosticDetails(*d))
+	}
+
+	return builder.String()
+}
+
+// renderDiagnosticsList renders a scrolling window of diagnostics that keeps the cursor visible
+func (m Model) renderDiagnosticsList(visibleRows, width int) string {
+	var builder strings.Builder
+
+	locationWidth := 0
+	for _, d := range m.Diagnostics {
+		locationWidth = max(locationWidth, min(len(d.Location()), 30))
+	}
+
+	// Scroll so the cursor stays inside the window
+	start := 0
+	if m.DiagnosticCursor >= visibleRows {
+		start = m.DiagnosticCursor - visibleRows + 1
+	}
+	end := min(start+visibleRows, len(m.Diagnostics))
+
+	messageWidth := max(width-(2+7+2+locationWidth+2), 10)
+
+	for i := start; i < end; i++ {
+		d := m.Diagnostics[i]
+		selected := i == m.DiagnosticCursor
+
+		rowStyle := lipgloss.NewStyle().Foreground(TextPrimary)
+		cursor := "  "
+		if selected {
+			rowStyle = rowStyle.Foreground(PrimaryColor).Bold(true)
+			cursor = lipgloss.NewStyle().Foreground(PrimaryColor).Render("▶ ")
+		}
+
+		severity := diagnosticSeverityLabel(d)
+		location := rowStyle.Render(fmt.Sprintf("%-*s", locationWidth, truncateValue(d.Location(), 30)))
+		message := rowStyle.Render(truncateValue(d.Message, messageWidth))
+		if d.Suppressed {
+			message = lipgloss.NewStyle().Foreground(TextMuted).Render(truncateValue(d.Message, messageWidth))
+		}
+
+		util.WriteString(&builder, cursor+severity+"  "+location+"  "+message+"\n")
+	}
+
+	return builder.String()
+}
+
+// diagnosticSeverityLabel renders a diagnostic's severity, colored and padded to one width
+func diagnosticSeverityLabel(d lint.Diagnostic) string {
+	style := lipgloss.NewStyle().Width(7)
+	if d.Suppressed {
+		return style.Foreground(TextMuted).Render("allowed")
+	}
+
+	switch d.Severity {
+	case lint.SeverityError:
+		style = style.Foreground(ErrorColor)
+	case lint.SeverityWarning:
+		style = style.Foreground(WarningColor)
+	default:
+		style = style.Foreground(SecondaryColor)
+	}
+	return style.Render(d.Severity.String())
+}
+
+// renderDiagnosticDetails renders the rule behind the selected diagnostic and how to fix it
+func renderDiagnosticDetails(d lint.Diagnostic) string {
+	labelStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+	valueStyle := lipgloss.NewStyle().Foreground(TextPrimary)
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted).Italic(true)
+
+	var builder strings.Builder
+
+	rule := d.RuleID
+	fix := ""
+	if catalog, ok := lint.LookupRule(d.RuleID); ok {
+		rule += " - " + catalog.Description
+		fix = catalog.Help
+	}
+	util.WriteString(&builder, labelStyle.Render("Rule:    ")+valueStyle.Render(rule)+"\n")
+	if fix != "" {
+		util.WriteString(&builder, labelStyle.Render("Fix:     ")+valueStyle.Render(fix)+"\n")
+	}
+
+	if d.Suppressed {
+		reason := d.SuppressionReason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		util.WriteString(&builder, labelStyle.Render("Allowed: ")+mutedStyle.Render(reason)+"\n")
+	} else if d.Target != "" {
+		allow := "# lazymake:allow " + d.RuleID + " <reason>"
+		util.WriteString(&builder, labelStyle.Render("Allow:   ")+mutedStyle.Render(allow)+"\n")
+	}
+
+	return builder.String()
+}
+
+// renderDiagnosticsStatusBar renders the status bar with counts by severity
+func (m Model) renderDiagnosticsStatusBar() string {
+	counts := make(map[lint.Severity]int)
+	allowed := 0
+	for _, d := range m.Diagnostics {
+		if d.Suppressed {
+			allowed++
+		} else {
+			counts[d.Severity]++
+		}
+	}
+
+	coloredNuggetStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"}).
+		Background(PrimaryColor).
+		Padding(0, 1).
+		MarginRight(1)
+
+	plainNuggetStyle := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Padding(0, 1)
+
+	var sections []string
+	sections = append(sections, coloredNuggetStyle.Render(fmt.Sprintf("%d diagnostics", len(m.Diagnostics)-allowed)))
+	for _, severity := range []lint.Severity{lint.SeverityError, lint.SeverityWarning, lint.SeverityInfo} {
+		if n := counts[severity]; n > 0 {
+			sections = append(sections, plainNuggetStyle.Render(fmt.Sprintf("%d %s", n, severity)))
+		}
+	}
+	if allowed > 0 {
+		sections = append(sections, plainNuggetStyle.Render(fmt.Sprintf("%d allowed", allowed)))
+	}
+	leftBar := lipgloss.JoinHorizontal(lipgloss.Top, sections...)
+
+	helpText := "enter: go to target • d/esc: return • q: quit"
+
+	return m.assembleStatusBar(leftBar, helpText)
+}
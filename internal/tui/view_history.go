@@ -0,0 +1,252 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/util"
+)
+
+// historyDetailLines is the height reserved below the table for the selected run's details
+const historyDetailLines = 6
+
+// renderHistoryView displays the run history browser across all Makefiles
+func (m Model) renderHistoryView() string {
+	if m.Width == 0 || m.Height == 0 {
+		return "Loading history..."
+	}
+
+	// Same layout as the other full-screen views: bordered container + status bar
+	statusBarHeight := 3
+	availableHeight := m.Height - statusBarHeight
+	contentWidth := m.Width - 8
+	contentHeight := availableHeight - 6
+
+	content := lipgloss.Place(
+		contentWidth,
+		contentHeight,
+		lipgloss.Left,
+		lipgloss.Top,
+		m.buildHistoryContent(contentWidth, contentHeight),
+	)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(BorderColor).
+		Padding(2, 3).
+		Width(m.Width - 2)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		containerStyle.Render(content),
+		m.renderHistoryStatusBar(),
+	)
+}
+
+// buildHistoryContent renders the filter line, the runs table and the selected run's details
+//
+// Example:
+//
+//	Run History
+//	Filter: project:api status:failed
+//
+//	  WHEN              STATUS   DURATION  PROJECT  TARGET
+//	▶ 2026-03-14 10:42  ✗ exit 2     5.0s  api      test VERBOSE=1
+//	  2026-03-13 18:03  ✓            2.0s  api      build
+func (m Model) buildHistoryContent(width, height int) string {
+	var builder strings.Builder
+
+	util.WriteString(&builder, TitleStyle.Render("Run History")+"\n")
+	util.WriteString(&builder, m.renderHistoryFilterLine()+"\n\n")
+
+	if len(m.HistoryRuns) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(TextMuted).
+			Italic(true)
+		util.WriteString(&builder, emptyStyle.Render("No runs match the current filters")+"\n")
+		if m.HistoryMessage != "" {
+			util.WriteString(&builder, "\n"+renderHistoryMessage(m.HistoryMessage))
+		}
+		return builder.String()
+	}
+
+	// Title (1) + filter (1) + blank (1) + header (1) + details
+	visibleRows := max(height-4-historyDetailLines, 3)
+	util.WriteString(&builder, m.renderHistoryTable(visibleRows, width))
+
+	if run := m.selectedHistoryRun(); run != nil {
+		util.WriteString(&builder, "\n"+renderHistoryRunDetails(*run))
+	}
+	if m.HistoryMessage != "" {
+		util.WriteString(&builder, renderHistoryMessage(m.HistoryMessage))
+	}
+
+	return builder.String()
+}
+
+// renderHistoryFilterLine shows the typed query (with a cursor while typing)
+func (m Model) renderHistoryFilterLine() string {
+	label := lipgloss.NewStyle().Foreground(TextSecondary).Render("Filter: ")
+
+	if m.HistoryFiltering {
+		input := lipgloss.NewStyle().Foreground(PrimaryColor).Render(m.HistoryFilter + "▌")
+		return label + input
+	}
+
+	if m.HistoryFilter == "" {
+		hint := lipgloss.NewStyle().
+			Foreground(TextMuted).
+			Italic(true).
+			Render("press / - e.g. project:api target:test status:failed since:7d until:2026-01-31")
+		return label + hint
+	}
+
+	return label + lipgloss.NewStyle().Foreground(TextPrimary).Render(m.HistoryFilter)
+}
+
+// renderHistoryTable renders a scrolling window of runs that keeps the cursor visible
+func (m Model) renderHistoryTable(visibleRows, width int) string {
+	var builder strings.Builder
+
+	// Column widths: project names are short (directory name), target takes the rest
+	projectWidth := len("PROJECT")
+	for _, run := range m.HistoryRuns {
+		projectWidth = max(projectWidth, min(len(historyProjectName(run.MakefilePath)), 20))
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Bold(true)
+	header := fmt.Sprintf("  %-16s  %-8s  %8s  %-*s  %s",
+		"WHEN", "STATUS", "DURATION", projectWidth, "PROJECT", "TARGET")
+	util.WriteString(&builder, headerStyle.Render(header)+"\n")
+
+	// Scroll so the cursor stays inside the window
+	start := 0
+	if m.HistoryCursor >= visibleRows {
+		start = m.HistoryCursor - visibleRows + 1
+	}
+	end := min(start+visibleRows, len(m.HistoryRuns))
+
+	targetWidth := max(width-(2+16+2+8+2+8+2+projectWidth+2), 10)
+
+	for i := start; i < end; i++ {
+		run := m.HistoryRuns[i]
+		selected := i == m.HistoryCursor
+
+		// Width pads by display cells, so the multi-byte icons stay aligned
+		status := lipgloss.NewStyle().Foreground(SuccessColor).Width(8).Render(IconSuccess)
+		if !run.Success {
+			status = lipgloss.NewStyle().Foreground(ErrorColor).Width(8).Render(fmt.Sprintf("%s exit %d", IconError, run.ExitCode))
+		}
+
+		command := run.Target
+		if len(run.Args) > 0 {
+			command += " " + strings.Join(run.Args, " ")
+		}
+
+		rowStyle := lipgloss.NewStyle().Foreground(TextPrimary)
+		cursor := "  "
+		if selected {
+			rowStyle = rowStyle.Foreground(PrimaryColor).Bold(true)
+			cursor = lipgloss.NewStyle().Foreground(PrimaryColor).Render("▶ ")
+		}
+
+		when := rowStyle.Render(run.Timestamp.Format("2006-01-02 15:04"))
+		duration := rowStyle.Render(fmt.Sprintf("%8s", formatDuration(run.Duration)))
+		project := rowStyle.Render(fmt.Sprintf("%-*s", projectWidth, truncateValue(historyProjectName(run.MakefilePath), 20)))
+		target := rowStyle.Render(truncateValue(command, targetWidth))
+
+		util.WriteString(&builder, cursor+when+"  "+status+"  "+duration+"  "+project+"  "+target+"\n")
+	}
+
+	return builder.String()
+}
+
+// renderHistoryRunDetails renders the full details of the selected run
+func renderHistoryRunDetails(run history.Run) string {
+	labelStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+	valueStyle := lipgloss.NewStyle().Foreground(TextPrimary)
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted).Italic(true)
+
+	var builder strings.Builder
+
+	util.WriteString(&builder, labelStyle.Render("Makefile:  ")+valueStyle.Render(run.MakefilePath)+"\n")
+
+	args := mutedStyle.Render("none")
+	if len(run.Args) > 0 {
+		args = valueStyle.Render(strings.Join(run.Args, " "))
+	}
+	util.WriteString(&builder, labelStyle.Render("Arguments: ")+args+"\n")
+
+	captured := mutedStyle.Render("not captured")
+	switch {
+	case run.HasOutput:
+		captured = valueStyle.Render("stored in history database")
+	case run.ExportPath != "":
+		captured = valueStyle.Render(run.ExportPath)
+	}
+	util.WriteString(&builder, labelStyle.Render("Output:    ")+captured+"\n")
+
+	// Dangerous or protected targets record how they were confirmed
+	if c := run.Confirmation; c != nil {
+		confirmed := c.ConfirmedAt.Format("15:04:05")
+		if c.Typed != "" {
+			confirmed += ", typed " + c.Typed
+		}
+		if c.Reason != "" {
+			confirmed += ": " + c.Reason
+		}
+		util.WriteString(&builder, labelStyle.Render("Confirmed: ")+valueStyle.Render(confirmed)+"\n")
+	}
+
+	return builder.String()
+}
+
+// renderHistoryMessage renders feedback for the last action
+func renderHistoryMessage(message string) string {
+	return lipgloss.NewStyle().
+		Foreground(WarningColor).
+		Render(IconInfo+" "+message) + "\n"
+}
+
+// renderHistoryStatusBar renders the status bar with active quick filters
+func (m Model) renderHistoryStatusBar() string {
+	coloredNuggetStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"}).
+		Background(PrimaryColor).
+		Padding(0, 1).
+		MarginRight(1)
+
+	plainNuggetStyle := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Padding(0, 1)
+
+	var sections []string
+	sections = append(sections, coloredNuggetStyle.Render(fmt.Sprintf("%d runs", len(m.HistoryRuns))))
+	sections = append(sections, plainNuggetStyle.Render("status: "+m.HistoryStatus.String()))
+	sections = append(sections, plainNuggetStyle.Render(historyRanges[m.HistoryRange].Label))
+
+	scope := "all projects"
+	if m.HistoryThisProject {
+		scope = "this project"
+	}
+	sections = append(sections, plainNuggetStyle.Render(scope))
+
+	leftBar := lipgloss.JoinHorizontal(lipgloss.Top, sections...)
+
+	helpText := "enter: re-run • o: output • /: filter • s: status • d: date • c: project • esc: return"
+	if m.HistoryFiltering {
+		helpText = "enter: apply • esc: clear"
+	}
+
+	return m.assembleStatusBar(leftBar, helpText)
+}
+
+// historyProjectName returns a short project label: the Makefile's directory name
+func historyProjectName(makefilePath string) string {
+	return filepath.Base(filepath.Dir(makefilePath))
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/reflow/wordwrap"
+	"github.com/rshelekhov/lazymake/internal/graph"
+	"github.com/rshelekhov/lazymake/internal/makefile"
 	"github.com/rshelekhov/lazymake/internal/safety"
 	"github.com/rshelekhov/lazymake/internal/util"
 )
@@ -163,6 +165,25 @@ func (m Model) buildRecipeContent(target *Target, width int) string {
 		Render(target.Name)
 	util.WriteString(&builder, header+"\n\n")
 
+	// Aggregate mode: the command runs in the package's directory
+	if target.Package != "" {
+		util.WriteString(&builder, lipgloss.NewStyle().Foreground(TextMuted).Render(commandLine(*target))+"\n")
+		if n := m.packagesDefining(target.makeName()); n > 1 {
+			hint := fmt.Sprintf("%s Press 'a' to run %s in all %d packages", IconInfo, target.makeName(), n)
+			util.WriteString(&builder, lipgloss.NewStyle().Foreground(TextMuted).Italic(true).Render(hint)+"\n")
+		}
+		util.WriteString(&builder, "\n")
+	}
+
+	// @deprecated targets say what to use instead before anything else
+	if target.Docs.Deprecated {
+		notice := "Deprecated"
+		if target.Docs.DeprecationNote != "" {
+			notice += ": " + target.Docs.DeprecationNote
+		}
+		util.WriteString(&builder, lipgloss.NewStyle().Foreground(WarningColor).Render("⚠ "+notice)+"\n\n")
+	}
+
 	// Recipe section with label
 	if len(target.Recipe) > 0 {
 		// Section label
@@ -202,8 +223,20 @@ func (m Model) buildRecipeContent(target *Target, width int) string {
 		util.WriteString(&builder, noRecipeStyle.Render("(no recipe - meta target)")+"\n")
 	}
 
+	// Targets the recipe runs with $(MAKE) -C dir target
+	if subMakes := renderSubMakesSection(m.subMakesOf(*target), m.JumpStack); subMakes != "" {
+		util.WriteString(&builder, "\n")
+		util.WriteString(&builder, subMakes)
+	}
+
+	// Group, tags, arguments and examples from "## @tag" lines
+	if docs := renderDocsSection(target.Docs); docs != "" {
+		util.WriteString(&builder, "\n")
+		util.WriteString(&builder, docs)
+	}
+
 	// Variables section (if any variables used by this target)
-	targetVariables := m.getVariablesForTarget(target.Name)
+	targetVariables := m.getVariablesForTarget(*target)
 	if len(targetVariables) > 0 {
 		util.WriteString(&builder, "\n")
 		util.WriteString(&builder, renderVariablesSection(targetVariables))
@@ -215,6 +248,18 @@ func (m Model) buildRecipeContent(target *Target, width int) string {
 		util.WriteString(&builder, renderSafetyWarnings(target.SafetyMatches))
 	}
 
+	// Matches allowed by annotations in the Makefile, dimmed
+	if len(target.SafetySuppressed) > 0 {
+		util.WriteString(&builder, "\n")
+		util.WriteString(&builder, renderSuppressedWarnings(target.SafetySuppressed, 70))
+	}
+
+	// Dangerous prerequisites this target runs
+	if len(target.SafetyDependencies) > 0 {
+		util.WriteString(&builder, "\n")
+		util.WriteString(&builder, renderDependencyWarnings(target.SafetyDependencies, 70))
+	}
+
 	// Performance section (context-aware)
 	perfSection := renderPerformanceSection(*target)
 	if perfSection != "" {
@@ -355,6 +400,17 @@ func renderSafetyWarnings(matches []safety.MatchResult) string {
 			util.WriteString(&boxContent, wrappedMatched+"\n")
 		}
 
+		// The command within a compound line (a && b, sh -c '...') that matched
+		if match.Command != "" && match.Command != match.MatchedLine {
+			invocation := wordwrap.String("Invocation: "+match.Command, maxWidth)
+			util.WriteString(&boxContent, lipgloss.NewStyle().Foreground(TextMuted).Render(invocation)+"\n")
+		}
+
+		// Variable expansion that produced the dangerous command
+		if match.ExpandedFrom != "" {
+			util.WriteString(&boxContent, renderExpansionNote(match, maxWidth)+"\n")
+		}
+
 		// Description
 		if match.Rule.Description != "" {
 			util.WriteString(&boxContent, "\n")
@@ -388,6 +444,118 @@ func renderSafetyWarnings(matches []safety.MatchResult) string {
 	return builder.String()
 }
 
+// renderSuppressedWarnings lists matches allowed by "# lazymake:allow" annotations
+//
+// Example:
+//
+//	Allowed:
+//	○ git-force-push (line 12): release branch only
+//	  git push --force origin release
+func renderSuppressedWarnings(matches []safety.MatchResult, maxWidth int) string {
+	var builder strings.Builder
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	util.WriteString(&builder, mutedStyle.Bold(true).Render("Allowed:")+"\n")
+
+	for _, match := range matches {
+		header := "○ " + match.Rule.ID
+		if match.Line > 0 {
+			header += fmt.Sprintf(" (line %d)", match.Line)
+		}
+		if match.Suppression != nil && match.Suppression.Reason != "" {
+			header += ": " + match.Suppression.Reason
+		}
+		util.WriteString(&builder, mutedStyle.Render(wordwrap.String(header, maxWidth))+"\n")
+
+		if match.MatchedLine != "" {
+			command := wordwrap.String(match.MatchedLine, maxWidth-2)
+			util.WriteString(&builder, mutedStyle.Italic(true).Render(indentLines(command, "  "))+"\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// renderExpansionNote shows the recipe line as written and the variables that made it dangerous
+//
+// Example:
+//
+//	Expanded from: $(RM) -rf $(DEST)
+//	Variable: DEST
+func renderExpansionNote(match safety.MatchResult, maxWidth int) string {
+	label := "Variable"
+	if len(match.Variables) > 1 {
+		label = "Variables"
+	}
+
+	note := wordwrap.String("Expanded from: "+match.ExpandedFrom, maxWidth) + "\n" +
+		wordwrap.String(label+": "+strings.Join(match.Variables, ", "), maxWidth)
+
+	return lipgloss.NewStyle().Foreground(TextMuted).Render(note)
+}
+
+// renderDependencyWarnings renders dangerous prerequisites with the path that pulls each in
+//
+// Example:
+//
+//	Runs dangerous dependencies
+//
+//	○ critical db-reset (database-drop)
+//	  via release → db-reset
+//	  psql -c 'DROP DATABASE app;'
+func renderDependencyWarnings(dependencies []safety.DependencyMatch, maxWidth int) string {
+	var boxContent strings.Builder
+
+	title := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Bold(true).
+		Render("Runs dangerous dependencies")
+	util.WriteString(&boxContent, title+"\n")
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+
+	for _, dep := range dependencies {
+		severityColor := SecondaryColor
+		switch dep.Result.DangerLevel {
+		case safety.SeverityCritical:
+			severityColor = ErrorColor
+		case safety.SeverityWarning:
+			severityColor = WarningColor
+		}
+		severityStyle := lipgloss.NewStyle().Foreground(severityColor).Bold(true)
+
+		ruleIDs := make([]string, 0, len(dep.Result.Matches))
+		for _, match := range dep.Result.Matches {
+			ruleIDs = append(ruleIDs, match.Rule.ID)
+		}
+
+		header := severityStyle.Render(IconDangerCritical+" "+strings.ToLower(dep.Result.DangerLevel.String())) + " " +
+			lipgloss.NewStyle().Foreground(PrimaryColor).Bold(true).Render(dep.Target()) + " " +
+			mutedStyle.Render("("+strings.Join(ruleIDs, ", ")+")")
+		util.WriteString(&boxContent, "\n"+header+"\n")
+
+		via := wordwrap.String("via "+strings.Join(dep.Path, " → "), maxWidth-2)
+		util.WriteString(&boxContent, indentLines(via, "  ")+"\n")
+
+		if len(dep.Result.Matches) > 0 && dep.Result.Matches[0].MatchedLine != "" {
+			command := wordwrap.String(dep.Result.Matches[0].MatchedLine, maxWidth-2)
+			util.WriteString(&boxContent, mutedStyle.Render(indentLines(command, "  "))+"\n")
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(BorderColor).
+		Padding(1, 2).
+		Render(strings.TrimSuffix(boxContent.String(), "\n")) + "\n"
+}
+
+// indentLines prefixes every line of text with indent
+func indentLines(text, indent string) string {
+	return indent + strings.ReplaceAll(text, "\n", "\n"+indent)
+}
+
 // renderEmptyPreview shows placeholder when no target selected
 func renderEmptyPreview(width, height int) string {
 	emptyText := "Select a target to preview recipe"
@@ -496,6 +664,31 @@ func (m Model) buildLeftStatusBar(stats targetStats) string {
 	// Target count
 	sections = append(sections, plainNuggetStyle.Render(fmt.Sprintf("%d targets", stats.total)))
 
+	// Active safety profile (only when it isn't the built-in default)
+	if profile := m.renderProfileNugget(); profile != "" {
+		sections = append(sections, plainNuggetStyle.Render(profile))
+	}
+
+	// Custom rules left out because they are invalid
+	if n := len(m.RuleErrors); n > 0 {
+		sections = append(sections, yellowNuggetStyle.Render(fmt.Sprintf("%d invalid rules (lazymake rules test)", n)))
+	}
+
+	// Config file errors (unknown keys, invalid values)
+	if n := len(m.ConfigProblems); n > 0 {
+		sections = append(sections, yellowNuggetStyle.Render(fmt.Sprintf("%d config problems (lazymake config validate)", n)))
+	}
+
+	// History backend that couldn't be opened (runs are kept in the JSON history)
+	if m.Tracker != nil && m.Tracker.HistoryError != nil {
+		sections = append(sections, yellowNuggetStyle.Render("history database unavailable"))
+	}
+
+	// Lint errors and warnings (d opens the diagnostics panel)
+	if n := m.countLintProblems(); n > 0 {
+		sections = append(sections, yellowNuggetStyle.Render(fmt.Sprintf("%d lint problem%s (d)", n, pluralize(n))))
+	}
+
 	// Dangerous count
 	if stats.dangerous > 0 {
 		dangerIcon := lipgloss.NewStyle().Foreground(WarningColor).Render("○")
@@ -516,6 +709,32 @@ func (m Model) buildLeftStatusBar(stats targetStats) string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, sections...)
 }
 
+// renderProfileNugget describes the active safety profile and why it was chosen
+// Returns "" for the built-in default profile when nothing selected it.
+//
+// Example: "◈ prod-oncall (kube context prod-east)"
+func (m Model) renderProfileNugget() string {
+	if m.ProfileError != nil {
+		return lipgloss.NewStyle().Foreground(WarningColor).Render(IconProfile + " " + m.ProfileError.Error())
+	}
+
+	profile := m.SafetyProfile.Profile
+	if profile == nil || (profile.Name == safety.DefaultProfileName && m.SafetyProfile.Reason == "") {
+		return ""
+	}
+
+	color := TextSecondary
+	if profile.Confirm.MinSeverity < safety.SeverityCritical && !profile.Confirm.Never {
+		color = WarningColor // Stricter than the default: warnings ask for confirmation too
+	}
+
+	nugget := lipgloss.NewStyle().Foreground(color).Render(IconProfile + " " + profile.Name)
+	if m.SafetyProfile.Reason != "" {
+		nugget += " " + lipgloss.NewStyle().Foreground(TextMuted).Render("("+m.SafetyProfile.Reason+")")
+	}
+	return nugget
+}
+
 // getHelpText returns appropriate help text based on selected item
 func (m Model) getHelpText() string {
 	item := m.List.SelectedItem()
@@ -523,21 +742,31 @@ func (m Model) getHelpText() string {
 		return formatKeyBindings(m.KeyBindings)
 	}
 
+	if header, ok := item.(HeaderTarget); ok && header.Collapsed {
+		return "space/enter: expand section • q: quit"
+	}
+
 	target, ok := item.(Target)
 	if !ok || !target.IsDangerous {
 		return formatKeyBindings(m.KeyBindings)
 	}
 
+	// The active profile decides which levels ask for confirmation
+	enterHelp := "enter: run"
+	if m.requiresConfirmation(target) {
+		enterHelp = "enter: confirm"
+	}
+
 	switch target.DangerLevel {
 	case safety.SeverityCritical:
 		criticalIcon := lipgloss.NewStyle().Foreground(ErrorColor).Render("○")
-		return criticalIcon + " Critical • enter: confirm • esc: cancel • q: quit"
+		return criticalIcon + " Critical • " + enterHelp + " • esc: cancel • q: quit"
 	case safety.SeverityWarning:
 		warningIcon := lipgloss.NewStyle().Foreground(WarningColor).Render("○")
-		return warningIcon + " Warning • enter: run • esc: cancel • q: quit"
+		return warningIcon + " Warning • " + enterHelp + " • esc: cancel • q: quit"
 	case safety.SeverityInfo:
 		infoIcon := lipgloss.NewStyle().Foreground(SecondaryColor).Render("○")
-		return infoIcon + " Info • enter: run • esc: cancel • q: quit"
+		return infoIcon + " Info • " + enterHelp + " • esc: cancel • q: quit"
 	default:
 		return formatKeyBindings(m.KeyBindings)
 	}
@@ -694,16 +923,28 @@ func renderRecentTargetInfo(target Target) string {
 
 	util.WriteString(&builder, statsBox+"\n")
 
+	hint := lipgloss.NewStyle().
+		Foreground(TextMuted).
+		Italic(true).
+		Render(IconInfo + " Press 'p' to view trends and percentiles")
+	util.WriteString(&builder, hint+"\n")
+
 	return builder.String()
 }
 
 // getVariablesForTarget returns variables used by a specific target
-func (m Model) getVariablesForTarget(targetName string) []string {
+// In aggregate mode they come from the target's own Makefile.
+func (m Model) getVariablesForTarget(target Target) []string {
 	var result []string
 
-	for _, variable := range m.Variables {
+	vars := m.Variables
+	if pkg := m.packageOf(target); pkg != nil {
+		vars = pkg.Variables
+	}
+
+	for _, variable := range vars {
 		for _, usedTarget := range variable.UsedByTargets {
-			if usedTarget == targetName {
+			if usedTarget == target.makeName() {
 				// Format: NAME = value
 				result = append(result, fmt.Sprintf("%s = %s", variable.Name, variable.ExpandedValue))
 				break
@@ -714,6 +955,59 @@ func (m Model) getVariablesForTarget(targetName string) []string {
 	return result
 }
 
+// renderSubMakesSection lists the targets a recipe runs with recursive make
+//
+// Example:
+//
+//	Recursive Make
+//
+//	┄▶ services/api:build  Build the API server
+//	┄▶ web:all
+//
+//	ⓘ Press '>' to open services/api:build
+func renderSubMakesSection(subMakes []*graph.Node, jumps []jumpOrigin) string {
+	if len(subMakes) == 0 && len(jumps) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	hintStyle := lipgloss.NewStyle().
+		Foreground(TextMuted).
+		Italic(true)
+
+	if len(subMakes) > 0 {
+		separator := lipgloss.NewStyle().
+			Foreground(BorderColor).
+			Render(strings.Repeat("─", 50))
+		util.WriteString(&builder, separator+"\n\n")
+
+		header := lipgloss.NewStyle().
+			Foreground(SecondaryColor).
+			Bold(true).
+			Render("Recursive Make")
+		util.WriteString(&builder, header+"\n\n")
+
+		edgeStyle := lipgloss.NewStyle().Foreground(PrimaryColor)
+		descriptionStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+		for _, sub := range subMakes {
+			line := edgeStyle.Render("┄▶ ") + sub.Target.Name
+			if sub.Target.Description != "" {
+				line += "  " + descriptionStyle.Render(sub.Target.Description)
+			}
+			util.WriteString(&builder, line+"\n")
+		}
+
+		util.WriteString(&builder, "\n")
+		util.WriteString(&builder, hintStyle.Render(IconInfo+" Press '>' to open "+subMakes[0].Target.Name)+"\n")
+	}
+
+	if len(jumps) > 0 {
+		util.WriteString(&builder, hintStyle.Render(IconInfo+" Press '<' to return to "+jumps[len(jumps)-1].Target)+"\n")
+	}
+
+	return builder.String()
+}
+
 // renderVariablesSection renders the variables used by a target
 func renderVariablesSection(vars []string) string {
 	if len(vars) == 0 {
@@ -760,8 +1054,79 @@ func renderVariablesSection(vars []string) string {
 	return builder.String()
 }
 
+// buildSectionContent builds the preview shown while a collapsed section is selected
+func buildSectionContent(header HeaderTarget) string {
+	title := lipgloss.NewStyle().
+		Foreground(PrimaryColor).
+		Bold(true).
+		Render(header.Label)
+	hint := lipgloss.NewStyle().
+		Foreground(TextMuted).
+		Italic(true).
+		Render(fmt.Sprintf("%d target%s folded • space or enter to expand", header.Count, pluralize(header.Count)))
+	return title + "\n\n" + hint
+}
+
+// renderDocsSection renders a target's group, tags, documented arguments and examples
+func renderDocsSection(docs makefile.Docs) string {
+	if docs.Group == "" && len(docs.Tags) == 0 && len(docs.Args) == 0 && len(docs.Examples) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	separator := lipgloss.NewStyle().
+		Foreground(BorderColor).
+		Render(strings.Repeat("─", 50))
+	util.WriteString(&builder, separator+"\n\n")
+
+	header := lipgloss.NewStyle().
+		Foreground(SecondaryColor).
+		Bold(true).
+		Render("Documentation")
+	util.WriteString(&builder, header+"\n\n")
+
+	labelStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+	valueStyle := lipgloss.NewStyle().Foreground(TextPrimary)
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+
+	if docs.Group != "" {
+		util.WriteString(&builder, labelStyle.Render("Group: ")+valueStyle.Render(docs.Group)+"\n")
+	}
+	if len(docs.Tags) > 0 {
+		util.WriteString(&builder, labelStyle.Render("Tags:  ")+valueStyle.Render(strings.Join(docs.Tags, ", "))+"\n")
+	}
+
+	if len(docs.Args) > 0 {
+		util.WriteString(&builder, "\n"+labelStyle.Render("Arguments:")+"\n")
+		for _, arg := range docs.Args {
+			line := "  " + valueStyle.Bold(true).Render(arg.Name)
+			if arg.Default != "" {
+				line += mutedStyle.Render(" = " + arg.Default)
+			}
+			if arg.Description != "" {
+				line += "  " + mutedStyle.Render(arg.Description)
+			}
+			util.WriteString(&builder, line+"\n")
+		}
+	}
+
+	if len(docs.Examples) > 0 {
+		util.WriteString(&builder, "\n"+labelStyle.Render("Examples:")+"\n")
+		for _, example := range docs.Examples {
+			util.WriteString(&builder, "  "+valueStyle.Render(example)+"\n")
+		}
+	}
+
+	return builder.String()
+}
+
 // getWorkspaceDisplayPath returns the relative path to the current Makefile for display in status bar
+// In aggregate mode it names the workspace root and how many Makefiles it has.
 func (m Model) getWorkspaceDisplayPath() string {
+	if m.aggregated() {
+		return fmt.Sprintf("%s/ (%d Makefiles)", filepath.Base(m.WorkspaceRoot), len(m.Packages))
+	}
 	if m.WorkspaceManager == nil {
 		return filepath.Base(m.MakefilePath)
 	}
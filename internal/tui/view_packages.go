@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rshelekhov/lazymake/internal/safety"
+	"github.com/rshelekhov/lazymake/internal/util"
+)
+
+// renderPackagesView displays every package that defines the selected make target
+func (m Model) renderPackagesView() string {
+	if m.Width == 0 || m.Height == 0 {
+		return "Loading packages..."
+	}
+
+	// Same layout as the other full-screen views: bordered container + status bar
+	statusBarHeight := 3
+	availableHeight := m.Height - statusBarHeight
+	contentWidth := m.Width - 8
+	contentHeight := availableHeight - 6
+
+	content := lipgloss.Place(
+		contentWidth,
+		contentHeight,
+		lipgloss.Left,
+		lipgloss.Top,
+		m.buildPackagesContent(contentWidth, contentHeight),
+	)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(BorderColor).
+		Padding(2, 3).
+		Width(m.Width - 2)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		containerStyle.Render(content),
+		m.renderPackagesStatusBar(),
+	)
+}
+
+// buildPackagesContent renders the packages the target is run in, in order
+//
+// Example:
+//
+//	test in 3 packages
+//
+//	▶ .              avg 4.2s   Run all tests
+//	  services/api   avg 12.3s  Run API tests
+//	  services/web   ○ critical Run e2e tests against staging
+func (m Model) buildPackagesContent(width, height int) string {
+	var builder strings.Builder
+
+	util.WriteString(&builder, TitleStyle.Render(fmt.Sprintf("%s in %d packages", m.PackagesTarget, len(m.PackageTargets)))+"\n\n")
+
+	dirWidth := 0
+	for _, t := range m.PackageTargets {
+		dirWidth = max(dirWidth, min(len(t.Package), 30))
+	}
+
+	// Title (1) + blank (1)
+	visibleRows := max(height-2, 3)
+	start := 0
+	if m.PackageCursor >= visibleRows {
+		start = m.PackageCursor - visibleRows + 1
+	}
+	end := min(start+visibleRows, len(m.PackageTargets))
+
+	descriptionWidth := max(width-(2+dirWidth+2+12+2), 10)
+
+	for i := start; i < end; i++ {
+		t := m.PackageTargets[i]
+		selected := i == m.PackageCursor
+
+		rowStyle := lipgloss.NewStyle().Foreground(TextPrimary)
+		cursor := "  "
+		if selected {
+			rowStyle = rowStyle.Foreground(PrimaryColor).Bold(true)
+			cursor = lipgloss.NewStyle().Foreground(PrimaryColor).Render("▶ ")
+		}
+
+		dir := rowStyle.Render(fmt.Sprintf("%-*s", dirWidth, truncateValue(t.Package, 30)))
+		description := lipgloss.NewStyle().Foreground(TextSecondary).Render(truncateValue(t.Description, descriptionWidth))
+
+		util.WriteString(&builder, cursor+dir+"  "+packageStatus(t)+"  "+description+"\n")
+	}
+
+	return builder.String()
+}
+
+// packageStatus renders a package's danger level, or else its average duration
+func packageStatus(t Target) string {
+	style := lipgloss.NewStyle().Width(12)
+	switch {
+	case t.IsDangerous && t.DangerLevel == safety.SeverityCritical:
+		return style.Foreground(ErrorColor).Render(IconDangerCritical + " critical")
+	case t.IsDangerous && t.DangerLevel == safety.SeverityWarning:
+		return style.Foreground(WarningColor).Render(IconDangerWarning + " warning")
+	case t.PerfStats != nil && t.PerfStats.AvgDuration > 0:
+		return style.Foreground(TextMuted).Render("avg " + formatDuration(t.PerfStats.AvgDuration))
+	}
+	return style.Render("")
+}
+
+// renderPackagesStatusBar renders the status bar for the all-packages view
+func (m Model) renderPackagesStatusBar() string {
+	coloredNuggetStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"}).
+		Background(PrimaryColor).
+		Padding(0, 1).
+		MarginRight(1)
+
+	plainNuggetStyle := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Padding(0, 1)
+
+	dangerous := 0
+	for _, t := range m.PackageTargets {
+		if t.IsDangerous {
+			dangerous++
+		}
+	}
+
+	sections := []string{coloredNuggetStyle.Render(fmt.Sprintf("%d packages", len(m.PackageTargets)))}
+	if dangerous > 0 {
+		sections = append(sections, plainNuggetStyle.Render(fmt.Sprintf("%d dangerous", dangerous)))
+	}
+	leftBar := lipgloss.JoinHorizontal(lipgloss.Top, sections...)
+
+	helpText := "enter: run in all • a/esc: return • q: quit"
+
+	return m.assembleStatusBar(leftBar, helpText)
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rshelekhov/lazymake/internal/graph"
+	"github.com/rshelekhov/lazymake/internal/util"
+)
+
+// maxSpeedupBarWidth is the width of the longest bar in the speedup curve
+const maxSpeedupBarWidth = 30
+
+// buildParallelAnalysis runs the parallelism simulation for the current graph target
+// using recorded durations from history (converted to per-target self-times)
+func (m Model) buildParallelAnalysis() *graph.ParallelAnalysis {
+	if m.Graph == nil || m.GraphTarget == "" {
+		return nil
+	}
+
+	totals := make(map[string]time.Duration)
+	for _, target := range m.Targets {
+		if target.PerfStats != nil && target.PerfStats.AvgDuration > 0 {
+			totals[target.Name] = target.PerfStats.AvgDuration
+		}
+	}
+
+	return m.Graph.AnalyzeParallelism(m.GraphTarget, m.Graph.EstimateSelfDurations(totals))
+}
+
+// renderParallelismSection renders the -j recommendation and estimated speedup curve
+//
+// Example:
+//
+//	Parallelism  widest level 2 • up to 3 jobs at once • critical path 5.0s
+//	Recommended: make -j2 build
+//
+//	-j1   ██████████████████████████████    11.0s  1.0x
+//	-j2   ████████████████                   6.0s  1.8x ◀
+//	-j3   ███████████████                    5.8s  1.9x
+func renderParallelismSection(analysis *graph.ParallelAnalysis, width int) string {
+	if analysis == nil || len(analysis.Estimates) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	separator := lipgloss.NewStyle().
+		Foreground(BorderColor).
+		Render(strings.Repeat("─", max(width-8, 10)))
+	util.WriteString(&builder, "\n"+separator+"\n\n")
+
+	header := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Bold(true).
+		Render("Parallelism")
+	summary := lipgloss.NewStyle().
+		Foreground(TextMuted).
+		Render(fmt.Sprintf("  widest level %d • up to %d jobs at once • critical path %s",
+			analysis.MaxWidth, analysis.MaxJobs, formatDuration(analysis.CriticalPath)))
+	util.WriteString(&builder, header+summary+"\n")
+
+	recommendation := lipgloss.NewStyle().
+		Foreground(SuccessColor).
+		Bold(true).
+		Render(fmt.Sprintf("make -j%d %s", analysis.RecommendedJobs, analysis.Target))
+	util.WriteString(&builder, "Recommended: "+recommendation+"\n")
+
+	if !analysis.HasTimingData {
+		note := lipgloss.NewStyle().
+			Foreground(TextMuted).
+			Italic(true).
+			Render(IconInfo + " No recorded durations yet - estimates assume every recipe takes the same time")
+		util.WriteString(&builder, note+"\n")
+	}
+	util.WriteString(&builder, "\n")
+
+	// Speedup curve: one bar per -j value, scaled to the -j1 makespan
+	longest := analysis.Estimates[0].Makespan
+	for _, estimate := range analysis.Estimates {
+		barWidth := maxSpeedupBarWidth
+		if longest > 0 {
+			barWidth = max(int(float64(maxSpeedupBarWidth)*float64(estimate.Makespan)/float64(longest)), 1)
+		}
+
+		color := SecondaryColor
+		marker := ""
+		if estimate.Jobs == analysis.RecommendedJobs {
+			color = SuccessColor
+			marker = " ◀"
+		}
+
+		jobs := lipgloss.NewStyle().Foreground(TextSecondary).Render(fmt.Sprintf("-j%-3d", estimate.Jobs))
+		bar := lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("█", barWidth))
+		padding := strings.Repeat(" ", maxSpeedupBarWidth-barWidth)
+		stats := lipgloss.NewStyle().Foreground(TextPrimary).Render(
+			fmt.Sprintf("%8s  %.1fx", formatDuration(estimate.Makespan), estimate.Speedup))
+
+		util.WriteString(&builder, jobs+" "+bar+padding+" "+stats+marker+"\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,271 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rshelekhov/lazymake/internal/history"
+	"github.com/rshelekhov/lazymake/internal/util"
+)
+
+const (
+	// maxSlowestTargets is how many targets the "Slowest targets" chart shows
+	maxSlowestTargets = 5
+
+	// maxSlowestBarWidth is the width of the longest bar in the slowest targets chart
+	maxSlowestBarWidth = 30
+
+	// maxDashboardNameWidth truncates long target names in the trends table
+	maxDashboardNameWidth = 28
+)
+
+// sparkBlocks are the eight levels used to draw sparklines, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// renderPerformanceView displays the full-screen performance dashboard
+func (m Model) renderPerformanceView() string {
+	if m.Width == 0 || m.Height == 0 {
+		return "Loading performance dashboard..."
+	}
+
+	// Calculate dimensions (same layout as the variable inspector)
+	statusBarHeight := 3
+	availableHeight := m.Height - statusBarHeight
+	contentWidth := m.Width - 8
+	contentHeight := availableHeight - 6
+
+	viewportContent := lipgloss.Place(
+		contentWidth,
+		contentHeight,
+		lipgloss.Left,
+		lipgloss.Top,
+		m.PerformanceViewport.View(),
+	)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(BorderColor).
+		Padding(2, 3).
+		Width(m.Width - 2)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		containerStyle.Render(viewportContent),
+		m.renderPerformanceStatusBar(),
+	)
+}
+
+// buildPerformanceContent builds the dashboard content for the current Makefile
+//
+// Example:
+//
+//	TARGET   RUNS  FAIL    P50    P90    P99  TREND
+//	build      42    2%  12.3s  14.1s  18.0s  ▃▃▄▃▃▅▄▃▃█ ↑
+//	test       40    0%   4.2s   4.9s   5.3s  ▄▄▃▄▅▄▄▃▄▄
+func (m Model) buildPerformanceContent(width int) string {
+	var builder strings.Builder
+
+	title := TitleStyle.Render("Performance Dashboard")
+	util.WriteString(&builder, title+"\n\n")
+
+	trends := m.trends()
+
+	if len(trends) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(TextMuted).
+			Italic(true)
+		util.WriteString(&builder, emptyStyle.Render("No timed runs recorded for this Makefile yet")+"\n")
+		return builder.String()
+	}
+
+	util.WriteString(&builder, renderTrendsTable(trends)+"\n")
+	util.WriteString(&builder, renderSlowestTargets(trends, width))
+
+	return builder.String()
+}
+
+// renderTrendsTable renders one row per target with run counts, percentiles and a sparkline
+func renderTrendsTable(trends []history.TargetTrend) string {
+	var builder strings.Builder
+
+	nameWidth := len("TARGET")
+	for _, trend := range trends {
+		nameWidth = max(nameWidth, min(len(trend.Name), maxDashboardNameWidth))
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Bold(true)
+	header := fmt.Sprintf("%-*s  %5s  %5s  %7s  %7s  %7s  %s",
+		nameWidth, "TARGET", "RUNS", "FAIL", "P50", "P90", "P99", "TREND")
+	util.WriteString(&builder, headerStyle.Render(header)+"\n")
+
+	for _, trend := range trends {
+		name := lipgloss.NewStyle().
+			Foreground(PrimaryColor).
+			Render(fmt.Sprintf("%-*s", nameWidth, truncateValue(trend.Name, maxDashboardNameWidth)))
+
+		failStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+		if trend.Failures > 0 {
+			failStyle = failStyle.Foreground(ErrorColor)
+		}
+		failRate := failStyle.Render(fmt.Sprintf("%4.0f%%", trend.FailureRate*100))
+
+		stats := lipgloss.NewStyle().
+			Foreground(TextPrimary).
+			Render(fmt.Sprintf("%7s  %7s  %7s",
+				formatOptionalDuration(trend.P50),
+				formatOptionalDuration(trend.P90),
+				formatOptionalDuration(trend.P99)))
+
+		sparkColor := SecondaryColor
+		marker := ""
+		if trend.IsRegressed {
+			sparkColor = WarningColor
+			marker = " " + lipgloss.NewStyle().Foreground(WarningColor).Bold(true).Render(IconRegression)
+		}
+		spark := lipgloss.NewStyle().Foreground(sparkColor).Render(sparkline(trend.Recent))
+
+		runs := lipgloss.NewStyle().Foreground(TextSecondary).Render(fmt.Sprintf("%5d", trend.Runs))
+
+		util.WriteString(&builder, name+"  "+runs+"  "+failRate+"  "+stats+"  "+spark+marker+"\n")
+	}
+
+	return builder.String()
+}
+
+// renderSlowestTargets renders a bar chart of the slowest targets by p90
+func renderSlowestTargets(trends []history.TargetTrend, width int) string {
+	// trends are sorted by p50; the chart ranks by p90 to surface tail latency
+	slowest := make([]history.TargetTrend, 0, len(trends))
+	for _, trend := range trends {
+		if trend.P90 > 0 {
+			slowest = append(slowest, trend)
+		}
+	}
+	if len(slowest) == 0 {
+		return ""
+	}
+	sort.Slice(slowest, func(i, j int) bool {
+		if slowest[i].P90 != slowest[j].P90 {
+			return slowest[i].P90 > slowest[j].P90
+		}
+		return slowest[i].Name < slowest[j].Name
+	})
+	slowest = slowest[:min(len(slowest), maxSlowestTargets)]
+
+	var builder strings.Builder
+
+	separator := lipgloss.NewStyle().
+		Foreground(BorderColor).
+		Render(strings.Repeat("─", max(min(width, 80), 10)))
+	util.WriteString(&builder, separator+"\n\n")
+
+	header := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Bold(true).
+		Render("Slowest targets (p90)")
+	util.WriteString(&builder, header+"\n")
+
+	nameWidth := 0
+	for _, trend := range slowest {
+		nameWidth = max(nameWidth, min(len(trend.Name), maxDashboardNameWidth))
+	}
+
+	longest := slowest[0].P90
+	for _, trend := range slowest {
+		barWidth := max(int(float64(maxSlowestBarWidth)*float64(trend.P90)/float64(longest)), 1)
+
+		name := lipgloss.NewStyle().
+			Foreground(TextPrimary).
+			Render(fmt.Sprintf("%-*s", nameWidth, truncateValue(trend.Name, maxDashboardNameWidth)))
+		bar := lipgloss.NewStyle().Foreground(SecondaryColor).Render(strings.Repeat("█", barWidth))
+		duration := lipgloss.NewStyle().Foreground(TextSecondary).Render(formatDuration(trend.P90))
+
+		util.WriteString(&builder, name+"  "+bar+" "+duration+"\n")
+	}
+
+	return builder.String()
+}
+
+// renderPerformanceStatusBar renders the status bar for the performance dashboard
+func (m Model) renderPerformanceStatusBar() string {
+	trends := m.trends()
+
+	runs, failures, regressed := 0, 0, 0
+	for _, trend := range trends {
+		runs += trend.Runs
+		failures += trend.Failures
+		if trend.IsRegressed {
+			regressed++
+		}
+	}
+
+	coloredNuggetStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"}).
+		Background(PrimaryColor).
+		Padding(0, 1).
+		MarginRight(1)
+
+	plainNuggetStyle := lipgloss.NewStyle().
+		Foreground(TextSecondary).
+		Padding(0, 1)
+
+	yellowNuggetStyle := lipgloss.NewStyle().
+		Foreground(WarningColor).
+		Padding(0, 1)
+
+	var sections []string
+	sections = append(sections, coloredNuggetStyle.Render(fmt.Sprintf("%d targets tracked", len(trends))))
+	if runs > 0 {
+		sections = append(sections, plainNuggetStyle.Render(
+			fmt.Sprintf("%d runs • %.0f%% failed", runs, float64(failures)/float64(runs)*100)))
+	}
+	if regressed > 0 {
+		sections = append(sections, yellowNuggetStyle.Render(fmt.Sprintf("%d regressed", regressed)))
+	}
+	leftBar := lipgloss.JoinHorizontal(lipgloss.Top, sections...)
+
+	helpText := "p/esc: return • q: quit"
+	if m.PerformanceViewport.TotalLineCount() > m.PerformanceViewport.VisibleLineCount() {
+		helpText = "↑/↓: scroll • " + helpText
+	}
+
+	return m.assembleStatusBar(leftBar, helpText)
+}
+
+// sparkline renders durations as a row of block characters scaled between min and max
+// A flat series renders at mid height so it doesn't look like an empty or maxed-out trend.
+func sparkline(values []time.Duration) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lowest, highest := values[0], values[0]
+	for _, v := range values {
+		lowest = min(lowest, v)
+		highest = max(highest, v)
+	}
+
+	var builder strings.Builder
+	for _, v := range values {
+		level := len(sparkBlocks) / 2
+		if highest > lowest {
+			level = int(float64(v-lowest) / float64(highest-lowest) * float64(len(sparkBlocks)-1))
+		}
+		builder.WriteRune(sparkBlocks[level])
+	}
+
+	return builder.String()
+}
+
+// formatOptionalDuration formats a duration, or "-" when there is no successful run
+func formatOptionalDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return formatDuration(d)
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -84,7 +85,8 @@ func (m Model) renderVariablesView() string {
 }
 
 // buildVariablesContent builds the main content for the variables view
-func (m Model) buildVariablesContent() string {
+// Also returns the first and last line of the selected variable, to scroll it into view.
+func (m Model) buildVariablesContent() (content string, selectedStart, selectedEnd int) {
 	var builder strings.Builder
 
 	// Title
@@ -100,17 +102,25 @@ func (m Model) buildVariablesContent() string {
 			Italic(true)
 		util.WriteString(&builder, emptyStyle.Render("No variables found in Makefile")+"\n\n")
 	} else {
-		// Render all variables (no selection/navigation)
+		cursor := min(m.VariableCursor, totalVars-1)
 		for i, variable := range m.Variables {
 			if i > 0 {
 				util.WriteString(&builder, "\n") // Separator between variables
 			}
-			varBlock := renderVariableBlock(variable)
+			varBlock := renderVariableBlock(variable, i == cursor, filepath.Dir(m.VariablesPath))
+			if i == cursor {
+				selectedStart = strings.Count(builder.String(), "\n")
+				selectedEnd = selectedStart + strings.Count(varBlock, "\n")
+			}
 			util.WriteString(&builder, varBlock)
 		}
 	}
 
-	return builder.String()
+	if len(m.UndefinedVariables) > 0 {
+		util.WriteString(&builder, "\n"+renderUndefinedVariables(m.UndefinedVariables))
+	}
+
+	return builder.String(), selectedStart, selectedEnd
 }
 
 // renderVariablesStatusBar renders the status bar for the variables view
@@ -153,13 +163,20 @@ func (m Model) renderVariablesStatusBar() string {
 		sections = append(sections, unusedInfo)
 	}
 
+	// Referenced but never defined
+	if len(m.UndefinedVariables) > 0 {
+		undefinedInfo := plainNuggetStyle.Foreground(WarningColor).
+			Render(fmt.Sprintf("%d undefined", len(m.UndefinedVariables)))
+		sections = append(sections, undefinedInfo)
+	}
+
 	leftBar := lipgloss.JoinHorizontal(lipgloss.Top, sections...)
 	leftWidth := lipgloss.Width(leftBar)
 
-	// Help text on the right (add scroll hint if scrollable)
-	helpText := "v/esc: return • q: quit"
-	if m.VariablesViewport.TotalLineCount() > m.VariablesViewport.VisibleLineCount() {
-		helpText = "↑/↓: scroll • " + helpText
+	// Help text on the right
+	helpText := "↑/↓: select • enter/t: trace expansion • v/esc: return • q: quit"
+	if m.VariableTrace != nil {
+		helpText = "←/→: step • g/G: first/last • esc/t: back • q: quit"
 	}
 
 	right := lipgloss.NewStyle().
@@ -186,14 +203,10 @@ func (m Model) renderVariablesStatusBar() string {
 }
 
 // renderVariableBlock renders a single variable's information
-func renderVariableBlock(v variables.Variable) string {
+// Files in the definition chain are shown relative to dir, the Makefile's directory.
+func renderVariableBlock(v variables.Variable, selected bool, dir string) string {
 	var builder strings.Builder
 
-	// Simple styles without selection (no border, no padding)
-	titleStyle := lipgloss.NewStyle().
-		Foreground(TextPrimary).
-		Bold(true)
-
 	contentStyle := lipgloss.NewStyle().
 		Foreground(TextSecondary)
 
@@ -209,15 +222,19 @@ func renderVariableBlock(v variables.Variable) string {
 		Foreground(TextMuted).
 		Render(v.Type.String())
 
-	// Variable name in white (like target names)
-	nameStyled := lipgloss.NewStyle().
+	// Variable name in white (like target names), highlighted when selected
+	nameStyle := lipgloss.NewStyle().
 		Foreground(TextPrimary).
-		Bold(true).
-		Render(v.Name)
-	varHeader := nameStyled + " " + typeBadge + typeLabel
+		Bold(true)
+	cursor := "  "
+	if selected {
+		nameStyle = nameStyle.Foreground(PrimaryColor)
+		cursor = lipgloss.NewStyle().Foreground(PrimaryColor).Render("▶ ")
+	}
+	varHeader := cursor + nameStyle.Render(v.Name) + " " + typeBadge + typeLabel
 
 	// Render title
-	util.WriteString(&builder, titleStyle.Render(varHeader)+"\n")
+	util.WriteString(&builder, varHeader+"\n")
 
 	// Detail lines
 	var details []string
@@ -236,42 +253,183 @@ func renderVariableBlock(v variables.Variable) string {
 		details = append(details, expandedStyle.Render(expandedLine))
 	}
 
-	// Usage information
-	usageCount := len(v.UsedByTargets)
-	if usageCount > 0 {
-		// Show first few targets, then "and N more"
-		displayTargets := v.UsedByTargets
-		moreCount := 0
-		if usageCount > 3 {
-			displayTargets = v.UsedByTargets[:3]
-			moreCount = usageCount - 3
+	// What $(origin) and $(flavor) report
+	if v.Origin != "" {
+		originLine := fmt.Sprintf("Origin:   %s, %s", v.Origin, v.Flavor)
+		details = append(details, contentStyle.Render(originLine))
+	}
+
+	// Every assignment, in the order make reads them
+	details = append(details, renderAssignmentChain(v.Assignments, dir)...)
+
+	// Usage information: targets, directly and through other variables, and variables
+	details = append(details, renderUsage(v, contentStyle)...)
+
+	// Render all detail lines
+	for _, detail := range details {
+		util.WriteString(&builder, "  "+detail+"\n")
+	}
+
+	return builder.String()
+}
+
+// renderAssignmentChain renders a variable's assignments, one per line
+// Assignments that make up the final value are highlighted; the others say
+// why they didn't apply.
+//
+// Example:
+//
+//	Defined:  ● Makefile:2   =  -O2
+//	          ● extra.mk:1   += -Iinc
+//	            Makefile:6   += -g  branch not taken (ifdef DEBUG)
+func renderAssignmentChain(assignments []variables.Assignment, dir string) []string {
+	finalStyle := lipgloss.NewStyle().Foreground(SuccessColor)
+	appliedStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+	skippedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	noteStyle := lipgloss.NewStyle().Foreground(TextMuted).Italic(true)
+
+	locationWidth := 0
+	for _, a := range assignments {
+		locationWidth = max(locationWidth, len(assignmentLocation(a, dir)))
+	}
+
+	lines := make([]string, len(assignments))
+	for i, a := range assignments {
+		label := "          "
+		if i == 0 {
+			label = "Defined:  "
 		}
 
-		usageLine := fmt.Sprintf("Used by:  %s", strings.Join(displayTargets, ", "))
-		if moreCount > 0 {
-			usageLine += fmt.Sprintf(" (and %d more)", moreCount)
+		marker, style := "  ", skippedStyle
+		switch {
+		case a.Final:
+			marker, style = "● ", finalStyle
+		case a.Applied():
+			style = appliedStyle
 		}
-		usageLine += fmt.Sprintf(" (%d target%s)", usageCount, pluralize(usageCount))
 
-		details = append(details, contentStyle.Render(usageLine))
-	} else {
-		unusedStyle := contentStyle.Foreground(TextMuted).Italic(true)
-		details = append(details, unusedStyle.Render("Not used by any target"))
+		value := strings.ReplaceAll(a.Value, "\n", "↵")
+		text := fmt.Sprintf("%-*s  %-3s %s", locationWidth, assignmentLocation(a, dir), a.Operator, truncateValue(value, 50))
+		if a.Override {
+			text = fmt.Sprintf("%-*s  override %s %s", locationWidth, assignmentLocation(a, dir), a.Operator, truncateValue(value, 50))
+		}
+
+		var notes []string
+		if !a.Applied() {
+			notes = append(notes, a.Effect.String())
+		}
+		if len(a.Conditions) > 0 {
+			notes = append(notes, "("+strings.Join(a.Conditions, " › ")+")")
+		}
+		note := ""
+		if len(notes) > 0 {
+			note = "  " + noteStyle.Render(strings.Join(notes, " "))
+		}
+
+		lines[i] = appliedStyle.Render(label) + style.Render(marker+strings.TrimRight(text, " ")) + note
 	}
+	return lines
+}
 
-	// Render all detail lines
-	for _, detail := range details {
-		util.WriteString(&builder, detail+"\n")
+// assignmentLocation returns where an assignment is, with its file relative to dir
+func assignmentLocation(a variables.Assignment, dir string) string {
+	if a.File == "" {
+		return a.Location()
+	}
+	if abs, err := filepath.Abs(a.File); err == nil {
+		if rel, err := filepath.Rel(dir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			a.File = rel
+		}
+	}
+	return a.Location()
+}
+
+// renderUsage renders where a variable is used and which variables it refers to
+//
+// Example:
+//
+//	Used by:  build, deploy (2 targets)
+//	Via vars: release (1 target)
+//	Used in:  LDFLAGS (1 variable)
+//	Refers:   PKG, VERSION
+func renderUsage(v variables.Variable, style lipgloss.Style) []string {
+	var lines []string
+	if len(v.UsedByTargets) > 0 {
+		lines = append(lines, style.Render("Used by:  "+usageList(v.UsedByTargets, "target")))
+	}
+	if len(v.IndirectTargets) > 0 {
+		lines = append(lines, style.Render("Via vars: "+usageList(v.IndirectTargets, "target")))
+	}
+	if len(v.UsedByVariables) > 0 {
+		lines = append(lines, style.Render("Used in:  "+usageList(v.UsedByVariables, "variable")))
+	}
+	if len(lines) == 0 {
+		unusedStyle := style.Foreground(TextMuted).Italic(true)
+		lines = append(lines, unusedStyle.Render("Not used by any target or variable"))
+	}
+	if len(v.References) > 0 {
+		lines = append(lines, style.Render("Refers:   "+truncateValue(strings.Join(v.References, ", "), 80)))
+	}
+	return lines
+}
+
+// usageList shows the first few names, then "and N more", and the count
+func usageList(names []string, noun string) string {
+	display := names
+	moreCount := 0
+	if len(names) > 3 {
+		display = names[:3]
+		moreCount = len(names) - 3
+	}
+
+	list := strings.Join(display, ", ")
+	if moreCount > 0 {
+		list += fmt.Sprintf(" (and %d more)", moreCount)
+	}
+	return list + fmt.Sprintf(" (%d %s%s)", len(names), noun, pluralize(len(names)))
+}
+
+// renderUndefinedVariables renders the variables referred to but never given a value
+//
+// Example:
+//
+//	Undefined but referenced
+//	  ⚠ DEPLOY_HOST  deploy (target)
+//	  ⚠ prod_PORT    URL (variable)
+func renderUndefinedVariables(undefined []variables.UndefinedReference) string {
+	var builder strings.Builder
+
+	headerStyle := lipgloss.NewStyle().Foreground(WarningColor).Bold(true)
+	nameStyle := lipgloss.NewStyle().Foreground(WarningColor)
+	whereStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+
+	util.WriteString(&builder, headerStyle.Render("Undefined but referenced")+"\n")
+
+	nameWidth := 0
+	for _, u := range undefined {
+		nameWidth = max(nameWidth, len(u.Name))
+	}
+	for _, u := range undefined {
+		var where []string
+		for _, target := range u.Targets {
+			where = append(where, target+" (target)")
+		}
+		for _, name := range u.Variables {
+			where = append(where, name+" (variable)")
+		}
+		name := nameStyle.Render(fmt.Sprintf("⚠ %-*s", nameWidth, u.Name))
+		util.WriteString(&builder, "  "+name+"  "+whereStyle.Render(truncateValue(strings.Join(where, ", "), 80))+"\n")
 	}
 
 	return builder.String()
 }
 
-// countUsedVariables counts how many variables are used by at least one target
+// countUsedVariables counts how many variables are used by at least one target,
+// directly or through other variables
 func countUsedVariables(vars []variables.Variable) int {
 	count := 0
 	for _, v := range vars {
-		if len(v.UsedByTargets) > 0 {
+		if len(v.UsedByTargets) > 0 || len(v.IndirectTargets) > 0 {
 			count++
 		}
 	}
@@ -293,3 +451,107 @@ func pluralize(count int) string {
 	}
 	return "s"
 }
+
+// buildTraceContent builds the expansion trace of the selected variable, up to the revealed step
+//
+// Example:
+//
+//	LDFLAGS = -s -w $(VERSION_FLAG)   recursive, line 4
+//
+//	0  -s -w $(VERSION_FLAG)
+//	1  -s -w -X $(PKG).version=$(VERSION)
+//	     $(VERSION_FLAG) → -X $(PKG).version=$(VERSION)   line 3, recursive
+func (m Model) buildTraceContent() string {
+	trace := m.VariableTrace
+	var builder strings.Builder
+
+	labelStyle := lipgloss.NewStyle().Foreground(TextSecondary)
+	valueStyle := lipgloss.NewStyle().Foreground(TextPrimary)
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted).Italic(true)
+	refStyle := lipgloss.NewStyle().Foreground(SecondaryColor)
+
+	util.WriteString(&builder, TitleStyle.Render("Expansion of "+trace.Variable.Name)+"\n\n")
+
+	v := trace.Variable
+	definition := fmt.Sprintf("%s = %s", v.Name, truncateValue(v.RawValue, 80))
+	util.WriteString(&builder, valueStyle.Render(definition)+"  "+
+		mutedStyle.Render(traceDefinedAt(v, m.VariablesPath))+"\n\n")
+
+	valueWidth := max(m.VariablesViewport.Width-4, 20)
+	for i, step := range trace.Steps[:m.TraceStep+1] {
+		number := labelStyle.Render(fmt.Sprintf("%-3d", i))
+		style := valueStyle
+		if i == m.TraceStep {
+			style = style.Foreground(PrimaryColor).Bold(true)
+		}
+		util.WriteString(&builder, number+style.Render(truncateValue(step.Value, valueWidth))+"\n")
+
+		for _, ref := range step.Refs {
+			line := "     " + refStyle.Render(ref.Text)
+			if !ref.Kept {
+				result := ref.Result
+				if result == "" {
+					result = `""`
+				}
+				line += labelStyle.Render(" → " + truncateValue(result, max(valueWidth/2, 20)))
+			}
+			if details := traceRefDetails(ref, m.VariablesPath); details != "" {
+				line += "  " + mutedStyle.Render(details)
+			}
+			util.WriteString(&builder, line+"\n")
+		}
+	}
+
+	// Outcome once the last step is revealed
+	if m.TraceStep == len(trace.Steps)-1 {
+		util.WriteString(&builder, "\n")
+		switch {
+		case trace.Truncated:
+			util.WriteString(&builder, lipgloss.NewStyle().Foreground(WarningColor).
+				Render(fmt.Sprintf("⚠ Stopped after %d steps: the value refers to itself or keeps growing", len(trace.Steps)-1))+"\n")
+		case !trace.Complete():
+			util.WriteString(&builder, lipgloss.NewStyle().Foreground(WarningColor).
+				Render("Some references are left as written (see above)")+"\n")
+		case len(trace.Steps) == 1:
+			util.WriteString(&builder, mutedStyle.Render("No references to expand")+"\n")
+		default:
+			util.WriteString(&builder, lipgloss.NewStyle().Foreground(SuccessColor).
+				Render(fmt.Sprintf("%s Fully expanded in %d %s", IconSuccess, len(trace.Steps)-1, "step"+pluralize(len(trace.Steps)-1)))+"\n")
+		}
+	} else {
+		util.WriteString(&builder, "\n"+mutedStyle.Render(fmt.Sprintf("step %d of %d - press → to expand the next level", m.TraceStep, len(trace.Steps)-1))+"\n")
+	}
+
+	return builder.String()
+}
+
+// traceRefDetails describes where a traced reference's value comes from
+func traceRefDetails(ref variables.TraceRef, makefilePath string) string {
+	var details []string
+	switch {
+	case ref.Variable != nil:
+		details = append(details, fmt.Sprintf("%s, %s", traceDefinedAt(*ref.Variable, makefilePath), ref.Flavor))
+	case ref.Kind == variables.RefFunction:
+		details = append(details, ref.Name)
+	}
+	if ref.Note != "" {
+		details = append(details, ref.Note)
+	}
+	return strings.Join(details, ", ")
+}
+
+// traceDefinedAt describes where a variable's value was set: "line 4" in the
+// Makefile, "extra.mk:3" in an included file, or "from environment"
+func traceDefinedAt(v variables.Variable, makefilePath string) string {
+	switch {
+	case v.Origin == "environment":
+		return "from environment"
+	case v.DefinedIn == "":
+		return fmt.Sprintf("line %d", v.DefinedAt)
+	}
+	location := assignmentLocation(variables.Assignment{File: v.DefinedIn, Line: v.DefinedAt}, filepath.Dir(makefilePath))
+	if file, _, _ := strings.Cut(location, ":"); file == filepath.Base(makefilePath) {
+		return fmt.Sprintf("line %d", v.DefinedAt)
+	}
+	return location
+}
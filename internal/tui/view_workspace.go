@@ -128,7 +128,7 @@ func (m Model) renderWorkspaceStatusBar() string {
 	leftWidth := lipgloss.Width(leftBar)
 
 	// Right side: help text
-	helpText := "enter: switch • f: favorite • esc/w: cancel • q: quit"
+	helpText := "enter: switch • a: all Makefiles • f: favorite • esc/w: cancel • q: quit"
 	right := lipgloss.NewStyle().
 		Foreground(TextMuted).
 		Padding(0, 1).
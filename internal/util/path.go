@@ -0,0 +1,13 @@
+package util
+
+import "path/filepath"
+
+// SamePath reports whether two paths name the same file
+func SamePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
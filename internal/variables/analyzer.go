@@ -1,25 +1,45 @@
 package variables
 
 import (
-	"regexp"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/rshelekhov/lazymake/internal/makefile"
 )
 
-var (
-	// Matches variable references: $(VAR_NAME) or $(VAR)
-	parenVarPattern = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+// makeBuiltins are variables make defines itself, so referring to them without
+// defining them is fine
+var makeBuiltins = map[string]bool{
+	"MAKE": true, "MAKEFLAGS": true, "MFLAGS": true, "MAKECMDGOALS": true, "MAKEFILE_LIST": true,
+	"MAKELEVEL": true, "MAKEFILES": true, "MAKE_VERSION": true, "MAKE_HOST": true, "MAKE_RESTARTS": true,
+	"MAKE_TERMOUT": true, "MAKE_TERMERR": true, "CURDIR": true, "SHELL": true, ".SHELLFLAGS": true,
+	".DEFAULT_GOAL": true, ".RECIPEPREFIX": true, ".VARIABLES": true, ".FEATURES": true,
+	".INCLUDE_DIRS": true, ".EXTRA_PREREQS": true, ".LOADED": true, "VPATH": true, "GPATH": true,
+	"SUFFIXES": true, ".LIBPATTERNS": true,
 
-	// Matches single-character variable references: $X or $@
-	// We only want to capture uppercase letters and underscores for user variables
-	singleVarPattern = regexp.MustCompile(`\$([A-Z_])`)
-)
+	// Implicit rule variables
+	"AR": true, "AS": true, "CC": true, "CXX": true, "CPP": true, "FC": true, "M2C": true, "PC": true,
+	"CO": true, "GET": true, "LEX": true, "YACC": true, "LINT": true, "MAKEINFO": true, "TEX": true,
+	"TEXI2DVI": true, "WEAVE": true, "CWEAVE": true, "TANGLE": true, "CTANGLE": true, "RM": true,
+	"ARFLAGS": true, "ASFLAGS": true, "CFLAGS": true, "CXXFLAGS": true, "COFLAGS": true,
+	"CPPFLAGS": true, "FFLAGS": true, "GFLAGS": true, "LDFLAGS": true, "LDLIBS": true, "LFLAGS": true,
+	"YFLAGS": true, "PFLAGS": true, "RFLAGS": true, "LINTFLAGS": true, "OUTPUT_OPTION": true,
+}
+
+// UndefinedReference is a variable referred to but never given a value
+type UndefinedReference struct {
+	Name      string
+	Targets   []string // Targets whose recipe or prerequisites refer to it
+	Variables []string // Variables whose value refers to it
+}
 
-// AnalyzeUsage scans target recipes to find which variables are used
-// and updates the UsedByTargets field for all variables
+// AnalyzeUsage finds which variables each target and variable refers to, and updates
+// UsedByTargets (recipes and prerequisites), References, UsedByVariables and
+// IndirectTargets (targets that use a variable through other variables)
 // This function modifies the variables slice in place
 func AnalyzeUsage(variables []Variable, targets []makefile.Target) {
-	if len(variables) == 0 || len(targets) == 0 {
+	if len(variables) == 0 {
 		return
 	}
 
@@ -27,61 +47,302 @@ func AnalyzeUsage(variables []Variable, targets []makefile.Target) {
 	varMap := make(map[string]*Variable)
 	for i := range variables {
 		varMap[variables[i].Name] = &variables[i]
+		variables[i].UsedByTargets = nil
+		variables[i].UsedByVariables = nil
+		variables[i].IndirectTargets = nil
+	}
+
+	// Variable-to-variable references, from the assignments that make up each value
+	for i := range variables {
+		v := &variables[i]
+		v.References = extractVariableReferences(definitionText(*v), variables)
+		for _, ref := range v.References {
+			if used, found := varMap[ref]; found && ref != v.Name {
+				used.UsedByVariables = appendUnique(used.UsedByVariables, v.Name)
+			}
+		}
 	}
 
-	// Scan each target's recipe for variable references
+	// Scan each target's recipe and prerequisites for variable references
 	for _, target := range targets {
-		if len(target.Recipe) == 0 {
-			continue
+		direct := extractVariableReferences(targetText(target), variables)
+
+		// Update the UsedByTargets field for each variable found
+		for _, varName := range direct {
+			if variable, found := varMap[varName]; found {
+				variable.UsedByTargets = appendUnique(variable.UsedByTargets, target.Name)
+			}
 		}
 
-		// Track which variables are used by this target (use map to avoid duplicates)
-		usedVars := make(map[string]bool)
+		// Variables reached through the values of the ones used directly
+		for _, varName := range reachableVariables(direct, varMap) {
+			if variable := varMap[varName]; !slices.Contains(variable.UsedByTargets, target.Name) {
+				variable.IndirectTargets = appendUnique(variable.IndirectTargets, target.Name)
+			}
+		}
+	}
+}
 
-		for _, recipeLine := range target.Recipe {
-			// Find all variable references in this recipe line
-			refs := extractVariableReferences(recipeLine)
-			for _, ref := range refs {
-				usedVars[ref] = true
+// UndefinedReferences lists the variables that targets or other variables refer
+// to but that are never given a value: not in the Makefile, not set for a target,
+// not built into make and not in the environment
+func UndefinedReferences(variables []Variable, targets []makefile.Target) []UndefinedReference {
+	defined := make(map[string]bool)
+	for _, v := range variables {
+		if v.Origin != "undefined" {
+			defined[v.Name] = true
+		}
+	}
+	for _, target := range targets {
+		if name, _, ok := targetSpecificAssignment(target.Prerequisites); ok {
+			defined[name] = true
+		}
+	}
+	isUndefined := func(name string) bool {
+		if defined[name] || IsBuiltin(name) {
+			return false
+		}
+		_, inEnv := os.LookupEnv(name)
+		return !inEnv
+	}
+
+	var undefined []UndefinedReference
+	index := make(map[string]int)
+	record := func(name string) *UndefinedReference {
+		i, ok := index[name]
+		if !ok {
+			i = len(undefined)
+			index[name] = i
+			undefined = append(undefined, UndefinedReference{Name: name})
+		}
+		return &undefined[i]
+	}
+
+	for _, v := range variables {
+		for _, ref := range v.References {
+			if isUndefined(ref) {
+				r := record(ref)
+				r.Variables = appendUnique(r.Variables, v.Name)
+			}
+		}
+	}
+	for _, target := range targets {
+		for _, ref := range extractVariableReferences(targetText(target), variables) {
+			if isUndefined(ref) {
+				r := record(ref)
+				r.Targets = appendUnique(r.Targets, target.Name)
 			}
 		}
+	}
 
-		// Update the UsedByTargets field for each variable found
-		for varName := range usedVars {
-			if variable, found := varMap[varName]; found {
-				variable.UsedByTargets = append(variable.UsedByTargets, target.Name)
+	return undefined
+}
+
+// IsBuiltin reports whether make defines a variable itself (MAKE, CURDIR, CC, ...)
+func IsBuiltin(name string) bool {
+	return makeBuiltins[name]
+}
+
+// References returns the variables text refers to, in order of first use
+// vars resolve computed names like $($(ENV)_HOST).
+func References(text string, vars []Variable) []string {
+	return extractVariableReferences(text, vars)
+}
+
+// definitionText returns the Makefile text of the assignments that make up a value
+func definitionText(v Variable) string {
+	if len(v.Assignments) == 0 {
+		return v.RawValue
+	}
+	var parts []string
+	for _, a := range v.Assignments {
+		if a.Final && a.File != "" {
+			parts = append(parts, a.Value)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// targetText returns the parts of a target that refer to variables: its
+// prerequisites (or target-specific value) and its recipe
+func targetText(target makefile.Target) string {
+	prerequisites := target.Prerequisites
+	if _, value, ok := targetSpecificAssignment(prerequisites); ok {
+		prerequisites = value
+	}
+	return prerequisites + "\n" + strings.Join(target.Recipe, "\n")
+}
+
+// targetSpecificAssignment parses the "VAR = value" of a target-specific
+// variable ("build: VAR = value") from a target's prerequisites
+func targetSpecificAssignment(prerequisites string) (name, value string, ok bool) {
+	line := prerequisites
+	for _, directive := range []string{"export ", "override ", "private "} {
+		line = strings.TrimPrefix(line, directive)
+	}
+	matches := varPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], matches[3], true
+}
+
+// reachableVariables returns the variables reached from names through the
+// variables their values refer to, excluding names themselves
+func reachableVariables(names []string, varMap map[string]*Variable) []string {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
+	}
+
+	var reached []string
+	queue := slices.Clone(names)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		variable, found := varMap[name]
+		if !found {
+			continue
+		}
+		for _, ref := range variable.References {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			if _, defined := varMap[ref]; defined {
+				reached = append(reached, ref)
 			}
+			queue = append(queue, ref)
 		}
 	}
+	return reached
 }
 
-// extractVariableReferences finds all variable references in a string
-// Returns a slice of variable names (without the $() or $ prefix)
-func extractVariableReferences(text string) []string {
-	var refs []string
-	seen := make(map[string]bool)
+// extractVariableReferences finds the variables text refers to, in order of first use:
+// $(NAME), ${NAME} and $N, substitution references ($(SRCS:.c=.o)), references in
+// function arguments, the variables named by $(call), $(value), $(origin) and
+// $(flavor), and computed names: $($(ENV)_HOST) refers to ENV and, with ENV = prod,
+// to prod_HOST. Automatic variables, $(call) parameters ($(1)) and $(foreach)
+// loop variables are left out.
+func extractVariableReferences(text string, variables []Variable) []string {
+	scan := referenceScan{variables: variables, seen: make(map[string]bool)}
+	scan.scan(text, nil)
+	return scan.names
+}
+
+// referenceScan collects the variable references in Makefile text
+type referenceScan struct {
+	variables []Variable // For computed names
+	names     []string
+	seen      map[string]bool
+}
+
+// scan finds the references in text; local names (loop variables) are skipped
+func (r *referenceScan) scan(text string, local []string) {
+	for i := 0; i+1 < len(text); i++ {
+		if text[i] != '$' {
+			continue
+		}
 
-	// Find $(VAR) style references
-	matches := parenVarPattern.FindAllStringSubmatch(text, -1)
-	for _, match := range matches {
-		varName := match[1]
-		if !seen[varName] {
-			refs = append(refs, varName)
-			seen[varName] = true
+		switch next := text[i+1]; next {
+		case '$':
+			i++ // Escaped dollar
+		case '(', '{':
+			end := matchingClose(text, i+1)
+			if end < 0 {
+				return
+			}
+			r.scanReference(text[i+2:end], local)
+			i = end
+		default:
+			if name := string(next); !isAutomatic(name) {
+				r.add(name, local)
+			}
+			i++
 		}
 	}
+}
+
+// scanReference finds the references in one $(...) reference
+func (r *referenceScan) scanReference(inner string, local []string) {
+	if isAutomatic(inner) {
+		return
+	}
 
-	// Find $X style references (single uppercase letter or underscore)
-	matches = singleVarPattern.FindAllStringSubmatch(text, -1)
-	for _, match := range matches {
-		varName := match[1]
-		if !seen[varName] {
-			refs = append(refs, varName)
-			seen[varName] = true
+	if fn, argText, ok := splitFunction(inner); ok {
+		args := splitArgs(argText, functionArity[fn])
+		switch fn {
+		case "call", "value", "origin", "flavor":
+			if name := strings.TrimSpace(args[0]); !hasReference(name) {
+				r.add(name, local)
+			}
+		case "foreach", "let":
+			// The loop variables are only defined in the body
+			if len(args) == 3 {
+				r.scan(args[0]+","+args[1], local)
+				r.scan(args[2], append(slices.Clip(local), strings.Fields(args[0])...))
+				return
+			}
+		}
+		r.scan(argText, local)
+		return
+	}
+
+	// $($(ENV)_HOST): the references in the name, then the name they build
+	// when every one of them has a value
+	if hasReference(inner) {
+		parts := referenceScan{variables: r.variables, seen: make(map[string]bool)}
+		parts.scan(inner, local)
+		for _, name := range parts.names {
+			r.add(name, local)
 		}
+		if !slices.ContainsFunc(parts.names, r.undefined) {
+			name, ok := expandText(inner, r.variables, TraceOptions{})
+			if !ok {
+				return
+			}
+			if base, _, _, isSubst := splitSubstitution(name); isSubst {
+				name = base
+			}
+			r.add(strings.TrimSpace(name), local)
+		}
+		return
 	}
 
-	return refs
+	if name, _, _, ok := splitSubstitution(inner); ok {
+		r.add(name, local)
+		return
+	}
+	r.add(strings.TrimSpace(inner), local)
+}
+
+// undefined reports whether a name has no value in the Makefile or the environment
+func (r *referenceScan) undefined(name string) bool {
+	if slices.ContainsFunc(r.variables, func(v Variable) bool { return v.Name == name }) {
+		return false
+	}
+	_, inEnv := os.LookupEnv(name)
+	return !inEnv
+}
+
+// add records a referenced name once, skipping local names and $(call) parameters
+func (r *referenceScan) add(name string, local []string) {
+	if name == "" || r.seen[name] || slices.Contains(local, name) || strings.ContainsAny(name, " \t") {
+		return
+	}
+	if strings.Trim(name, "0123456789") == "" {
+		return // $(1), $2: parameters of $(call)
+	}
+	r.seen[name] = true
+	r.names = append(r.names, name)
+}
+
+// appendUnique appends s to list unless it's already there
+func appendUnique(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
 }
 
 // GetVariablesForTarget returns all variables used by a specific target
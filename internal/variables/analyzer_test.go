@@ -0,0 +1,141 @@
+package variables
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+	"github.com/rshelekhov/lazymake/internal/testutil"
+)
+
+func TestExtractVariableReferences(t *testing.T) {
+	vars := []Variable{
+		{Name: "LZ_ENV", RawValue: "prod"},
+		{Name: "prod_HOST", RawValue: "example.com"},
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"parentheses and braces", "$(CC) ${CFLAGS} $(CC)", []string{"CC", "CFLAGS"}},
+		{"single character", "echo $X $@ $<", []string{"X"}},
+		{"escaped dollar", "echo $$HOME $$(pwd)", nil},
+		{"automatic variants", "mkdir -p $(@D) $(<F)", nil},
+		{"substitution reference", "$(SRCS:.c=.o)", []string{"SRCS"}},
+		{"function arguments", "$(patsubst %.c,%.o,$(SRCS)) $(if $(DEBUG),-g)", []string{"SRCS", "DEBUG"}},
+		{"call names a variable", "$(call greet,$(NAME))", []string{"greet", "NAME"}},
+		{"call parameters", "hello $(1) $2", nil},
+		{"value and origin", "$(value RAW) $(origin HOST)", []string{"RAW", "HOST"}},
+		{"foreach loop variable", "$(foreach d,$(DIRS),$(d)/out $(OUT))", []string{"DIRS", "OUT"}},
+		{"nested references", "$(addprefix $(PREFIX),$(notdir $(FILES)))", []string{"PREFIX", "FILES"}},
+		{"computed name", "$($(LZ_ENV)_HOST)", []string{"LZ_ENV", "prod_HOST"}},
+		{"computed name from undefined", "$($(LZ_MISSING)_HOST)", []string{"LZ_MISSING"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractVariableReferences(tt.text, vars)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("extractVariableReferences(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeUsage(t *testing.T) {
+	dir := t.TempDir()
+	path := testutil.WriteFile(t, filepath.Join(dir, "Makefile"), `LZ_PKG = github.com/acme/app
+LZ_VERSION = 1.0
+LZ_LDFLAGS = -X $(LZ_PKG).version=$(LZ_VERSION)
+LZ_BIN = bin/app
+LZ_SRCS = main.go
+LZ_UNUSED = nothing
+
+$(LZ_BIN): ${LZ_SRCS}
+	go build -ldflags "$(LZ_LDFLAGS)" -o $@
+
+build: $(LZ_BIN)
+
+deploy:
+	scp $(LZ_BIN) $(LZ_HOST):/srv
+`)
+	vars, err := ParseVariables(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets, err := makefile.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	AnalyzeUsage(vars, targets)
+
+	bin := findVariable(t, vars, "LZ_BIN")
+	if !slices.Equal(bin.UsedByTargets, []string{"build", "deploy"}) {
+		t.Errorf("LZ_BIN used by %q, want the build prerequisite and the deploy recipe", bin.UsedByTargets)
+	}
+	srcs := findVariable(t, vars, "LZ_SRCS")
+	if !slices.Equal(srcs.UsedByTargets, []string{"$(LZ_BIN)"}) {
+		t.Errorf("LZ_SRCS used by %q, want the ${} prerequisite of $(LZ_BIN)", srcs.UsedByTargets)
+	}
+
+	ldflags := findVariable(t, vars, "LZ_LDFLAGS")
+	if !slices.Equal(ldflags.References, []string{"LZ_PKG", "LZ_VERSION"}) {
+		t.Errorf("LZ_LDFLAGS references %q", ldflags.References)
+	}
+	pkg := findVariable(t, vars, "LZ_PKG")
+	if !slices.Equal(pkg.UsedByVariables, []string{"LZ_LDFLAGS"}) {
+		t.Errorf("LZ_PKG used by variables %q, want LZ_LDFLAGS", pkg.UsedByVariables)
+	}
+	if len(pkg.UsedByTargets) != 0 || !slices.Equal(pkg.IndirectTargets, []string{"$(LZ_BIN)"}) {
+		t.Errorf("LZ_PKG should reach $(LZ_BIN) only through LZ_LDFLAGS, got direct %q, indirect %q",
+			pkg.UsedByTargets, pkg.IndirectTargets)
+	}
+
+	unused := findVariable(t, vars, "LZ_UNUSED")
+	if len(unused.UsedByTargets)+len(unused.UsedByVariables)+len(unused.IndirectTargets) != 0 {
+		t.Errorf("expected LZ_UNUSED to be unused, got %+v", unused)
+	}
+
+	undefined := UndefinedReferences(vars, targets)
+	if len(undefined) != 1 || undefined[0].Name != "LZ_HOST" || !slices.Equal(undefined[0].Targets, []string{"deploy"}) {
+		t.Errorf("expected LZ_HOST undefined in deploy, got %+v", undefined)
+	}
+}
+
+func TestAnalyzeUsage_Cycle(t *testing.T) {
+	vars := []Variable{
+		{Name: "A", RawValue: "$(B)"},
+		{Name: "B", RawValue: "$(A) $(C)"},
+		{Name: "C", RawValue: "c"},
+	}
+	targets := []makefile.Target{{Name: "all", Recipe: []string{"echo $(A)"}}}
+
+	AnalyzeUsage(vars, targets)
+
+	if !slices.Equal(vars[1].IndirectTargets, []string{"all"}) || !slices.Equal(vars[2].IndirectTargets, []string{"all"}) {
+		t.Errorf("expected B and C to reach all through A, got %+v", vars)
+	}
+	if len(vars[0].IndirectTargets) != 0 {
+		t.Errorf("A is used directly, got indirect %q", vars[0].IndirectTargets)
+	}
+}
+
+func TestUndefinedReferences_Exclusions(t *testing.T) {
+	t.Setenv("LZ_FROM_ENV", "1")
+	vars := []Variable{{Name: "OUT", RawValue: "$(CURDIR)/out $(LZ_FROM_ENV) $(LZ_MODE)"}}
+	targets := []makefile.Target{
+		{Name: "debug", Prerequisites: "LZ_MODE = debug"},
+		{Name: "all", Recipe: []string{"$(MAKE) -C sub CC=$(CC) $(LZ_TYPO)"}},
+	}
+
+	AnalyzeUsage(vars, targets)
+	undefined := UndefinedReferences(vars, targets)
+
+	if len(undefined) != 1 || undefined[0].Name != "LZ_TYPO" {
+		t.Errorf("expected only LZ_TYPO undefined, got %+v", undefined)
+	}
+}
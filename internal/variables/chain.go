@@ -0,0 +1,213 @@
+package variables
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rshelekhov/lazymake/internal/util"
+)
+
+// Assignment is one place a variable is given a value
+type Assignment struct {
+	File       string   // Makefile or included file ("" for the environment)
+	Line       int      // Line in File (0 for the environment)
+	Operator   string   // "=", ":=", "::=", "+=", "?=", "!=", "export" (no value) or "" for the environment
+	Value      string   // As written (several lines for define blocks)
+	Override   bool     // Uses the override directive
+	Exported   bool     // Uses the export directive
+	Conditions []string // Enclosing conditional branches, outermost first: "ifdef DEBUG", "else (ifdef DEBUG)"
+	Effect     Effect   // What the assignment did to the value
+	Final      bool     // Part of the final value: the assignment that set it and the appends after
+}
+
+// Effect is what an assignment did to a variable's value
+type Effect int
+
+const (
+	EffectSets     Effect = iota // Replaced the value
+	EffectAppends                // Added to the value
+	EffectSkipped                // ?= on a variable that already had a value
+	EffectIgnored                // Plain assignment after an override
+	EffectNotTaken               // In a conditional branch make doesn't take
+	EffectExports                // export without a value: marks the variable for the environment
+)
+
+// String describes an effect for display
+func (e Effect) String() string {
+	switch e {
+	case EffectSets:
+		return "sets"
+	case EffectAppends:
+		return "appends"
+	case EffectSkipped:
+		return "skipped: already set"
+	case EffectIgnored:
+		return "ignored: overridden"
+	case EffectNotTaken:
+		return "branch not taken"
+	case EffectExports:
+		return "exports"
+	default:
+		return "unknown"
+	}
+}
+
+// Location returns "file:line", or "environment" for a value from the environment
+func (a Assignment) Location() string {
+	if a.File == "" {
+		return "environment"
+	}
+	return a.File + ":" + strconv.Itoa(a.Line)
+}
+
+// Applied reports whether the assignment changed the value
+func (a Assignment) Applied() bool {
+	return a.Effect == EffectSets || a.Effect == EffectAppends
+}
+
+// chainState is a variable's value while its assignments are read
+type chainState struct {
+	set        bool
+	value      string // Current value, unexpanded
+	overridden bool   // Set by an override assignment
+}
+
+// apply records what an assignment does to the variable, given whether its
+// conditional branch is taken, and returns it with its Effect set
+func (s *chainState) apply(a Assignment, taken bool) Assignment {
+	switch {
+	case !taken:
+		a.Effect = EffectNotTaken
+	case a.Operator == "export":
+		a.Effect = EffectExports
+	case s.overridden && !a.Override:
+		a.Effect = EffectIgnored
+	case a.Operator == "?=" && s.set:
+		a.Effect = EffectSkipped
+	case a.Operator == "+=" && s.set:
+		a.Effect = EffectAppends
+		s.value = strings.TrimPrefix(s.value+" "+a.Value, " ")
+		s.overridden = s.overridden || a.Override
+	default:
+		a.Effect = EffectSets
+		s.set, s.value = true, a.Value
+		s.overridden = s.overridden || a.Override
+	}
+	return a
+}
+
+// resolveChain marks the assignments that make up the final value and sets the
+// variable's fields from them: RawValue, Type, DefinedAt, DefinedIn, Origin and Flavor
+func resolveChain(v *Variable) {
+	last := -1 // Last assignment that set the value
+	for i := range v.Assignments {
+		v.Assignments[i].Final = false
+		if v.Assignments[i].Effect == EffectSets {
+			last = i
+		}
+		if v.Assignments[i].Exported {
+			v.IsExported = true
+		}
+	}
+
+	if last < 0 {
+		// Only exported (export VAR) without a value
+		v.Type, v.Origin, v.Flavor, v.RawValue = VarEnvironment, "undefined", "undefined", ""
+		if len(v.Assignments) > 0 {
+			v.DefinedAt, v.DefinedIn = v.Assignments[0].Line, v.Assignments[0].File
+		}
+		return
+	}
+
+	setter := v.Assignments[last]
+	values := []string{setter.Value}
+	v.Assignments[last].Final = true
+	for i := last + 1; i < len(v.Assignments); i++ {
+		if v.Assignments[i].Effect == EffectAppends {
+			v.Assignments[i].Final = true
+			values = append(values, v.Assignments[i].Value)
+		}
+	}
+
+	v.RawValue = strings.TrimSpace(strings.Join(values, " "))
+	v.DefinedAt, v.DefinedIn = setter.Line, setter.File
+	v.Type = operatorToVarType(setter.Operator)
+	v.Flavor = flavorOf(setter.Operator)
+
+	switch {
+	case setter.File == "":
+		v.Type, v.Origin = VarEnvironment, "environment"
+	case setter.Override:
+		v.Origin = "override"
+	default:
+		v.Origin = "file"
+	}
+}
+
+// reconcileChain corrects the chain with where make says the value was last
+// set (from its database), for conditionals lazymake couldn't evaluate:
+// assignments after that place didn't apply, so their branch wasn't taken
+func reconcileChain(v *Variable, file string, line int) {
+	at := -1
+	for i, a := range v.Assignments {
+		if a.Line == line && util.SamePath(a.File, file) {
+			at = i
+		}
+	}
+	if at < 0 {
+		return
+	}
+
+	changed := false
+	if !v.Assignments[at].Applied() {
+		v.Assignments[at].Effect = EffectSets
+		if v.Assignments[at].Operator == "+=" && slices.ContainsFunc(v.Assignments[:at], Assignment.Applied) {
+			v.Assignments[at].Effect = EffectAppends
+		}
+		changed = true
+	}
+	for i := at + 1; i < len(v.Assignments); i++ {
+		if v.Assignments[i].Applied() {
+			v.Assignments[i].Effect = EffectNotTaken
+			changed = true
+		}
+	}
+	if changed {
+		resolveChain(v)
+	}
+}
+
+// ComposedValue is the value built from the assignments that make up the final
+// value, unexpanded; $(shell) assignments (!=) contribute their command
+func (v Variable) ComposedValue() string {
+	if len(v.Assignments) == 0 {
+		if v.Type == VarShell {
+			return "$(shell " + v.RawValue + ")"
+		}
+		return v.RawValue
+	}
+
+	var values []string
+	for _, a := range v.Assignments {
+		if !a.Final {
+			continue
+		}
+		if a.Operator == "!=" {
+			values = append(values, "$(shell "+a.Value+")")
+		} else {
+			values = append(values, a.Value)
+		}
+	}
+	return strings.TrimSpace(strings.Join(values, " "))
+}
+
+// flavorOf returns the flavor a variable gets from the operator that set it
+func flavorOf(operator string) string {
+	switch operator {
+	case ":=", "::=", "!=":
+		return "simple"
+	default:
+		return "recursive"
+	}
+}
@@ -0,0 +1,234 @@
+package variables
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rshelekhov/lazymake/internal/testutil"
+)
+
+// findVariable returns the variable named name, failing the test if there is none
+func findVariable(t *testing.T, vars []Variable, name string) Variable {
+	t.Helper()
+	for _, v := range vars {
+		if v.Name == name {
+			return v
+		}
+	}
+	t.Fatalf("variable %s not found", name)
+	return Variable{}
+}
+
+// chainSummary describes a chain as "location operator effect" lines
+func chainSummary(v Variable) []string {
+	var summary []string
+	for _, a := range v.Assignments {
+		line := filepath.Base(a.Location()) + " " + a.Operator + " " + a.Effect.String()
+		if a.Final {
+			line += " *"
+		}
+		summary = append(summary, line)
+	}
+	return summary
+}
+
+func TestParseVariables_Chain(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFile(t, filepath.Join(dir, "extra.mk"), "LZ_CFLAGS += -Iinc\n")
+	path := testutil.WriteFile(t, filepath.Join(dir, "Makefile"), `LZ_CC ?= gcc
+LZ_CFLAGS = -O2
+LZ_CFLAGS += -Wall
+include extra.mk
+ifdef LZ_DEBUG
+LZ_CFLAGS += -g
+else
+LZ_CFLAGS += -DNDEBUG
+endif
+override LZ_OPT := fast
+LZ_OPT = slow
+LZ_CC ?= clang
+define LZ_BLOCK
+line1
+line2
+endef
+export LZ_CC
+
+build:
+	$(LZ_CC) $(LZ_CFLAGS)
+`)
+
+	vars, err := ParseVariables(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.Name
+	}
+	if !slices.Equal(names, []string{"LZ_CC", "LZ_CFLAGS", "LZ_OPT", "LZ_BLOCK"}) {
+		t.Fatalf("expected one variable per name in order, got %v", names)
+	}
+
+	tests := []struct {
+		name   string
+		chain  []string
+		raw    string
+		origin string
+		flavor string
+	}{
+		{
+			name:   "LZ_CC",
+			chain:  []string{"Makefile:1 ?= sets *", "Makefile:12 ?= skipped: already set", "Makefile:17 export exports"},
+			raw:    "gcc",
+			origin: "file",
+			flavor: "recursive",
+		},
+		{
+			name: "LZ_CFLAGS",
+			chain: []string{
+				"Makefile:2 = sets *",
+				"Makefile:3 += appends *",
+				"extra.mk:1 += appends *",
+				"Makefile:6 += branch not taken",
+				"Makefile:8 += appends *",
+			},
+			raw:    "-O2 -Wall -Iinc -DNDEBUG",
+			origin: "file",
+			flavor: "recursive",
+		},
+		{
+			name:   "LZ_OPT",
+			chain:  []string{"Makefile:10 := sets *", "Makefile:11 = ignored: overridden"},
+			raw:    "fast",
+			origin: "override",
+			flavor: "simple",
+		},
+		{
+			name:   "LZ_BLOCK",
+			chain:  []string{"Makefile:13 = sets *"},
+			raw:    "line1\nline2",
+			origin: "file",
+			flavor: "recursive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := findVariable(t, vars, tt.name)
+			if got := chainSummary(v); !slices.Equal(got, tt.chain) {
+				t.Errorf("chain = %q, want %q", got, tt.chain)
+			}
+			if v.RawValue != tt.raw || v.Origin != tt.origin || v.Flavor != tt.flavor {
+				t.Errorf("got raw=%q origin=%s flavor=%s, want raw=%q origin=%s flavor=%s",
+					v.RawValue, v.Origin, v.Flavor, tt.raw, tt.origin, tt.flavor)
+			}
+		})
+	}
+
+	cflags := findVariable(t, vars, "LZ_CFLAGS")
+	if got := cflags.Assignments[4].Conditions; !slices.Equal(got, []string{"else (ifdef LZ_DEBUG)"}) {
+		t.Errorf("expected the else branch as condition, got %q", got)
+	}
+	if cc := findVariable(t, vars, "LZ_CC"); !cc.IsExported {
+		t.Error("expected LZ_CC to be exported")
+	}
+}
+
+func TestParseVariables_Environment(t *testing.T) {
+	t.Setenv("LZ_REGISTRY", "registry.local")
+	t.Setenv("LZ_TAG", "from-env")
+	path := testutil.WriteFile(t, filepath.Join(t.TempDir(), "Makefile"), "LZ_REGISTRY ?= docker.io\nLZ_TAG = latest\n")
+
+	vars, err := ParseVariables(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := findVariable(t, vars, "LZ_REGISTRY")
+	if got := chainSummary(registry); !slices.Equal(got, []string{"environment  sets *", "Makefile:1 ?= skipped: already set"}) {
+		t.Errorf("LZ_REGISTRY chain = %q", got)
+	}
+	if registry.Origin != "environment" || registry.RawValue != "registry.local" {
+		t.Errorf("expected LZ_REGISTRY from the environment, got %s %q", registry.Origin, registry.RawValue)
+	}
+
+	// A plain assignment in the Makefile wins over the environment
+	tag := findVariable(t, vars, "LZ_TAG")
+	if tag.Origin != "file" || tag.RawValue != "latest" || tag.DefinedAt != 2 {
+		t.Errorf("expected LZ_TAG from the Makefile, got %s %q line %d", tag.Origin, tag.RawValue, tag.DefinedAt)
+	}
+}
+
+func TestParseVariables_Conditionals(t *testing.T) {
+	path := testutil.WriteFile(t, filepath.Join(t.TempDir(), "Makefile"), `LZ_OS = linux
+ifeq ($(LZ_OS),darwin)
+LZ_SED = gsed
+else ifeq (linux,linux)
+LZ_SED = sed
+else
+LZ_SED = busybox sed
+endif
+ifneq "a" "b"
+LZ_X = 1
+endif
+`)
+
+	vars, err := ParseVariables(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ifeq with a reference can't be evaluated: assumed taken until make says otherwise
+	sed := findVariable(t, vars, "LZ_SED")
+	want := []string{"Makefile:3 = sets", "Makefile:5 = sets *", "Makefile:7 = branch not taken"}
+	if got := chainSummary(sed); !slices.Equal(got, want) {
+		t.Errorf("LZ_SED chain = %q, want %q", got, want)
+	}
+	if got := sed.Assignments[1].Conditions; !slices.Equal(got, []string{"else ifeq (linux,linux)"}) {
+		t.Errorf("unexpected conditions %q", got)
+	}
+	if x := findVariable(t, vars, "LZ_X"); x.Assignments[0].Effect != EffectSets {
+		t.Errorf("expected ifneq \"a\" \"b\" to be taken, got %s", x.Assignments[0].Effect)
+	}
+
+	// make reports line 3 as where LZ_SED was set: the first ifeq was taken
+	reconcileChain(&sed, path, 3)
+	want = []string{"Makefile:3 = sets *", "Makefile:5 = branch not taken", "Makefile:7 = branch not taken"}
+	if got := chainSummary(sed); !slices.Equal(got, want) {
+		t.Errorf("reconciled chain = %q, want %q", got, want)
+	}
+	if sed.RawValue != "gsed" || sed.DefinedAt != 3 {
+		t.Errorf("expected the reconciled value from line 3, got %q line %d", sed.RawValue, sed.DefinedAt)
+	}
+}
+
+func TestParseMakeDatabase(t *testing.T) {
+	output := `# Variables
+
+# makefile (from 'Makefile', line 8)
+CFLAGS = -O2 -Wall
+# 'override' directive (from 'Makefile', line 10)
+OPT := fast
+# environment
+HOME = /root
+# automatic
+@D = $(patsubst %/,%,$(dir $@))
+# Files
+`
+	db := parseMakeDatabase(output)
+
+	tests := []dbVariable{
+		{value: "-O2 -Wall", origin: "file", flavor: "recursive", file: "Makefile", line: 8},
+		{value: "fast", origin: "override", flavor: "simple", file: "Makefile", line: 10},
+		{value: "/root", origin: "environment", flavor: "recursive"},
+	}
+	for i, name := range []string{"CFLAGS", "OPT", "HOME"} {
+		if got := db[name]; got != tests[i] {
+			t.Errorf("%s = %+v, want %+v", name, got, tests[i])
+		}
+	}
+	if _, ok := db["@D"]; ok {
+		t.Error("expected automatic variables to be left out")
+	}
+}
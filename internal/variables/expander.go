@@ -2,21 +2,52 @@ package variables
 
 import (
 	"bufio"
+	"errors"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 var (
-	// Matches the source line: # makefile (from 'Makefile', line 3)
-	sourcePattern = regexp.MustCompile(`^#\s+(makefile|environment|automatic|default)`)
+	// Matches the source line: # makefile (from 'Makefile', line 3), # 'override' directive (...)
+	sourcePattern = regexp.MustCompile(`^#\s+(makefile|environment under -e|environment|command line|'override' directive|automatic|default)`)
+
+	// Matches where a variable was last set: (from 'Makefile', line 3)
+	locationPattern = regexp.MustCompile("\\(from [`']([^']+)', line (\\d+)\\)")
 
 	// Matches variable assignment in database output: VAR = value or VAR := value
 	dbVarPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*([:+?!]?=)\s*(.*)$`)
 )
 
+// databaseGoal is the empty target make is asked for while printing its database
+const databaseGoal = "lazymake-print-database"
+
+// dbOrigins maps the sources make prints in its database to what $(origin) reports
+var dbOrigins = map[string]string{
+	"makefile":             "file",
+	"environment":          "environment",
+	"environment under -e": "environment override",
+	"command line":         "command line",
+	"'override' directive": "override",
+	"automatic":            "automatic",
+	"default":              "default",
+}
+
+// dbVariable is a variable as make's database reports it
+type dbVariable struct {
+	value  string
+	origin string // What $(origin) reports: "file", "override", "environment"...
+	flavor string // "recursive" or "simple"
+	file   string // Where the value was last set ("" if make doesn't say)
+	line   int
+}
+
 // ExpandVariables runs `make --print-data-base` to get expanded variable values
 // and updates the ExpandedValue field for all variables in the slice
+// Origin and Flavor are set from make's database too, and each assignment chain
+// is corrected with where make says the value was set.
 // This function modifies the variables slice in place
 func ExpandVariables(makefilePath string, variables []Variable) error {
 	if len(variables) == 0 {
@@ -24,11 +55,22 @@ func ExpandVariables(makefilePath string, variables []Variable) error {
 	}
 
 	// Run make --print-data-base to get all variable values.
-	// The -q (question mode) flag prevents make from executing the default target,
-	// which would otherwise run as a side effect of --print-data-base.
-	cmd := exec.Command("make", "-f", makefilePath, "-q", "--print-data-base", "--no-builtin-rules", "--no-builtin-variables")
-	output, err := cmd.CombinedOutput()
+	// The -q (question mode) flag keeps make from running recipes, which would
+	// otherwise run as a side effect of --print-data-base. Question mode still
+	// runs recipe lines that use $(MAKE), so the goal is an empty target read
+	// from stdin rather than the Makefile's default goal.
+	// In question mode, make exits with status 1 when the target isn't up to date.
+	// make runs from the Makefile's directory, as it would for the user, so
+	// relative includes and $(shell ...) resolve there.
+	absPath, err := filepath.Abs(makefilePath)
 	if err != nil {
+		return nil
+	}
+	cmd := exec.Command("make", "-C", filepath.Dir(absPath), "-f", absPath, "-f", "-",
+		"-q", "--print-data-base", "--no-builtin-rules", "--no-builtin-variables", databaseGoal)
+	cmd.Stdin = strings.NewReader(databaseGoal + ": ;\n")
+	output, err := cmd.CombinedOutput()
+	if exitErr := (*exec.ExitError)(nil); err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() != 1) {
 		// Graceful degradation: if make fails, just return without expanding
 		// Variables will still have their RawValue populated
 		return nil
@@ -40,7 +82,12 @@ func ExpandVariables(makefilePath string, variables []Variable) error {
 	// Update the ExpandedValue field for each variable
 	for i := range variables {
 		if expanded, found := expandedVars[variables[i].Name]; found {
-			variables[i].ExpandedValue = expanded
+			variables[i].ExpandedValue = expanded.value
+			if expanded.file != "" {
+				reconcileChain(&variables[i], expanded.file, expanded.line)
+			}
+			variables[i].Origin = expanded.origin
+			variables[i].Flavor = expanded.flavor
 		} else {
 			// If not found in database, use raw value as expanded value
 			variables[i].ExpandedValue = variables[i].RawValue
@@ -51,13 +98,15 @@ func ExpandVariables(makefilePath string, variables []Variable) error {
 }
 
 // parseMakeDatabase parses the output of `make --print-data-base`
-// and returns a map of variable names to their expanded values
-func parseMakeDatabase(output string) map[string]string {
-	result := make(map[string]string)
+// and returns a map of variable names to their values, origins and locations
+func parseMakeDatabase(output string) map[string]dbVariable {
+	result := make(map[string]dbVariable)
 	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // Long variable values
 
 	inVariablesSection := false
 	currentSource := ""
+	currentFile, currentLine := "", 0
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -78,15 +127,30 @@ func parseMakeDatabase(output string) map[string]string {
 			break
 		}
 
-		// Track the current source (makefile, environment, etc.)
+		// Track the current source (makefile, environment, etc.) and location
 		if matches := sourcePattern.FindStringSubmatch(line); matches != nil {
 			currentSource = matches[1]
+			currentFile, currentLine = "", 0
+			if location := locationPattern.FindStringSubmatch(line); location != nil {
+				currentFile = location[1]
+				currentLine, _ = strconv.Atoi(location[2])
+			}
 			continue
 		}
 
 		// Parse and store variable if valid
 		if varName, value, ok := parseVariableLine(line, currentSource); ok {
-			result[varName] = value
+			flavor := "recursive"
+			if matches := dbVarPattern.FindStringSubmatch(line); matches != nil && matches[2] == ":=" {
+				flavor = "simple"
+			}
+			result[varName] = dbVariable{
+				value:  value,
+				origin: dbOrigins[currentSource],
+				flavor: flavor,
+				file:   currentFile,
+				line:   currentLine,
+			}
 		}
 	}
 
@@ -119,9 +183,10 @@ func parseVariableLine(line, currentSource string) (varName, value string, ok bo
 	varName = matches[1]
 	value = matches[3]
 
-	// Only accept variables from makefile or environment
+	// Only accept variables from the makefile, the environment or overrides
 	// Skip automatic and default variables unless they were explicitly defined
-	if currentSource != "makefile" && currentSource != "environment" {
+	switch currentSource {
+	case "automatic", "default", "":
 		return "", "", false
 	}
 
@@ -0,0 +1,42 @@
+package variables
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestExpandVariables_RunsNothing reads values without running the default
+// goal, even the recursive make lines question mode would run
+func TestExpandVariables_RunsNothing(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not installed")
+	}
+
+	root := t.TempDir()
+	stamp := filepath.Join(root, "sub", "ran")
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "Makefile"),
+		[]byte("DEST := /var/lib/app\n\nall:\n\t$(MAKE) -C sub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "Makefile"),
+		[]byte("DEST := dist\n\nall:\n\ttouch "+stamp+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := []Variable{{Name: "DEST", RawValue: "/var/lib/app"}}
+	if err := ExpandVariables(filepath.Join(root, "Makefile"), vars); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(stamp); err == nil {
+		t.Error("ExpandVariables ran the sub-make")
+	}
+	if vars[0].ExpandedValue != "/var/lib/app" {
+		t.Errorf("expanded DEST = %q, want the Makefile's own value", vars[0].ExpandedValue)
+	}
+}
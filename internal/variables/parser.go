@@ -3,6 +3,7 @@ package variables
 import (
 	"bufio"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -10,26 +11,82 @@ import (
 var (
 	// varPattern matches variable assignments: VAR = value, VAR := value, etc.
 	// Captures: (1) variable name, (2) operator, (3) value
-	varPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*([:+?!]?=)\s*(.*)$`)
+	varPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(:::=|::=|:=|[+?!]?=)\s*(.*)$`)
 
-	// exportPattern matches export declarations: export VAR or export VAR = value
-	exportPattern = regexp.MustCompile(`^export\s+([A-Za-z_][A-Za-z0-9_]*)`)
+	// definePattern matches the start of a multi-line definition: define VAR or define VAR :=
+	definePattern = regexp.MustCompile(`^define\s+([A-Za-z_][A-Za-z0-9_]*)\s*(:::=|::=|:=|[+?!]?=)?\s*$`)
 
-	// exportWithAssignPattern matches: export VAR = value
-	exportWithAssignPattern = regexp.MustCompile(`^export\s+([A-Za-z_][A-Za-z0-9_]*)\s*([:+?!]?=)\s*(.*)$`)
+	// exportNamesPattern matches export declarations without a value: export VAR [VAR...]
+	exportNamesPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\s+[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+	// includePattern matches include directives: include a.mk, -include b.mk, sinclude c.mk
+	includePattern = regexp.MustCompile(`^(?:-include|sinclude|include)\s+(.+)$`)
+
+	// conditionalPattern matches the start of a conditional: ifeq (a,b), ifdef VAR
+	conditionalPattern = regexp.MustCompile(`^(ifeq|ifneq|ifdef|ifndef)(?:\s+(.*))?$`)
 )
 
-// ParseVariables extracts variable definitions from a Makefile
-// Returns a slice of Variable structs with Name, RawValue, Type, DefinedAt, and IsExported fields populated
+// ParseVariables extracts variable definitions from a Makefile and the files it includes
+// Returns one Variable per name, in the order they're first assigned, with every
+// assignment in Assignments (the environment first, when it has the variable).
+// Conditional branches lazymake can tell aren't taken are marked, but not evaluated
+// otherwise; ExpandVariables corrects the chain with what make reports.
 // The ExpandedValue and UsedByTargets fields will be empty and should be populated by other functions
 func ParseVariables(makefilePath string) ([]Variable, error) {
-	file, err := os.Open(makefilePath)
-	if err != nil {
+	p := &variableParser{
+		index:   make(map[string]int),
+		states:  make(map[string]*chainState),
+		visited: make(map[string]bool),
+		dir:     filepath.Dir(makefilePath),
+		env:     os.LookupEnv,
+	}
+	if err := p.parseFile(makefilePath); err != nil {
 		return nil, err
 	}
+
+	for i := range p.variables {
+		resolveChain(&p.variables[i])
+	}
+	return p.variables, nil
+}
+
+// variableParser reads assignments in the order make does, following includes
+type variableParser struct {
+	variables []Variable
+	index     map[string]int // Name -> position in variables
+	states    map[string]*chainState
+	branches  []branch        // Enclosing conditional branches, innermost last
+	define    *pendingDefine  // Multi-line definition being read
+	visited   map[string]bool // Files already read, so include cycles end
+	dir       string          // Directory of the Makefile: include paths are relative to it
+	env       func(name string) (string, bool)
+}
+
+// branch is the conditional branch being read
+type branch struct {
+	text      string // As shown: "ifdef DEBUG", or "else (ifdef DEBUG)"
+	directive string // The line that opened the conditional
+	taken     bool   // Whether make takes the branch (assumed when unknown)
+	decided   bool   // A branch read so far is known to be taken
+}
+
+// pendingDefine is a define block whose lines are still being read
+type pendingDefine struct {
+	name       string
+	assignment Assignment
+	lines      []string
+	depth      int // Nested define blocks
+}
+
+// parseFile reads one Makefile or included file
+func (p *variableParser) parseFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
+	p.visited[path] = true
 
-	var variables []Variable
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
 	var continuedLine string
@@ -39,44 +96,38 @@ func ParseVariables(makefilePath string) ([]Variable, error) {
 		lineNum++
 		line := scanner.Text()
 
+		if p.define != nil {
+			p.readDefineLine(line)
+			continue
+		}
+
 		// Handle line continuations
 		if handleLineContinuation(line, &continuedLine, &continuedLineStart, lineNum) {
 			continue
 		}
 
 		// If we were building a continued line, append this final part
+		assignLine := lineNum
 		if continuedLine != "" {
 			line = continuedLine + line
-			lineNum = continuedLineStart
+			assignLine = continuedLineStart
 			continuedLine = ""
 		}
 
-		// Skip comments, empty lines, targets, and recipe lines
-		if shouldSkipLine(line) {
-			continue
-		}
-
+		// Skip comments, empty lines and recipe lines
 		trimmedLine := strings.TrimSpace(line)
-
-		// Try to process as export statement
-		if variable, found := processExportStatement(trimmedLine, &variables, lineNum); found {
-			if variable.Name != "" { // New variable needs to be added
-				variables = append(variables, variable)
-			}
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") || strings.HasPrefix(line, "\t") {
 			continue
 		}
 
-		// Try to process as regular variable assignment
-		if variable, found := processVariableAssignment(trimmedLine, lineNum); found {
-			variables = append(variables, variable)
+		if p.readConditional(trimmedLine) || p.readInclude(trimmedLine) {
+			continue
 		}
+		p.readAssignment(trimmedLine, path, assignLine)
 	}
+	p.define = nil // An unterminated define ends with its file
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	return variables, nil
+	return scanner.Err()
 }
 
 // handleLineContinuation manages line continuations (backslash at end)
@@ -93,89 +144,263 @@ func handleLineContinuation(line string, continuedLine *string, continuedLineSta
 	return false
 }
 
-// shouldSkipLine checks if a line should be skipped during parsing
-func shouldSkipLine(line string) bool {
-	trimmedLine := strings.TrimSpace(line)
+// readAssignment records an assignment, define block or export declaration
+// Directives in front of the variable (export, override, private) can come in any order.
+func (p *variableParser) readAssignment(line, path string, lineNum int) {
+	var exported, override bool
+	for {
+		word, rest, _ := strings.Cut(line, " ")
+		switch word {
+		case "export":
+			exported = true
+		case "override":
+			override = true
+		case "private":
+		case "unexport", "undefine", "vpath":
+			return
+		default:
+			p.readDefinition(line, path, lineNum, exported, override)
+			return
+		}
+		line = strings.TrimSpace(rest)
+	}
+}
 
-	// Skip comments and empty lines
-	if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
-		return true
+// readDefinition records what follows the directives of an assignment line
+func (p *variableParser) readDefinition(line, path string, lineNum int, exported, override bool) {
+	assignment := Assignment{
+		File:     path,
+		Line:     lineNum,
+		Override: override,
+		Exported: exported,
 	}
 
-	// Skip target definitions (lines with ':' that aren't variable assignments)
-	if strings.Contains(trimmedLine, ":") && !strings.Contains(trimmedLine, "=") {
+	if matches := definePattern.FindStringSubmatch(line); matches != nil {
+		assignment.Operator = matches[2]
+		if assignment.Operator == "" {
+			assignment.Operator = "="
+		}
+		p.define = &pendingDefine{name: matches[1], assignment: assignment}
+		return
+	}
+
+	if matches := varPattern.FindStringSubmatch(line); matches != nil {
+		assignment.Operator = matches[2]
+		assignment.Value = strings.TrimSpace(matches[3])
+		p.add(matches[1], assignment)
+		return
+	}
+
+	// export VAR [VAR...]: marks variables without giving them a value
+	if exported && exportNamesPattern.MatchString(line) {
+		assignment.Operator = "export"
+		for _, name := range strings.Fields(line) {
+			p.add(name, assignment)
+		}
+	}
+}
+
+// readDefineLine adds a line to the define block being read, or ends it
+func (p *variableParser) readDefineLine(line string) {
+	d := p.define
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "endef" || strings.HasPrefix(trimmed, "endef "):
+		if d.depth == 0 {
+			d.assignment.Value = strings.Join(d.lines, "\n")
+			p.define = nil
+			p.add(d.name, d.assignment)
+			return
+		}
+		d.depth--
+	case strings.HasPrefix(trimmed, "define "):
+		d.depth++
+	}
+	d.lines = append(d.lines, line)
+}
+
+// add records an assignment in its variable's chain
+func (p *variableParser) add(name string, assignment Assignment) {
+	i, ok := p.index[name]
+	if !ok {
+		i = len(p.variables)
+		p.index[name] = i
+		p.variables = append(p.variables, Variable{Name: name})
+		p.states[name] = &chainState{}
+
+		// The environment gives the variable its first value
+		if value, inEnv := p.env(name); inEnv {
+			env := p.states[name].apply(Assignment{Value: value}, true)
+			p.variables[i].Assignments = append(p.variables[i].Assignments, env)
+		}
+	}
+
+	assignment.Conditions = p.conditions()
+	assignment = p.states[name].apply(assignment, p.taken())
+	p.variables[i].Assignments = append(p.variables[i].Assignments, assignment)
+}
+
+// readInclude reads the files named by an include directive
+// Names are expanded with the variables known so far; missing files are skipped.
+func (p *variableParser) readInclude(line string) bool {
+	matches := includePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return false
+	}
+	if !p.taken() {
 		return true
 	}
 
-	// Skip recipe lines (start with tab)
-	if strings.HasPrefix(line, "\t") {
+	names := matches[1]
+	if hasReference(names) {
+		known := make([]Variable, len(p.variables))
+		copy(known, p.variables)
+		for i := range known {
+			resolveChain(&known[i])
+		}
+		expanded, ok := expandText(names, known, TraceOptions{Env: p.env, Dir: p.dir})
+		if !ok {
+			return true
+		}
+		names = expanded
+	}
+
+	for _, name := range strings.Fields(names) {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(p.dir, name)
+		}
+		paths, _ := filepath.Glob(name)
+		for _, path := range paths {
+			if !p.visited[path] {
+				_ = p.parseFile(path) // Unreadable includes are skipped, as -include would
+			}
+		}
+	}
+	return true
+}
+
+// readConditional tracks ifeq/ifdef/else/endif lines
+func (p *variableParser) readConditional(line string) bool {
+	if matches := conditionalPattern.FindStringSubmatch(line); matches != nil {
+		taken, known := p.evaluate(matches[1], strings.TrimSpace(matches[2]))
+		p.branches = append(p.branches, branch{
+			text:      line,
+			directive: line,
+			taken:     taken || !known,
+			decided:   known && taken,
+		})
 		return true
 	}
 
+	word, rest, _ := strings.Cut(line, " ")
+	switch word {
+	case "else":
+		if len(p.branches) == 0 {
+			return true
+		}
+		b := &p.branches[len(p.branches)-1]
+		rest = strings.TrimSpace(rest)
+		if matches := conditionalPattern.FindStringSubmatch(rest); matches != nil {
+			// else ifdef X: taken if no earlier branch was and its test passes
+			taken, known := p.evaluate(matches[1], strings.TrimSpace(matches[2]))
+			b.text = "else " + rest
+			b.taken = !b.decided && (taken || !known)
+			b.decided = b.decided || (known && taken)
+			return true
+		}
+		b.text = "else (" + b.directive + ")"
+		b.taken = !b.decided
+		b.decided = true
+		return true
+
+	case "endif":
+		if len(p.branches) > 0 {
+			p.branches = p.branches[:len(p.branches)-1]
+		}
+		return true
+	}
 	return false
 }
 
-// processExportStatement handles export declarations
-// Returns the variable and true if this is an export statement
-// If the variable already exists and was just marked as exported, returns empty variable
-func processExportStatement(trimmedLine string, variables *[]Variable, lineNum int) (Variable, bool) {
-	// Check for export with assignment: export VAR = value
-	if matches := exportWithAssignPattern.FindStringSubmatch(trimmedLine); matches != nil {
-		return Variable{
-			Name:       matches[1],
-			RawValue:   strings.TrimSpace(matches[3]),
-			Type:       operatorToVarType(matches[2]),
-			DefinedAt:  lineNum,
-			IsExported: true,
-		}, true
-	}
-
-	// Check for export without assignment: export VAR
-	if matches := exportPattern.FindStringSubmatch(trimmedLine); matches != nil {
-		varName := matches[1]
-
-		// Mark existing variable as exported, or create a new one
-		for i := range *variables {
-			if (*variables)[i].Name == varName {
-				(*variables)[i].IsExported = true
-				return Variable{}, true // Return empty variable since we modified existing one
-			}
+// evaluate tells whether a conditional's test passes, when it can be told
+// from the variables read so far: ifdef/ifndef and comparisons of plain text
+func (p *variableParser) evaluate(directive, args string) (taken, known bool) {
+	switch directive {
+	case "ifdef", "ifndef":
+		if hasReference(args) {
+			return false, false
+		}
+		defined := false
+		if state, ok := p.states[args]; ok {
+			defined = state.set && state.value != ""
+		} else if value, ok := p.env(args); ok {
+			defined = value != ""
 		}
+		return defined == (directive == "ifdef"), true
 
-		// Variable exported but not yet defined in this file
-		return Variable{
-			Name:       varName,
-			Type:       VarEnvironment,
-			DefinedAt:  lineNum,
-			IsExported: true,
-		}, true
+	case "ifeq", "ifneq":
+		a, b, ok := splitComparison(args)
+		if !ok || hasReference(a) || hasReference(b) {
+			return false, false
+		}
+		return (a == b) == (directive == "ifeq"), true
 	}
+	return false, false
+}
 
-	return Variable{}, false
+// splitComparison splits the arguments of ifeq/ifneq: (a,b), "a" "b" or 'a' 'b'
+func splitComparison(args string) (a, b string, ok bool) {
+	if strings.HasPrefix(args, "(") && strings.HasSuffix(args, ")") {
+		parts := splitArgs(args[1:len(args)-1], 2)
+		if len(parts) != 2 {
+			return "", "", false
+		}
+		return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return "", "", false
+	}
+	unquote := func(s string) (string, bool) {
+		if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+			return s[1 : len(s)-1], true
+		}
+		return "", false
+	}
+	a, okA := unquote(fields[0])
+	b, okB := unquote(fields[1])
+	return a, b, okA && okB
 }
 
-// processVariableAssignment handles regular variable assignments
-// Returns the variable and true if this is a valid assignment
-func processVariableAssignment(trimmedLine string, lineNum int) (Variable, bool) {
-	matches := varPattern.FindStringSubmatch(trimmedLine)
-	if matches == nil {
-		return Variable{}, false
+// taken reports whether make reads the current branch (true outside conditionals)
+func (p *variableParser) taken() bool {
+	for _, b := range p.branches {
+		if !b.taken {
+			return false
+		}
 	}
+	return true
+}
 
-	return Variable{
-		Name:      matches[1],
-		RawValue:  strings.TrimSpace(matches[3]),
-		Type:      operatorToVarType(matches[2]),
-		DefinedAt: lineNum,
-	}, true
+// conditions returns the enclosing conditional branches, outermost first
+func (p *variableParser) conditions() []string {
+	if len(p.branches) == 0 {
+		return nil
+	}
+	conditions := make([]string, len(p.branches))
+	for i, b := range p.branches {
+		conditions[i] = b.text
+	}
+	return conditions
 }
 
 // operatorToVarType converts a Makefile assignment operator to a VarType
 func operatorToVarType(operator string) VarType {
 	switch operator {
-	case "=":
+	case "=", ":::=":
 		return VarRecursive
-	case ":=":
+	case ":=", "::=":
 		return VarSimple
 	case "+=":
 		return VarAppend
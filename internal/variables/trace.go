@@ -0,0 +1,696 @@
+package variables
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxTraceSteps bounds a trace so self-referencing definitions terminate
+	maxTraceSteps = 32
+
+	// maxTraceLength stops a trace whose value keeps growing
+	maxTraceLength = 64 * 1024
+)
+
+// RefKind tells what a reference in a trace step is
+type RefKind int
+
+const (
+	RefVariable RefKind = iota // $(NAME), ${NAME} or $N
+	RefFunction                // $(patsubst ...), $(shell ...)
+	RefComputed                // $($(ENV)_HOST): the name is built from other references
+)
+
+// TraceRef is a reference expanded (or left as written) in one step of a trace
+type TraceRef struct {
+	Text     string    // As written in the step's input: "$(VERSION)"
+	Kind     RefKind   // Variable, function or computed name
+	Name     string    // Variable or function name
+	Result   string    // What the reference was replaced with
+	Variable *Variable // Last definition in the Makefile (nil for functions and variables defined elsewhere)
+	Flavor   string    // "recursive" or "simple" for variables defined in the Makefile
+	Note     string    // How the result was found: "undefined", "from environment", "condition is true"
+	Kept     bool      // Left as written: automatic variable, $(shell) not run, unsupported function
+}
+
+// TraceStep is the value after expanding one more level of references
+type TraceStep struct {
+	Value string
+	Refs  []TraceRef // References expanded in this step (none for the first step)
+}
+
+// Trace shows how a variable's value is produced, one level of references at a time
+type Trace struct {
+	Variable  Variable
+	Steps     []TraceStep // Steps[0] is the value as make stores it
+	Truncated bool        // Stopped early: self-referencing or ever-growing value
+}
+
+// Final returns the value after the last step
+func (t Trace) Final() string {
+	if len(t.Steps) == 0 {
+		return ""
+	}
+	return t.Steps[len(t.Steps)-1].Value
+}
+
+// Complete reports whether the final value has no references left
+func (t Trace) Complete() bool {
+	return !t.Truncated && !hasReference(t.Final())
+}
+
+// TraceOptions configures how a trace evaluates what depends on the outside world
+type TraceOptions struct {
+	// Shell runs $(shell ...) commands and returns their output; nil leaves them as written
+	Shell func(command string) (string, error)
+
+	// Env looks up variables not defined in the Makefile; nil uses the process environment
+	Env func(name string) (string, bool)
+
+	// Dir resolves $(wildcard ...) and $(abspath ...) ("" = working directory)
+	Dir string
+}
+
+// TraceExpansion expands a variable one level at a time, the way make would
+// when the variable is used. Each step replaces the references found in the
+// previous step's value: variables by their stored value, functions by their
+// result once their arguments have no references left. $$ is kept as written.
+// Returns false if the variable isn't defined in vars.
+func TraceExpansion(name string, vars []Variable, opts TraceOptions) (Trace, bool) {
+	t := newTracer(vars, opts)
+	v, ok := t.vars[name]
+	if !ok {
+		return Trace{}, false
+	}
+
+	trace := Trace{
+		Variable: v,
+		Steps:    []TraceStep{{Value: t.storedValue(name)}},
+	}
+
+	value := trace.Steps[0].Value
+	for range maxTraceSteps {
+		next, refs := t.step(value)
+		if next == value {
+			// Only references that can't be expanded are left; report them once
+			last := &trace.Steps[len(trace.Steps)-1]
+			last.Refs = append(last.Refs, refs...)
+			return trace, true
+		}
+
+		trace.Steps = append(trace.Steps, TraceStep{Value: next, Refs: refs})
+		value = next
+		if len(value) > maxTraceLength {
+			break
+		}
+	}
+
+	trace.Truncated = true
+	return trace, true
+}
+
+// expandText expands every reference in text that can be expanded, for values
+// lazymake needs itself (include file names); returns false if it can't finish
+func expandText(text string, vars []Variable, opts TraceOptions) (string, bool) {
+	t := newTracer(vars, opts)
+	for range maxTraceSteps {
+		next, _ := t.step(text)
+		if next == text {
+			return text, !hasReference(text)
+		}
+		text = next
+	}
+	return text, false
+}
+
+// tracer holds the variables a trace expands
+type tracer struct {
+	vars     map[string]Variable
+	opts     TraceOptions
+	reported map[string]bool // References already reported as kept
+}
+
+func newTracer(vars []Variable, opts TraceOptions) *tracer {
+	t := &tracer{
+		vars:     make(map[string]Variable, len(vars)),
+		opts:     opts,
+		reported: make(map[string]bool),
+	}
+	for _, v := range vars {
+		t.vars[v.Name] = v
+	}
+	if t.opts.Env == nil {
+		t.opts.Env = os.LookupEnv
+	}
+	return t
+}
+
+// storedValue returns a variable's value as make stores it: unexpanded for
+// recursive variables, already expanded for simple ones
+// make's database value is used when available; otherwise the value is built
+// from the assignments that make it up.
+func (t *tracer) storedValue(name string) string {
+	v, ok := t.vars[name]
+	if !ok {
+		return ""
+	}
+	if v.ExpandedValue != "" {
+		return v.ExpandedValue
+	}
+	return v.ComposedValue()
+}
+
+// flavor returns "recursive" or "simple" for a variable defined in the Makefile
+func (t *tracer) flavor(name string) string {
+	v := t.vars[name]
+	if v.Flavor != "" {
+		return v.Flavor
+	}
+	return flavorOf(v.Type.Symbol())
+}
+
+// step expands the outermost references in text once
+func (t *tracer) step(text string) (string, []TraceRef) {
+	var b strings.Builder
+	var refs []TraceRef
+
+	for i := 0; i < len(text); {
+		if text[i] != '$' || i+1 >= len(text) {
+			b.WriteByte(text[i])
+			i++
+			continue
+		}
+
+		switch next := text[i+1]; next {
+		case '$':
+			b.WriteString("$$") // Escaped dollar: stays until the shell sees it
+			i += 2
+		case '(', '{':
+			end := matchingClose(text, i+1)
+			if end < 0 {
+				b.WriteString(text[i:]) // Unterminated: left as written
+				i = len(text)
+				continue
+			}
+			b.WriteString(t.expandRef(text[i:end+1], text[i+2:end], next, &refs))
+			i = end + 1
+		default:
+			b.WriteString(t.expandRef(text[i:i+2], string(next), 0, &refs))
+			i += 2
+		}
+	}
+	return b.String(), refs
+}
+
+// expandRef replaces one reference: ref is the whole text, inner the part between the brackets
+func (t *tracer) expandRef(ref, inner string, open byte, refs *[]TraceRef) string {
+	if isAutomatic(inner) {
+		return t.keep(ref, TraceRef{Text: ref, Name: inner, Note: "automatic variable, set per target"}, refs)
+	}
+
+	if fn, args, ok := splitFunction(inner); ok {
+		return t.expandFunction(ref, fn, args, open, refs)
+	}
+
+	// $($(ENV)_HOST): expand the name first
+	if hasReference(inner) {
+		name, nested := t.step(inner)
+		*refs = append(*refs, nested...)
+		result := "$" + string(open) + name + string(closeFor(open))
+		*refs = append(*refs, TraceRef{Text: ref, Kind: RefComputed, Result: result, Note: "name built from other variables"})
+		return result
+	}
+
+	// $(SRCS:.c=.o): a substitution reference is patsubst on the value
+	if name, from, to, ok := splitSubstitution(inner); ok {
+		if !strings.Contains(from, "%") {
+			from, to = "%"+from, "%"+to
+		}
+		result := "$(patsubst " + from + "," + to + "," + t.storedValue(name) + ")"
+		*refs = append(*refs, t.variableRef(ref, name, result, "substitution reference, same as patsubst"))
+		return result
+	}
+
+	name := strings.TrimSpace(inner)
+	if t.defined(name) {
+		result := t.storedValue(name)
+		note := ""
+		if t.flavor(name) == "simple" {
+			note = "expanded when defined"
+		}
+		*refs = append(*refs, t.variableRef(ref, name, result, note))
+		return result
+	}
+	if value, ok := t.opts.Env(name); ok {
+		*refs = append(*refs, TraceRef{Text: ref, Name: name, Result: value, Note: "from environment"})
+		return value
+	}
+	*refs = append(*refs, TraceRef{Text: ref, Name: name, Note: "undefined, expands to nothing"})
+	return ""
+}
+
+// variableRef describes a reference to a variable defined in the Makefile
+func (t *tracer) variableRef(ref, name, result, note string) TraceRef {
+	r := TraceRef{Text: ref, Name: name, Result: result, Note: note}
+	if v, ok := t.vars[name]; ok {
+		r.Variable = &v
+		r.Flavor = t.flavor(name)
+	}
+	return r
+}
+
+// keep leaves a reference as written and reports it the first time
+func (t *tracer) keep(ref string, r TraceRef, refs *[]TraceRef) string {
+	if !t.reported[ref] {
+		t.reported[ref] = true
+		r.Kept = true
+		r.Result = ref
+		*refs = append(*refs, r)
+	}
+	return ref
+}
+
+// functionArity is the number of comma-separated arguments of make's functions
+// (0: any number). The last argument keeps any further commas.
+var functionArity = map[string]int{
+	"subst": 3, "patsubst": 3, "strip": 1, "findstring": 2, "filter": 2, "filter-out": 2,
+	"sort": 1, "word": 2, "wordlist": 3, "words": 1, "firstword": 1, "lastword": 1,
+	"dir": 1, "notdir": 1, "suffix": 1, "basename": 1, "addsuffix": 2, "addprefix": 2,
+	"join": 2, "wildcard": 1, "realpath": 1, "abspath": 1,
+	"if": 3, "or": 0, "and": 0, "foreach": 3, "call": 0,
+	"value": 1, "origin": 1, "flavor": 1, "shell": 1,
+	"info": 1, "warning": 1, "error": 1,
+	"eval": 1, "file": 2, "let": 3, "intcmp": 0, "guile": 1,
+}
+
+// expandFunction evaluates a function call once its arguments have no references,
+// or expands its arguments one level. if and foreach expand lazily, like make.
+func (t *tracer) expandFunction(ref, fn, argText string, open byte, refs *[]TraceRef) string {
+	args := splitArgs(argText, functionArity[fn])
+	call := func(args []string) string {
+		return "$" + string(open) + fn + " " + strings.Join(args, ",") + string(closeFor(open))
+	}
+	function := func(result, note string) string {
+		*refs = append(*refs, TraceRef{Text: ref, Kind: RefFunction, Name: fn, Result: result, Note: note})
+		return result
+	}
+
+	switch fn {
+	case "if":
+		condition := strings.TrimSpace(args[0])
+		if hasReference(condition) {
+			return call(slices.Concat([]string{t.stepInto(args[0], refs)}, args[1:]))
+		}
+		if condition != "" {
+			return function(argAt(args, 1), "condition is true")
+		}
+		return function(argAt(args, 2), "condition is empty")
+
+	case "foreach":
+		if len(args) < 3 {
+			return t.keep(ref, TraceRef{Text: ref, Kind: RefFunction, Name: fn, Note: "needs three arguments"}, refs)
+		}
+		if hasReference(args[0]) || hasReference(args[1]) {
+			return call([]string{t.stepInto(args[0], refs), t.stepInto(args[1], refs), args[2]})
+		}
+		variable, words := strings.TrimSpace(args[0]), strings.Fields(args[1])
+		results := make([]string, len(words))
+		for i, word := range words {
+			results[i] = substituteVariable(args[2], variable, word)
+		}
+		return function(strings.Join(results, " "), "for each of "+strconv.Itoa(len(words))+" words")
+	}
+
+	// The other functions need their arguments expanded first
+	if slices.ContainsFunc(args, hasReference) {
+		stepped := make([]string, len(args))
+		for i, arg := range args {
+			stepped[i] = t.stepInto(arg, refs)
+		}
+		return call(stepped)
+	}
+
+	switch fn {
+	case "shell":
+		if t.opts.Shell == nil {
+			return t.keep(ref, TraceRef{Text: ref, Kind: RefFunction, Name: fn, Note: "command not run"}, refs)
+		}
+		output, err := t.opts.Shell(args[0])
+		if err != nil {
+			return function("", "command failed: "+err.Error())
+		}
+		return function(shellOutput(output), "output of "+strings.TrimSpace(args[0]))
+
+	case "call":
+		name := strings.TrimSpace(args[0])
+		body := t.storedValue(name)
+		for i := len(args) - 1; i >= 0; i-- {
+			body = substituteVariable(body, strconv.Itoa(i), args[i])
+		}
+		return function(body, "calls "+name)
+
+	case "value":
+		name := strings.TrimSpace(args[0])
+		return function(t.storedValue(name), "unexpanded value of "+name)
+
+	case "origin":
+		return function(t.origin(strings.TrimSpace(args[0])), "")
+
+	case "flavor":
+		name := strings.TrimSpace(args[0])
+		if t.origin(name) == "undefined" {
+			return function("undefined", "")
+		}
+		return function(t.flavor(name), "")
+
+	case "info", "warning", "error":
+		return function("", "prints a message")
+
+	case "eval", "file", "let", "intcmp", "guile":
+		return t.keep(ref, TraceRef{Text: ref, Kind: RefFunction, Name: fn, Note: "not evaluated"}, refs)
+	}
+
+	result, ok := evaluateTextFunction(fn, args, t.opts.Dir)
+	if !ok {
+		return t.keep(ref, TraceRef{Text: ref, Kind: RefFunction, Name: fn, Note: "not evaluated"}, refs)
+	}
+	return function(result, "")
+}
+
+// stepInto expands one level of a function argument, recording its references
+func (t *tracer) stepInto(text string, refs *[]TraceRef) string {
+	result, nested := t.step(text)
+	*refs = append(*refs, nested...)
+	return result
+}
+
+// defined reports whether the Makefile gives a variable a value
+func (t *tracer) defined(name string) bool {
+	v, ok := t.vars[name]
+	return ok && v.Flavor != "undefined" && (v.Type != VarEnvironment || v.RawValue != "" || v.ExpandedValue != "")
+}
+
+// origin returns what $(origin NAME) would: "file", "override", "environment" or "undefined"
+func (t *tracer) origin(name string) string {
+	if t.defined(name) {
+		return cmp.Or(t.vars[name].Origin, "file")
+	}
+	if _, ok := t.opts.Env(name); ok {
+		return "environment"
+	}
+	return "undefined"
+}
+
+// evaluateTextFunction evaluates make's text and file name functions
+// Returns false for functions it doesn't know.
+func evaluateTextFunction(fn string, args []string, dir string) (string, bool) {
+	words := func(i int) []string { return strings.Fields(argAt(args, i)) }
+
+	switch fn {
+	case "subst":
+		if args[0] == "" {
+			return argAt(args, 2), true
+		}
+		return strings.ReplaceAll(argAt(args, 2), args[0], argAt(args, 1)), true
+	case "patsubst":
+		return mapWords(words(2), func(w string) string {
+			return patsubst(strings.TrimSpace(args[0]), strings.TrimSpace(argAt(args, 1)), w)
+		}), true
+	case "strip":
+		return strings.Join(words(0), " "), true
+	case "findstring":
+		if strings.Contains(argAt(args, 1), args[0]) {
+			return args[0], true
+		}
+		return "", true
+	case "filter", "filter-out":
+		patterns := words(0)
+		var kept []string
+		for _, w := range words(1) {
+			matched := slices.ContainsFunc(patterns, func(p string) bool { return matchPattern(p, w) })
+			if matched == (fn == "filter") {
+				kept = append(kept, w)
+			}
+		}
+		return strings.Join(kept, " "), true
+	case "sort":
+		sorted := slices.Compact(slices.Sorted(slices.Values(words(0))))
+		return strings.Join(sorted, " "), true
+	case "word":
+		n, err := strconv.Atoi(strings.TrimSpace(args[0]))
+		if list := words(1); err == nil && n >= 1 && n <= len(list) {
+			return list[n-1], true
+		}
+		return "", true
+	case "wordlist":
+		start, err1 := strconv.Atoi(strings.TrimSpace(args[0]))
+		end, err2 := strconv.Atoi(strings.TrimSpace(argAt(args, 1)))
+		list := words(2)
+		if err1 != nil || err2 != nil || start < 1 || start > len(list) || end < start {
+			return "", true
+		}
+		return strings.Join(list[start-1:min(end, len(list))], " "), true
+	case "words":
+		return strconv.Itoa(len(words(0))), true
+	case "firstword", "lastword":
+		list := words(0)
+		if len(list) == 0 {
+			return "", true
+		}
+		if fn == "firstword" {
+			return list[0], true
+		}
+		return list[len(list)-1], true
+	case "dir":
+		return mapWords(words(0), func(w string) string {
+			if i := strings.LastIndex(w, "/"); i >= 0 {
+				return w[:i+1]
+			}
+			return "./"
+		}), true
+	case "notdir":
+		return mapWords(words(0), func(w string) string { return w[strings.LastIndex(w, "/")+1:] }), true
+	case "suffix":
+		var suffixes []string
+		for _, w := range words(0) {
+			if ext := filepath.Ext(w); ext != "" && !strings.Contains(ext, "/") {
+				suffixes = append(suffixes, ext)
+			}
+		}
+		return strings.Join(suffixes, " "), true
+	case "basename":
+		return mapWords(words(0), func(w string) string { return strings.TrimSuffix(w, filepath.Ext(w)) }), true
+	case "addsuffix":
+		return mapWords(words(1), func(w string) string { return w + args[0] }), true
+	case "addprefix":
+		return mapWords(words(1), func(w string) string { return args[0] + w }), true
+	case "join":
+		first, second := words(0), words(1)
+		joined := make([]string, max(len(first), len(second)))
+		for i := range joined {
+			joined[i] = argAt(first, i) + argAt(second, i)
+		}
+		return strings.Join(joined, " "), true
+	case "wildcard":
+		var matches []string
+		for _, pattern := range words(0) {
+			found, _ := filepath.Glob(filepath.Join(dir, pattern))
+			for _, match := range found {
+				if rel, err := filepath.Rel(dir, match); err == nil && !filepath.IsAbs(pattern) {
+					match = rel
+				}
+				matches = append(matches, match)
+			}
+		}
+		slices.Sort(matches)
+		return strings.Join(matches, " "), true
+	case "abspath", "realpath":
+		return mapWords(words(0), func(w string) string {
+			if !filepath.IsAbs(w) {
+				base := dir
+				if base == "" {
+					base, _ = os.Getwd()
+				}
+				w = filepath.Join(base, w)
+			}
+			if fn == "realpath" {
+				if resolved, err := filepath.EvalSymlinks(w); err == nil {
+					return resolved
+				}
+			}
+			return filepath.Clean(w)
+		}), true
+	case "or":
+		for _, arg := range args {
+			if strings.TrimSpace(arg) != "" {
+				return arg, true
+			}
+		}
+		return "", true
+	case "and":
+		last := ""
+		for _, arg := range args {
+			if strings.TrimSpace(arg) == "" {
+				return "", true
+			}
+			last = arg
+		}
+		return last, true
+	}
+	return "", false
+}
+
+// hasReference reports whether text contains a reference ($$ doesn't count)
+func hasReference(text string) bool {
+	for i := 0; i+1 < len(text); i++ {
+		if text[i] != '$' {
+			continue
+		}
+		if text[i+1] == '$' {
+			i++
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// isAutomatic reports whether a reference names an automatic variable: $@, $(@D), $(<F)
+func isAutomatic(name string) bool {
+	if name == "" || !strings.ContainsRune("@<^+?*%|", rune(name[0])) {
+		return false
+	}
+	return len(name) == 1 || (len(name) == 2 && (name[1] == 'D' || name[1] == 'F'))
+}
+
+// splitFunction splits "patsubst %.c,%.o,$(SRCS)" into the function name and its arguments
+func splitFunction(inner string) (fn, args string, ok bool) {
+	i := strings.IndexAny(inner, " \t")
+	if i <= 0 {
+		return "", "", false
+	}
+	if _, known := functionArity[inner[:i]]; !known {
+		return "", "", false
+	}
+	return inner[:i], strings.TrimLeft(inner[i:], " \t"), true
+}
+
+// splitSubstitution splits "SRCS:.c=.o" into the variable name and the suffixes
+func splitSubstitution(inner string) (name, from, to string, ok bool) {
+	name, rest, found := strings.Cut(inner, ":")
+	if !found || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", "", false
+	}
+	from, to, found = strings.Cut(rest, "=")
+	if !found {
+		return "", "", "", false
+	}
+	return name, from, to, true
+}
+
+// splitArgs splits function arguments at top-level commas into at most n parts (0: no limit)
+func splitArgs(text string, n int) []string {
+	var args []string
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(', '{':
+			depth++
+		case ')', '}':
+			depth--
+		case ',':
+			if depth == 0 && (n == 0 || len(args) < n-1) {
+				args = append(args, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(args, text[start:])
+}
+
+// matchingClose returns the index of the bracket closing the one at open, or -1
+func matchingClose(text string, open int) int {
+	opening, closing := text[open], closeFor(text[open])
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case opening:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func closeFor(open byte) byte {
+	if open == '{' {
+		return '}'
+	}
+	return ')'
+}
+
+// substituteVariable replaces references to one variable in text: $(name), ${name}, and $n for one-letter names
+func substituteVariable(text, name, value string) string {
+	text = strings.ReplaceAll(text, "$("+name+")", value)
+	text = strings.ReplaceAll(text, "${"+name+"}", value)
+	if len(name) == 1 {
+		text = strings.ReplaceAll(text, "$"+name, value)
+	}
+	return text
+}
+
+// shellOutput converts command output the way $(shell) does: newlines become spaces
+func shellOutput(output string) string {
+	output = strings.TrimRight(output, "\n")
+	return strings.ReplaceAll(output, "\n", " ")
+}
+
+// patsubst replaces a word matching pattern (with one % wildcard) by replacement
+func patsubst(pattern, replacement, word string) string {
+	prefix, suffix, wildcard := strings.Cut(pattern, "%")
+	if !wildcard {
+		if word == pattern {
+			return replacement
+		}
+		return word
+	}
+	if len(word) < len(prefix)+len(suffix) || !strings.HasPrefix(word, prefix) || !strings.HasSuffix(word, suffix) {
+		return word
+	}
+	stem := word[len(prefix) : len(word)-len(suffix)]
+	return strings.Replace(replacement, "%", stem, 1)
+}
+
+// matchPattern reports whether word matches a filter pattern with an optional % wildcard
+func matchPattern(pattern, word string) bool {
+	prefix, suffix, wildcard := strings.Cut(pattern, "%")
+	if !wildcard {
+		return word == pattern
+	}
+	return len(word) >= len(prefix)+len(suffix) && strings.HasPrefix(word, prefix) && strings.HasSuffix(word, suffix)
+}
+
+func mapWords(words []string, f func(string) string) string {
+	mapped := make([]string, len(words))
+	for i, w := range words {
+		mapped[i] = f(w)
+	}
+	return strings.Join(mapped, " ")
+}
+
+// argAt returns list[i], or "" if there is no such element
+func argAt(list []string, i int) string {
+	if i < len(list) {
+		return list[i]
+	}
+	return ""
+}
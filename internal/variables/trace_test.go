@@ -0,0 +1,225 @@
+package variables
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// noEnv keeps the process environment out of traces
+func noEnv(string) (string, bool) { return "", false }
+
+func TestTraceExpansion_Steps(t *testing.T) {
+	vars := []Variable{
+		{Name: "PKG", RawValue: "github.com/acme/app", Type: VarRecursive, DefinedAt: 1},
+		{Name: "VERSION", RawValue: "git describe --tags", ExpandedValue: "v1.2.0", Type: VarShell, DefinedAt: 2},
+		{Name: "VERSION_FLAG", RawValue: "-X $(PKG).version=$(VERSION)", Type: VarRecursive, DefinedAt: 3},
+		{Name: "LDFLAGS", RawValue: "-s -w $(VERSION_FLAG)", Type: VarRecursive, DefinedAt: 4},
+	}
+
+	trace, ok := TraceExpansion("LDFLAGS", vars, TraceOptions{Env: noEnv})
+	if !ok {
+		t.Fatal("expected LDFLAGS to be traced")
+	}
+
+	want := []string{
+		"-s -w $(VERSION_FLAG)",
+		"-s -w -X $(PKG).version=$(VERSION)",
+		"-s -w -X github.com/acme/app.version=v1.2.0",
+	}
+	if len(trace.Steps) != len(want) {
+		t.Fatalf("expected %d steps, got %d: %+v", len(want), len(trace.Steps), trace.Steps)
+	}
+	for i, step := range trace.Steps {
+		if step.Value != want[i] {
+			t.Errorf("step %d = %q, want %q", i, step.Value, want[i])
+		}
+	}
+	if !trace.Complete() || trace.Final() != want[2] {
+		t.Errorf("expected a complete trace ending in %q, got %q", want[2], trace.Final())
+	}
+
+	refs := trace.Steps[2].Refs
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 references in the last step, got %+v", refs)
+	}
+	version := refs[1]
+	if version.Name != "VERSION" || version.Flavor != "simple" || version.Variable == nil || version.Variable.DefinedAt != 2 {
+		t.Errorf("expected VERSION from line 2 with simple flavor, got %+v", version)
+	}
+}
+
+func TestTraceExpansion_Functions(t *testing.T) {
+	tests := []struct {
+		name  string
+		vars  []Variable
+		trace string
+		want  string
+	}{
+		{
+			name: "patsubst after its argument",
+			vars: []Variable{
+				{Name: "SRCS", RawValue: "main.c util.c"},
+				{Name: "OBJS", RawValue: "$(patsubst %.c,%.o,$(SRCS))"},
+			},
+			trace: "OBJS",
+			want:  "main.o util.o",
+		},
+		{
+			name: "substitution reference",
+			vars: []Variable{
+				{Name: "SRCS", RawValue: "main.c util.c"},
+				{Name: "OBJS", RawValue: "$(SRCS:.c=.o)"},
+			},
+			trace: "OBJS",
+			want:  "main.o util.o",
+		},
+		{
+			name: "computed name",
+			vars: []Variable{
+				{Name: "ENV", RawValue: "prod"},
+				{Name: "prod_HOST", RawValue: "example.com"},
+				{Name: "HOST", RawValue: "$($(ENV)_HOST)"},
+			},
+			trace: "HOST",
+			want:  "example.com",
+		},
+		{
+			name: "if takes one branch",
+			vars: []Variable{
+				{Name: "DEBUG", RawValue: ""},
+				{Name: "FLAGS", RawValue: "$(if $(DEBUG),-g,-O2)"},
+			},
+			trace: "FLAGS",
+			want:  "-O2",
+		},
+		{
+			name: "foreach",
+			vars: []Variable{
+				{Name: "DIRS", RawValue: "a b"},
+				{Name: "CLEAN", RawValue: "$(foreach d,$(DIRS),$(d)/out)"},
+			},
+			trace: "CLEAN",
+			want:  "a/out b/out",
+		},
+		{
+			name: "call",
+			vars: []Variable{
+				{Name: "greet", RawValue: "hello $(1)"},
+				{Name: "MSG", RawValue: "$(call greet,world)"},
+			},
+			trace: "MSG",
+			want:  "hello world",
+		},
+		{
+			name: "text functions",
+			vars: []Variable{
+				{Name: "LIST", RawValue: "  c  a b a  "},
+				{Name: "OUT", RawValue: "$(words $(sort $(LIST))) $(firstword $(strip $(LIST))) $(addprefix -I,$(filter-out c,$(LIST)))"},
+			},
+			trace: "OUT",
+			want:  "3 c -Ia -Ib -Ia",
+		},
+		{
+			name: "automatic variables and $$ are kept",
+			vars: []Variable{
+				{Name: "DIR", RawValue: "out"},
+				{Name: "CMD", RawValue: "cp $< $(DIR)/$@ && echo $$HOME"},
+			},
+			trace: "CMD",
+			want:  "cp $< out/$@ && echo $$HOME",
+		},
+		{
+			name: "undefined expands to nothing",
+			vars: []Variable{
+				{Name: "X", RawValue: "[$(MISSING)]"},
+			},
+			trace: "X",
+			want:  "[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace, ok := TraceExpansion(tt.trace, tt.vars, TraceOptions{Env: noEnv})
+			if !ok {
+				t.Fatalf("expected %s to be traced", tt.trace)
+			}
+			if got := trace.Final(); got != tt.want {
+				t.Errorf("final = %q, want %q (steps: %+v)", got, tt.want, trace.Steps)
+			}
+		})
+	}
+}
+
+func TestTraceExpansion_Shell(t *testing.T) {
+	vars := []Variable{{Name: "SHA", RawValue: "$(shell git rev-parse HEAD)"}}
+
+	trace, _ := TraceExpansion("SHA", vars, TraceOptions{Env: noEnv})
+	if trace.Complete() || len(trace.Steps) != 1 {
+		t.Errorf("expected $(shell) to be left as written without a runner, got %+v", trace.Steps)
+	}
+	if refs := trace.Steps[0].Refs; len(refs) != 1 || !refs[0].Kept || refs[0].Note != "command not run" {
+		t.Errorf("expected the kept $(shell) to be reported, got %+v", refs)
+	}
+
+	var ran string
+	shell := func(command string) (string, error) {
+		ran = command
+		return "abc123\n", nil
+	}
+	trace, _ = TraceExpansion("SHA", vars, TraceOptions{Env: noEnv, Shell: shell})
+	if ran != "git rev-parse HEAD" || trace.Final() != "abc123" {
+		t.Errorf("expected the command's output, ran %q and got %q", ran, trace.Final())
+	}
+
+	failing := func(string) (string, error) { return "", errors.New("exit status 128") }
+	trace, _ = TraceExpansion("SHA", vars, TraceOptions{Env: noEnv, Shell: failing})
+	if trace.Final() != "" || !strings.Contains(trace.Steps[1].Refs[0].Note, "exit status 128") {
+		t.Errorf("expected a failed command to expand to nothing with its error, got %+v", trace.Steps)
+	}
+}
+
+func TestTraceExpansion_Environment(t *testing.T) {
+	vars := []Variable{{Name: "URL", RawValue: "https://$(HOST)/$(origin HOST)"}}
+	env := func(name string) (string, bool) {
+		if name == "HOST" {
+			return "localhost", true
+		}
+		return "", false
+	}
+
+	trace, _ := TraceExpansion("URL", vars, TraceOptions{Env: env})
+	if trace.Final() != "https://localhost/environment" {
+		t.Errorf("final = %q, want the environment value", trace.Final())
+	}
+	if ref := trace.Steps[1].Refs[0]; ref.Note != "from environment" || ref.Variable != nil {
+		t.Errorf("expected HOST from the environment, got %+v", ref)
+	}
+}
+
+func TestTraceExpansion_SelfReference(t *testing.T) {
+	vars := []Variable{{Name: "LOOP", RawValue: "x $(LOOP)"}}
+
+	trace, ok := TraceExpansion("LOOP", vars, TraceOptions{Env: noEnv})
+	if !ok || !trace.Truncated || trace.Complete() {
+		t.Errorf("expected a truncated trace, got truncated=%v", trace.Truncated)
+	}
+	if len(trace.Steps) > maxTraceSteps+1 {
+		t.Errorf("expected at most %d steps, got %d", maxTraceSteps+1, len(trace.Steps))
+	}
+}
+
+func TestTraceExpansion_Undefined(t *testing.T) {
+	if _, ok := TraceExpansion("NOPE", nil, TraceOptions{}); ok {
+		t.Error("expected no trace for an undefined variable")
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	got := splitArgs("a,$(subst x,y,z),c,d", 3)
+	want := []string{"a", "$(subst x,y,z)", "c,d"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitArgs = %q, want %q", got, want)
+	}
+}
@@ -2,13 +2,20 @@ package variables
 
 // Variable represents a Makefile variable with its definition and usage information
 type Variable struct {
-	Name          string   // Variable name (e.g., "GOFLAGS", "CC")
-	RawValue      string   // Value as written in Makefile
-	ExpandedValue string   // Value after expansion by make
-	Type          VarType  // How the variable is defined (=, :=, +=, ?=, !=)
-	DefinedAt     int      // Line number in Makefile where defined
-	IsExported    bool     // Whether the variable is exported to environment
-	UsedByTargets []string // Names of targets that use this variable
+	Name            string       // Variable name (e.g., "GOFLAGS", "CC")
+	RawValue        string       // Value as written in Makefile (appends included)
+	ExpandedValue   string       // Value after expansion by make
+	Type            VarType      // How the variable is defined (=, :=, +=, ?=, !=)
+	DefinedAt       int          // Line number where the value was set
+	DefinedIn       string       // File where the value was set: the Makefile or an included file
+	IsExported      bool         // Whether the variable is exported to environment
+	Origin          string       // What $(origin VAR) reports: "file", "override", "environment"
+	Flavor          string       // What $(flavor VAR) reports: "recursive", "simple" or "undefined"
+	Assignments     []Assignment // Every assignment, in the order make reads them
+	UsedByTargets   []string     // Names of targets whose recipe or prerequisites use this variable
+	References      []string     // Variables this variable's value refers to
+	UsedByVariables []string     // Variables whose value refers to this variable
+	IndirectTargets []string     // Targets that use this variable only through other variables
 }
 
 // VarType represents the type of variable assignment in a Makefile
@@ -0,0 +1,104 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rshelekhov/lazymake/internal/makefile"
+)
+
+// RootPackage is the Dir of the Makefile in the workspace root
+const RootPackage = "."
+
+// Package is one Makefile of a workspace loaded in aggregate mode
+type Package struct {
+	Dir     string // Directory relative to the workspace root, with forward slashes ("." for the root)
+	Path    string // Absolute path to the Makefile
+	Targets []makefile.Target
+	Err     error // Why the Makefile couldn't be parsed (the package has no targets)
+}
+
+// LoadPackages discovers the Makefiles under root and parses them in parallel
+// Each directory contributes the Makefile make would read there (GNUmakefile,
+// makefile, then Makefile); fragments such as *.mk are left to the Makefiles
+// that include them. Packages are sorted by directory, the root first.
+func LoadPackages(root string, opts DiscoveryOptions) ([]Package, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+
+	discovered, err := DiscoverMakefiles(root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []Package
+	seen := make(map[string]bool)
+	for _, result := range discovered {
+		dir := filepath.Dir(result.Path)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		path, err := makefile.Find(dir)
+		if err != nil {
+			continue // Only fragments in this directory
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			continue
+		}
+		packages = append(packages, Package{Dir: filepath.ToSlash(rel), Path: path})
+	}
+
+	// Parse in parallel, bounded by the number of CPUs
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, runtime.NumCPU())
+	for i := range packages {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			packages[i].Targets, packages[i].Err = makefile.Parse(packages[i].Path)
+		})
+	}
+	wg.Wait()
+
+	slices.SortFunc(packages, func(a, b Package) int {
+		switch {
+		case a.Dir == b.Dir:
+			return 0
+		case a.Dir == RootPackage:
+			return -1
+		case b.Dir == RootPackage:
+			return 1
+		}
+		return strings.Compare(a.Dir, b.Dir)
+	})
+	return packages, nil
+}
+
+// QualifiedName namespaces a target by its package: "services/api:test"
+// Targets of the root Makefile keep their name.
+func QualifiedName(dir, target string) string {
+	if dir == "" || dir == RootPackage {
+		return target
+	}
+	return dir + ":" + target
+}
+
+// Defining returns the packages with a target of the given name, in order
+func Defining(packages []Package, target string) []Package {
+	var result []Package
+	for _, pkg := range packages {
+		if slices.ContainsFunc(pkg.Targets, func(t makefile.Target) bool { return t.Name == target }) {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
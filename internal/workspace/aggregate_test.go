@@ -0,0 +1,73 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadPackages(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"Makefile":                  "build:\n\techo root\n",
+		"services/api/Makefile":     "test:\n\tgo test\n\nbuild:\n\tgo build\n",
+		"services/api/common.mk":    "lint:\n\tgolangci-lint run\n",
+		"services/web/GNUmakefile":  "test:\n\tnpm test\n",
+		"services/web/Makefile":     "ignored:\n\techo ignored\n",
+		"tools/fragments/rules.mk":  "fmt:\n\tgofmt -l .\n",
+		"node_modules/pkg/Makefile": "build:\n\techo vendored\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packages, err := LoadPackages(root, DefaultDiscoveryOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dirs []string
+	for _, pkg := range packages {
+		dirs = append(dirs, pkg.Dir)
+		if pkg.Err != nil {
+			t.Errorf("%s: %v", pkg.Dir, pkg.Err)
+		}
+	}
+	if want := []string{".", "services/api", "services/web"}; !slices.Equal(dirs, want) {
+		t.Fatalf("packages = %q, want %q (root first, fragments and excluded dirs skipped)", dirs, want)
+	}
+
+	if web := packages[2]; filepath.Base(web.Path) != "GNUmakefile" || len(web.Targets) != 1 {
+		t.Errorf("expected the GNUmakefile make would read in services/web, got %s with %d targets", web.Path, len(web.Targets))
+	}
+
+	var defining []string
+	for _, pkg := range Defining(packages, "test") {
+		defining = append(defining, pkg.Dir)
+	}
+	if want := []string{"services/api", "services/web"}; !slices.Equal(defining, want) {
+		t.Errorf("Defining(test) = %q, want %q", defining, want)
+	}
+}
+
+func TestQualifiedName(t *testing.T) {
+	tests := []struct {
+		dir, target, want string
+	}{
+		{"services/api", "test", "services/api:test"},
+		{RootPackage, "build", "build"},
+		{"", "build", "build"},
+	}
+	for _, tt := range tests {
+		if got := QualifiedName(tt.dir, tt.target); got != tt.want {
+			t.Errorf("QualifiedName(%q, %q) = %q, want %q", tt.dir, tt.target, got, tt.want)
+		}
+	}
+}
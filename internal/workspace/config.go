@@ -0,0 +1,27 @@
+package workspace
+
+// Config controls Makefile discovery and aggregate (monorepo) mode
+type Config struct {
+	// Aggregate loads every discovered Makefile into one target list
+	Aggregate bool `yaml:"aggregate"`
+
+	// MaxDepth is how many directory levels below the working directory are searched
+	MaxDepth int `yaml:"max_depth"`
+}
+
+// Defaults returns a Config with sensible default values
+func Defaults() *Config {
+	return &Config{
+		Aggregate: false,
+		MaxDepth:  defaultMaxDepth,
+	}
+}
+
+// DiscoveryOptions returns the default discovery settings with the configured depth
+func (c *Config) DiscoveryOptions() DiscoveryOptions {
+	opts := DefaultDiscoveryOptions()
+	if c != nil && c.MaxDepth > 0 {
+		opts.MaxDepth = c.MaxDepth
+	}
+	return opts
+}
@@ -2,12 +2,15 @@ package workspace
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/rshelekhov/lazymake/internal/filelock"
 )
 
 const (
@@ -26,10 +29,14 @@ type Workspace struct {
 
 // Manager handles workspace persistence and operations
 type Manager struct {
-	Workspaces []Workspace `json:"workspaces"`
-	path       string      // Cache file path
+	Workspaces []Workspace      `json:"workspaces"`
+	path       string           // Cache file path
+	pending    []func(*Manager) // Changes since the last Save, replayed onto the file when saving
 }
 
+// errCorrupt is wrapped by readFile when a workspaces file cannot be decoded
+var errCorrupt = errors.New("corrupt workspaces file")
+
 // Load reads workspace data from the cache directory
 // Returns an empty manager on error (graceful degradation)
 func Load() (*Manager, error) {
@@ -38,57 +45,97 @@ func Load() (*Manager, error) {
 		return newEmpty(), fmt.Errorf("failed to get cache path: %w", err)
 	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		// File doesn't exist yet - return empty manager
-		if os.IsNotExist(err) {
-			m := newEmpty()
-			m.path = path
-			return m, nil
-		}
-		return newEmpty(), fmt.Errorf("failed to read workspaces file: %w", err)
-	}
+	return loadFile(path)
+}
 
-	var m Manager
-	if err := json.Unmarshal(data, &m); err != nil {
+// loadFile reads workspace data from path (see Load)
+func loadFile(path string) (*Manager, error) {
+	m, err := readFile(path)
+	switch {
+	case os.IsNotExist(err):
+		// File doesn't exist yet - return empty manager
+		m = newEmpty()
+	case errors.Is(err, errCorrupt):
 		// Corrupt JSON - return empty manager and log warning
 		_, _ = fmt.Fprintf(os.Stderr, "Warning: corrupt workspaces file, resetting: %v\n", err)
-		m = *newEmpty()
-	}
-
-	if m.Workspaces == nil {
-		m.Workspaces = []Workspace{}
+		m = newEmpty()
+	case err != nil:
+		return newEmpty(), fmt.Errorf("failed to read workspaces file: %w", err)
 	}
 
 	// Clean up invalid workspace paths (files that no longer exist)
 	m.cleanupInvalidWorkspaces()
 
 	m.path = path
-	return &m, nil
+	return m, nil
 }
 
 // Save writes workspace data to disk
+// Like history, changes from other lazymake instances are kept: this manager's
+// changes are replayed onto the current file under a lock, then the file is
+// replaced atomically and the manager is updated to the merged result.
 func (m *Manager) Save() error {
 	if m.path == "" {
 		return fmt.Errorf("workspace path not set")
 	}
 
-	// Ensure cache directory exists
-	dir := filepath.Dir(m.path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create cache directory: %w", err)
+	return filelock.WithLock(m.path, func() error {
+		merged, err := m.mergeWithDisk()
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(merged, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal workspaces: %w", err)
+		}
+
+		if err := filelock.WriteAtomic(m.path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write workspaces file: %w", err)
+		}
+
+		m.Workspaces = merged.Workspaces
+		m.pending = nil
+		return nil
+	})
+}
+
+// mergeWithDisk replays this manager's unsaved changes onto the file's current content
+// A missing or corrupt file has nothing to merge, so the manager is written as-is.
+func (m *Manager) mergeWithDisk() (*Manager, error) {
+	disk, err := readFile(m.path)
+	switch {
+	case os.IsNotExist(err), errors.Is(err, errCorrupt):
+		return m, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read workspaces file: %w", err)
 	}
 
-	data, err := json.MarshalIndent(m, "", "  ")
+	disk.cleanupInvalidWorkspaces()
+	for _, change := range m.pending {
+		change(disk)
+	}
+
+	return disk, nil
+}
+
+// readFile reads and decodes a workspaces file
+// Returns an error wrapping errCorrupt if the file is not valid JSON.
+func readFile(path string) (*Manager, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to marshal workspaces: %w", err)
+		return nil, err
 	}
 
-	if err := os.WriteFile(m.path, data, 0600); err != nil {
-		return fmt.Errorf("failed to write workspaces file: %w", err)
+	var m Manager
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorrupt, err)
 	}
 
-	return nil
+	if m.Workspaces == nil {
+		m.Workspaces = []Workspace{}
+	}
+	return &m, nil
 }
 
 // RecordAccess updates or creates a workspace entry when accessed
@@ -99,10 +146,20 @@ func (m *Manager) RecordAccess(makefilePath string) {
 		absPath = makefilePath
 	}
 
+	now := time.Now()
+	m.applyAccess(absPath, now)
+	m.pending = append(m.pending, func(d *Manager) { d.applyAccess(absPath, now) })
+}
+
+// applyAccess records an access to the workspace at absPath
+func (m *Manager) applyAccess(absPath string, now time.Time) {
 	// Find existing workspace
 	for i := range m.Workspaces {
 		if m.Workspaces[i].Path == absPath {
-			m.Workspaces[i].LastAccessed = time.Now()
+			// A replayed access may be older than another instance's
+			if now.After(m.Workspaces[i].LastAccessed) {
+				m.Workspaces[i].LastAccessed = now
+			}
 			m.Workspaces[i].AccessCount++
 			return
 		}
@@ -111,7 +168,7 @@ func (m *Manager) RecordAccess(makefilePath string) {
 	// Create new workspace entry
 	m.Workspaces = append(m.Workspaces, Workspace{
 		Path:         absPath,
-		LastAccessed: time.Now(),
+		LastAccessed: now,
 		AccessCount:  1,
 		IsFavorite:   false,
 	})
@@ -155,7 +212,20 @@ func (m *Manager) ToggleFavorite(makefilePath string) {
 	// Find and toggle
 	for i := range m.Workspaces {
 		if m.Workspaces[i].Path == absPath {
-			m.Workspaces[i].IsFavorite = !m.Workspaces[i].IsFavorite
+			// Replay the resulting state, not the toggle, so a concurrent toggle isn't undone
+			favorite := !m.Workspaces[i].IsFavorite
+			m.setFavorite(absPath, favorite)
+			m.pending = append(m.pending, func(d *Manager) { d.setFavorite(absPath, favorite) })
+			return
+		}
+	}
+}
+
+// setFavorite sets the favorite status of the workspace at absPath, if present
+func (m *Manager) setFavorite(absPath string, favorite bool) {
+	for i := range m.Workspaces {
+		if m.Workspaces[i].Path == absPath {
+			m.Workspaces[i].IsFavorite = favorite
 			return
 		}
 	}
@@ -0,0 +1,169 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// workspaceHelperEnv makes the test binary act as a separate lazymake process
+const workspaceHelperEnv = "LAZYMAKE_WORKSPACE_HELPER_PATH"
+
+// writeMakefiles creates n Makefiles so workspace cleanup keeps them
+func writeMakefiles(t *testing.T, dir string, n int) []string {
+	t.Helper()
+
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("project-%d", i), "Makefile")
+		if err := os.MkdirAll(filepath.Dir(paths[i]), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(paths[i], []byte("build:\n\techo build\n"), 0600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	return paths
+}
+
+func TestSave_MergesConcurrentChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, workspaceFileName)
+	makefiles := writeMakefiles(t, dir, 2)
+
+	first, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+	second, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+
+	first.RecordAccess(makefiles[0])
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// second was loaded before first saved; its save must not drop first's workspace
+	second.RecordAccess(makefiles[1])
+	second.ToggleFavorite(makefiles[1])
+	if err := second.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if len(second.Workspaces) != 2 {
+		t.Fatalf("Expected merged manager to see 2 workspaces, got %d", len(second.Workspaces))
+	}
+
+	loaded, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+	if len(loaded.Workspaces) != 2 {
+		t.Fatalf("Expected 2 workspaces on disk, got %d", len(loaded.Workspaces))
+	}
+	for _, ws := range loaded.Workspaces {
+		if ws.IsFavorite != (ws.Path == makefiles[1]) {
+			t.Errorf("Unexpected favorite status %v for %s", ws.IsFavorite, ws.Path)
+		}
+	}
+}
+
+func TestSave_NoTempFilesLeft(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, workspaceFileName)
+	makefiles := writeMakefiles(t, dir, 1)
+
+	m, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+	m.RecordAccess(makefiles[0])
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	matches, err := filepath.Glob(path + ".tmp-*")
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("Expected no temporary files after save, got %v", matches)
+	}
+}
+
+func TestSave_MultipleProcesses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, workspaceFileName)
+
+	const processes = 4
+	const accessesPerProcess = 10
+	makefiles := writeMakefiles(t, dir, processes)
+
+	cmds := make([]*exec.Cmd, processes)
+	for i := range cmds {
+		cmds[i] = exec.Command(os.Args[0], "-test.run=^TestSave_HelperProcess$")
+		cmds[i].Env = append(os.Environ(),
+			workspaceHelperEnv+"="+path,
+			"LAZYMAKE_HELPER_MAKEFILE="+makefiles[i],
+			"LAZYMAKE_HELPER_SHARED="+makefiles[0],
+			"LAZYMAKE_HELPER_COUNT="+strconv.Itoa(accessesPerProcess))
+		if err := cmds[i].Start(); err != nil {
+			t.Fatalf("Failed to start helper process: %v", err)
+		}
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("Helper process failed: %v", err)
+		}
+	}
+
+	m, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+	if len(m.Workspaces) != processes {
+		t.Fatalf("Expected %d workspaces, got %d", processes, len(m.Workspaces))
+	}
+
+	for _, ws := range m.Workspaces {
+		// Every process accesses its own Makefile and the shared one
+		want := accessesPerProcess
+		if ws.Path == makefiles[0] {
+			want = accessesPerProcess * (processes + 1)
+		}
+		if ws.AccessCount != want {
+			t.Errorf("Expected access count %d for %s, got %d", want, ws.Path, ws.AccessCount)
+		}
+	}
+}
+
+// TestSave_HelperProcess is run as a child process by TestSave_MultipleProcesses
+func TestSave_HelperProcess(t *testing.T) {
+	path := os.Getenv(workspaceHelperEnv)
+	if path == "" {
+		t.Skip("helper process for TestSave_MultipleProcesses")
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LAZYMAKE_HELPER_COUNT"))
+	if err != nil {
+		t.Fatalf("invalid count: %v", err)
+	}
+
+	m, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+
+	for i := 0; i < count; i++ {
+		m.RecordAccess(os.Getenv("LAZYMAKE_HELPER_MAKEFILE"))
+		m.RecordAccess(os.Getenv("LAZYMAKE_HELPER_SHARED"))
+		if err := m.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+}